@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Result formats of validate, policy check, and watch
+const (
+	FormatText   = "text"
+	FormatGitHub = "github" // GitHub Actions workflow annotations
+)
+
+// Annotation levels of GitHub Actions workflow commands
+const (
+	AnnotationNotice  = "notice"
+	AnnotationWarning = "warning"
+	AnnotationError   = "error"
+)
+
+// Annotation is a GitHub Actions workflow annotation, shown in the job log
+// and, when File is set, on the line of that file in pull requests
+type Annotation struct {
+	Level   string
+	Title   string
+	File    string
+	Line    int
+	Message string
+}
+
+// ValidateResultFormat checks a --format value
+func ValidateResultFormat(format string) error {
+	switch format {
+	case FormatText, FormatGitHub:
+		return nil
+	}
+	return fmt.Errorf("invalid format: %s (use text or github)", format)
+}
+
+// annotationData escapes a workflow command message
+var annotationData = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+
+// annotationProperty escapes a workflow command property value
+var annotationProperty = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+
+// String formats the annotation as a workflow command, e.g.
+// "::error file=models.txt,line=3,title=Unknown model::openai/gpt-5o"
+func (a Annotation) String() string {
+	var props []string
+	if a.File != "" {
+		props = append(props, "file="+annotationProperty.Replace(a.File))
+		if a.Line > 0 {
+			props = append(props, fmt.Sprintf("line=%d", a.Line))
+		}
+	}
+	if a.Title != "" {
+		props = append(props, "title="+annotationProperty.Replace(a.Title))
+	}
+	command := "::" + a.Level
+	if len(props) > 0 {
+		command += " " + strings.Join(props, ",")
+	}
+	return command + "::" + annotationData.Replace(a.Message)
+}
+
+// PrintAnnotations writes annotations to stdout, where the runner reads them
+func PrintAnnotations(annotations []Annotation) {
+	for _, a := range annotations {
+		fmt.Println(a)
+	}
+}
+
+// ModelIDLines returns the line number of the first occurrence of each model
+// ID in a file read by ReadModelIDs, so annotations can point at it
+// Returns nil for stdin ("" or "-") or an unreadable file
+func ModelIDLines(path string) map[string]int {
+	if path == "" || path == "-" {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	lines := make(map[string]int)
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+		if id := strings.TrimSpace(line); id != "" {
+			if _, ok := lines[id]; !ok {
+				lines[id] = n
+			}
+		}
+	}
+	return lines
+}
+
+// annotationAt returns an annotation pointing at the line of id in file, if known
+func annotationAt(level, title, message, file string, lines map[string]int, id string) Annotation {
+	a := Annotation{Level: level, Title: title, Message: message}
+	if lines != nil {
+		a.File, a.Line = file, lines[id]
+	}
+	return a
+}
+
+// ValidationAnnotations annotates unknown and renamed IDs as errors and
+// deprecated IDs as warnings
+func ValidationAnnotations(results []ValidationResult, file string) []Annotation {
+	lines := ModelIDLines(file)
+	var annotations []Annotation
+	for _, r := range results {
+		switch r.Status {
+		case ValidationUnknown:
+			message := r.ID + " is not in the catalog"
+			if r.Detail != "" {
+				message += "; did you mean " + r.Detail + "?"
+			}
+			annotations = append(annotations, annotationAt(AnnotationError, "Unknown model", message, file, lines, r.ID))
+		case ValidationRenamed:
+			annotations = append(annotations, annotationAt(AnnotationError, "Renamed model",
+				r.ID+" was renamed to "+r.Detail, file, lines, r.ID))
+		case ValidationDeprecated:
+			annotations = append(annotations, annotationAt(AnnotationWarning, "Deprecated model",
+				r.ID+" expires on "+r.Detail, file, lines, r.ID))
+		}
+	}
+	return annotations
+}
+
+// PolicyAnnotations annotates listed models that violate the policy or are
+// unknown as errors; in catalog mode (no file) violations are warnings
+func PolicyAnnotations(results []PolicyResult, file string, catalogMode bool) []Annotation {
+	lines := ModelIDLines(file)
+	level := AnnotationError
+	if catalogMode {
+		level = AnnotationWarning
+	}
+	var annotations []Annotation
+	for _, r := range results {
+		switch {
+		case !r.Found:
+			annotations = append(annotations, annotationAt(level, "Unknown model",
+				r.ID+" is not in the catalog", file, lines, r.ID))
+		case !r.Passed():
+			annotations = append(annotations, annotationAt(level, "Policy violation",
+				r.ID+": "+strings.Join(r.Violations, "; "), file, lines, r.ID))
+		}
+	}
+	return annotations
+}
+
+// CatalogDiffAnnotations annotates added and changed models as notices and
+// removed models as warnings
+func CatalogDiffAnnotations(diff CatalogDiff) []Annotation {
+	var annotations []Annotation
+	for _, model := range diff.Added {
+		annotations = append(annotations, Annotation{Level: AnnotationNotice, Title: "Model added", Message: model.ID})
+	}
+	for _, model := range diff.Removed {
+		annotations = append(annotations, Annotation{Level: AnnotationWarning, Title: "Model removed", Message: model.ID})
+	}
+	for _, model := range diff.Changed {
+		annotations = append(annotations, Annotation{Level: AnnotationNotice, Title: "Model changed", Message: model.ID})
+	}
+	return annotations
+}
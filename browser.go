@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// OpenBrowser opens url in the default web browser
+func OpenBrowser(url string) error {
+	if dryRun {
+		fmt.Fprintf(os.Stderr, "[dry-run] open %s\n", url)
+		return nil
+	}
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to open browser: %w", err)
+	}
+	// Let the launcher run on its own; it exits once the browser has the URL
+	go cmd.Wait()
+	return nil
+}
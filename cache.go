@@ -0,0 +1,281 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// cacheEndpoint is the path a shared cache server (llmls serve) answers on
+const cacheEndpoint = "/v1/cache"
+
+// GetCacheTTL returns the cache lifetime from LLMLS_CACHE_TTL (e.g. 30m; 0 disables the cache)
+func GetCacheTTL() (time.Duration, error) {
+	value := os.Getenv("LLMLS_CACHE_TTL")
+	if value == "" {
+		return catalog.DefaultCacheTTL, nil
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl < 0 {
+		return 0, fmt.Errorf("invalid LLMLS_CACHE_TTL: %s", value)
+	}
+	return ttl, nil
+}
+
+// GetCacheURL returns the shared cache server URL from LLMLS_CACHE_URL, or "" if unset
+func GetCacheURL() string {
+	return strings.TrimSuffix(os.Getenv("LLMLS_CACHE_URL"), "/")
+}
+
+// GetCacheDir returns the directory of cached vendor catalogs
+func GetCacheDir() (string, error) {
+	dir, err := catalog.DefaultCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "catalog"), nil
+}
+
+// readCache returns the cached body of url and its age
+func readCache(url string) ([]byte, time.Duration, bool) {
+	dir, err := catalog.DefaultCacheDir()
+	if err != nil {
+		return nil, 0, false
+	}
+	return catalog.Cache{Dir: dir}.Read(url)
+}
+
+// writeCache stores the body of url where catalog clients read it
+func writeCache(url string, body []byte) error {
+	dir, err := catalog.DefaultCacheDir()
+	if err != nil {
+		return err
+	}
+	return catalog.Cache{Dir: dir}.Write(url, body)
+}
+
+// fetchBody retrieves a catalog URL directly
+func fetchBody(url string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch models: %w", err)
+	}
+	return fetchRequest(req)
+}
+
+// fetchRequest sends a catalog request and returns the body of a 200 response
+func fetchRequest(req *http.Request) ([]byte, error) {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch models: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &catalog.HTTPStatusError{StatusCode: resp.StatusCode}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return body, nil
+}
+
+// fetchShared retrieves a catalog URL through the shared cache server at
+// cacheURL, authenticating with LLMLS_CACHE_TOKEN if set
+func fetchShared(cacheURL, vendorURL string) ([]byte, error) {
+	client := NewServeClient(cacheURL)
+	client.Token = os.Getenv("LLMLS_CACHE_TOKEN")
+	body, err := client.GetCachedCatalog(vendorURL)
+	if err != nil {
+		return nil, fmt.Errorf("shared cache %s: %w", cacheURL, err)
+	}
+	return body, nil
+}
+
+// fetchUpstream retrieves a catalog URL from the shared cache server when one
+// is configured, falling back to the vendor with a warning if it fails
+func fetchUpstream(url, cacheURL string) ([]byte, error) {
+	if cacheURL != "" {
+		body, err := fetchShared(cacheURL, url)
+		if err == nil {
+			return body, nil
+		}
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	return fetchBody(url)
+}
+
+// flightGroup coalesces concurrent calls with the same key into one, like
+// golang.org/x/sync/singleflight, so a burst of requests fetches once
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+// flightCall is an in-flight or completed flightGroup call
+type flightCall struct {
+	wg   sync.WaitGroup
+	body []byte
+	err  error
+}
+
+// Do runs fn once for concurrent callers with the same key and gives all of them its result
+func (g *flightGroup) Do(key string, fn func() ([]byte, error)) ([]byte, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		call.wg.Wait()
+		return call.body, call.err
+	}
+	call := &flightCall{}
+	call.wg.Add(1)
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	call.body, call.err = fn()
+	call.wg.Done()
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	return call.body, call.err
+}
+
+// catalogFlights coalesces concurrent refreshes of the same catalog URL
+var catalogFlights flightGroup
+
+// refreshCache fetches a catalog URL and stores it, sharing one fetch among
+// concurrent callers
+func refreshCache(url, cacheURL string) ([]byte, error) {
+	return catalogFlights.Do(url, func() ([]byte, error) {
+		body, err := fetchUpstream(url, cacheURL)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeCache(url, body); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		return body, nil
+	})
+}
+
+// cachedGet returns the body of a catalog URL from the local cache, the
+// shared cache server at cacheURL, or the vendor, in that order, storing
+// fetched responses locally
+func cachedGet(url, cacheURL string) ([]byte, error) {
+	ttl, err := GetCacheTTL()
+	if err != nil {
+		return nil, err
+	}
+	// Record and replay sessions must see every catalog request
+	if ttl == 0 || !catalog.IsCacheableURL(url) || sessionActive {
+		return catalogFlights.Do(url, func() ([]byte, error) { return fetchUpstream(url, cacheURL) })
+	}
+	if body, age, ok := readCache(url); ok && age < ttl {
+		return body, nil
+	}
+	return refreshCache(url, cacheURL)
+}
+
+// Cache states reported by ServeCached
+const (
+	CacheHit   = "hit"
+	CacheStale = "stale"
+	CacheMiss  = "miss"
+)
+
+// ServeCached returns the body of a catalog URL for the shared cache server
+// A stale copy is returned at once while a single background refresh updates
+// it (stale-while-revalidate), so clients never wait on or stampede the vendor
+// once the catalog has been fetched
+func ServeCached(url string) ([]byte, string, error) {
+	ttl, err := GetCacheTTL()
+	if err != nil {
+		return nil, "", err
+	}
+	if ttl == 0 {
+		body, err := catalogFlights.Do(url, func() ([]byte, error) { return fetchBody(url) })
+		return body, CacheMiss, err
+	}
+
+	body, age, ok := readCache(url)
+	switch {
+	case ok && age < ttl:
+		return body, CacheHit, nil
+	case ok:
+		go refreshCache(url, "")
+		return body, CacheStale, nil
+	}
+	body, err = refreshCache(url, "")
+	return body, CacheMiss, err
+}
+
+// CachedGet returns the body of a vendor catalog URL, using the local cache
+// and the shared cache server at LLMLS_CACHE_URL before the vendor API
+func CachedGet(url string) ([]byte, error) {
+	return cachedGet(url, GetCacheURL())
+}
+
+// WarmResult is the outcome of refreshing one cached URL
+type WarmResult struct {
+	URL    string
+	From   string // shared cache or vendor
+	Models int
+	Error  error
+}
+
+// WarmCache refetches every cacheable URL, ignoring cached copies, and stores
+// the responses so the following commands are served locally
+func WarmCache() []WarmResult {
+	cacheURL := GetCacheURL()
+	var results []WarmResult
+	for _, u := range catalog.CacheableURLs() {
+		result := WarmResult{URL: u, From: "vendor"}
+		var body []byte
+		var err error
+		if cacheURL != "" {
+			if body, err = fetchShared(cacheURL, u); err == nil {
+				result.From = "shared cache"
+			} else {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		}
+		if body == nil {
+			body, err = fetchBody(u)
+		}
+		if err == nil {
+			var models []catalog.Model
+			if models, err = catalog.ParseOpenRouterModels(body); err == nil {
+				result.Models = len(models)
+				err = writeCache(u, body)
+			}
+		}
+		result.Error = err
+		results = append(results, result)
+	}
+	return results
+}
+
+// ClearCache removes every cached catalog
+func ClearCache() error {
+	dir, err := GetCacheDir()
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to clear cache: %w", err)
+	}
+	return nil
+}
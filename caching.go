@@ -0,0 +1,26 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// SupportsCaching reports whether a model prices prompt cache reads or writes,
+// which is how OpenRouter marks models with prompt caching
+func SupportsCaching(model catalog.Model) bool {
+	return model.Pricing.InputCacheRead != "" || model.Pricing.InputCacheWrite != ""
+}
+
+// FormatCachePricing renders cache pricing per 1K prompt tokens, e.g.
+// "$0.000500 / 1K cache reads, $0.006250 / 1K cache writes"
+func FormatCachePricing(pricing catalog.Pricing) string {
+	var parts []string
+	if pricing.InputCacheRead != "" {
+		parts = append(parts, FormatUSD(FormatPrice(pricing.InputCacheRead))+" / 1K cache reads")
+	}
+	if pricing.InputCacheWrite != "" {
+		parts = append(parts, FormatUSD(FormatPrice(pricing.InputCacheWrite))+" / 1K cache writes")
+	}
+	return strings.Join(parts, ", ")
+}
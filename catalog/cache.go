@@ -0,0 +1,103 @@
+package catalog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultCacheTTL is how long cached vendor catalogs are reused
+const DefaultCacheTTL = 10 * time.Minute
+
+// DefaultCacheDir returns the llmls directory of the user cache directory
+func DefaultCacheDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+	return filepath.Join(cacheDir, "llmls"), nil
+}
+
+// CacheableURLs are the vendor catalog URLs kept in the cache, including the
+// rankings and per-category catalogs; they need no credentials, so a shared cache server
+// may fetch them on behalf of clients
+func CacheableURLs() []string {
+	urls := []string{OpenRouterModelsURL, OpenRouterModelsURL + "?output_modalities=all", openRouterRankingsURL}
+	for _, category := range openRouterCategories {
+		urls = append(urls, categoryURL(category))
+	}
+	return urls
+}
+
+// IsCacheableURL reports whether url is one of CacheableURLs
+func IsCacheableURL(url string) bool {
+	for _, u := range CacheableURLs() {
+		if u == url {
+			return true
+		}
+	}
+	return false
+}
+
+// Cache holds vendor catalog responses in the catalog directory of Dir
+type Cache struct {
+	Dir string
+}
+
+// File returns the cache file of a URL
+func (c Cache) File(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.Dir, "catalog", hex.EncodeToString(sum[:])[:16]+".json")
+}
+
+// Read returns the cached body of url and its age
+func (c Cache) Read(url string) ([]byte, time.Duration, bool) {
+	path := c.File(url)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, 0, false
+	}
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, false
+	}
+	return body, time.Since(info.ModTime()), true
+}
+
+// Write stores the body of url, replacing the file atomically so
+// concurrent readers never see a partial catalog
+func (c Cache) Write(url string, body []byte) error {
+	path := c.File(url)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return writeFileAtomic(path, body)
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it into place
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package catalog
+
+import (
+	"cmp"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// openRouterCategories are the use-case categories OpenRouter ranks models in,
+// accepted by its catalog API as ?category=
+var openRouterCategories = []string{
+	"programming", "roleplay", "marketing", "marketing/seo", "technology", "science",
+	"translation", "legal", "finance", "health", "trivia", "academia",
+}
+
+// ValidateCategory checks a --category value
+func ValidateCategory(category string) error {
+	if category == "" {
+		return nil
+	}
+	for _, known := range openRouterCategories {
+		if category == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown category: %s (expected %s)", category, strings.Join(openRouterCategories, ", "))
+}
+
+// categoryURL returns the catalog URL of the models OpenRouter ranks in a category
+func categoryURL(category string) string {
+	return modelsURL("v1", url.Values{"category": {category}})
+}
+
+// FetchCategoryModelIDs returns the IDs of the models OpenRouter ranks in a
+// category, through the catalog cache
+func (c *Client) FetchCategoryModelIDs(category string) (map[string]bool, error) {
+	models, err := c.fetchOpenRouter(url.Values{"category": {category}})
+	if err != nil {
+		return nil, fmt.Errorf("category %s: %w", category, err)
+	}
+	ids := make(map[string]bool, len(models))
+	for _, model := range models {
+		ids[model.ID] = true
+	}
+	return ids, nil
+}
+
+// FilterModelsByCategory returns models OpenRouter ranks in a category; local
+// models are never categorized
+// If category is empty, returns all models
+func (c *Client) FilterModelsByCategory(models []Model, category string) ([]Model, error) {
+	if category == "" {
+		return models, nil
+	}
+	ids, err := c.FetchCategoryModelIDs(category)
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []Model
+	for _, model := range models {
+		if ids[model.ID] {
+			filtered = append(filtered, model)
+		}
+	}
+	return filtered, nil
+}
+
+// FillCategories sets the categories of each model, fetching every category
+// concurrently; categories that cannot be fetched are skipped with one warning
+func (c *Client) FillCategories(models []Model) {
+	members := make([]map[string]bool, len(openRouterCategories))
+	errs := make([]error, len(openRouterCategories))
+	var wg sync.WaitGroup
+	for i, category := range openRouterCategories {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			members[i], errs[i] = c.FetchCategoryModelIDs(category)
+		}()
+	}
+	wg.Wait()
+
+	var failed []string
+	var first error
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, openRouterCategories[i])
+			first = cmp.Or(first, err)
+		}
+	}
+	if first != nil {
+		c.logger.Printf("%d of %d categories could not be fetched (%s): %v", len(failed), len(openRouterCategories), strings.Join(failed, ", "), first)
+	}
+
+	for i := range models {
+		models[i].Categories = nil
+		for c, category := range openRouterCategories {
+			if members[c][models[i].ID] {
+				models[i].Categories = append(models[i].Categories, category)
+			}
+		}
+	}
+}
@@ -0,0 +1,293 @@
+// Package catalog retrieves the model catalogs of OpenRouter, shared llmls
+// catalogs, Replicate, and local Ollama, llama.cpp, and TGI servers, and
+// filters and orders them as the llmls listing does
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Client queries the model catalog with its own HTTP client, cache,
+// sources, and logger, so an embedding application can run several
+// differently configured clients side by side
+// The zero configuration (NewClient()) reads OpenRouter through the llmls
+// cache directory; local servers and other sources are only queried when
+// set with WithSources
+// A Client is safe for concurrent use: queries read an in-memory catalog,
+// and once it is older than the cache lifetime they keep reading it while
+// one background refresh replaces it
+type Client struct {
+	httpClient *http.Client
+	fetch      func(url string) ([]byte, error) // Replaces the HTTP client and cache for OpenRouter pages
+	cacheDir   string
+	cacheTTL   time.Duration
+	sources    Sources
+	columns    *Columns
+	logger     *log.Logger
+	debug      *log.Logger
+	dryRun     bool
+	drifts     *[]SchemaDrift         // Collects schema drift during fetchCatalog (see checkSchema)
+	stores     map[bool]*CatalogStore // By whether media models are included
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client of every request; local servers and
+// other catalogs are additionally bounded by short timeouts
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) { c.httpClient = httpClient }
+}
+
+// WithFetcher retrieves OpenRouter catalog pages with fetch instead of the
+// HTTP client and on-disk cache, e.g. through a shared cache server
+func WithFetcher(fetch func(url string) ([]byte, error)) Option {
+	return func(c *Client) { c.fetch = fetch }
+}
+
+// WithCacheDir sets the directory of cached catalogs and Hugging Face
+// counts; "" disables the on-disk cache
+func WithCacheDir(dir string) Option {
+	return func(c *Client) { c.cacheDir = dir }
+}
+
+// WithCacheTTL sets how long cached catalogs are reused; 0 disables the cache
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(c *Client) { c.cacheTTL = ttl }
+}
+
+// WithSources sets the remote catalogs and local servers queried besides OpenRouter
+func WithSources(sources Sources) Option {
+	return func(c *Client) { c.sources = sources }
+}
+
+// WithColumns sets the computed columns usable in where expressions and sort keys
+func WithColumns(columns *Columns) Option {
+	return func(c *Client) { c.columns = columns }
+}
+
+// WithLogger sets the logger receiving warnings, e.g. an unreachable remote
+// catalog; nil discards them
+func WithLogger(logger *log.Logger) Option {
+	return func(c *Client) { c.logger = discardNil(logger) }
+}
+
+// WithDebugLogger sets the logger receiving diagnostics, e.g. schema drift
+// tolerated without StrictSchema; nil, the default, discards them
+func WithDebugLogger(logger *log.Logger) Option {
+	return func(c *Client) { c.debug = discardNil(logger) }
+}
+
+// WithDryRun keeps fetching the other sources after a failed OpenRouter
+// request, so a transport that prints requests instead of sending them sees
+// the requests of every source
+func WithDryRun() Option {
+	return func(c *Client) { c.dryRun = true }
+}
+
+// discardNil returns logger, or a logger discarding its output if nil
+func discardNil(logger *log.Logger) *log.Logger {
+	if logger == nil {
+		return log.New(io.Discard, "", 0)
+	}
+	return logger
+}
+
+// NewClient returns a client configured by opts
+func NewClient(opts ...Option) *Client {
+	cacheDir, _ := DefaultCacheDir() // Without a user cache directory nothing is cached
+	c := &Client{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		cacheDir:   cacheDir,
+		cacheTTL:   DefaultCacheTTL,
+		logger:     log.New(os.Stderr, "Warning: ", 0),
+		debug:      discardNil(nil),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.stores = make(map[bool]*CatalogStore, 2)
+	for _, media := range []bool{false, true} {
+		sources := c.sources
+		sources.IncludeMedia = media
+		c.stores[media] = NewCatalogStore(func() ([]Model, error) {
+			models, _, err := c.fetchCatalog(sources)
+			return models, err
+		})
+	}
+	return c
+}
+
+// Query selects and orders models, as the listing flags of the same names do
+type Query struct {
+	Pattern string // Glob over ID or name, or a provider, e.g. "anthropic/*"
+	Where   string // --where expression
+	Series  string
+	Type    string // Model type; image, audio, tts, and stt add media sources
+	Variant string
+	Sort    string // --sort keys, e.g. "provider,created:desc" (default: created)
+	Blend   *Blend // Ratio for price and value sort keys (default: 1:1)
+	Limit   int    // Maximum number of models, 0 for all
+}
+
+// InvalidQueryError reports a Query that cannot be run, as opposed to a
+// failure to retrieve the catalog
+type InvalidQueryError struct {
+	Err error
+}
+
+func (e *InvalidQueryError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *InvalidQueryError) Unwrap() error {
+	return e.Err
+}
+
+// do sends req with the client's HTTP client, bounded by timeout unless it
+// is 0, and returns the body of a 200 response
+func (c *Client) do(req *http.Request, timeout time.Duration) ([]byte, error) {
+	if timeout > 0 {
+		ctx, cancel := context.WithTimeout(req.Context(), timeout)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &HTTPStatusError{StatusCode: resp.StatusCode}
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return body, nil
+}
+
+// getBody retrieves url with do
+func (c *Client) getBody(url string, timeout time.Duration) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.do(req, timeout)
+}
+
+// getJSON retrieves url with do and decodes the JSON body into v
+func (c *Client) getJSON(url string, timeout time.Duration, v interface{}) error {
+	body, err := c.getBody(url, timeout)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+// get retrieves an OpenRouter catalog URL with the fetcher or else the
+// client's HTTP client, through the on-disk cache when enabled
+func (c *Client) get(url string) ([]byte, error) {
+	if c.fetch != nil {
+		return c.fetch(url)
+	}
+
+	cache := Cache{Dir: c.cacheDir}
+	cacheable := c.cacheTTL > 0 && c.cacheDir != "" && IsCacheableURL(url)
+	if cacheable {
+		if body, age, ok := cache.Read(url); ok && age < c.cacheTTL {
+			return body, nil
+		}
+	}
+
+	body, err := c.getBody(url, 0)
+	var statusErr *HTTPStatusError
+	switch {
+	case errors.As(err, &statusErr):
+		return nil, err
+	case err != nil:
+		return nil, fmt.Errorf("failed to fetch models: %w", err)
+	}
+
+	if cacheable {
+		if err := cache.Write(url, body); err != nil {
+			c.logger.Print(err)
+		}
+	}
+	return body, nil
+}
+
+// Catalog retrieves every model from the configured sources, replacing the
+// in-memory catalog
+func (c *Client) Catalog() ([]Model, error) {
+	v, err := c.stores[c.sources.IncludeMedia].Refresh()
+	if err != nil {
+		return nil, err
+	}
+	return v.ModelsCopy(), nil
+}
+
+// FetchCatalog retrieves every model from the configured sources, leaving
+// the in-memory catalog as it is; it also returns the names of the sources
+// that answered (see ModelSourceName)
+func (c *Client) FetchCatalog() ([]Model, []string, error) {
+	return c.fetchCatalog(c.sources)
+}
+
+// Models returns the models matching q, in order, from the in-memory catalog,
+// fetching it on first use (see CatalogStore.Get)
+func (c *Client) Models(q Query) ([]Model, error) {
+	if q.Type != "" && !IsModelType(q.Type) {
+		return nil, &InvalidQueryError{fmt.Errorf("unknown model type: %s", q.Type)}
+	}
+	if err := ValidateVariant(q.Variant); err != nil {
+		return nil, &InvalidQueryError{err}
+	}
+	var where *WhereFilter
+	if q.Where != "" {
+		var err error
+		where, err = ParseWhere(q.Where, c.columns)
+		if err != nil {
+			return nil, &InvalidQueryError{fmt.Errorf("invalid where expression: %w", err)}
+		}
+	}
+	blend := DefaultBlend
+	if q.Blend != nil {
+		blend = *q.Blend
+	}
+
+	v, err := c.stores[c.sources.IncludeMedia || IsMediaType(q.Type)].Get(c.cacheTTL)
+	if err != nil {
+		return nil, err
+	}
+	models := v.ModelsCopy()
+
+	models = FilterModels(models, q.Pattern)
+	models = FilterModelsBySeries(models, q.Series)
+	models = FilterModelsByType(models, q.Type)
+	models = FilterModelsByVariant(models, q.Variant)
+	models = FilterModelsWhere(models, where)
+	if SortUsesHFStats(q.Sort) {
+		c.FillHFStats(models)
+	}
+	if SortUsesRankings(q.Sort) {
+		c.FillRankings(models)
+	}
+	if err := SortModels(models, q.Sort, blend, c.columns); err != nil {
+		return nil, &InvalidQueryError{err}
+	}
+	if q.Limit > 0 && len(models) > q.Limit {
+		models = models[:q.Limit]
+	}
+	return models, nil
+}
@@ -0,0 +1,270 @@
+package catalog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.starlark.net/resolve"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	"go.starlark.net/syntax"
+)
+
+// maxColumnSteps bounds the work of one column for one model, so a runaway
+// comprehension cannot hang a listing
+const maxColumnSteps = 100000
+
+// ColumnDef is the definition of a computed column: a Starlark expression
+// over the fields of a model, e.g. score = ctx/1000 - price*2
+type ColumnDef struct {
+	Name   string
+	Source string
+	Pos    string // Where it is defined, e.g. config:12, for errors
+}
+
+// ComputedColumn is a compiled column, evaluated per model
+type ComputedColumn struct {
+	Name   string
+	Source string
+	prog   *starlark.Program
+	names  []string                   // Identifiers the expression refers to
+	deps   map[string]*ComputedColumn // Earlier columns among names
+}
+
+// Columns are computed columns usable in where expressions and sort keys
+// A nil *Columns defines none
+type Columns struct {
+	byName map[string]*ComputedColumn
+	order  []string
+}
+
+// columnFileOptions are the Starlark dialect of column expressions
+var columnFileOptions = &syntax.FileOptions{}
+
+// ParseColumns compiles column definitions in order; a column may refer to
+// the columns defined before it, the fields of --where, and the Starlark
+// builtins such as min, max, len, and float
+func ParseColumns(defs []ColumnDef) (*Columns, error) {
+	columns := &Columns{byName: make(map[string]*ComputedColumn)}
+	for _, def := range defs {
+		if err := columns.checkName(def.Name); err != nil {
+			return nil, fmt.Errorf("%s: %v", def.Pos, err)
+		}
+		column, err := columns.compile(def)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %v", def.Pos, def.Name, starlarkErrorMessage(err))
+		}
+		columns.byName[def.Name] = column
+		columns.order = append(columns.order, def.Name)
+	}
+	return columns, nil
+}
+
+// compile parses a column expression and resolves its names against the
+// fields and the columns defined so far
+// The expression becomes the program "name = expression", so positions in
+// errors are those of the expression
+func (c *Columns) compile(def ColumnDef) (*ComputedColumn, error) {
+	if strings.TrimSpace(def.Source) == "" {
+		return nil, fmt.Errorf("missing expression")
+	}
+	expr, err := columnFileOptions.ParseExpr(def.Name, def.Source, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	column := &ComputedColumn{Name: def.Name, Source: def.Source, deps: make(map[string]*ComputedColumn)}
+	seen := make(map[string]bool)
+	syntax.Walk(expr, func(n syntax.Node) bool {
+		if ident, ok := n.(*syntax.Ident); ok && !seen[ident.Name] {
+			seen[ident.Name] = true
+			column.names = append(column.names, ident.Name)
+			if dep, ok := c.Lookup(ident.Name); ok {
+				column.deps[ident.Name] = dep
+			}
+		}
+		return true
+	})
+	if seen[def.Name] {
+		return nil, fmt.Errorf("column refers to itself")
+	}
+
+	start, _ := expr.Span()
+	f := &syntax.File{
+		Path:    def.Name,
+		Stmts:   []syntax.Stmt{&syntax.AssignStmt{Op: syntax.EQ, LHS: &syntax.Ident{NamePos: start, Name: def.Name}, RHS: expr}},
+		Options: columnFileOptions,
+	}
+	column.prog, err = starlark.FileProgram(f, func(name string) bool {
+		_, virtual := whereVirtualFields[name]
+		_, defined := c.Lookup(name)
+		return virtual || defined || IsModelField(name)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return column, nil
+}
+
+// starlarkErrorMessage returns the message of a Starlark parse or resolve
+// error without its position, which names the column rather than the file
+func starlarkErrorMessage(err error) string {
+	var syntaxErr syntax.Error
+	if errors.As(err, &syntaxErr) {
+		return syntaxErr.Msg
+	}
+	var resolveErrs resolve.ErrorList
+	if errors.As(err, &resolveErrs) && len(resolveErrs) > 0 {
+		return resolveErrs[0].Msg
+	}
+	return err.Error()
+}
+
+// checkName rejects names that are not identifiers or that would shadow a
+// model field, a virtual field, a keyword, or another column
+func (c *Columns) checkName(name string) error {
+	if name == "" {
+		return fmt.Errorf("missing column name")
+	}
+	if expr, err := columnFileOptions.ParseExpr("", name, 0); err != nil {
+		return fmt.Errorf("invalid column name %q", name)
+	} else if ident, ok := expr.(*syntax.Ident); !ok || ident.Name != name {
+		return fmt.Errorf("invalid column name %q", name)
+	}
+	switch name {
+	case "true", "false", "null", "contains", "in", "None", "True", "False":
+		return fmt.Errorf("column name %q is a keyword", name)
+	}
+	if _, ok := whereVirtualFields[name]; ok {
+		return fmt.Errorf("column %q shadows a field", name)
+	}
+	if IsModelField(name) {
+		return fmt.Errorf("column %q shadows a field", name)
+	}
+	if _, ok := c.Lookup(name); ok {
+		return fmt.Errorf("column %q is defined twice", name)
+	}
+	return nil
+}
+
+// Lookup returns the column of a name
+func (c *Columns) Lookup(name string) (*ComputedColumn, bool) {
+	if c == nil {
+		return nil, false
+	}
+	column, ok := c.byName[name]
+	return column, ok
+}
+
+// Names returns the column names in definition order
+func (c *Columns) Names() []string {
+	if c == nil {
+		return nil
+	}
+	return c.order
+}
+
+// Value evaluates the column for a model; the result is a number, string,
+// boolean, list, or nil, which is also the value of a failing expression
+func (c *ComputedColumn) Value(model Model) interface{} {
+	fields, err := ModelJSONMap(model)
+	if err != nil {
+		return nil
+	}
+	return fromStarlark(c.eval(model, fields))
+}
+
+// whereValue evaluates the column in a where expression
+func (c *ComputedColumn) whereValue(env *whereEnv) interface{} {
+	return fromStarlark(c.eval(env.model, env.fields))
+}
+
+// eval runs the expression with the names it refers to bound to the fields
+// of the model and the values of earlier columns; missing fields are None
+func (c *ComputedColumn) eval(model Model, fields map[string]interface{}) starlark.Value {
+	env := make(starlark.StringDict, len(c.names))
+	for _, name := range c.names {
+		if dep, ok := c.deps[name]; ok {
+			env[name] = dep.eval(model, fields)
+		} else if virtual, ok := whereVirtualFields[name]; ok {
+			env[name] = toStarlark(virtual(model))
+		} else if v, ok := fields[name]; ok {
+			env[name] = toStarlark(v)
+		} else if IsModelField(name) {
+			env[name] = starlark.None
+		}
+	}
+
+	thread := &starlark.Thread{Name: c.Name}
+	thread.SetMaxExecutionSteps(maxColumnSteps)
+	globals, err := c.prog.Init(thread, env)
+	if err != nil {
+		return starlark.None
+	}
+	return globals[c.Name]
+}
+
+// toStarlark converts a JSON value of a model to Starlark: objects become
+// structs, so nested fields read as pricing.prompt, as in --where
+func toStarlark(v interface{}) starlark.Value {
+	switch v := v.(type) {
+	case bool:
+		return starlark.Bool(v)
+	case string:
+		return starlark.String(v)
+	case float64:
+		return starlark.Float(v)
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return starlark.MakeInt64(n)
+		}
+		f, _ := v.Float64()
+		return starlark.Float(f)
+	case []interface{}:
+		elems := make([]starlark.Value, len(v))
+		for i, elem := range v {
+			elems[i] = toStarlark(elem)
+		}
+		return starlark.NewList(elems)
+	case map[string]interface{}:
+		members := make(starlark.StringDict, len(v))
+		for key, elem := range v {
+			members[key] = toStarlark(elem)
+		}
+		return starlarkstruct.FromStringDict(starlarkstruct.Default, members)
+	}
+	return starlark.None
+}
+
+// fromStarlark converts a column value to the values of --where: numbers
+// are float64, lists are []interface{}, and other values are nil
+func fromStarlark(v starlark.Value) interface{} {
+	switch v := v.(type) {
+	case starlark.Bool:
+		return bool(v)
+	case starlark.String:
+		return string(v)
+	case starlark.Int:
+		f, _ := starlark.AsFloat(v)
+		return f
+	case starlark.Float:
+		return float64(v)
+	case starlark.Indexable: // Lists and tuples
+		elems := make([]interface{}, v.Len())
+		for i := range elems {
+			elems[i] = fromStarlark(v.Index(i))
+		}
+		return elems
+	case *starlark.Dict:
+		m := make(map[string]interface{}, v.Len())
+		for _, item := range v.Items() {
+			if key, ok := item[0].(starlark.String); ok {
+				m[string(key)] = fromStarlark(item[1])
+			}
+		}
+		return m
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package catalog
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParseColumns(t *testing.T) {
+	columns, err := ParseColumns([]ColumnDef{
+		{Name: "score", Source: "ctx/1000 - price*2", Pos: "config:1"},
+		{Name: "cheap", Source: "price != None and price < 1", Pos: "config:2"},
+		{Name: "vision", Source: `"image" in architecture.input_modalities`, Pos: "config:3"},
+		{Name: "rank", Source: `"A" if cheap and score > 100 else "B"`, Pos: "config:4"},
+		{Name: "tokens", Source: "top_provider.max_completion_tokens or 0", Pos: "config:5"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(columns.Names(), ","); got != "score,cheap,vision,rank,tokens" {
+		t.Errorf("Names() = %s", got)
+	}
+
+	model := Model{
+		ID:            "meta-llama/llama-3.1-8b-instruct",
+		ContextLength: 131072,
+		Pricing:       Pricing{Prompt: "0.00000002", Completion: "0.00000005"},
+		Architecture:  Architecture{InputModalities: []string{"text"}},
+	}
+	local := Model{ID: "ollama/llama3.1:8b", ContextLength: 8192}
+
+	tests := []struct {
+		column string
+		model  Model
+		want   interface{}
+	}{
+		{"score", model, 131.002},
+		{"cheap", model, true},
+		{"vision", model, false},
+		{"rank", model, "A"},
+		{"tokens", model, 0.0},
+		{"score", local, nil}, // Arithmetic on None
+		{"cheap", local, false},
+		{"rank", local, "B"},
+	}
+	for _, tt := range tests {
+		column, _ := columns.Lookup(tt.column)
+		got := column.Value(tt.model)
+		if f, ok := got.(float64); ok {
+			got = math.Round(f*10000) / 10000
+		}
+		if got != tt.want {
+			t.Errorf("%s(%s) = %v, want %v", tt.column, tt.model.ID, got, tt.want)
+		}
+	}
+
+	f, err := ParseWhere("cheap && score > 100", columns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.Match(model) || f.Match(local) {
+		t.Errorf("where over columns matched %v and %v, want true and false", f.Match(model), f.Match(local))
+	}
+}
+
+func TestParseColumnsErrors(t *testing.T) {
+	tests := []struct {
+		defs []ColumnDef
+		want string
+	}{
+		{[]ColumnDef{{Name: "score", Source: "ctx +", Pos: "config:1"}}, "config:1: score: "},
+		{[]ColumnDef{{Name: "score", Source: "no_such_field * 2", Pos: "config:1"}}, "config:1: score: undefined: no_such_field"},
+		{[]ColumnDef{{Name: "score", Source: "score + 1", Pos: "config:1"}}, "config:1: score: column refers to itself"},
+		{[]ColumnDef{{Name: "a", Source: "b", Pos: "config:1"}, {Name: "b", Source: "1", Pos: "config:2"}}, "config:1: a: undefined: b"},
+		{[]ColumnDef{{Name: "ctx", Source: "1", Pos: "config:1"}}, `config:1: column "ctx" shadows a field`},
+		{[]ColumnDef{{Name: "name", Source: "1", Pos: "config:1"}}, `config:1: column "name" shadows a field`},
+		{[]ColumnDef{{Name: "if", Source: "1", Pos: "config:1"}}, `config:1: invalid column name "if"`},
+		{[]ColumnDef{{Name: "null", Source: "1", Pos: "config:1"}}, `config:1: column name "null" is a keyword`},
+		{[]ColumnDef{{Name: "a", Source: "1", Pos: "config:1"}, {Name: "a", Source: "2", Pos: "config:2"}}, `config:2: column "a" is defined twice`},
+		{[]ColumnDef{{Name: "a", Source: "", Pos: "config:1"}}, "config:1: a: missing expression"},
+	}
+	for _, tt := range tests {
+		_, err := ParseColumns(tt.defs)
+		if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("ParseColumns(%+v) error = %v, want %q", tt.defs, err, tt.want)
+		}
+	}
+}
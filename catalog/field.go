@@ -0,0 +1,123 @@
+package catalog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ModelJSONMap converts a model to a generic map keyed by its JSON field names
+func ModelJSONMap(model Model) (map[string]interface{}, error) {
+	data, err := json.Marshal(model)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep numbers as written so large values are not printed in exponent form
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var m map[string]interface{}
+	if err := decoder.Decode(&m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// LookupPath walks a dotted path such as "pricing.prompt" through nested maps
+func LookupPath(m map[string]interface{}, path string) (interface{}, bool) {
+	var value interface{} = m
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		value, ok = obj[key]
+		if !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// IsModelField reports whether a dotted path names a field of the model JSON,
+// including optional fields omitted from models without them; any key of a
+// map field such as extra is accepted
+func IsModelField(path string) bool {
+	t := reflect.TypeOf(Model{})
+	for _, key := range strings.Split(path, ".") {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		switch t.Kind() {
+		case reflect.Struct:
+			field, ok := jsonField(t, key)
+			if !ok {
+				return false
+			}
+			t = field.Type
+		case reflect.Map:
+			t = t.Elem()
+		case reflect.Interface:
+			return true // Arbitrary JSON, e.g. plugin fields
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// jsonField returns the field of a struct encoded under a JSON name, looking
+// into embedded structs as encoding/json does
+func jsonField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || !field.IsExported() {
+			continue
+		}
+		tagName, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && tagName == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if f, ok := jsonField(embedded, name); ok {
+					return f, true
+				}
+			}
+			continue
+		}
+		if tagName == "" {
+			tagName = field.Name
+		}
+		if tagName == name {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// FormatFieldValue formats a JSON value for shell use: strings on one line,
+// numbers as-is, null as empty, arrays and objects as compact JSON
+func FormatFieldValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.Join(strings.Fields(v), " ")
+	case json.Number:
+		return v.String()
+	case bool:
+		return fmt.Sprintf("%t", v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(data)
+	}
+}
@@ -0,0 +1,192 @@
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// HuggingFaceURL is the Hugging Face Hub
+const HuggingFaceURL = "https://huggingface.co"
+
+// hfTimeout bounds each Hugging Face API request
+const hfTimeout = 10 * time.Second
+
+// hfStatsTTL is how long looked-up Hugging Face counts are reused
+const hfStatsTTL = 24 * time.Hour
+
+// hfStatsSortKeys are the --sort keys ordering by Hugging Face popularity
+var hfStatsSortKeys = []string{"downloads", "likes", "trending"}
+
+// HFStats are the popularity counts of a model's Hugging Face repo
+type HFStats struct {
+	Repo          string  `json:"repo"`
+	Downloads     int     `json:"downloads"` // Last 30 days
+	Likes         int     `json:"likes"`
+	TrendingScore float64 `json:"trending_score"`
+}
+
+// hfStatsEntry is a cached lookup
+type hfStatsEntry struct {
+	Stats     HFStats   `json:"stats"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// hfModelInfo is the part of the Hugging Face model API used for counts
+type hfModelInfo struct {
+	Downloads     int     `json:"downloads"`
+	Likes         int     `json:"likes"`
+	TrendingScore float64 `json:"trendingScore"`
+}
+
+// HFRepo returns the Hugging Face repo of a model whose page or open weights
+// are on the Hub, e.g. TGI models, Ollama models pulled from hf.co, and
+// hosted models with a hugging_face_id, or "" otherwise
+func HFRepo(model Model) string {
+	if model.HuggingFaceID != "" {
+		return model.HuggingFaceID
+	}
+	url := ModelURL(model)
+	for _, prefix := range []string{HuggingFaceURL + "/", "https://hf.co/"} {
+		if repo, ok := strings.CutPrefix(url, prefix); ok {
+			return repo
+		}
+	}
+	return ""
+}
+
+// IsOpenWeights reports whether a model's weights are published: models run
+// on a local server, and hosted models with a hugging_face_id
+func IsOpenWeights(model Model) bool {
+	return model.HuggingFaceID != "" || IsLocalSource(ModelSourceName(model))
+}
+
+// FilterModelsByOpenWeights returns the open-weights models (see IsOpenWeights)
+func FilterModelsByOpenWeights(models []Model) []Model {
+	var filtered []Model
+	for _, model := range models {
+		if IsOpenWeights(model) {
+			filtered = append(filtered, model)
+		}
+	}
+	return filtered
+}
+
+// hfStatsCachePath returns the file caching looked-up counts, or "" without a cache directory
+func (c *Client) hfStatsCachePath() string {
+	if c.cacheDir == "" {
+		return ""
+	}
+	return filepath.Join(c.cacheDir, "hfstats.json")
+}
+
+// loadHFStatsCache reads cached counts; a missing or unreadable cache starts empty
+func (c *Client) loadHFStatsCache() map[string]hfStatsEntry {
+	cache := make(map[string]hfStatsEntry)
+	if path := c.hfStatsCachePath(); path != "" {
+		if data, err := os.ReadFile(path); err == nil {
+			json.Unmarshal(data, &cache)
+		}
+	}
+	return cache
+}
+
+// saveHFStatsCache writes cached counts
+func (c *Client) saveHFStatsCache(cache map[string]hfStatsEntry) error {
+	path := c.hfStatsCachePath()
+	if path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return fmt.Errorf("failed to encode hugging face cache: %w", err)
+	}
+	return writeFileAtomic(path, data)
+}
+
+// FetchHFStats looks up the counts of a Hugging Face repo
+func (c *Client) FetchHFStats(repo string) (HFStats, error) {
+	var info hfModelInfo
+	if err := c.getJSON(HuggingFaceURL+"/api/models/"+repo+"?expand[]=downloads&expand[]=likes&expand[]=trendingScore", hfTimeout, &info); err != nil {
+		return HFStats{}, fmt.Errorf("hugging face: %w", err)
+	}
+	return HFStats{Repo: repo, Downloads: info.Downloads, Likes: info.Likes, TrendingScore: info.TrendingScore}, nil
+}
+
+// FillHFStats sets HFStats on models hosted on Hugging Face, reusing counts
+// looked up within the last day
+// A repo that cannot be looked up is skipped with a warning
+func (c *Client) FillHFStats(models []Model) {
+	cache := c.loadHFStatsCache()
+	updated := false
+
+	for i, model := range models {
+		repo := HFRepo(model)
+		if repo == "" {
+			continue
+		}
+		if cached, ok := cache[repo]; ok && time.Since(cached.CheckedAt) < hfStatsTTL {
+			stats := cached.Stats
+			models[i].HFStats = &stats
+			continue
+		}
+
+		stats, err := c.FetchHFStats(repo)
+		if err != nil {
+			c.logger.Printf("%s: %v", repo, err)
+			continue
+		}
+		cache[repo] = hfStatsEntry{Stats: stats, CheckedAt: time.Now().UTC()}
+		models[i].HFStats = &stats
+		updated = true
+	}
+
+	if updated {
+		if err := c.saveHFStatsCache(cache); err != nil {
+			c.logger.Print(err)
+		}
+	}
+}
+
+// hfPopularity returns a model's Hugging Face count for a sort key, taken
+// from HFStats or else from the same-named field of an enrichment plugin
+// (downloads, likes, trending_score); nil when neither has it
+func hfPopularity(model Model, key string) interface{} {
+	if model.HFStats != nil {
+		switch key {
+		case "downloads":
+			return float64(model.HFStats.Downloads)
+		case "likes":
+			return float64(model.HFStats.Likes)
+		case "trending":
+			return model.HFStats.TrendingScore
+		}
+	}
+	field := key
+	if key == "trending" {
+		field = "trending_score"
+	}
+	if n, ok := whereNumber(model.Extra[field]); ok {
+		return n
+	}
+	return nil
+}
+
+// SortUsesHFStats reports whether a --sort spec orders by a Hugging Face count
+func SortUsesHFStats(spec string) bool {
+	for _, item := range strings.Split(spec, ",") {
+		name, _, _ := strings.Cut(strings.TrimSpace(item), ":")
+		for _, key := range hfStatsSortKeys {
+			if name == key {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package catalog
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Tunings of text generation models
+const (
+	TuningInstruct = "instruct" // Tuned to follow instructions in chat turns
+	TuningBase     = "base"     // Pretrained only, continuing text as given
+)
+
+// baseNameTokens mark pretrained-only models in names, e.g. "Llama 3.1 405B (base)"
+// or gemma-2-9b-pt
+var baseNameTokens = map[string]bool{"base": true, "pt": true, "pretrained": true, "pretrain": true}
+
+// instructNameTokens mark chat-tuned models in names, e.g. gemma-2-9b-it
+var instructNameTokens = map[string]bool{
+	"instruct": true, "chat": true, "it": true, "sft": true, "dpo": true, "orpo": true, "rlhf": true,
+}
+
+// tuningTokenSplit splits names into tokens for the name markers
+var tuningTokenSplit = regexp.MustCompile(`[^a-z0-9]+`)
+
+// ModelTuning classifies a text generation model as instruct or base, with
+// the reason, e.g. "instruct_type llama3"; "" for other model types and for
+// models nothing tells apart
+// Explicit name markers win, then the prompt format OpenRouter reports, then
+// the source: OpenRouter and the Ollama library serve chat-tuned models
+// unless marked otherwise
+func ModelTuning(model Model) (tuning, reason string) {
+	if model.Type != "" && model.Type != TypeChat && model.Type != TypeCompletion {
+		return "", ""
+	}
+
+	names := []string{BaseModelID(model.ID), model.Name, model.HuggingFaceID}
+	var tokens []string
+	for _, name := range names {
+		tokens = append(tokens, tuningTokenSplit.Split(strings.ToLower(name), -1)...)
+	}
+	for _, token := range tokens {
+		if baseNameTokens[token] {
+			return TuningBase, "name"
+		}
+	}
+	source := ModelSourceName(model)
+	if source == "ollama" {
+		// Ollama tags pretrained builds as text, e.g. llama3:8b-text-q4_0
+		_, tag, _ := strings.Cut(strings.ToLower(OllamaModelName(model.ID)), ":")
+		for _, token := range tuningTokenSplit.Split(tag, -1) {
+			if token == "text" {
+				return TuningBase, "ollama tag"
+			}
+		}
+	}
+	for _, token := range tokens {
+		if instructNameTokens[token] {
+			return TuningInstruct, "name"
+		}
+	}
+	if model.Architecture.InstructType != "" {
+		return TuningInstruct, "instruct_type " + model.Architecture.InstructType
+	}
+
+	switch {
+	case model.Type == TypeCompletion:
+		return TuningBase, "completion model"
+	case source == "openrouter":
+		return TuningInstruct, "openrouter chat model"
+	case source == "ollama":
+		return TuningInstruct, "ollama library default"
+	}
+	return "", ""
+}
+
+// FilterModelsByTuning returns models of the given tuning (see ModelTuning);
+// models that cannot be classified are dropped
+// If tuning is empty, returns all models
+func FilterModelsByTuning(models []Model, tuning string) []Model {
+	if tuning == "" {
+		return models
+	}
+
+	var filtered []Model
+	for _, model := range models {
+		if t, _ := ModelTuning(model); t == tuning {
+			filtered = append(filtered, model)
+		}
+	}
+	return filtered
+}
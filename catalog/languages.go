@@ -0,0 +1,156 @@
+package catalog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// languages are the languages llmls knows, by ISO 639-1 code, with the names
+// descriptions mention them by
+var languages = []struct {
+	Code  string
+	Names []string
+}{
+	{"en", []string{"english"}},
+	{"zh", []string{"chinese", "mandarin", "cantonese"}},
+	{"ja", []string{"japanese"}},
+	{"ko", []string{"korean"}},
+	{"fr", []string{"french"}},
+	{"de", []string{"german"}},
+	{"es", []string{"spanish"}},
+	{"pt", []string{"portuguese"}},
+	{"it", []string{"italian"}},
+	{"ru", []string{"russian"}},
+	{"ar", []string{"arabic"}},
+	{"hi", []string{"hindi"}},
+	{"bn", []string{"bengali"}},
+	{"id", []string{"indonesian"}},
+	{"vi", []string{"vietnamese"}},
+	{"th", []string{"thai"}},
+	{"tr", []string{"turkish"}},
+	{"nl", []string{"dutch"}},
+	{"pl", []string{"polish"}},
+	{"tl", []string{"tagalog", "filipino"}},
+	{"sw", []string{"swahili"}},
+}
+
+// languageWord matches a word of a description
+var languageWord = regexp.MustCompile(`[a-z]+`)
+
+// majorLanguages are covered by the multilingual evaluations frontier model
+// vendors publish, e.g. MMMLU
+var majorLanguages = []string{"en", "zh", "ja", "ko", "fr", "de", "es", "pt", "it", "ar", "hi", "bn", "id", "sw"}
+
+// seriesLanguages are the languages model families officially support, by
+// series prefix (see seriesFamilyMatch); the first matching entry applies
+var seriesLanguages = []struct {
+	Series    string
+	Languages []string
+}{
+	{"llama-3.1", []string{"en", "de", "fr", "it", "pt", "hi", "es", "th"}},
+	{"llama-3.2", []string{"en", "de", "fr", "it", "pt", "hi", "es", "th"}},
+	{"llama-3.3", []string{"en", "de", "fr", "it", "pt", "hi", "es", "th"}},
+	{"llama-4", []string{"en", "ar", "fr", "de", "hi", "id", "it", "pt", "es", "tl", "th", "vi"}},
+	{"qwen-2", []string{"en", "zh", "fr", "es", "pt", "de", "it", "ru", "ja", "ko", "vi", "th", "ar"}},
+	{"qwen-3", []string{"en", "zh", "fr", "es", "pt", "de", "it", "ru", "ja", "ko", "vi", "th", "ar", "id", "tr", "nl", "pl"}},
+	{"gemma-3", majorLanguages},
+	{"mistral", []string{"en", "fr", "de", "es", "it"}},
+	{"command", []string{"en", "fr", "es", "it", "de", "pt", "ja", "ko", "zh", "ar"}},
+	{"deepseek", []string{"en", "zh"}},
+	{"gpt-4", majorLanguages},
+	{"gpt-5", majorLanguages},
+	{"claude-3", majorLanguages},
+	{"claude-4", majorLanguages},
+	{"gemini-1", majorLanguages},
+	{"gemini-2", majorLanguages},
+	{"gemini-3", majorLanguages},
+}
+
+// seriesFamilyMatch reports whether series belongs to a family prefix:
+// "gpt-4" matches gpt-4o and gpt-4.1, and "mistral" matches mistral-large,
+// but "qwen-2" does not match qwen-20
+func seriesFamilyMatch(prefix, series string) bool {
+	rest, ok := strings.CutPrefix(series, prefix)
+	return ok && (rest == "" || rest[0] < '0' || rest[0] > '9')
+}
+
+// ModelLanguages returns the languages a model is known to handle, from its
+// family's official support and the languages its description names, in the
+// order of languages; nil when nothing is known
+func ModelLanguages(model Model) []string {
+	found := make(map[string]bool)
+	if series := ModelSeries(model); series != "" {
+		for _, s := range seriesLanguages {
+			if seriesFamilyMatch(s.Series, series) {
+				for _, code := range s.Languages {
+					found[code] = true
+				}
+				break
+			}
+		}
+	}
+	for _, word := range languageWord.FindAllString(strings.ToLower(model.Description), -1) {
+		for _, language := range languages {
+			for _, name := range language.Names {
+				if word == name {
+					found[language.Code] = true
+				}
+			}
+		}
+	}
+
+	var codes []string
+	for _, language := range languages {
+		if found[language.Code] {
+			codes = append(codes, language.Code)
+		}
+	}
+	return codes
+}
+
+// SetModelLanguages fills in Languages for every model
+func SetModelLanguages(models []Model) {
+	for i := range models {
+		models[i].Languages = ModelLanguages(models[i])
+	}
+}
+
+// ParseLanguage resolves a --language value, a code such as ja or a name
+// such as Japanese, to its code
+func ParseLanguage(s string) (string, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	codes := make([]string, len(languages))
+	for i, language := range languages {
+		if s == language.Code {
+			return language.Code, nil
+		}
+		for _, name := range language.Names {
+			if s == name {
+				return language.Code, nil
+			}
+		}
+		codes[i] = language.Code
+	}
+	return "", fmt.Errorf("unknown language: %s (expected %s)", s, strings.Join(codes, ", "))
+}
+
+// FilterModelsByLanguage returns models known to handle a language (see
+// ModelLanguages); models with no language data are dropped
+// If code is empty, returns all models
+func FilterModelsByLanguage(models []Model, code string) []Model {
+	if code == "" {
+		return models
+	}
+
+	var filtered []Model
+	for _, model := range models {
+		for _, language := range model.Languages {
+			if language == code {
+				filtered = append(filtered, model)
+				break
+			}
+		}
+	}
+	return filtered
+}
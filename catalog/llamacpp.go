@@ -0,0 +1,119 @@
+package catalog
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// llamaCppPages are llama.cpp's web pages
+var llamaCppPages = ProviderPages{Home: "https://github.com/ggml-org/llama.cpp"}
+
+// LlamaCppModel represents a model entry from the OpenAI-compatible /v1/models endpoint
+type LlamaCppModel struct {
+	ID      string `json:"id"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+	Meta    struct {
+		NCtxTrain int   `json:"n_ctx_train"`
+		NParams   int64 `json:"n_params"`
+		Size      int64 `json:"size"`
+	} `json:"meta"`
+}
+
+// LlamaCppModelsResponse represents the /v1/models response
+type LlamaCppModelsResponse struct {
+	Data []LlamaCppModel `json:"data"`
+}
+
+// LlamaCppProps represents the llama.cpp /props response
+type LlamaCppProps struct {
+	ModelPath                 string `json:"model_path"`
+	NGPULayers                int    `json:"n_gpu_layers"`
+	DefaultGenerationSettings struct {
+		NCtx int `json:"n_ctx"`
+	} `json:"default_generation_settings"`
+}
+
+// KoboldCppContextResponse represents the KoboldCpp /api/extra/true_max_context_length response
+type KoboldCppContextResponse struct {
+	Value int `json:"value"`
+}
+
+// FetchLlamaCppModels retrieves the loaded model from a llama.cpp or KoboldCpp server
+// Callers listing models ignore errors so an unavailable server is skipped silently
+func (c *Client) FetchLlamaCppModels(host string) ([]Model, error) {
+	var modelsResp LlamaCppModelsResponse
+	if err := c.getJSON(host+"/v1/models", localTimeout, &modelsResp); err != nil {
+		return nil, fmt.Errorf("failed to fetch models: %w", err)
+	}
+
+	// Server-wide settings: llama.cpp exposes /props, KoboldCpp its own extra API
+	var props LlamaCppProps
+	if err := c.getJSON(host+"/props", localTimeout, &props); err != nil {
+		var kobold KoboldCppContextResponse
+		if err := c.getJSON(host+"/api/extra/true_max_context_length", localTimeout, &kobold); err == nil {
+			props.DefaultGenerationSettings.NCtx = kobold.Value
+		}
+	}
+
+	models := make([]Model, 0, len(modelsResp.Data))
+	for _, lm := range modelsResp.Data {
+		name := llamaCppModelName(lm.ID)
+		contextSize := props.DefaultGenerationSettings.NCtx
+		if contextSize == 0 {
+			contextSize = lm.Meta.NCtxTrain
+		}
+
+		model := Model{
+			ID:            "llamacpp/" + name,
+			Name:          name,
+			Created:       lm.Created, // 0 when the server does not report it
+			ContextLength: contextSize,
+			Description:   buildLlamaCppDescription(lm, contextSize),
+			// Store llama.cpp-specific data for detailed view
+			LlamaCppDetails: &LlamaCppDetails{
+				Server:      host,
+				ModelPath:   props.ModelPath,
+				ContextSize: contextSize,
+				GPULayers:   props.NGPULayers,
+				Size:        lm.Meta.Size,
+			},
+		}
+		models = append(models, model)
+	}
+
+	return models, nil
+}
+
+// llamaCppModelName derives a short model name from a model ID that may be a file path
+func llamaCppModelName(id string) string {
+	// KoboldCpp reports "koboldcpp/<name>"
+	id = strings.TrimPrefix(id, "koboldcpp/")
+	// llama.cpp reports the GGUF path when no alias is set
+	name := filepath.Base(id)
+	return strings.TrimSuffix(name, ".gguf")
+}
+
+// buildLlamaCppDescription creates a description from llama.cpp model details
+func buildLlamaCppDescription(lm LlamaCppModel, contextSize int) string {
+	desc := ""
+
+	if contextSize > 0 {
+		desc = "ctx " + formatCount(contextSize)
+	}
+
+	sizeGB := float64(lm.Meta.Size) / (1024 * 1024 * 1024)
+	if sizeGB > 0 {
+		if desc != "" {
+			desc += " - "
+		}
+		desc += fmt.Sprintf("%.1f GB", sizeGB)
+	}
+
+	if desc == "" {
+		desc = "llama.cpp local model"
+	}
+
+	return desc
+}
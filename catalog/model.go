@@ -0,0 +1,271 @@
+package catalog
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Model represents an OpenRouter model
+type Model struct {
+	ID                  string                 `json:"id"`
+	Name                string                 `json:"name"`
+	Created             int64                  `json:"created"`
+	Description         string                 `json:"description"`
+	ContextLength       int                    `json:"context_length"`
+	Architecture        Architecture           `json:"architecture"`
+	Pricing             Pricing                `json:"pricing"`
+	TopProvider         TopProvider            `json:"top_provider"`
+	ExpirationDate      string                 `json:"expiration_date"`                // Set when the model is scheduled for removal
+	HuggingFaceID       string                 `json:"hugging_face_id"`                // Open-weights repo of a hosted model, e.g. meta-llama/Llama-3.1-8B-Instruct
+	SupportedParameters []string               `json:"supported_parameters,omitempty"` // Request parameters accepted, e.g. temperature or tools
+	DefaultParameters   map[string]interface{} `json:"default_parameters,omitempty"`   // Provider defaults of unset parameters, e.g. temperature
+	Type                string                 `json:"type"`                           // chat, completion, embedding, rerank, image, or audio
+	URL                 string                 `json:"url"`                            // Model page, filled in by SetModelURLs
+	MatchedBy           string                 `json:"-"`                              // Match criterion shown by --explain
+	OllamaDetails       *OllamaDetails         `json:"-"`                              // Ollama-specific details (not from JSON)
+	LlamaCppDetails     *LlamaCppDetails       `json:"-"`                              // llama.cpp-specific details (not from JSON)
+	TGIDetails          *TGIDetails            `json:"-"`                              // TGI-specific details (not from JSON)
+	RateLimits          *RateLimits            `json:"-"`                              // Probed first-party rate limits (not from JSON)
+	Uptime              *ModelUptime           `json:"-"`                              // Upstream provider uptimes (not from JSON)
+	GGUFCrossRef        *GGUFCrossRef          `json:"-"`                              // Related Hugging Face GGUF builds (not from JSON)
+	HFStats             *HFStats               `json:"hf_stats,omitempty"`             // Hugging Face popularity, filled in by FillHFStats
+	Languages           []string               `json:"languages,omitempty"`            // Languages known to be handled, filled in by SetModelLanguages
+	Categories          []string               `json:"categories,omitempty"`           // OpenRouter use-case categories, filled in by FillCategories
+	PopularityRank      int                    `json:"popularity_rank,omitempty"`      // OpenRouter weekly rank, filled in by FillRankings
+	Sources             []ModelSource          `json:"sources,omitempty"`              // Sources merged by --dedupe
+	Extra               map[string]interface{} `json:"extra,omitempty"`                // Fields added by enrichment plugins
+}
+
+// Architecture represents model architecture details
+type Architecture struct {
+	Modality         string   `json:"modality"`
+	InputModalities  []string `json:"input_modalities"`
+	OutputModalities []string `json:"output_modalities"`
+	Tokenizer        string   `json:"tokenizer"`
+	InstructType     string   `json:"instruct_type"` // Chat prompt format, e.g. llama3; empty for base and proprietary models
+}
+
+// Pricing represents model pricing information
+type Pricing struct {
+	Prompt            string `json:"prompt"`
+	Completion        string `json:"completion"`
+	Request           string `json:"request"`
+	Image             string `json:"image"`
+	Audio             string `json:"audio"` // Per audio input token
+	WebSearch         string `json:"web_search"`
+	InternalReasoning string `json:"internal_reasoning"`
+	InputCacheRead    string `json:"input_cache_read"`  // Per prompt token read from the prompt cache
+	InputCacheWrite   string `json:"input_cache_write"` // Per prompt token written to the prompt cache
+}
+
+// TopProvider represents top provider details
+type TopProvider struct {
+	ContextLength       int  `json:"context_length"`
+	MaxCompletionTokens int  `json:"max_completion_tokens"`
+	IsModerated         bool `json:"is_moderated"`
+}
+
+// OllamaDetails represents Ollama-specific model details
+type OllamaDetails struct {
+	Size              int64
+	Digest            string
+	Format            string
+	Family            string
+	ParameterSize     string
+	QuantizationLevel string
+}
+
+// LlamaCppDetails represents llama.cpp/KoboldCpp server details
+type LlamaCppDetails struct {
+	Server      string
+	ModelPath   string
+	ContextSize int
+	GPULayers   int
+	Size        int64
+}
+
+// TGIDetails represents text-generation-inference server details
+type TGIDetails struct {
+	Server         string
+	MaxInputTokens int
+	MaxTotalTokens int
+	Quantization   string
+	Dtype          string
+	DeviceType     string
+	Version        string
+}
+
+// ModelsResponse represents the API response structure
+type ModelsResponse struct {
+	Data []Model `json:"data"`
+}
+
+// GlobMatch performs case-insensitive glob pattern matching
+// Supports * (any sequence including /) and ? (single character including /)
+func GlobMatch(pattern, str string) bool {
+	// Convert pattern to regex
+	// Escape special regex characters except * and ?
+	regexPattern := regexp.QuoteMeta(pattern)
+	// Replace escaped glob wildcards with regex equivalents
+	regexPattern = strings.ReplaceAll(regexPattern, "\\*", ".*")
+	regexPattern = strings.ReplaceAll(regexPattern, "\\?", ".")
+	// Anchor pattern to match entire string
+	regexPattern = "^" + regexPattern + "$"
+
+	// Case-insensitive match
+	re, err := regexp.Compile("(?i)" + regexPattern)
+	if err != nil {
+		// If pattern is invalid, fall back to case-insensitive exact match
+		return strings.EqualFold(pattern, str)
+	}
+	return re.MatchString(str)
+}
+
+// Match criteria reported by MatchReason
+const (
+	MatchIDGlob        = "ID glob"
+	MatchNameGlob      = "name glob"
+	MatchProviderExact = "provider exact"
+)
+
+// MatchReason returns which criterion matched model against pattern, or "" if none
+// Criteria are checked in order: model ID glob, model name glob, provider exact match
+func MatchReason(model Model, pattern string) string {
+	switch {
+	case GlobMatch(pattern, model.ID):
+		return MatchIDGlob
+	case GlobMatch(pattern, model.Name):
+		return MatchNameGlob
+	case CanonicalProvider(pattern) == ModelProvider(model):
+		return MatchProviderExact
+	}
+	return ""
+}
+
+// FilterModels filters models by model ID using glob patterns
+// Supports * (any sequence) and ? (single character) in patterns
+// Also supports exact match against provider name (case-insensitive, aliases
+// such as meta for meta-llama included)
+// If pattern is empty, returns all models
+func FilterModels(models []Model, pattern string) []Model {
+	// If no pattern, return all models
+	if pattern == "" {
+		return models
+	}
+
+	var filtered []Model
+	for _, model := range models {
+		if MatchReason(model, pattern) != "" {
+			filtered = append(filtered, model)
+		}
+	}
+
+	return filtered
+}
+
+// SortModelsByCreatedDesc sorts models by creation date in descending order
+func SortModelsByCreatedDesc(models []Model) {
+	sort.Slice(models, func(i, j int) bool {
+		return models[i].Created > models[j].Created
+	})
+}
+
+// ExtractProvider extracts provider name from model ID
+func ExtractProvider(modelID string) string {
+	if idx := strings.Index(modelID, "/"); idx > 0 {
+		return modelID[:idx]
+	}
+	return "Unknown"
+}
+
+// RateLimits are a model's per-minute throughput limits for the caller's API key
+// Zero means the provider did not report that limit
+type RateLimits struct {
+	Requests     int64     `json:"requests"`
+	Tokens       int64     `json:"tokens"`
+	InputTokens  int64     `json:"input_tokens"`
+	OutputTokens int64     `json:"output_tokens"`
+	CheckedAt    time.Time `json:"checked_at"`
+	Error        string    `json:"-"` // Why the limits could not be read
+}
+
+// ModelUptime is the last-30-minute availability of a model's upstream providers
+type ModelUptime struct {
+	Providers []ProviderUptime // Best first; providers without a reported uptime are omitted
+	Error     string           // Set when the endpoints could not be fetched
+}
+
+// ProviderUptime is one upstream provider's uptime in percent
+type ProviderUptime struct {
+	Name   string
+	Uptime float64
+}
+
+// Best returns the highest provider uptime; ok is false when none was reported
+func (u ModelUptime) Best() (float64, bool) {
+	if len(u.Providers) == 0 {
+		return 0, false
+	}
+	return u.Providers[0].Uptime, true
+}
+
+// GGUFBuild is a GGUF file on Hugging Face
+type GGUFBuild struct {
+	Repo  string
+	File  string
+	Quant string
+	URL   string
+}
+
+// GGUFCrossRef lists GGUF builds on Hugging Face related to a local model
+type GGUFCrossRef struct {
+	Query     string
+	Installed string // Installed quantization, if known
+	Repos     []string
+	Builds    []GGUFBuild // Files of the top repo in other quantizations
+	Error     string
+}
+
+// ModelSource is one source of a model merged by --dedupe
+type ModelSource struct {
+	ID      string  `json:"id"`
+	Source  string  `json:"source"` // openrouter, ollama, tgi, llamacpp, or replicate
+	Pricing Pricing `json:"pricing"`
+}
+
+// ParsePrice parses a per-token price string, returning 0 for empty or invalid values
+func ParsePrice(price string) float64 {
+	p := 0.0
+	fmt.Sscanf(price, "%f", &p)
+	return p
+}
+
+// formatCount formats a count in descriptions with comma thousands
+// separators, e.g. 131,072; descriptions do not follow the display locale
+func formatCount(n int) string {
+	s := strconv.Itoa(n)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	var b strings.Builder
+	for i, c := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(c)
+	}
+	return sign + b.String()
+}
+
+// ModelSuffix returns the part of a model ID after the provider
+func ModelSuffix(modelID string) string {
+	if idx := strings.Index(modelID, "/"); idx >= 0 {
+		return modelID[idx+1:]
+	}
+	return modelID
+}
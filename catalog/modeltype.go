@@ -0,0 +1,103 @@
+package catalog
+
+import (
+	"strings"
+)
+
+// Model types
+const (
+	TypeChat       = "chat"
+	TypeCompletion = "completion"
+	TypeEmbedding  = "embedding"
+	TypeRerank     = "rerank"
+	TypeImage      = "image"
+	TypeAudio      = "audio"
+	TypeTTS        = "tts" // Text-to-speech
+	TypeSTT        = "stt" // Speech-to-text
+)
+
+// ModelTypes lists the valid --type values
+var ModelTypes = []string{TypeChat, TypeCompletion, TypeEmbedding, TypeRerank, TypeImage, TypeAudio, TypeTTS, TypeSTT}
+
+// InferModelType guesses a model's type from its output modalities and ID
+// Used for models whose source does not report a type
+func InferModelType(model Model) string {
+	id := strings.ToLower(model.ID)
+	switch {
+	case strings.Contains(id, "rerank"):
+		return TypeRerank
+	case strings.Contains(id, "embed"):
+		return TypeEmbedding
+	case strings.Contains(id, "whisper"):
+		return TypeSTT
+	case strings.Contains(id, "tts"):
+		return TypeTTS
+	}
+
+	for _, modality := range model.Architecture.OutputModalities {
+		switch modality {
+		case "embeddings":
+			return TypeEmbedding
+		case "image":
+			return TypeImage
+		case "audio":
+			return TypeAudio
+		}
+	}
+
+	return TypeChat
+}
+
+// SetModelTypes fills in Type for models whose source did not set it, and
+// marks base models (see ModelTuning) as completion models
+func SetModelTypes(models []Model) {
+	for i := range models {
+		if models[i].Type == "" {
+			models[i].Type = InferModelType(models[i])
+		}
+		if tuning, _ := ModelTuning(models[i]); models[i].Type == TypeChat && tuning == TuningBase {
+			models[i].Type = TypeCompletion
+		}
+	}
+}
+
+// IsModelType reports whether t is a known model type
+func IsModelType(t string) bool {
+	for _, known := range ModelTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// IsMediaType reports whether t selects image or speech models, which are only
+// fetched when requested
+func IsMediaType(t string) bool {
+	return t == TypeImage || t == TypeAudio || t == TypeTTS || t == TypeSTT
+}
+
+// matchModelType reports whether a model of type actual matches the requested type
+// "audio" also matches the speech types tts and stt
+func matchModelType(requested, actual string) bool {
+	if requested == TypeAudio {
+		return actual == TypeAudio || actual == TypeTTS || actual == TypeSTT
+	}
+	return requested == actual
+}
+
+// FilterModelsByType returns models of the given type
+// If modelType is empty, returns all models
+func FilterModelsByType(models []Model, modelType string) []Model {
+	if modelType == "" {
+		return models
+	}
+
+	var filtered []Model
+	for _, model := range models {
+		if matchModelType(modelType, model.Type) {
+			filtered = append(filtered, model)
+		}
+	}
+	return filtered
+}
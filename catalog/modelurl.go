@@ -0,0 +1,125 @@
+package catalog
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ProviderPages are a provider's web pages; "" when the provider has none
+type ProviderPages struct {
+	Home    string
+	Pricing string
+	Status  string
+}
+
+// ProviderPageKinds are the pages open can launch besides a model page
+var ProviderPageKinds = []string{"home", "pricing", "status"}
+
+// vendorPages are the first-party pages of model vendors reached through OpenRouter
+var vendorPages = map[string]ProviderPages{
+	"openai": {
+		Home:    "https://platform.openai.com/docs/models",
+		Pricing: "https://openai.com/api/pricing",
+		Status:  "https://status.openai.com",
+	},
+	"anthropic": {
+		Home:    "https://docs.anthropic.com/en/docs/about-claude/models",
+		Pricing: "https://www.anthropic.com/pricing#api",
+		Status:  "https://status.anthropic.com",
+	},
+	"google": {
+		Home:    "https://ai.google.dev/gemini-api/docs/models",
+		Pricing: "https://ai.google.dev/gemini-api/docs/pricing",
+		Status:  "https://aistudio.google.com/status",
+	},
+	"mistralai": {
+		Home:    "https://docs.mistral.ai/getting-started/models",
+		Pricing: "https://mistral.ai/pricing#api-pricing",
+		Status:  "https://status.mistral.ai",
+	},
+	"deepseek": {
+		Home:    "https://api-docs.deepseek.com",
+		Pricing: "https://api-docs.deepseek.com/quick_start/pricing",
+		Status:  "https://status.deepseek.com",
+	},
+	"x-ai": {
+		Home:    "https://docs.x.ai/docs/models",
+		Pricing: "https://docs.x.ai/docs/models",
+		Status:  "https://status.x.ai",
+	},
+	"cohere": {
+		Home:    "https://docs.cohere.com/docs/models",
+		Pricing: "https://cohere.com/pricing",
+		Status:  "https://status.cohere.com",
+	},
+}
+
+// PagesForProvider returns the web pages of a provider prefix; vendors without
+// an entry fall back to their OpenRouter listing
+func PagesForProvider(provider string) ProviderPages {
+	switch provider {
+	case "openrouter":
+		return openRouterPages
+	case "replicate":
+		return replicatePages
+	case "ollama":
+		return ollamaPages
+	case "tgi":
+		return tgiPages
+	case "llamacpp":
+		return llamaCppPages
+	}
+	if pages, ok := vendorPages[CanonicalProvider(provider)]; ok {
+		return pages
+	}
+	return ProviderPages{Home: "https://openrouter.ai/" + provider}
+}
+
+// Page returns the page of the given kind (home, pricing, or status)
+func (p ProviderPages) Page(kind string) (string, error) {
+	switch kind {
+	case "home":
+		return p.Home, nil
+	case "pricing":
+		return p.Pricing, nil
+	case "status":
+		return p.Status, nil
+	}
+	return "", fmt.Errorf("invalid page: %s (expected %s)", kind, strings.Join(ProviderPageKinds, ", "))
+}
+
+// ModelURL returns the canonical web page of a model: its OpenRouter, Replicate,
+// Ollama library, or Hugging Face page; "" when there is none (llama.cpp)
+// A URL provided by the source (e.g. a shared remote catalog) takes precedence
+func ModelURL(model Model) string {
+	if model.URL != "" {
+		return model.URL
+	}
+
+	switch ExtractProvider(model.ID) {
+	case "ollama":
+		name, _, _ := strings.Cut(OllamaModelName(model.ID), ":")
+		if host, _, ok := strings.Cut(name, "/"); ok && strings.Contains(host, ".") {
+			// Models pulled from another registry, e.g. hf.co/user/repo
+			return "https://" + name
+		}
+		if !strings.Contains(name, "/") {
+			name = "library/" + name
+		}
+		return "https://ollama.com/" + name
+	case "tgi":
+		return HuggingFaceURL + "/" + ModelSuffix(model.ID)
+	case "llamacpp":
+		return ""
+	case "replicate":
+		return "https://replicate.com/" + ModelSuffix(model.ID)
+	}
+	return "https://openrouter.ai/" + BaseModelID(model.ID)
+}
+
+// SetModelURLs fills in URL for models whose source did not set it
+func SetModelURLs(models []Model) {
+	for i := range models {
+		models[i].URL = ModelURL(models[i])
+	}
+}
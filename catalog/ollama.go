@@ -0,0 +1,134 @@
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ollamaPages are Ollama's web pages; local models have no pricing or status
+var ollamaPages = ProviderPages{Home: "https://ollama.com/library"}
+
+// OllamaModel represents a model from Ollama API
+type OllamaModel struct {
+	Name       string    `json:"name"`
+	ModifiedAt time.Time `json:"modified_at"`
+	Size       int64     `json:"size"`
+	Digest     string    `json:"digest"`
+	Details    struct {
+		Format            string   `json:"format"`
+		Family            string   `json:"family"`
+		Families          []string `json:"families"`
+		ParameterSize     string   `json:"parameter_size"`
+		QuantizationLevel string   `json:"quantization_level"`
+	} `json:"details"`
+}
+
+// OllamaModelsResponse represents the Ollama API response
+type OllamaModelsResponse struct {
+	Models []OllamaModel `json:"models"`
+}
+
+// FetchOllamaModels retrieves models from Ollama API
+// Callers listing models ignore errors so an unavailable server is skipped silently
+func (c *Client) FetchOllamaModels(host string) ([]Model, error) {
+	body, err := c.getBody(host+"/api/tags", localTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch models: %w", err)
+	}
+
+	var ollamaResp OllamaModelsResponse
+	if err := json.Unmarshal(body, &ollamaResp); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	var raw struct {
+		Models []json.RawMessage `json:"models"`
+	}
+	if json.Unmarshal(body, &raw) == nil {
+		if err := c.checkSchema(CheckSchema("ollama", raw.Models)); err != nil {
+			return nil, err
+		}
+	}
+
+	// Convert Ollama models to unified Model format
+	models := make([]Model, 0, len(ollamaResp.Models))
+	for _, om := range ollamaResp.Models {
+		model := Model{
+			ID:          "ollama/" + om.Name,
+			Name:        om.Name,
+			Created:     om.ModifiedAt.Unix(),
+			Description: buildOllamaDescription(om),
+			Type:        ollamaModelType(om),
+			// Store Ollama-specific data for detailed view
+			OllamaDetails: &OllamaDetails{
+				Size:              om.Size,
+				Digest:            om.Digest,
+				Format:            om.Details.Format,
+				Family:            om.Details.Family,
+				ParameterSize:     om.Details.ParameterSize,
+				QuantizationLevel: om.Details.QuantizationLevel,
+			},
+		}
+		models = append(models, model)
+	}
+
+	return models, nil
+}
+
+// OllamaModelName returns the Ollama model name for an ID with or without the "ollama/" prefix
+// Names may contain a namespace (user/model), so other prefixes are kept as-is
+func OllamaModelName(id string) string {
+	return strings.TrimPrefix(id, "ollama/")
+}
+
+// ollamaModelType classifies BERT-family models as embedding models and everything else as chat
+func ollamaModelType(om OllamaModel) string {
+	for _, family := range append([]string{om.Details.Family}, om.Details.Families...) {
+		if strings.Contains(family, "bert") {
+			return TypeEmbedding
+		}
+	}
+	if strings.Contains(om.Name, "embed") {
+		return TypeEmbedding
+	}
+	return TypeChat
+}
+
+// buildOllamaDescription creates a description from Ollama model details
+func buildOllamaDescription(om OllamaModel) string {
+	desc := ""
+
+	if om.Details.Family != "" {
+		desc = om.Details.Family
+	}
+
+	if om.Details.ParameterSize != "" {
+		if desc != "" {
+			desc += " "
+		}
+		desc += om.Details.ParameterSize
+	}
+
+	if om.Details.QuantizationLevel != "" {
+		if desc != "" {
+			desc += " "
+		}
+		desc += "(" + om.Details.QuantizationLevel + ")"
+	}
+
+	// Add size information
+	sizeGB := float64(om.Size) / (1024 * 1024 * 1024)
+	if sizeGB > 0 {
+		if desc != "" {
+			desc += " - "
+		}
+		desc += fmt.Sprintf("%.1f GB", sizeGB)
+	}
+
+	if desc == "" {
+		desc = "Ollama local model"
+	}
+
+	return desc
+}
@@ -0,0 +1,158 @@
+package catalog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// OpenRouterModelsURL is the catalog of the current API version
+const OpenRouterModelsURL = "https://openrouter.ai/api/v1/models"
+
+// openRouterPages are OpenRouter's web pages
+var openRouterPages = ProviderPages{
+	Home:    "https://openrouter.ai",
+	Pricing: "https://openrouter.ai/models?order=pricing-low-to-high",
+	Status:  "https://status.openrouter.ai",
+}
+
+// openRouterModelsURLs are the catalog URLs of the API versions this client
+// understands, keyed by version
+var openRouterModelsURLs = map[string]string{
+	"v1": OpenRouterModelsURL,
+}
+
+// openRouterAPIVersions are the supported API versions, newest first
+var openRouterAPIVersions = []string{"v1"}
+
+// maxCatalogPages bounds pagination in case the API keeps returning next links
+const maxCatalogPages = 100
+
+// HTTPStatusError is a non-200 response from an API
+type HTTPStatusError struct {
+	StatusCode int
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("API returned status %d", e.StatusCode)
+}
+
+// modelsURL returns the first catalog page URL of an API version with
+// query parameters params, e.g. category or output_modalities
+func modelsURL(version string, params url.Values) string {
+	u := openRouterModelsURLs[version]
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+	return u
+}
+
+// modelsPage is one page of the catalog response
+// next and next_cursor are not sent today; they are honored if the API starts paginating
+type modelsPage struct {
+	Data       []json.RawMessage `json:"data"`
+	Next       string            `json:"next"`
+	NextCursor string            `json:"next_cursor"`
+}
+
+// FetchOpenRouterModels retrieves the OpenRouter catalog
+func (c *Client) FetchOpenRouterModels() ([]Model, error) {
+	return c.fetchOpenRouter(nil)
+}
+
+// fetchOpenRouter retrieves every page of the OpenRouter catalog with query
+// parameters params from the newest API version that answers; a version that
+// is gone (404 or 410) is skipped
+func (c *Client) fetchOpenRouter(params url.Values) ([]Model, error) {
+	var lastErr error
+	for _, version := range openRouterAPIVersions {
+		models, err := c.fetchVersion(version, params)
+		var statusErr *HTTPStatusError
+		if errors.As(err, &statusErr) && (statusErr.StatusCode == http.StatusNotFound || statusErr.StatusCode == http.StatusGone) {
+			lastErr = fmt.Errorf("OpenRouter API %s is no longer available (%w); a newer llmls may be required", version, err)
+			continue
+		}
+		return models, err
+	}
+	return nil, lastErr
+}
+
+// fetchVersion retrieves every page of the catalog from one API version,
+// checking the entries against the expected schema so upstream changes are
+// not dropped silently
+func (c *Client) fetchVersion(version string, params url.Values) ([]Model, error) {
+	var raw []json.RawMessage
+	next := modelsURL(version, params)
+	for page := 0; next != ""; page++ {
+		if page == maxCatalogPages {
+			return nil, fmt.Errorf("catalog exceeds %d pages", maxCatalogPages)
+		}
+		body, err := c.get(next)
+		if err != nil {
+			return nil, err
+		}
+		var p modelsPage
+		if err := json.Unmarshal(body, &p); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+		raw = append(raw, p.Data...)
+		next, err = nextPageURL(next, p)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := c.checkSchema(CheckSchema("openrouter", raw)); err != nil {
+		return nil, err
+	}
+	return decodeCatalogModels(raw)
+}
+
+// nextPageURL resolves the next page of a response, or "" on the last page
+func nextPageURL(current string, p modelsPage) (string, error) {
+	switch {
+	case p.Next != "":
+		base, err := url.Parse(current)
+		if err != nil {
+			return "", err
+		}
+		ref, err := url.Parse(p.Next)
+		if err != nil {
+			return "", fmt.Errorf("invalid next page URL: %s", p.Next)
+		}
+		return base.ResolveReference(ref).String(), nil
+	case p.NextCursor != "":
+		u, err := url.Parse(current)
+		if err != nil {
+			return "", err
+		}
+		q := u.Query()
+		q.Set("cursor", p.NextCursor)
+		u.RawQuery = q.Encode()
+		return u.String(), nil
+	}
+	return "", nil
+}
+
+// ParseOpenRouterModels decodes a single, unpaginated catalog response
+func ParseOpenRouterModels(body []byte) ([]Model, error) {
+	var p modelsPage
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	return decodeCatalogModels(p.Data)
+}
+
+// decodeCatalogModels decodes raw catalog entries
+func decodeCatalogModels(raw []json.RawMessage) ([]Model, error) {
+	models := make([]Model, 0, len(raw))
+	for _, entry := range raw {
+		var model Model
+		if err := json.Unmarshal(entry, &model); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+		models = append(models, model)
+	}
+	return models, nil
+}
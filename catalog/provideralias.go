@@ -0,0 +1,79 @@
+package catalog
+
+import (
+	"sort"
+	"strings"
+)
+
+// providerAliases map other spellings of a provider prefix, as used by
+// Hugging Face organizations, shared catalogs, and hosting platforms, to
+// the canonical OpenRouter prefix; keys are lowercase
+var providerAliases = map[string]string{
+	"meta":             "meta-llama",
+	"metaai":           "meta-llama",
+	"meta-ai":          "meta-llama",
+	"facebook":         "meta-llama",
+	"google-ai-studio": "google",
+	"google-vertex":    "google",
+	"googleai":         "google",
+	"mistral":          "mistralai",
+	"mistral-ai":       "mistralai",
+	"xai":              "x-ai",
+	"deepseek-ai":      "deepseek",
+	"cohereforai":      "cohere",
+	"coherelabs":       "cohere",
+	"amazon-bedrock":   "amazon",
+	"aws":              "amazon",
+	"moonshot":         "moonshotai",
+	"zhipuai":          "z-ai",
+	"thudm":            "z-ai",
+	"nous":             "nousresearch",
+	"ai21labs":         "ai21",
+	"perplexity-ai":    "perplexity",
+}
+
+// CanonicalProvider returns the canonical name of a provider prefix, so
+// meta, metaai, and meta-llama are all meta-llama; case is ignored
+func CanonicalProvider(provider string) string {
+	lower := strings.ToLower(provider)
+	if canonical, ok := providerAliases[lower]; ok {
+		return canonical
+	}
+	if provider == "Unknown" {
+		return provider
+	}
+	return lower
+}
+
+// ModelProvider returns the canonical provider of a model; the model ID keeps
+// the provider prefix of its source
+func ModelProvider(model Model) string {
+	return CanonicalProvider(ExtractProvider(model.ID))
+}
+
+// ProviderSpellings returns the raw provider prefixes of models grouped
+// under each canonical provider, for those with a prefix other than the
+// canonical name
+func ProviderSpellings(models []Model) map[string][]string {
+	seen := make(map[string]map[string]bool)
+	for _, model := range models {
+		raw := ExtractProvider(model.ID)
+		canonical := CanonicalProvider(raw)
+		if raw == canonical {
+			continue
+		}
+		if seen[canonical] == nil {
+			seen[canonical] = make(map[string]bool)
+		}
+		seen[canonical][raw] = true
+	}
+
+	spellings := make(map[string][]string)
+	for canonical, raws := range seen {
+		for raw := range raws {
+			spellings[canonical] = append(spellings[canonical], raw)
+		}
+		sort.Strings(spellings[canonical])
+	}
+	return spellings
+}
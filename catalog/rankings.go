@@ -0,0 +1,67 @@
+package catalog
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// openRouterRankingsURL is the catalog ordered by tokens routed to each model
+// over the past week, the order of OpenRouter's public rankings
+var openRouterRankingsURL = OpenRouterModelsURL + "?order=top-weekly"
+
+// FetchRankings returns the 1-based weekly popularity rank of each OpenRouter
+// model, through the catalog cache
+// A response in the default newest-first order means the API did not rank
+// it, so no ranks are returned rather than misleading ones
+func (c *Client) FetchRankings() (map[string]int, error) {
+	models, err := c.fetchOpenRouter(url.Values{"order": {"top-weekly"}})
+	if err != nil {
+		return nil, fmt.Errorf("rankings: %w", err)
+	}
+	if newestFirst(models) {
+		return nil, fmt.Errorf("rankings: OpenRouter returned the catalog unranked")
+	}
+
+	ranks := make(map[string]int, len(models))
+	for i, model := range models {
+		ranks[model.ID] = i + 1
+	}
+	return ranks, nil
+}
+
+// newestFirst reports whether models are in creation order, newest first
+func newestFirst(models []Model) bool {
+	if len(models) < 3 {
+		return false
+	}
+	for i := 1; i < len(models); i++ {
+		if models[i].Created > models[i-1].Created {
+			return false
+		}
+	}
+	return true
+}
+
+// FillRankings sets the popularity rank of each ranked model; a failure is a
+// warning, leaving every model unranked
+func (c *Client) FillRankings(models []Model) {
+	ranks, err := c.FetchRankings()
+	if err != nil {
+		c.logger.Print(err)
+		return
+	}
+	for i := range models {
+		models[i].PopularityRank = ranks[models[i].ID]
+	}
+}
+
+// SortUsesRankings reports whether a --sort spec orders by popularity rank
+func SortUsesRankings(spec string) bool {
+	for _, item := range strings.Split(spec, ",") {
+		if name, _, _ := strings.Cut(strings.TrimSpace(item), ":"); name == "popularity" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package catalog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// FetchRemoteCatalog retrieves a shared catalog published by another llmls or
+// any server returning llmls JSON: a model array, an OpenRouter-style
+// {"data": [...]} response, or a snapshot {"models": [...]}
+// Sources.RemoteToken, if set, is sent as a bearer token
+func (c *Client) FetchRemoteCatalog(url string) ([]Model, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote catalog URL: %w", err)
+	}
+	if c.sources.RemoteToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.sources.RemoteToken)
+	}
+
+	body, err := c.do(req, remoteTimeout)
+	var statusErr *HTTPStatusError
+	switch {
+	case errors.As(err, &statusErr):
+		return nil, fmt.Errorf("remote catalog returned status %d", statusErr.StatusCode)
+	case err != nil:
+		return nil, fmt.Errorf("failed to fetch remote catalog: %w", err)
+	}
+
+	var models []Model
+	if strings.HasPrefix(strings.TrimSpace(string(body)), "[") {
+		if err := json.Unmarshal(body, &models); err != nil {
+			return nil, fmt.Errorf("failed to parse remote catalog: %w", err)
+		}
+		return models, nil
+	}
+
+	var wrapped struct {
+		Data   []Model `json:"data"`
+		Models []Model `json:"models"`
+	}
+	if err := json.Unmarshal(body, &wrapped); err != nil {
+		return nil, fmt.Errorf("failed to parse remote catalog: %w", err)
+	}
+	if wrapped.Data != nil {
+		return wrapped.Data, nil
+	}
+	return wrapped.Models, nil
+}
+
+// MergeCatalog overlays remote models onto models: entries with the same ID are
+// replaced by the curated remote version, and new IDs are appended
+func MergeCatalog(models, remote []Model) []Model {
+	index := make(map[string]int, len(models))
+	for i, model := range models {
+		index[model.ID] = i
+	}
+	for _, model := range remote {
+		if i, ok := index[model.ID]; ok {
+			models[i] = model
+			continue
+		}
+		index[model.ID] = len(models)
+		models = append(models, model)
+	}
+	return models
+}
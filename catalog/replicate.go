@@ -0,0 +1,96 @@
+package catalog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const replicateAPIURL = "https://api.replicate.com/v1"
+
+// replicatePages are Replicate's web pages
+var replicatePages = ProviderPages{
+	Home:    "https://replicate.com",
+	Pricing: "https://replicate.com/pricing",
+	Status:  "https://www.replicatestatus.com",
+}
+
+// replicateCollections maps Replicate collection slugs to model types
+var replicateCollections = map[string]string{
+	"text-to-image":  TypeImage,
+	"text-to-speech": TypeTTS,
+	"speech-to-text": TypeSTT,
+}
+
+// ReplicateModel represents a model in a Replicate collection
+type ReplicateModel struct {
+	Owner         string `json:"owner"`
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	URL           string `json:"url"`
+	RunCount      int64  `json:"run_count"`
+	LatestVersion *struct {
+		CreatedAt time.Time `json:"created_at"`
+	} `json:"latest_version"`
+}
+
+// ReplicateCollectionResponse represents the Replicate collection API response
+type ReplicateCollectionResponse struct {
+	Models []ReplicateModel `json:"models"`
+}
+
+// FetchReplicateModels retrieves image and speech models from Replicate collections
+// Requires Sources.ReplicateToken; returns an error if it is unset
+func (c *Client) FetchReplicateModels() ([]Model, error) {
+	if c.sources.ReplicateToken == "" {
+		return nil, fmt.Errorf("no Replicate API token")
+	}
+
+	var models []Model
+	for slug, modelType := range replicateCollections {
+		collection, err := c.fetchReplicateCollection(slug)
+		if err != nil {
+			return nil, err
+		}
+		for _, rm := range collection.Models {
+			model := Model{
+				ID:          "replicate/" + rm.Owner + "/" + rm.Name,
+				Name:        rm.Owner + "/" + rm.Name,
+				Description: rm.Description,
+				Type:        modelType,
+			}
+			if rm.LatestVersion != nil {
+				model.Created = rm.LatestVersion.CreatedAt.Unix()
+			}
+			models = append(models, model)
+		}
+	}
+
+	return models, nil
+}
+
+// fetchReplicateCollection retrieves one Replicate collection
+func (c *Client) fetchReplicateCollection(slug string) (*ReplicateCollectionResponse, error) {
+	req, err := http.NewRequest("GET", replicateAPIURL+"/collections/"+slug, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.sources.ReplicateToken)
+
+	body, err := c.do(req, remoteTimeout)
+	var statusErr *HTTPStatusError
+	switch {
+	case errors.As(err, &statusErr):
+		return nil, fmt.Errorf("Replicate API returned status %d", statusErr.StatusCode)
+	case err != nil:
+		return nil, fmt.Errorf("failed to fetch Replicate collection %s: %w", slug, err)
+	}
+
+	var collection ReplicateCollectionResponse
+	if err := json.Unmarshal(body, &collection); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	return &collection, nil
+}
@@ -0,0 +1,146 @@
+package catalog
+
+import (
+	"embed"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// schemaFiles are the expected response schemas of each provider, keyed by file name
+//
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+// Schema is the expected shape of the objects a provider returns
+type Schema struct {
+	Required []string            `json:"required"` // Fields every response must contain
+	Fields   map[string][]string `json:"fields"`   // Known fields per nested object; "" is the top level
+}
+
+// loadSchema reads the embedded schema of a source; ok is false if there is none
+func loadSchema(source string) (Schema, bool) {
+	var schema Schema
+	data, err := schemaFiles.ReadFile("schemas/" + source + ".json")
+	if err != nil {
+		return schema, false
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return schema, false
+	}
+	return schema, true
+}
+
+// SchemaDrift lists where a provider response differs from its expected schema
+type SchemaDrift struct {
+	Source  string
+	New     []string // Fields the schema does not know, e.g. pricing.audio
+	Missing []string // Required fields absent from every object, usually renamed
+}
+
+// Empty reports whether the response matched the schema
+func (d SchemaDrift) Empty() bool {
+	return len(d.New) == 0 && len(d.Missing) == 0
+}
+
+// String formats the drift as e.g. "openrouter: new fields foo; missing fields bar"
+func (d SchemaDrift) String() string {
+	var parts []string
+	if len(d.New) > 0 {
+		parts = append(parts, "new fields "+strings.Join(d.New, ", "))
+	}
+	if len(d.Missing) > 0 {
+		parts = append(parts, "missing fields "+strings.Join(d.Missing, ", "))
+	}
+	return d.Source + ": " + strings.Join(parts, "; ")
+}
+
+// CheckSchema compares response objects (catalog entries, or a single info
+// object) with the embedded schema of source
+func CheckSchema(source string, objects []json.RawMessage) SchemaDrift {
+	drift := SchemaDrift{Source: source}
+	schema, ok := loadSchema(source)
+	if !ok || len(objects) == 0 {
+		return drift
+	}
+
+	known := make(map[string]map[string]bool)
+	for object, fields := range schema.Fields {
+		known[object] = make(map[string]bool)
+		for _, field := range fields {
+			known[object][field] = true
+		}
+	}
+
+	seen := make(map[string]bool)
+	added := make(map[string]bool)
+	for _, raw := range objects {
+		var top map[string]json.RawMessage
+		if json.Unmarshal(raw, &top) != nil {
+			continue
+		}
+		for object := range schema.Fields {
+			fields := top
+			if object != "" {
+				fields = nil
+				json.Unmarshal(top[object], &fields)
+			}
+			for field := range fields {
+				path := strings.TrimPrefix(object+"."+field, ".")
+				seen[path] = true
+				if !known[object][field] {
+					added[path] = true
+				}
+			}
+		}
+	}
+
+	for field := range added {
+		drift.New = append(drift.New, field)
+	}
+	sort.Strings(drift.New)
+	for _, field := range schema.Required {
+		if !seen[field] {
+			drift.Missing = append(drift.Missing, field)
+		}
+	}
+	return drift
+}
+
+// SchemaDriftError lists provider responses that do not match their
+// expected schema, returned instead of the models with Sources.StrictSchema
+type SchemaDriftError struct {
+	Drifts []SchemaDrift
+}
+
+func (e *SchemaDriftError) Error() string {
+	seen := make(map[string]bool)
+	var drifts []string
+	for _, drift := range e.Drifts {
+		if s := drift.String(); !seen[s] {
+			seen[s] = true
+			drifts = append(drifts, s)
+		}
+	}
+	sort.Strings(drifts)
+	return "provider responses do not match the expected schema (--strict-schema):\n  " + strings.Join(drifts, "\n  ")
+}
+
+// checkSchema handles drift in a response: an error with StrictSchema,
+// otherwise only a debug message, since the models are still usable
+// During a catalog fetch the drift is collected instead, so that every
+// source is reported at once
+func (c *Client) checkSchema(drift SchemaDrift) error {
+	if drift.Empty() {
+		return nil
+	}
+	switch {
+	case !c.sources.StrictSchema:
+		c.debug.Printf("schema drift in %s", drift)
+	case c.drifts != nil:
+		*c.drifts = append(*c.drifts, drift)
+	default:
+		return &SchemaDriftError{Drifts: []SchemaDrift{drift}}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package catalog
+
+import (
+	"regexp"
+	"strings"
+)
+
+// seriesRule maps a model ID pattern to a series name
+// The first submatch of Pattern is the version appended to Family
+type seriesRule struct {
+	Family  string
+	Pattern *regexp.Regexp
+}
+
+// seriesRules are checked in order against the lowercased model ID without its provider
+var seriesRules = []seriesRule{
+	{"gpt", regexp.MustCompile(`^(?:chatgpt-)?gpt-(\d+(?:\.\d+)?o?)`)},
+	{"o", regexp.MustCompile(`^o(\d+)(?:-|:|$)`)},
+	{"claude", regexp.MustCompile(`^claude-(?:opus|sonnet|haiku)-(\d+(?:[.-]\d)?)(?:[^\d]|$)`)},
+	{"claude", regexp.MustCompile(`^claude-(\d+(?:[.-]\d)?)(?:[^\d]|$)`)},
+	{"llama", regexp.MustCompile(`(?:^|[^a-z])llama-?(\d+(?:\.\d+)?)(?:[^\d.]|$)`)},
+	{"gemini", regexp.MustCompile(`^gemini-(\d+(?:\.\d+)?)`)},
+	{"gemma", regexp.MustCompile(`^gemma-?(\d+(?:\.\d+)?)`)},
+	{"qwen", regexp.MustCompile(`^qwen-?(\d+(?:\.\d+)?)`)},
+	{"deepseek", regexp.MustCompile(`^deepseek-((?:v|r)\d+(?:\.\d+)?)`)},
+	{"phi", regexp.MustCompile(`^phi-?(\d+(?:\.\d+)?)`)},
+	{"grok", regexp.MustCompile(`^grok-(\d+(?:\.\d+)?)`)},
+	{"mistral", regexp.MustCompile(`^mistral-(large|medium|small|nemo)`)},
+	{"command", regexp.MustCompile(`^command-(r|a)(?:[^a-z]|$)`)},
+}
+
+// ModelSeries classifies a model into a cross-provider series such as
+// "gpt-4o", "claude-3.5", "llama-3.1", or "qwen-2.5"; returns "" if unknown
+func ModelSeries(model Model) string {
+	id := strings.ToLower(ModelSuffix(model.ID))
+	for _, rule := range seriesRules {
+		m := rule.Pattern.FindStringSubmatch(id)
+		if m == nil {
+			continue
+		}
+		// Normalize "claude-3-5" style versions to "3.5"
+		version := strings.ReplaceAll(m[1], "-", ".")
+		if rule.Family == "o" {
+			return "o" + version
+		}
+		return rule.Family + "-" + version
+	}
+	return ""
+}
+
+// seriesMatch reports whether series belongs to query, either exactly or as a
+// more specific version ("llama-3" matches "llama-3.1")
+func seriesMatch(query, series string) bool {
+	query = strings.ToLower(query)
+	if series == query {
+		return true
+	}
+	return strings.HasPrefix(series, query) && series[len(query)] == '.'
+}
+
+// FilterModelsBySeries returns models whose series matches query
+// If query is empty, returns all models
+func FilterModelsBySeries(models []Model, query string) []Model {
+	if query == "" {
+		return models
+	}
+
+	var filtered []Model
+	for _, model := range models {
+		if seriesMatch(query, ModelSeries(model)) {
+			filtered = append(filtered, model)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,138 @@
+package catalog
+
+import (
+	"net/url"
+	"time"
+)
+
+// localTimeout bounds each request to a local server, so an unavailable one
+// is skipped quickly
+const localTimeout = 3 * time.Second
+
+// remoteTimeout bounds each request to a shared catalog or Replicate
+const remoteTimeout = 10 * time.Second
+
+// Sources are the catalogs queried besides OpenRouter
+// Hosts are base URLs such as http://localhost:11434; an empty host is not
+// queried
+type Sources struct {
+	OllamaHost     string
+	LlamaCppHost   string   // llama.cpp or KoboldCpp server
+	TGIHosts       []string // text-generation-inference servers
+	RemoteURLs     []string // Shared llmls catalogs
+	RemoteToken    string   // Bearer token sent to RemoteURLs
+	ReplicateToken string   // Replicate API token; Replicate is skipped without it
+	IncludeMedia   bool     // Also fetch image and speech models
+	StrictSchema   bool     // Fail when a provider response does not match its expected schema
+}
+
+// localSources are the model ID prefixes of sources that run models locally
+var localSources = []string{"ollama", "tgi", "llamacpp"}
+
+// ModelSourceName returns the source a model comes from: its local server or
+// platform prefix, otherwise openrouter
+func ModelSourceName(model Model) string {
+	switch prefix := ExtractProvider(model.ID); prefix {
+	case "ollama", "tgi", "llamacpp", "replicate":
+		return prefix
+	}
+	return "openrouter"
+}
+
+// IsLocalSource reports whether a source runs models on a local server
+func IsLocalSource(source string) bool {
+	for _, local := range localSources {
+		if source == local {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchCatalog retrieves models from OpenRouter, shared remote catalogs, and
+// every reachable local source of sources, and returns the names of the
+// sources that answered
+// OpenRouter errors are returned unless WithDryRun is set; remote failures
+// are warnings; unavailable local servers are skipped silently
+// With StrictSchema, schema drift in any response is returned as an error
+// With IncludeMedia, image and speech models from OpenRouter and Replicate are included
+func (c *Client) fetchCatalog(sources Sources) ([]Model, []string, error) {
+	var drifts []SchemaDrift
+	fetch := *c
+	fetch.sources, fetch.drifts = sources, &drifts
+	c = &fetch
+
+	var reached []string
+	params := url.Values{}
+	if sources.IncludeMedia {
+		params.Set("output_modalities", "all")
+	}
+	models, err := c.fetchOpenRouter(params)
+	if err != nil && !c.dryRun {
+		return nil, nil, err
+	}
+	if err == nil {
+		reached = append(reached, "openrouter")
+	}
+
+	if sources.IncludeMedia {
+		// Replicate is optional: skipped silently without a token
+		if replicateModels, err := c.FetchReplicateModels(); err == nil {
+			models = append(models, replicateModels...)
+			reached = append(reached, "replicate")
+		}
+	}
+
+	// Shared catalogs are curated, so their entries win over OpenRouter's
+	for _, remote := range sources.RemoteURLs {
+		remoteModels, err := c.FetchRemoteCatalog(remote)
+		if err != nil {
+			c.logger.Print(err)
+			continue
+		}
+		models = MergeCatalog(models, remoteModels)
+	}
+
+	type localSource struct {
+		name, host string
+		fetch      func(string) ([]Model, error)
+	}
+	var local []localSource
+	if sources.OllamaHost != "" {
+		local = append(local, localSource{"ollama", sources.OllamaHost, c.FetchOllamaModels})
+	}
+	if sources.LlamaCppHost != "" {
+		local = append(local, localSource{"llamacpp", sources.LlamaCppHost, c.FetchLlamaCppModels})
+	}
+	for _, host := range sources.TGIHosts {
+		local = append(local, localSource{"tgi", host, c.FetchTGIModels})
+	}
+
+	// A source of several hosts (TGI) counts as reached only if all answer
+	var names []string
+	failed := make(map[string]bool)
+	for _, source := range local {
+		if _, tried := failed[source.name]; !tried {
+			names = append(names, source.name)
+		}
+		localModels, err := source.fetch(source.host)
+		failed[source.name] = failed[source.name] || err != nil
+		if err == nil {
+			models = append(models, localModels...)
+		}
+	}
+	for _, name := range names {
+		if !failed[name] {
+			reached = append(reached, name)
+		}
+	}
+
+	if len(drifts) > 0 {
+		return nil, nil, &SchemaDriftError{Drifts: drifts}
+	}
+
+	SetModelTypes(models)
+	SetModelLanguages(models)
+	SetModelURLs(models)
+	return models, reached, nil
+}
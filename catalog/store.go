@@ -0,0 +1,79 @@
+package catalog
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CatalogVersion is one fetched catalog; it is never modified after it is stored
+type CatalogVersion struct {
+	Models    []Model
+	FetchedAt time.Time
+}
+
+// CatalogStore keeps the latest catalog in memory for long-running modes
+// Readers get the current version without locking while a refresh builds
+// the next one and swaps it in atomically, so queries never wait on or see a
+// partial refresh; concurrent refreshes are coalesced into one fetch
+type CatalogStore struct {
+	current      atomic.Pointer[CatalogVersion]
+	refreshMu    sync.Mutex
+	revalidating atomic.Bool // A background refresh of Get is running
+	fetch        func() ([]Model, error)
+}
+
+// NewCatalogStore returns an empty store filled by fetch
+func NewCatalogStore(fetch func() ([]Model, error)) *CatalogStore {
+	return &CatalogStore{fetch: fetch}
+}
+
+// Current returns the stored version, or nil before the first refresh
+func (s *CatalogStore) Current() *CatalogVersion {
+	return s.current.Load()
+}
+
+// Refresh fetches the catalog and stores it as the current version
+// A caller that waited for another refresh to finish returns its version
+// instead of fetching again; on error the current version is kept
+func (s *CatalogStore) Refresh() (*CatalogVersion, error) {
+	requested := time.Now()
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+	if v := s.current.Load(); v != nil && !v.FetchedAt.Before(requested) {
+		return v, nil
+	}
+
+	models, err := s.fetch()
+	if err != nil {
+		return nil, err
+	}
+	v := &CatalogVersion{Models: models, FetchedAt: time.Now()}
+	s.current.Store(v)
+	return v, nil
+}
+
+// Get returns the current version, starting a single background refresh
+// once it is older than maxAge (stale-while-revalidate), so only the first
+// query waits for a fetch; a failed background refresh keeps the current
+// version until a later Get tries again
+// A maxAge of 0 disables reuse: every Get refreshes and waits
+func (s *CatalogStore) Get(maxAge time.Duration) (*CatalogVersion, error) {
+	v := s.current.Load()
+	switch {
+	case v == nil || maxAge <= 0:
+		return s.Refresh()
+	case time.Since(v.FetchedAt) >= maxAge && s.revalidating.CompareAndSwap(false, true):
+		go func() {
+			defer s.revalidating.Store(false)
+			s.Refresh()
+		}()
+	}
+	return v, nil
+}
+
+// ModelsCopy returns a copy of the models of a version that the caller may
+// filter and sort in place
+func (v *CatalogVersion) ModelsCopy() []Model {
+	return append([]Model(nil), v.Models...)
+}
@@ -0,0 +1,48 @@
+package catalog
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCatalogStoreGet(t *testing.T) {
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	store := NewCatalogStore(func() ([]Model, error) {
+		n := fetches.Add(1)
+		if n > 1 {
+			<-release // Hold the background refresh
+		}
+		return []Model{{ID: fmt.Sprintf("v%d", n)}}, nil
+	})
+
+	v, err := store.Get(time.Hour)
+	if err != nil || v.Models[0].ID != "v1" {
+		t.Fatalf("first Get = %v, %v; want v1", v, err)
+	}
+
+	// A stale version is returned at once while one refresh runs
+	for i := 0; i < 3; i++ {
+		v, err = store.Get(time.Nanosecond)
+		if err != nil || v.Models[0].ID != "v1" {
+			t.Fatalf("stale Get = %v, %v; want v1", v, err)
+		}
+	}
+	close(release)
+	for deadline := time.Now().Add(time.Second); store.Current().Models[0].ID != "v2"; {
+		if time.Now().After(deadline) {
+			t.Fatal("background refresh did not replace the stale version")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if n := fetches.Load(); n != 2 {
+		t.Errorf("fetches = %d, want 2", n)
+	}
+
+	// Without a maximum age every Get fetches
+	if v, _ = store.Get(0); v.Models[0].ID != "v3" {
+		t.Errorf("Get(0) = %v, want v3", v.Models[0].ID)
+	}
+}
@@ -0,0 +1,105 @@
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// tgiPages are text-generation-inference's web pages
+var tgiPages = ProviderPages{Home: "https://huggingface.co/docs/text-generation-inference"}
+
+// TGIInfo represents the text-generation-inference /info response
+type TGIInfo struct {
+	ModelID           string `json:"model_id"`
+	ModelSHA          string `json:"model_sha"`
+	ModelDtype        string `json:"model_dtype"`
+	ModelDeviceType   string `json:"model_device_type"`
+	ModelPipelineTag  string `json:"model_pipeline_tag"`
+	Quantize          string `json:"quantize"`
+	MaxInputTokens    int    `json:"max_input_tokens"`
+	MaxInputLength    int    `json:"max_input_length"` // Name used by TGI < 2.0
+	MaxTotalTokens    int    `json:"max_total_tokens"`
+	MaxConcurrentReqs int    `json:"max_concurrent_requests"`
+	Version           string `json:"version"`
+}
+
+// FetchTGIModels retrieves the served model from a TGI server's /info endpoint
+// Callers listing models ignore errors so an unavailable server is skipped silently
+func (c *Client) FetchTGIModels(host string) ([]Model, error) {
+	body, err := c.getBody(host+"/info", localTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch server info: %w", err)
+	}
+
+	var info TGIInfo
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	if err := c.checkSchema(CheckSchema("tgi", []json.RawMessage{body})); err != nil {
+		return nil, err
+	}
+	if info.ModelID == "" {
+		return nil, fmt.Errorf("server info has no model_id")
+	}
+
+	maxInput := info.MaxInputTokens
+	if maxInput == 0 {
+		maxInput = info.MaxInputLength
+	}
+
+	model := Model{
+		ID:   "tgi/" + info.ModelID,
+		Name: info.ModelID,
+		// TGI does not report a load time, so Created stays 0
+		ContextLength: info.MaxTotalTokens,
+		Description:   buildTGIDescription(info, maxInput),
+		Type:          tgiModelType(info.ModelPipelineTag),
+		// Store TGI-specific data for detailed view
+		TGIDetails: &TGIDetails{
+			Server:         host,
+			MaxInputTokens: maxInput,
+			MaxTotalTokens: info.MaxTotalTokens,
+			Quantization:   info.Quantize,
+			Dtype:          info.ModelDtype,
+			DeviceType:     info.ModelDeviceType,
+			Version:        info.Version,
+		},
+	}
+	return []Model{model}, nil
+}
+
+// tgiModelType maps a Hugging Face pipeline tag to a model type
+// Returns "" for unknown tags so the type is inferred later
+func tgiModelType(pipelineTag string) string {
+	switch pipelineTag {
+	case "text-generation", "text2text-generation", "image-text-to-text":
+		return TypeChat
+	case "feature-extraction", "sentence-similarity":
+		return TypeEmbedding
+	case "text-classification":
+		return TypeRerank
+	}
+	return ""
+}
+
+// buildTGIDescription creates a description from TGI server info
+func buildTGIDescription(info TGIInfo, maxInput int) string {
+	var parts []string
+
+	if info.Quantize != "" {
+		parts = append(parts, info.Quantize)
+	} else if info.ModelDtype != "" {
+		parts = append(parts, info.ModelDtype)
+	}
+
+	if maxInput > 0 && info.MaxTotalTokens > 0 {
+		parts = append(parts, fmt.Sprintf("input %s / total %s tokens",
+			formatCount(maxInput), formatCount(info.MaxTotalTokens)))
+	}
+
+	if len(parts) == 0 {
+		return "text-generation-inference model"
+	}
+	return strings.Join(parts, " - ")
+}
@@ -0,0 +1,221 @@
+package catalog
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// modelSortKeys are the accepted --sort values for the model listing
+var modelSortKeys = []string{"created", "price", "value", "context-value", "context", "provider", "id", "name", "downloads", "likes", "trending", "popularity"}
+
+// Blend is an input:output token ratio used to combine prompt and completion
+// prices into a single price
+type Blend struct {
+	Input  float64
+	Output float64
+}
+
+// DefaultBlend weighs prompt and completion tokens equally
+var DefaultBlend = Blend{Input: 1, Output: 1}
+
+// ParseBlend parses an input:output ratio such as "3:1"
+func ParseBlend(s string) (Blend, error) {
+	input, output, ok := strings.Cut(s, ":")
+	if !ok {
+		return Blend{}, fmt.Errorf("invalid blend: %s (expected input:output, e.g. 3:1)", s)
+	}
+	in, err := strconv.ParseFloat(strings.TrimSpace(input), 64)
+	if err != nil || in < 0 {
+		return Blend{}, fmt.Errorf("invalid blend input ratio: %s", input)
+	}
+	out, err := strconv.ParseFloat(strings.TrimSpace(output), 64)
+	if err != nil || out < 0 {
+		return Blend{}, fmt.Errorf("invalid blend output ratio: %s", output)
+	}
+	if in+out == 0 {
+		return Blend{}, fmt.Errorf("invalid blend: %s (ratio cannot be 0:0)", s)
+	}
+	return Blend{Input: in, Output: out}, nil
+}
+
+// String formats the ratio as e.g. "3:1"
+func (b Blend) String() string {
+	return strconv.FormatFloat(b.Input, 'f', -1, 64) + ":" + strconv.FormatFloat(b.Output, 'f', -1, 64)
+}
+
+// Price returns the per-token price of a model weighted by the ratio
+// ok is false for models without pricing (e.g. local models)
+func (b Blend) Price(model Model) (float64, bool) {
+	if model.Pricing.Prompt == "" {
+		return 0, false
+	}
+	prompt := ParsePrice(model.Pricing.Prompt)
+	completion := ParsePrice(model.Pricing.Completion)
+	return (prompt*b.Input + completion*b.Output) / (b.Input + b.Output), true
+}
+
+// TokensPerDollar returns how many tokens one dollar buys at the blended price
+// Free models return +Inf
+func TokensPerDollar(model Model, blend Blend) (float64, bool) {
+	price, ok := blend.Price(model)
+	if !ok {
+		return 0, false
+	}
+	if price == 0 {
+		return math.Inf(1), true
+	}
+	return 1 / price, true
+}
+
+// ContextPerDollar returns the context length per dollar of the blended price
+// per 1M tokens, rewarding models that are both large and cheap
+// Free models return +Inf
+func ContextPerDollar(model Model, blend Blend) (float64, bool) {
+	price, ok := blend.Price(model)
+	if !ok || model.ContextLength == 0 {
+		return 0, false
+	}
+	if price == 0 {
+		return math.Inf(1), true
+	}
+	return float64(model.ContextLength) / (price * 1000000), true
+}
+
+// modelSortKey is one key of --sort: a value per model (nil if missing)
+// and its direction
+type modelSortKey struct {
+	value      func(Model) interface{}
+	descending bool
+}
+
+// modelSortValue returns the value function of a sort key and whether it
+// sorts descending by default: dates, context, value, and computed columns
+// largest first; price, provider, ID, and name smallest first
+func modelSortValue(name string, blend Blend, columns *Columns) (func(Model) interface{}, bool, bool) {
+	metric := func(f func(Model) (float64, bool)) func(Model) interface{} {
+		return func(m Model) interface{} {
+			if v, ok := f(m); ok {
+				return v
+			}
+			return nil
+		}
+	}
+	switch name {
+	case "created":
+		return func(m Model) interface{} { return float64(m.Created) }, true, true
+	case "price":
+		return metric(blend.Price), false, true
+	case "value":
+		return metric(func(m Model) (float64, bool) { return TokensPerDollar(m, blend) }), true, true
+	case "context-value":
+		return metric(func(m Model) (float64, bool) { return ContextPerDollar(m, blend) }), true, true
+	case "context":
+		return func(m Model) interface{} {
+			if m.ContextLength == 0 {
+				return nil
+			}
+			return float64(m.ContextLength)
+		}, true, true
+	case "provider":
+		return func(m Model) interface{} { return ModelProvider(m) }, false, true
+	case "id":
+		return func(m Model) interface{} { return m.ID }, false, true
+	case "name":
+		return func(m Model) interface{} { return m.Name }, false, true
+	case "downloads", "likes", "trending":
+		return func(m Model) interface{} { return hfPopularity(m, name) }, true, true
+	case "popularity":
+		return func(m Model) interface{} {
+			if m.PopularityRank == 0 {
+				return nil
+			}
+			return float64(m.PopularityRank)
+		}, false, true
+	}
+	if column, ok := columns.Lookup(name); ok {
+		return column.Value, true, true
+	}
+	return nil, false, false
+}
+
+// parseModelSort parses --sort, e.g. "provider,created" or
+// "provider:asc,created:desc"
+func parseModelSort(spec string, blend Blend, columns *Columns) ([]modelSortKey, error) {
+	var keys []modelSortKey
+	for _, item := range strings.Split(spec, ",") {
+		name, direction, hasDirection := strings.Cut(strings.TrimSpace(item), ":")
+		if name == "" {
+			continue
+		}
+		value, descending, ok := modelSortValue(name, blend, columns)
+		if !ok {
+			return nil, fmt.Errorf("invalid sort key: %s (expected %s, or a computed column)", name, strings.Join(modelSortKeys, ", "))
+		}
+		if hasDirection {
+			switch direction {
+			case "asc":
+				descending = false
+			case "desc":
+				descending = true
+			default:
+				return nil, fmt.Errorf("invalid sort direction: %s (expected asc or desc)", direction)
+			}
+		}
+		keys = append(keys, modelSortKey{value: value, descending: descending})
+	}
+	return keys, nil
+}
+
+// SortModels orders models by a comma-separated list of keys, each with an
+// optional :asc or :desc direction, e.g. "provider,created:desc"
+// Models missing a key's value sort last for that key; ties after all keys
+// keep the default order, newest first and then by ID, so output is stable
+func SortModels(models []Model, spec string, blend Blend, columns *Columns) error {
+	if spec == "" {
+		spec = "created"
+	}
+	keys, err := parseModelSort(spec, blend, columns)
+	if err != nil {
+		return err
+	}
+	keys = append(keys, modelSortKey{value: func(m Model) interface{} { return float64(m.Created) }, descending: true})
+	keys = append(keys, modelSortKey{value: func(m Model) interface{} { return m.ID }})
+
+	// Compute each value once; computed columns encode the model as JSON
+	rows := make([][]interface{}, len(models))
+	order := make([]int, len(models))
+	for i, model := range models {
+		rows[i] = make([]interface{}, len(keys))
+		for k, key := range keys {
+			rows[i][k] = key.value(model)
+		}
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := rows[order[i]], rows[order[j]]
+		for k, key := range keys {
+			if (a[k] == nil) != (b[k] == nil) {
+				return b[k] == nil
+			}
+			c := whereCompare(a[k], b[k])
+			if key.descending {
+				c = -c
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+
+	sorted := make([]Model, len(models))
+	for i, index := range order {
+		sorted[i] = models[index]
+	}
+	copy(models, sorted)
+	return nil
+}
@@ -0,0 +1,89 @@
+package catalog
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ModelVariants are OpenRouter routing variants, written as an ID suffix such as ":free"
+var ModelVariants = []string{"free", "nitro", "floor", "online", "extended", "thinking", "beta", "exacto"}
+
+// VariantDescriptions explain how OpenRouter routes each variant
+var VariantDescriptions = map[string]string{
+	"free":     "free tier with lower rate limits",
+	"nitro":    "providers sorted by throughput",
+	"floor":    "providers sorted by price",
+	"online":   "web search results attached",
+	"extended": "extended context window",
+	"thinking": "reasoning enabled",
+	"beta":     "unmoderated beta endpoint",
+	"exacto":   "providers with the best tool-calling accuracy",
+}
+
+// VariantNone selects models without a routing variant
+const VariantNone = "none"
+
+// ModelVariant returns the OpenRouter routing variant of a model ID, or "" for base models
+// Local model tags (ollama/llama3:8b) are not variants
+func ModelVariant(modelID string) string {
+	idx := strings.LastIndex(modelID, ":")
+	if idx < 0 {
+		return ""
+	}
+	suffix := modelID[idx+1:]
+	for _, variant := range ModelVariants {
+		if suffix == variant {
+			return variant
+		}
+	}
+	return ""
+}
+
+// BaseModelID returns the model ID without its routing variant
+func BaseModelID(modelID string) string {
+	if variant := ModelVariant(modelID); variant != "" {
+		return strings.TrimSuffix(modelID, ":"+variant)
+	}
+	return modelID
+}
+
+// IsModelVariant reports whether v is a known variant or "none"
+func IsModelVariant(v string) bool {
+	if v == VariantNone {
+		return true
+	}
+	for _, variant := range ModelVariants {
+		if v == variant {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateVariant checks a --variant value
+func ValidateVariant(v string) error {
+	if v == "" || IsModelVariant(v) {
+		return nil
+	}
+	return fmt.Errorf("unknown variant: %s (expected %s, or %s)", v, strings.Join(ModelVariants, ", "), VariantNone)
+}
+
+// FilterModelsByVariant returns models with the given routing variant; "none"
+// selects base models only
+// If variant is empty, returns all models
+func FilterModelsByVariant(models []Model, variant string) []Model {
+	if variant == "" {
+		return models
+	}
+	if variant == VariantNone {
+		variant = ""
+	}
+
+	var filtered []Model
+	for _, model := range models {
+		if ModelVariant(model.ID) == variant {
+			filtered = append(filtered, model)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,576 @@
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// whereVirtualFields are --where fields computed from a model rather than read from its JSON
+var whereVirtualFields = map[string]func(Model) interface{}{
+	"provider": func(m Model) interface{} { return ModelProvider(m) },
+	"series":   func(m Model) interface{} { return ModelSeries(m) },
+	"variant":  func(m Model) interface{} { return ModelVariant(m.ID) },
+	"ctx":      func(m Model) interface{} { return float64(m.ContextLength) },
+	"price":    func(m Model) interface{} { return blendedPricePerMillion(m) },
+}
+
+// blendedPricePerMillion returns the price field: the blended price in USD per
+// 1M tokens, or null for unpriced models
+func blendedPricePerMillion(m Model) interface{} {
+	price, ok := DefaultBlend.Price(m)
+	if !ok {
+		return nil
+	}
+	return price * 1000000
+}
+
+// whereEnv is the model an expression is evaluated against
+type whereEnv struct {
+	model  Model
+	fields map[string]interface{}
+}
+
+// whereExpr evaluates an expression against a model
+type whereExpr func(env *whereEnv) interface{}
+
+// WhereFilter is a parsed --where expression
+type WhereFilter struct {
+	expr whereExpr
+}
+
+// whereToken is a lexical token; Kind is "ident", "number", "string", "op", or "eof"
+type whereToken struct {
+	Kind string
+	Text string
+}
+
+// whereOperators are the recognized operator and punctuation tokens, longest first
+var whereOperators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "(", ")", "[", "]", ",", "-", "+", "*", "/"}
+
+// tokenizeWhere splits an expression into tokens
+// Identifiers may contain dots to address nested fields, e.g. pricing.prompt
+func tokenizeWhere(s string) ([]whereToken, error) {
+	var tokens []whereToken
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, whereToken{"ident", string(runes[start:i])})
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.' || runes[i] == 'e' || runes[i] == 'E' ||
+				((runes[i] == '-' || runes[i] == '+') && (runes[i-1] == 'e' || runes[i-1] == 'E'))) {
+				i++
+			}
+			tokens = append(tokens, whereToken{"number", string(runes[start:i])})
+		case r == '"' || r == '\'':
+			// Strings use either quote; a backslash escapes the next character
+			quote := r
+			var b strings.Builder
+			i++
+			for {
+				if i >= len(runes) {
+					return nil, fmt.Errorf("unterminated string literal")
+				}
+				if runes[i] == '\\' && i+1 < len(runes) {
+					b.WriteRune(runes[i+1])
+					i += 2
+					continue
+				}
+				if runes[i] == quote {
+					i++
+					break
+				}
+				b.WriteRune(runes[i])
+				i++
+			}
+			tokens = append(tokens, whereToken{"string", b.String()})
+		default:
+			op := ""
+			for _, candidate := range whereOperators {
+				if strings.HasPrefix(string(runes[i:]), candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character: %q", r)
+			}
+			tokens = append(tokens, whereToken{"op", op})
+			i += len([]rune(op))
+		}
+	}
+	return append(tokens, whereToken{Kind: "eof"}), nil
+}
+
+// whereParser is a recursive descent parser over tokens
+type whereParser struct {
+	tokens  []whereToken
+	pos     int
+	columns *Columns
+}
+
+// peek returns the current token
+func (p *whereParser) peek() whereToken {
+	return p.tokens[p.pos]
+}
+
+// next consumes and returns the current token
+func (p *whereParser) next() whereToken {
+	t := p.tokens[p.pos]
+	if t.Kind != "eof" {
+		p.pos++
+	}
+	return t
+}
+
+// acceptOp consumes the operator if present
+func (p *whereParser) acceptOp(op string) bool {
+	if t := p.peek(); t.Kind == "op" && t.Text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// acceptKeyword consumes the keyword if present
+func (p *whereParser) acceptKeyword(keyword string) bool {
+	if t := p.peek(); t.Kind == "ident" && t.Text == keyword {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// ParseWhere parses a --where expression such as
+// context_length >= 128000 && provider in ["anthropic", "openai"], which may
+// refer to columns
+func ParseWhere(s string, columns *Columns) (*WhereFilter, error) {
+	expr, err := parseWhereExpr(s, columns)
+	if err != nil {
+		return nil, err
+	}
+	return &WhereFilter{expr: expr}, nil
+}
+
+// parseWhereExpr parses an expression of the --where language
+func parseWhereExpr(s string, columns *Columns) (whereExpr, error) {
+	tokens, err := tokenizeWhere(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &whereParser{tokens: tokens, columns: columns}
+	expr, err := p.orExpr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.Kind != "eof" {
+		return nil, fmt.Errorf("unexpected %q", t.Text)
+	}
+	return expr, nil
+}
+
+// orExpr parses a || b
+func (p *whereParser) orExpr() (whereExpr, error) {
+	left, err := p.andExpr()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptOp("||") {
+		right, err := p.andExpr()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(env *whereEnv) interface{} { return whereTruthy(l(env)) || whereTruthy(right(env)) }
+	}
+	return left, nil
+}
+
+// andExpr parses a && b
+func (p *whereParser) andExpr() (whereExpr, error) {
+	left, err := p.notExpr()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptOp("&&") {
+		right, err := p.notExpr()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(env *whereEnv) interface{} { return whereTruthy(l(env)) && whereTruthy(right(env)) }
+	}
+	return left, nil
+}
+
+// notExpr parses !a
+func (p *whereParser) notExpr() (whereExpr, error) {
+	if p.acceptOp("!") {
+		inner, err := p.notExpr()
+		if err != nil {
+			return nil, err
+		}
+		return func(env *whereEnv) interface{} { return !whereTruthy(inner(env)) }, nil
+	}
+	return p.comparison()
+}
+
+// comparison parses a == b and the other comparison operators, a in [..], and a contains b
+func (p *whereParser) comparison() (whereExpr, error) {
+	left, err := p.additive()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.acceptKeyword("in") {
+		list, err := p.list()
+		if err != nil {
+			return nil, err
+		}
+		return func(env *whereEnv) interface{} {
+			l := left(env)
+			for _, item := range list {
+				if whereCompare(l, item) == 0 {
+					return true
+				}
+			}
+			return false
+		}, nil
+	}
+
+	if p.acceptKeyword("contains") {
+		right, err := p.additive()
+		if err != nil {
+			return nil, err
+		}
+		return func(env *whereEnv) interface{} {
+			r := right(env)
+			switch l := left(env).(type) {
+			case []interface{}:
+				for _, item := range l {
+					if whereCompare(item, r) == 0 {
+						return true
+					}
+				}
+				return false
+			case string:
+				s, ok := r.(string)
+				return ok && strings.Contains(strings.ToLower(l), strings.ToLower(s))
+			}
+			return false
+		}, nil
+	}
+
+	t := p.peek()
+	if t.Kind != "op" {
+		return left, nil
+	}
+	var test func(c int) bool
+	switch t.Text {
+	case "==":
+		test = func(c int) bool { return c == 0 }
+	case "!=":
+		test = func(c int) bool { return c != 0 }
+	case "<":
+		test = func(c int) bool { return c < 0 }
+	case "<=":
+		test = func(c int) bool { return c <= 0 }
+	case ">":
+		test = func(c int) bool { return c > 0 }
+	case ">=":
+		test = func(c int) bool { return c >= 0 }
+	default:
+		return left, nil
+	}
+	op := t.Text
+	p.pos++
+
+	right, err := p.additive()
+	if err != nil {
+		return nil, err
+	}
+	return func(env *whereEnv) interface{} {
+		l, r := left(env), right(env)
+		// Null, e.g. from a division by zero, only equals null and orders
+		// with nothing, so price / 0 > 1 is false
+		if l == nil || r == nil {
+			switch op {
+			case "==":
+				return l == nil && r == nil
+			case "!=":
+				return l != nil || r != nil
+			}
+			return false
+		}
+		return test(whereCompare(l, r))
+	}, nil
+}
+
+// additive parses a + b and a - b
+func (p *whereParser) additive() (whereExpr, error) {
+	left, err := p.multiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var op string
+		switch {
+		case p.acceptOp("+"):
+			op = "+"
+		case p.acceptOp("-"):
+			op = "-"
+		default:
+			return left, nil
+		}
+		right, err := p.multiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = whereArithmetic(op, left, right)
+	}
+}
+
+// multiplicative parses a * b and a / b
+func (p *whereParser) multiplicative() (whereExpr, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var op string
+		switch {
+		case p.acceptOp("*"):
+			op = "*"
+		case p.acceptOp("/"):
+			op = "/"
+		default:
+			return left, nil
+		}
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = whereArithmetic(op, left, right)
+	}
+}
+
+// unary parses -a
+func (p *whereParser) unary() (whereExpr, error) {
+	if p.acceptOp("-") {
+		inner, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return whereArithmetic("-", func(*whereEnv) interface{} { return 0.0 }, inner), nil
+	}
+	return p.operand()
+}
+
+// whereArithmetic applies an arithmetic operator to numbers and numeric
+// strings; the result is null if either side is not a number or on division by zero
+func whereArithmetic(op string, left, right whereExpr) whereExpr {
+	return func(env *whereEnv) interface{} {
+		l, ok := whereNumber(left(env))
+		if !ok {
+			return nil
+		}
+		r, ok := whereNumber(right(env))
+		if !ok {
+			return nil
+		}
+		switch op {
+		case "+":
+			return l + r
+		case "-":
+			return l - r
+		case "*":
+			return l * r
+		}
+		if r == 0 {
+			return nil
+		}
+		return l / r
+	}
+}
+
+// list parses ["a", "b"] into literal values
+func (p *whereParser) list() ([]interface{}, error) {
+	if !p.acceptOp("[") {
+		return nil, fmt.Errorf("expected [ after in near %q", p.peek().Text)
+	}
+	var items []interface{}
+	for !p.acceptOp("]") {
+		if len(items) > 0 && !p.acceptOp(",") {
+			return nil, fmt.Errorf("expected , or ] near %q", p.peek().Text)
+		}
+		item, err := p.literal()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// literal parses a number, string, true, false, or null
+func (p *whereParser) literal() (interface{}, error) {
+	negative := p.acceptOp("-")
+	t := p.next()
+	switch {
+	case t.Kind == "number":
+		v, err := strconv.ParseFloat(t.Text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number: %s", t.Text)
+		}
+		if negative {
+			v = -v
+		}
+		return v, nil
+	case negative:
+		return nil, fmt.Errorf("invalid number: -%s", t.Text)
+	case t.Kind == "string":
+		return t.Text, nil
+	case t.Kind == "ident" && (t.Text == "true" || t.Text == "false"):
+		return t.Text == "true", nil
+	case t.Kind == "ident" && t.Text == "null":
+		return nil, nil
+	}
+	return nil, fmt.Errorf("expected a value near %q", t.Text)
+}
+
+// operand parses a field, literal, or parenthesized expression
+func (p *whereParser) operand() (whereExpr, error) {
+	t := p.peek()
+	switch {
+	case t.Kind == "op" && t.Text == "(":
+		p.pos++
+		inner, err := p.orExpr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.acceptOp(")") {
+			return nil, fmt.Errorf("expected ) near %q", p.peek().Text)
+		}
+		return inner, nil
+	case t.Kind == "ident" && t.Text != "true" && t.Text != "false" && t.Text != "null":
+		p.pos++
+		path := t.Text
+		if virtual, ok := whereVirtualFields[path]; ok {
+			return func(env *whereEnv) interface{} { return virtual(env.model) }, nil
+		}
+		if column, ok := p.columns.Lookup(path); ok {
+			return column.whereValue, nil
+		}
+		if !IsModelField(path) {
+			return nil, fmt.Errorf("unknown field: %s", path)
+		}
+		return func(env *whereEnv) interface{} {
+			v, _ := LookupPath(env.fields, path)
+			return v
+		}, nil
+	}
+	v, err := p.literal()
+	if err != nil {
+		return nil, err
+	}
+	return func(*whereEnv) interface{} { return v }, nil
+}
+
+// whereNumber converts numbers and numeric strings (such as prices) to float64
+func whereNumber(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// whereTruthy converts a value to a boolean: true, non-zero numbers, and
+// non-empty strings and arrays are true; null is false
+func whereTruthy(v interface{}) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case []interface{}:
+		return len(v) > 0
+	case nil:
+		return false
+	}
+	f, ok := whereNumber(v)
+	return ok && f != 0
+}
+
+// boolCompare orders two booleans, false first
+func boolCompare(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case !a:
+		return -1
+	}
+	return 1
+}
+
+// whereCompare orders two values, numerically when both are numbers or
+// numeric strings, otherwise as case-insensitive strings
+func whereCompare(a, b interface{}) int {
+	if af, ok := whereNumber(a); ok {
+		if bf, ok := whereNumber(b); ok {
+			switch {
+			case af < bf:
+				return -1
+			case af > bf:
+				return 1
+			}
+			return 0
+		}
+	}
+	if ab, ok := a.(bool); ok {
+		if bb, ok := b.(bool); ok {
+			return boolCompare(ab, bb)
+		}
+	}
+	return strings.Compare(strings.ToLower(FormatFieldValue(a)), strings.ToLower(FormatFieldValue(b)))
+}
+
+// Match reports whether the expression is true for model
+func (f *WhereFilter) Match(model Model) bool {
+	fields, err := ModelJSONMap(model)
+	if err != nil {
+		return false
+	}
+	return whereTruthy(f.expr(&whereEnv{model: model, fields: fields}))
+}
+
+// FilterModelsWhere returns the models for which the expression is true
+func FilterModelsWhere(models []Model, f *WhereFilter) []Model {
+	if f == nil {
+		return models
+	}
+	var filtered []Model
+	for _, model := range models {
+		if f.Match(model) {
+			filtered = append(filtered, model)
+		}
+	}
+	return filtered
+}
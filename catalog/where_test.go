@@ -0,0 +1,66 @@
+package catalog
+
+import "testing"
+
+func TestParseWhere(t *testing.T) {
+	model := Model{
+		ID:            "anthropic/claude-3.5-sonnet",
+		Name:          "Anthropic: Claude 3.5 Sonnet",
+		ContextLength: 200000,
+		Pricing:       Pricing{Prompt: "0.000003", Completion: "0.000015"},
+	}
+	free := Model{ID: "meta-llama/llama-3.1-8b-instruct:free", ContextLength: 8192, Pricing: Pricing{Prompt: "0", Completion: "0"}}
+
+	tests := []struct {
+		expr  string
+		model Model
+		want  bool
+	}{
+		{`context_length >= 128000`, model, true},
+		{`context_length < 128000`, model, false},
+		{`ctx == 200000 && provider == "anthropic"`, model, true},
+		{`provider in ["openai", "anthropic"]`, model, true},
+		{`provider in ["openai"]`, model, false},
+		{`name contains "sonnet"`, model, true},
+		{`!(name contains "sonnet")`, model, false},
+		{`variant == "free" || ctx > 100000`, free, true},
+		{`variant == "free"`, model, false},
+		{`ctx / 1000 == 200`, model, true},
+		{`ctx - -1 == 200001`, model, true},
+		{`price > 0`, model, true},
+		{`price == 0`, free, true},
+		// Null from a division by zero matches only null comparisons
+		{`price / 0 > 1`, model, false},
+		{`price / 0 < 1`, model, false},
+		{`price / 0 == null`, model, true},
+		{`price / 0 != 1`, model, true},
+		{`expiration_date == ""`, model, true},
+	}
+	for _, tt := range tests {
+		f, err := ParseWhere(tt.expr, nil)
+		if err != nil {
+			t.Errorf("ParseWhere(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := f.Match(tt.model); got != tt.want {
+			t.Errorf("ParseWhere(%q).Match(%s) = %v, want %v", tt.expr, tt.model.ID, got, tt.want)
+		}
+	}
+}
+
+func TestParseWhereErrors(t *testing.T) {
+	tests := []string{
+		``,
+		`no_such_field == 1`,
+		`ctx >=`,
+		`(ctx > 1`,
+		`ctx > 1 )`,
+		`provider in "anthropic"`,
+		`name == "unterminated`,
+	}
+	for _, expr := range tests {
+		if _, err := ParseWhere(expr, nil); err == nil {
+			t.Errorf("ParseWhere(%q) succeeded, want an error", expr)
+		}
+	}
+}
@@ -0,0 +1,506 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// Chat routes selected with --via
+const (
+	ChatViaAuto       = "auto"       // First-party API when its key is set, otherwise OpenRouter
+	ChatViaOpenRouter = "openrouter" // Always OpenRouter
+	ChatViaDirect     = "direct"     // Always the first-party API
+)
+
+// chatVias are the accepted --via values
+var chatVias = []string{ChatViaAuto, ChatViaOpenRouter, ChatViaDirect}
+
+// defaultChatMaxTokens is sent to APIs that require a reply limit (Anthropic)
+const defaultChatMaxTokens = 1024
+
+// Chat API formats
+const (
+	chatFormatOpenAI    = "openai"    // OpenAI chat completions, also OpenRouter and Mistral
+	chatFormatAnthropic = "anthropic" // Anthropic messages
+	chatFormatOllama    = "ollama"    // Ollama /api/chat
+)
+
+// firstPartyNames are display names of the first-party chat APIs, keyed by
+// provider prefix; their endpoints and keys are in rateLimitProviders
+var firstPartyNames = map[string]string{
+	"openai":    "OpenAI",
+	"anthropic": "Anthropic",
+	"mistralai": "Mistral",
+}
+
+// ChatMessage is one message of a conversation
+type ChatMessage struct {
+	Role    string `json:"role"` // system, user, or assistant
+	Content string `json:"content"`
+}
+
+// ChatRequest is a single chat completion request
+type ChatRequest struct {
+	Model       string // Catalog model ID, e.g. openai/gpt-4.1 or ollama/llama3.1:8b
+	Messages    []ChatMessage
+	MaxTokens   int      // 0 uses the provider default
+	Temperature *float64 // nil uses the provider default
+	JSONMode    bool     // Ask for a JSON object reply where the API supports it
+	Tools       []ChatTool
+}
+
+// ChatTool is a function the model may call
+type ChatTool struct {
+	Name        string
+	Description string
+	Parameters  map[string]interface{} // JSON schema of the arguments
+}
+
+// ChatToolCall is a function call made by the model
+type ChatToolCall struct {
+	Name      string
+	Arguments string // JSON-encoded arguments
+}
+
+// ChatResult is the outcome of a chat completion
+type ChatResult struct {
+	Text         string
+	ToolCalls    []ChatToolCall
+	Backend      string // API that answered, e.g. OpenRouter or Anthropic
+	InputTokens  int
+	OutputTokens int
+	Latency      time.Duration // Until the reply was complete
+	FirstToken   time.Duration // Until the first text arrived
+}
+
+// ChatBackend is the API a chat request is sent to
+type ChatBackend struct {
+	Name           string
+	URL            string
+	Headers        map[string]string
+	Model          string // Model ID the API expects
+	Format         string
+	MaxTokensField string
+}
+
+// ValidateChatVia checks a --via value
+func ValidateChatVia(via string) error {
+	for _, v := range chatVias {
+		if v == via {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid --via: %s (expected %s)", via, strings.Join(chatVias, ", "))
+}
+
+// ParseTemperature parses a --temperature value; "" leaves the provider default
+func ParseTemperature(s string) (*float64, error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := strconv.ParseFloat(s, 64)
+	if err != nil || t < 0 {
+		return nil, fmt.Errorf("invalid --temperature: %s", s)
+	}
+	return &t, nil
+}
+
+// ResolveChatBackend chooses the API for a model: Ollama for ollama/ models,
+// the first-party API when its key is set (or --via direct), otherwise OpenRouter
+func ResolveChatBackend(modelID, via, ollamaHost string) (ChatBackend, error) {
+	if strings.HasPrefix(modelID, "ollama/") {
+		return ChatBackend{
+			Name:   "Ollama",
+			URL:    ollamaHost + "/api/chat",
+			Model:  strings.TrimPrefix(modelID, "ollama/"),
+			Format: chatFormatOllama,
+		}, nil
+	}
+
+	provider := catalog.ExtractProvider(modelID)
+	if p, ok := rateLimitProviders[provider]; ok && via != ChatViaOpenRouter {
+		if key := os.Getenv(p.KeyEnv); key != "" {
+			format := chatFormatOpenAI
+			if provider == "anthropic" {
+				format = chatFormatAnthropic
+			}
+			return ChatBackend{
+				Name:           firstPartyNames[provider],
+				URL:            p.URL,
+				Headers:        p.Headers(key),
+				Model:          p.NativeID(modelID),
+				Format:         format,
+				MaxTokensField: p.MaxTokensField,
+			}, nil
+		}
+		if via == ChatViaDirect {
+			return ChatBackend{}, fmt.Errorf("%s is required for --via direct", p.KeyEnv)
+		}
+	} else if via == ChatViaDirect {
+		return ChatBackend{}, fmt.Errorf("no first-party API for provider %s (supported: openai, anthropic, mistralai)", provider)
+	}
+
+	key := os.Getenv("OPENROUTER_API_KEY")
+	if key == "" {
+		return ChatBackend{}, fmt.Errorf("OPENROUTER_API_KEY is required to chat with %s", modelID)
+	}
+	return ChatBackend{
+		Name:           "OpenRouter",
+		URL:            openRouterChatURL,
+		Headers:        map[string]string{"Authorization": "Bearer " + key},
+		Model:          modelID,
+		Format:         chatFormatOpenAI,
+		MaxTokensField: "max_tokens",
+	}, nil
+}
+
+// requestBody builds the streaming request body in the backend's format
+func (b ChatBackend) requestBody(req ChatRequest) map[string]interface{} {
+	body := map[string]interface{}{"model": b.Model, "stream": true}
+	messages := req.Messages
+	maxTokens := req.MaxTokens
+
+	switch b.Format {
+	case chatFormatAnthropic:
+		// Anthropic takes the system prompt separately and requires max_tokens
+		var system []string
+		var rest []ChatMessage
+		for _, m := range messages {
+			if m.Role == "system" {
+				system = append(system, m.Content)
+			} else {
+				rest = append(rest, m)
+			}
+		}
+		if len(system) > 0 {
+			body["system"] = strings.Join(system, "\n\n")
+		}
+		messages = rest
+		if maxTokens == 0 {
+			maxTokens = defaultChatMaxTokens
+		}
+		if len(req.Tools) > 0 {
+			var tools []map[string]interface{}
+			for _, t := range req.Tools {
+				tools = append(tools, map[string]interface{}{
+					"name": t.Name, "description": t.Description, "input_schema": t.Parameters,
+				})
+			}
+			body["tools"] = tools
+		}
+	case chatFormatOllama:
+		options := map[string]interface{}{}
+		if maxTokens > 0 {
+			options["num_predict"] = maxTokens
+		}
+		if req.Temperature != nil {
+			options["temperature"] = *req.Temperature
+		}
+		if len(options) > 0 {
+			body["options"] = options
+		}
+		if req.JSONMode {
+			body["format"] = "json"
+		}
+		if len(req.Tools) > 0 {
+			body["tools"] = openAITools(req.Tools)
+		}
+		body["messages"] = messages
+		return body
+	case chatFormatOpenAI:
+		if b.Name != "Mistral" {
+			// Usage arrives in a final chunk; Mistral sends it unasked and rejects unknown fields
+			body["stream_options"] = map[string]bool{"include_usage": true}
+		}
+		if req.JSONMode {
+			body["response_format"] = map[string]string{"type": "json_object"}
+		}
+		if len(req.Tools) > 0 {
+			body["tools"] = openAITools(req.Tools)
+		}
+	}
+
+	body["messages"] = messages
+	if maxTokens > 0 {
+		body[b.MaxTokensField] = maxTokens
+	}
+	if req.Temperature != nil {
+		body["temperature"] = *req.Temperature
+	}
+	return body
+}
+
+// openAITools converts tools to the OpenAI format, which Ollama also uses
+func openAITools(tools []ChatTool) []map[string]interface{} {
+	var converted []map[string]interface{}
+	for _, t := range tools {
+		converted = append(converted, map[string]interface{}{
+			"type": "function",
+			"function": map[string]interface{}{
+				"name": t.Name, "description": t.Description, "parameters": t.Parameters,
+			},
+		})
+	}
+	return converted
+}
+
+// chatAPIError extracts the error message of a failed chat request
+func chatAPIError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	var apiErr struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(data, &apiErr) == nil && len(apiErr.Error) > 0 {
+		// Ollama sends a string, the others an object with a message
+		var message string
+		if json.Unmarshal(apiErr.Error, &message) != nil {
+			var obj struct {
+				Message string `json:"message"`
+			}
+			json.Unmarshal(apiErr.Error, &obj)
+			message = obj.Message
+		}
+		if message != "" {
+			return fmt.Errorf("status %d: %s", resp.StatusCode, message)
+		}
+	}
+	return &catalog.HTTPStatusError{StatusCode: resp.StatusCode}
+}
+
+// Chat sends a request to the backend and streams the reply text to onText
+// (which may be nil) as it arrives
+func (b ChatBackend) Chat(req ChatRequest, onText func(string)) (ChatResult, error) {
+	result := ChatResult{Backend: b.Name}
+	data, err := json.Marshal(b.requestBody(req))
+	if err != nil {
+		return result, err
+	}
+	httpReq, err := http.NewRequest(http.MethodPost, b.URL, bytes.NewReader(data))
+	if err != nil {
+		return result, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	for name, value := range b.Headers {
+		httpReq.Header.Set(name, value)
+	}
+
+	start := time.Now()
+	client := &http.Client{Timeout: 10 * time.Minute}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return result, fmt.Errorf("%s request failed: %w", b.Name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return result, fmt.Errorf("%s: %w", b.Name, chatAPIError(resp))
+	}
+
+	var text strings.Builder
+	emit := func(s string) {
+		if s == "" {
+			return
+		}
+		if text.Len() == 0 {
+			result.FirstToken = time.Since(start)
+		}
+		text.WriteString(s)
+		if onText != nil {
+			onText(s)
+		}
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if b.Format != chatFormatOllama {
+			// Server-sent events: only data lines carry payloads
+			payload, ok := strings.CutPrefix(line, "data:")
+			if !ok {
+				continue
+			}
+			line = strings.TrimSpace(payload)
+			if line == "[DONE]" {
+				break
+			}
+		}
+		if line == "" {
+			continue
+		}
+		if err := b.parseChunk([]byte(line), &result, emit); err != nil {
+			return result, fmt.Errorf("%s: %w", b.Name, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return result, fmt.Errorf("%s: failed to read response: %w", b.Name, err)
+	}
+
+	result.Text = text.String()
+	result.Latency = time.Since(start)
+	return result, nil
+}
+
+// parseChunk handles one streamed chunk, passing text to emit and recording usage
+func (b ChatBackend) parseChunk(data []byte, result *ChatResult, emit func(string)) error {
+	switch b.Format {
+	case chatFormatAnthropic:
+		var event struct {
+			Type    string `json:"type"`
+			Message struct {
+				Usage struct {
+					InputTokens int `json:"input_tokens"`
+				} `json:"usage"`
+			} `json:"message"`
+			ContentBlock struct {
+				Type string `json:"type"`
+				Name string `json:"name"`
+			} `json:"content_block"`
+			Delta struct {
+				Text        string `json:"text"`
+				PartialJSON string `json:"partial_json"`
+			} `json:"delta"`
+			Usage struct {
+				OutputTokens int `json:"output_tokens"`
+			} `json:"usage"`
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(data, &event); err != nil {
+			return fmt.Errorf("failed to parse stream: %w", err)
+		}
+		switch event.Type {
+		case "message_start":
+			result.InputTokens = event.Message.Usage.InputTokens
+		case "content_block_start":
+			if event.ContentBlock.Type == "tool_use" {
+				result.ToolCalls = append(result.ToolCalls, ChatToolCall{Name: event.ContentBlock.Name})
+			}
+		case "content_block_delta":
+			emit(event.Delta.Text)
+			if event.Delta.PartialJSON != "" && len(result.ToolCalls) > 0 {
+				result.ToolCalls[len(result.ToolCalls)-1].Arguments += event.Delta.PartialJSON
+			}
+		case "message_delta":
+			result.OutputTokens = event.Usage.OutputTokens
+		case "error":
+			return fmt.Errorf("%s", event.Error.Message)
+		}
+
+	case chatFormatOllama:
+		var chunk struct {
+			Message struct {
+				Content   string `json:"content"`
+				ToolCalls []struct {
+					Function struct {
+						Name      string          `json:"name"`
+						Arguments json.RawMessage `json:"arguments"` // An object, unlike OpenAI
+					} `json:"function"`
+				} `json:"tool_calls"`
+			} `json:"message"`
+			PromptEvalCount int    `json:"prompt_eval_count"`
+			EvalCount       int    `json:"eval_count"`
+			Error           string `json:"error"`
+		}
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			return fmt.Errorf("failed to parse stream: %w", err)
+		}
+		if chunk.Error != "" {
+			return fmt.Errorf("%s", chunk.Error)
+		}
+		emit(chunk.Message.Content)
+		for _, call := range chunk.Message.ToolCalls {
+			result.ToolCalls = append(result.ToolCalls, ChatToolCall{Name: call.Function.Name, Arguments: string(call.Function.Arguments)})
+		}
+		if chunk.EvalCount > 0 {
+			result.InputTokens, result.OutputTokens = chunk.PromptEvalCount, chunk.EvalCount
+		}
+
+	default:
+		var chunk struct {
+			Choices []struct {
+				Delta struct {
+					Content   string `json:"content"`
+					ToolCalls []struct {
+						Index    int `json:"index"`
+						Function struct {
+							Name      string `json:"name"`
+							Arguments string `json:"arguments"`
+						} `json:"function"`
+					} `json:"tool_calls"`
+				} `json:"delta"`
+			} `json:"choices"`
+			Usage *struct {
+				PromptTokens     int `json:"prompt_tokens"`
+				CompletionTokens int `json:"completion_tokens"`
+			} `json:"usage"`
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			return fmt.Errorf("failed to parse stream: %w", err)
+		}
+		if chunk.Error != nil {
+			return fmt.Errorf("%s", chunk.Error.Message)
+		}
+		for _, choice := range chunk.Choices {
+			emit(choice.Delta.Content)
+			// Tool call names arrive first and arguments in fragments, by index
+			for _, call := range choice.Delta.ToolCalls {
+				for len(result.ToolCalls) <= call.Index {
+					result.ToolCalls = append(result.ToolCalls, ChatToolCall{})
+				}
+				result.ToolCalls[call.Index].Name += call.Function.Name
+				result.ToolCalls[call.Index].Arguments += call.Function.Arguments
+			}
+		}
+		if chunk.Usage != nil {
+			result.InputTokens, result.OutputTokens = chunk.Usage.PromptTokens, chunk.Usage.CompletionTokens
+		}
+	}
+	return nil
+}
+
+// ChatCost returns the cost in USD of a chat result at the model's catalog
+// prices; ok is false for models without pricing (e.g. local models)
+func ChatCost(model catalog.Model, result ChatResult) (cost float64, ok bool) {
+	if model.Pricing.Prompt == "" {
+		return 0, false
+	}
+	return float64(result.InputTokens)*catalog.ParsePrice(model.Pricing.Prompt) +
+		float64(result.OutputTokens)*catalog.ParsePrice(model.Pricing.Completion) +
+		catalog.ParsePrice(model.Pricing.Request), true
+}
+
+// FormatCost formats a cost in USD with enough precision for single requests
+func FormatCost(cost float64) string {
+	if cost >= 0.01 {
+		return FormatUSD(fmt.Sprintf("%.4f", cost))
+	}
+	return FormatUSD(fmt.Sprintf("%.6f", cost))
+}
+
+// FormatChatStats summarizes a chat result, e.g.
+// "openai/gpt-4.1 via OpenAI · 1.84s (first token 0.42s) · 12 in / 85 out tokens · $0.000704"
+func FormatChatStats(modelID string, result ChatResult, cost float64, costOK bool) string {
+	parts := []string{
+		modelID + " via " + result.Backend,
+		fmt.Sprintf("%.2fs (first token %.2fs)", result.Latency.Seconds(), result.FirstToken.Seconds()),
+		fmt.Sprintf("%s in / %s out tokens", FormatNumber(result.InputTokens), FormatNumber(result.OutputTokens)),
+	}
+	if costOK {
+		parts = append(parts, FormatCost(cost))
+	}
+	return strings.Join(parts, " · ")
+}
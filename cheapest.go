@@ -0,0 +1,246 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// localSourceNames are the display names of local sources for the cheapest verdict
+var localSourceNames = map[string]string{
+	"ollama":   "Ollama",
+	"tgi":      "TGI",
+	"llamacpp": "llama.cpp",
+}
+
+// CheapestOption is one source of a model ranked by `llmls cheapest`
+type CheapestOption struct {
+	Source catalog.ModelSource
+	Label  string  // Source with its routing variant, e.g. openrouter:free
+	Price  float64 // Blended price per token; 0 for free and local sources
+	Priced bool    // False when the catalog has no price and the source is not local
+	Local  bool
+	// RequestCost is what the system prompt and media cost per request; 0 for
+	// free and local sources and without either
+	RequestCost float64
+	// RequestPriced is false when the source does not price a kind of media
+	RequestPriced bool
+	// Media is whether the model takes the media input; SupportYes without media
+	Media Support
+}
+
+// NameKey returns the DedupeKey of a model name given on the command line,
+// which may be a catalog ID, an Ollama name (llama3.1:8b), or a plain name
+// (llama-3.1-8b)
+func NameKey(name string) string {
+	if !strings.Contains(name, "/") && strings.Contains(name, ":") && catalog.ModelVariant(name) == "" {
+		name = "ollama/" + name
+	}
+	return DedupeKey(catalog.Model{ID: strings.ToLower(name)})
+}
+
+// FindModelKey resolves a model name to the DedupeKey of one model in the
+// catalog; a name without a size (llama-3.1) resolves when only one size is
+// available and is reported as ambiguous otherwise
+func FindModelKey(models []catalog.Model, name string) (string, error) {
+	want := NameKey(name)
+	if want == "" {
+		return "", fmt.Errorf("no model name given")
+	}
+	seen := make(map[string]bool)
+	var keys []string
+	for _, model := range models {
+		key := DedupeKey(model)
+		if key == want {
+			return key, nil
+		}
+		if strings.HasPrefix(key, want+"-") && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	switch len(keys) {
+	case 0:
+		return "", fmt.Errorf("no model matches %s", name)
+	case 1:
+		return keys[0], nil
+	}
+	sort.Strings(keys)
+	return "", fmt.Errorf("%s matches several models, be more specific: %s", name, strings.Join(keys, ", "))
+}
+
+// CheapestSources returns every source of the model with the given key,
+// cheapest first; local servers rank before free hosted variants at the same
+// price of zero, and sources without a known price rank last
+// With a system prompt or media, what they cost per request ranks first, since
+// that overhead can outweigh a lower token price; sources whose model does not
+// take the media rank last, after those not reporting whether it does
+func CheapestSources(models []catalog.Model, key string, blend catalog.Blend, overhead *SystemOverhead, media MediaInput) []CheapestOption {
+	var options []CheapestOption
+	for _, model := range models {
+		if DedupeKey(model) != key {
+			continue
+		}
+		option := CheapestOption{
+			Source:        catalog.ModelSource{ID: model.ID, Source: catalog.ModelSourceName(model), Pricing: model.Pricing},
+			Label:         catalog.ModelSourceName(model),
+			RequestPriced: true,
+			Media:         media.Support(model),
+		}
+		if variant := catalog.ModelVariant(model.ID); variant != "" {
+			option.Label += ":" + variant
+		}
+		if catalog.IsLocalSource(option.Source.Source) {
+			option.Local, option.Priced = true, true
+		} else {
+			option.Price, option.Priced = blend.Price(model)
+			if overhead != nil && option.Priced {
+				option.RequestCost = overhead.Cost(catalog.ParsePrice(model.Pricing.Prompt))
+			}
+			if !media.Empty() && option.Priced && option.Price != 0 {
+				cost, ok := media.Cost(model.Pricing)
+				option.RequestCost += cost
+				option.RequestPriced = ok
+			}
+		}
+		options = append(options, option)
+	}
+
+	// Local servers and free variants rarely report modalities, but they
+	// serve the same model as the sources that do
+	known := SupportUnknown
+	for _, option := range options {
+		if option.Media != SupportUnknown {
+			known = option.Media
+			break
+		}
+	}
+	for i := range options {
+		if options[i].Media == SupportUnknown {
+			options[i].Media = known
+		}
+	}
+
+	sort.SliceStable(options, func(i, j int) bool {
+		a, b := options[i], options[j]
+		if a.Media != b.Media {
+			return mediaRank[a.Media] < mediaRank[b.Media]
+		}
+		if a.Priced != b.Priced {
+			return a.Priced
+		}
+		if a.RequestPriced != b.RequestPriced {
+			return a.RequestPriced
+		}
+		if a.RequestCost != b.RequestCost {
+			return a.RequestCost < b.RequestCost
+		}
+		if a.Price != b.Price {
+			return a.Price < b.Price
+		}
+		return a.Local && !b.Local
+	})
+	return options
+}
+
+// mediaRank orders sources by whether their model takes the media input
+var mediaRank = map[Support]int{SupportYes: 0, SupportUnknown: 1, SupportNo: 2}
+
+// CheapestVerdict describes the cheapest option in a sentence
+func CheapestVerdict(option CheapestOption) string {
+	switch {
+	case option.Media == SupportNo:
+		return fmt.Sprintf("none; no source takes the media input (%s)", option.Source.ID)
+	case option.Local:
+		return fmt.Sprintf("free locally via %s (%s)", localSourceNames[option.Source.Source], option.Source.ID)
+	case !option.Priced:
+		return fmt.Sprintf("unknown; no source has a price (%s)", option.Source.ID)
+	case option.Price == 0:
+		return fmt.Sprintf("free via %s (%s)", option.Label, option.Source.ID)
+	}
+	return fmt.Sprintf("%s via %s (%s)", FormatModelPrice(catalog.Model{Pricing: option.Source.Pricing}), option.Label, option.Source.ID)
+}
+
+// DisplayCheapest prints the sources of a model with their prices per 1K
+// prompt and completion tokens and the blended price per 1M tokens,
+// followed by the cheapest one
+// With a system prompt overhead, each source also shows what the system
+// prompt costs per 1K requests, and likewise with media input
+func DisplayCheapest(key string, options []CheapestOption, blend catalog.Blend, overhead *SystemOverhead, media MediaInput) {
+	labelWidth, idWidth := len("SOURCE"), len("MODEL")
+	for _, o := range options {
+		labelWidth = max(labelWidth, len(o.Label))
+		idWidth = max(idWidth, len(o.Source.ID))
+	}
+
+	fmt.Printf("Sources of %s (prompt:completion %s):\n", key, blend)
+	if overhead != nil {
+		estimate := "estimated"
+		if overhead.Tokenizer != "" {
+			estimate += " for the " + overhead.Tokenizer + " tokenizer"
+		}
+		fmt.Printf("System prompt %s: ~%s tokens (%s)\n", overhead.File, FormatNumber(overhead.Tokens), estimate)
+	}
+	if !media.Empty() {
+		fmt.Printf("Media per request: %s\n", media)
+	}
+	fmt.Println()
+	header := fmt.Sprintf("%-*s %-*s %12s %12s %12s", labelWidth, "SOURCE", idWidth, "MODEL", "PROMPT/1K", "COMPL/1K", "BLENDED")
+	if overhead != nil {
+		header += fmt.Sprintf(" %14s", "SYSTEM/1K REQ")
+	}
+	if !media.Empty() {
+		header += fmt.Sprintf(" %14s", "MEDIA/1K REQ")
+	}
+	fmt.Println(header)
+	for _, o := range options {
+		prompt, completion, blended := "-", "-", "-"
+		switch {
+		case o.Local:
+			prompt, completion, blended = "local", "local", FormatUSD("0")+"/1M"
+		case o.Priced && o.Price == 0:
+			prompt, completion, blended = "free", "free", FormatUSD("0")+"/1M"
+		case o.Priced:
+			prompt = FormatUSD(FormatPrice(o.Source.Pricing.Prompt))
+			completion = FormatUSD(FormatPrice(o.Source.Pricing.Completion))
+			blended = FormatBlendedPrice(catalog.Model{Pricing: o.Source.Pricing}, blend)
+		}
+		row := fmt.Sprintf("%-*s %-*s %12s %12s %12s", labelWidth, o.Label, idWidth, o.Source.ID, prompt, completion, blended)
+		if overhead != nil {
+			system := "-"
+			switch {
+			case o.Local || (o.Priced && o.Price == 0):
+				system = prompt
+			case o.Priced:
+				system = FormatUSD(fmt.Sprintf("%.4f", o.RequestCost*1000))
+			}
+			row += fmt.Sprintf(" %14s", system)
+		}
+		if !media.Empty() {
+			row += fmt.Sprintf(" %14s", formatMediaCost(o, media, prompt))
+		}
+		fmt.Println(row)
+	}
+	fmt.Printf("\nCheapest: %s\n", CheapestVerdict(options[0]))
+}
+
+// formatMediaCost renders what the media input costs a source per 1K
+// requests; local and free sources show their prompt price label, and models
+// that do not take the media show unsupported
+func formatMediaCost(o CheapestOption, media MediaInput, prompt string) string {
+	switch {
+	case o.Media == SupportNo:
+		return "unsupported"
+	case o.Media == SupportUnknown:
+		return "?"
+	case o.Local || (o.Priced && o.Price == 0):
+		return prompt
+	}
+	cost, ok := media.Cost(o.Source.Pricing)
+	if !o.Priced || !ok {
+		return "-"
+	}
+	return FormatUSD(fmt.Sprintf("%.4f", cost*1000))
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// heatColors are ANSI 256-color codes from green (cheap) to red (expensive)
+var heatColors = []int{46, 118, 226, 214, 208, 196}
+
+// UseColor resolves a --color mode (auto, always, never) to whether output is colored
+// In auto mode, color is used on a terminal that renders ANSI escape sequences
+// unless NO_COLOR is set
+func UseColor(mode string) (bool, error) {
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto", "":
+		return os.Getenv("NO_COLOR") == "" && StdoutIsTerminal() && consoleColor, nil
+	}
+	return false, fmt.Errorf("invalid color mode: %s (expected auto, always, or never)", mode)
+}
+
+// colorize wraps s in an ANSI 256-color foreground escape sequence
+func colorize(s string, color int) string {
+	return fmt.Sprintf("\x1b[38;5;%dm%s\x1b[0m", color, s)
+}
+
+// modelPrice returns the combined prompt and completion per-token price
+// ok is false for models without pricing (e.g. local models)
+func modelPrice(model catalog.Model) (price float64, ok bool) {
+	if model.Pricing.Prompt == "" {
+		return 0, false
+	}
+	return catalog.ParsePrice(model.Pricing.Prompt) + catalog.ParsePrice(model.Pricing.Completion), true
+}
+
+// PriceHeat assigns each priced model a heat color by its price percentile in
+// the set; free models are always green; models without pricing get no entry
+func PriceHeat(models []catalog.Model) map[string]int {
+	var prices []float64
+	for _, model := range models {
+		if p, ok := modelPrice(model); ok && p > 0 {
+			prices = append(prices, p)
+		}
+	}
+	sort.Float64s(prices)
+
+	heat := make(map[string]int)
+	for _, model := range models {
+		p, ok := modelPrice(model)
+		if !ok {
+			continue
+		}
+		if p == 0 {
+			heat[model.ID] = heatColors[0]
+			continue
+		}
+		if len(prices) < 2 {
+			// A single paid model has no distribution to compare against
+			heat[model.ID] = heatColors[len(heatColors)/2]
+			continue
+		}
+		rank := sort.SearchFloat64s(prices, p)
+		percentile := float64(rank) / float64(len(prices)-1)
+		heat[model.ID] = heatColors[int(percentile*float64(len(heatColors)-1)+0.5)]
+	}
+	return heat
+}
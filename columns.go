@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// listColumns are the list columns that accept width and truncation settings
+var listColumns = []string{"id", "provider", "desc"}
+
+// parseColumnSettings parses "column=value,column=value" into a map,
+// rejecting unknown column names
+func parseColumnSettings(s string) (map[string]string, error) {
+	settings := make(map[string]string)
+	if s == "" {
+		return settings, nil
+	}
+
+	for _, item := range strings.Split(s, ",") {
+		column, value, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("invalid column setting %q (expected column=value)", item)
+		}
+		if !isListColumn(column) {
+			return nil, fmt.Errorf("unknown column %q (expected one of: %s)", column, strings.Join(listColumns, ", "))
+		}
+		settings[column] = value
+	}
+	return settings, nil
+}
+
+// isListColumn reports whether name is a configurable list column
+func isListColumn(name string) bool {
+	for _, column := range listColumns {
+		if column == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseMaxWidths parses --max-width, e.g. "id=40,desc=60"
+func ParseMaxWidths(s string) (map[string]int, error) {
+	settings, err := parseColumnSettings(s)
+	if err != nil {
+		return nil, err
+	}
+
+	widths := make(map[string]int, len(settings))
+	for column, value := range settings {
+		width, err := strconv.Atoi(value)
+		if err != nil || width < 1 {
+			return nil, fmt.Errorf("invalid width for %s: %s", column, value)
+		}
+		widths[column] = width
+	}
+	return widths, nil
+}
+
+// ParseTruncateSides parses --truncate, e.g. "id=left", returning the columns truncated from the left
+func ParseTruncateSides(s string) (map[string]bool, error) {
+	settings, err := parseColumnSettings(s)
+	if err != nil {
+		return nil, err
+	}
+
+	left := make(map[string]bool, len(settings))
+	for column, value := range settings {
+		switch value {
+		case "left":
+			left[column] = true
+		case "right":
+			left[column] = false
+		default:
+			return nil, fmt.Errorf("invalid truncation side for %s: %s (expected left or right)", column, value)
+		}
+	}
+	return left, nil
+}
+
+// TruncateColumn shortens s to at most width characters including the ellipsis
+// When left is true the beginning is cut, keeping the distinctive end visible
+func TruncateColumn(s string, width int, left bool, ellipsis string) string {
+	runes := []rune(s)
+	if width <= 0 || len(runes) <= width {
+		return s
+	}
+
+	keep := width - len([]rune(ellipsis))
+	if keep < 1 {
+		keep = 1
+	}
+	if left {
+		return ellipsis + string(runes[len(runes)-keep:])
+	}
+	return string(runes[:keep]) + ellipsis
+}
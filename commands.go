@@ -0,0 +1,560 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Command is an llmls subcommand; its help and man page section are
+// generated from these fields and the flags Flags declares
+type Command struct {
+	Name        string
+	Summary     string         // Shown in the help; a newline continues it on an indented line
+	Usage       string         // Synopsis after "llmls"; a newline starts another one
+	Description string         // Shown after the usage
+	Flags       FlagRegisterer // Flags of the command, or nil
+	Notes       string         // Shown after the options, e.g. examples
+	Actions     []Command      // Subcommands with flags of their own, e.g. manifest verify
+	Run         func()
+}
+
+// maxCommandDistance is the largest edit distance of a suggested subcommand
+const maxCommandDistance = 2
+
+// Commands returns the subcommands in help order
+func Commands() []Command {
+	return []Command{
+		{
+			Name:    "providers",
+			Summary: "List all provider names",
+			Usage:   "providers [--incidents] [--aliases]",
+			Description: `List all provider names. Spellings of the same provider (meta, metaai,
+meta-llama) are listed once under the canonical name.
+`,
+			Flags: new(providersFlags),
+			Run:   providersCommand,
+		},
+		{
+			Name:    "discover",
+			Summary: "Find local inference servers on the network",
+			Usage:   "discover [--scan] [--timeout 2s] [--save]",
+			Description: `Find Ollama, LM Studio, vLLM, and llama.cpp servers via mDNS and port probing.
+On a terminal, offers to add env OLLAMA_HOST and LLAMACPP_HOST entries for them
+to the config file.
+`,
+			Flags: new(discoverFlags),
+			Run:   discoverCommand,
+		},
+		{
+			Name:    "status",
+			Summary: "Show reachability and model counts of each source",
+			Usage:   "status [--json] [source options]",
+			Description: `Check every configured source and show reachability, latency, auth, and model counts,
+followed by the current incident state of the OpenRouter, OpenAI, and Anthropic status pages.
+`,
+			Flags: new(statusFlags),
+			Run:   statusCommand,
+		},
+		{
+			Name:    "validate",
+			Summary: "Check model IDs from a file or stdin against the catalog",
+			Usage:   "validate [--file models.txt] [source options]",
+			Description: `Check model IDs against the live catalog and report unknown, renamed, or deprecated IDs.
+Exits with status 1 if any ID is unknown or renamed.
+`,
+			Flags: new(validateFlags),
+			Run:   validateCommand,
+		},
+		{
+			Name:        "rm",
+			Summary:     "Remove Ollama models",
+			Usage:       "rm [--ollama-host URL] <ollama/name>...",
+			Description: "Remove models from the Ollama server. The ollama/ prefix is optional.\n",
+			Flags:       new(ollamaRemoveFlags),
+			Run:         ollamaRemoveCommand,
+		},
+		{
+			Name:    "cp",
+			Summary: "Copy an Ollama model to a new name",
+			Usage:   "cp [--ollama-host URL] <ollama/source> <ollama/destination>",
+			Description: `Copy an Ollama model under a new name. The copy shares the original's
+data on disk. The ollama/ prefix is optional.
+`,
+			Flags: new(ollamaCopyFlags),
+			Run:   ollamaCopyCommand,
+		},
+		{
+			Name:    "du",
+			Summary: "Show Ollama disk usage by model family",
+			Usage:   "du [--ollama-host URL]",
+			Description: `Show Ollama model disk usage by family, largest first. Copies made with
+'llmls cp' share data and are counted once.
+`,
+			Flags: new(ollamaDiskUsageFlags),
+			Run:   ollamaDiskUsageCommand,
+		},
+		{
+			Name:    "outdated",
+			Summary: "List Ollama models with newer versions on the registry",
+			Usage:   "outdated [--pull] [--ollama-host URL]",
+			Description: `Compare installed Ollama models with their tags on the Ollama registry and
+list those with a newer version. Models from other registries are skipped.
+`,
+			Flags: new(ollamaOutdatedFlags),
+			Run:   ollamaOutdatedCommand,
+		},
+		{
+			Name:    "open",
+			Summary: "Open a model or provider web page in the browser",
+			Usage:   "open [--page home|pricing|status] [--print] [source options] <model-id|provider>",
+			Description: `Open a web page in the default browser: the model's page (OpenRouter, Replicate,
+Ollama library, or Hugging Face), or a provider's home, pricing, or status page.
+`,
+			Flags: new(openFlags),
+			Notes: `Examples:
+  llmls open openai/gpt-4.1
+  llmls open --page status anthropic
+  llmls open --page pricing mistralai/mistral-large
+`,
+			Run: openCommand,
+		},
+		{
+			Name:    "endpoints",
+			Summary: "List the upstream providers serving an OpenRouter model",
+			Usage:   "endpoints [--sort price] [--min-uptime N] [--json] <model-id>",
+			Description: `List each upstream provider serving an OpenRouter model with its price,
+context, quantization, and last-30-minute uptime, latency, and throughput.
+`,
+			Flags: new(endpointsFlags),
+			Run:   endpointsCommand,
+		},
+		{
+			Name:    "policy",
+			Summary: "Check catalog or configured models against a policy file",
+			Usage:   "policy check --policy policy.yaml [--models models.txt] [--all] [--format github] [source options]",
+			Description: `Without --models, print the IDs of catalog models that satisfy the policy.
+With --models, report pass, fail, or unknown for each listed ID and exit
+with status 1 if any fails or is unknown. --format github prints failures as
+GitHub Actions annotations (errors on lines of --models; warnings with --all).
+`,
+			Flags: new(policyFlags),
+			Notes: `Policy keys (prices in USD per 1K tokens):
+  allowed_providers, allowed_models, banned_models, allowed_types,
+  max_prompt_price, max_completion_price, min_context,
+  required_input_modalities, banned_licenses, allow_deprecated
+`,
+			Run: policyCommand,
+		},
+		{
+			Name:    "get",
+			Summary: "Print a single field value of a model",
+			Usage:   "get [source options] <model-id> <field>",
+			Description: `Print a single field value of a model, e.g. llmls get openai/gpt-4.1 pricing.prompt
+Exits with status 1 if the model or field does not exist.
+`,
+			Flags: new(getFlags),
+			Run:   getCommand,
+		},
+		{
+			Name:        "random",
+			Summary:     "Print a random model ID, optionally matching a pattern",
+			Usage:       "random [--seed N] [--detail] [source options] [pattern]",
+			Description: "Pick a uniformly random model from the models matching pattern.\n",
+			Flags:       new(randomFlags),
+			Run:         randomCommand,
+		},
+		{
+			Name:    "snapshot",
+			Summary: "Save the current catalog for later comparison",
+			Usage:   "snapshot [source options]",
+			Description: `Save the current catalog for comparison with --added, --removed, and --changed.
+Snapshots are stored in $LLMLS_SNAPSHOT_DIR or the user cache directory.
+`,
+			Flags: new(snapshotFlags),
+			Run:   snapshotCommand,
+		},
+		{
+			Name:    "history",
+			Summary: "Show the timeline of a model across saved snapshots",
+			Usage:   "history --model <id>",
+			Description: `Show when a model appeared in the saved snapshots, each change to its name,
+context, pricing, or expiration, and when it was removed. Snapshots are saved
+by 'llmls snapshot' and 'llmls daemon'.
+`,
+			Flags: new(historyFlags),
+			Run:   historyCommand,
+		},
+		{
+			Name:    "search",
+			Summary: "Rank models by keywords in their names and descriptions",
+			Usage:   "search [--limit 10] [--detail] [--semantic] [source options] <keywords>",
+			Description: `Rank models by how well their ID, name, and description match the keywords,
+best match first, e.g. llmls search "tool use long context japanese"
+
+With --semantic, descriptions are embedded with a local Ollama model
+(--embed-model) and ranked by similarity to the query,
+e.g. llmls search --semantic "good at code review". Embeddings are cached.
+`,
+			Flags: new(searchFlags),
+			Run:   searchCommand,
+		},
+		{
+			Name:    "query",
+			Summary: "Run a SQL SELECT over the catalog or saved snapshots",
+			Usage:   `query [--header] [source options] "SELECT ..."`,
+			Description: `Run a read-only SQLite statement over the catalog database: table models
+holds the current catalog and table snapshots every saved snapshot, with a
+taken_at column. Tables may be joined. Results are tab-separated.
+`,
+			Flags: new(queryFlags),
+			Notes: "Database: $LLMLS_CATALOG_DB, or catalog.db in the llmls cache directory\n" +
+				"Columns: " + strings.Join(QueryColumnNames(), ", ") + "\n",
+			Run: queryCommand,
+		},
+		{
+			Name:    "watch",
+			Summary: "Periodically report catalog changes and send notifications",
+			Usage:   "watch [options] [source options]",
+			Description: `Periodically compare the catalog with the latest snapshot, print changes,
+save a new snapshot, and send notifications.
+`,
+			Flags: new(watchFlags),
+			Run:   watchCommand,
+		},
+		{
+			Name:    "daemon",
+			Summary: "Run the watch loop as a background service (daemon install)",
+			Usage:   "daemon [options] [source options]\ndaemon install [--print] [options] [source options]",
+			Description: `Run the refresh, snapshot, and notify loop until stopped. 'daemon install'
+writes a systemd user unit (Linux) or launchd agent (macOS) running it.
+`,
+			Flags: new(daemonFlags),
+			Run:   daemonCommand,
+		},
+		{
+			Name:    "feed",
+			Summary: "Write an Atom feed of new models and price changes",
+			Usage:   "feed [--out feed.xml] [--limit 50]",
+			Description: `Write an Atom feed of new models and price changes recorded in snapshots.
+Snapshots are recorded by 'llmls snapshot' and 'llmls watch'.
+`,
+			Flags: new(feedFlags),
+			Run:   feedCommand,
+		},
+		{
+			Name:    "report",
+			Summary: "Print or mail a digest of catalog changes and the current catalog",
+			Usage:   "report [--since 168h] [--email ADDR,...] [mail options] [source options]",
+			Description: `Report models added, removed, and changed since the snapshot taken before the
+period, followed by a table of the current catalog. The report is printed, or
+mailed with --email. 'llmls daemon --email' mails it every --report-every.
+`,
+			Flags: new(reportFlags),
+			Run:   reportCommand,
+		},
+		{
+			Name:    "manifest",
+			Summary: "Write an SBOM-style manifest of the models an application uses,\nor verify one against the live catalog (manifest verify)",
+			Usage:   "manifest --models model-ids.txt [-o manifest.json] [source options]\nmanifest verify [options] [source options] manifest.json",
+			Description: `Record the listed models as the catalog describes them now: provider, source,
+version or digest, context length, prices, license, and fetch time. The digest
+field is the SHA-256 of the compact JSON of the models array, for signing.
+`,
+			Flags: new(manifestFlags),
+			Actions: []Command{
+				{
+					Name:    "manifest verify",
+					Summary: "Report drift of a manifest from the live catalog",
+					Usage:   "manifest verify [--max-price-increase PCT] [--format github] [source options] manifest.json",
+					Description: `Re-resolve every manifest entry against the live catalogs and report removed
+models, prompt or completion price increases beyond the threshold, changed
+context lengths, and changed versions (reported only).
+
+Exit status: 0 no drift, 1 error, 2 drift, 3 the models array was edited
+after the manifest was generated (its digest does not match).
+`,
+					Flags: new(manifestVerifyFlags),
+					Run:   manifestVerifyCommand,
+				},
+			},
+			Run: manifestCommand,
+		},
+		{
+			Name:    "cache",
+			Summary: "Refresh (cache warm) or clear the catalog cache",
+			Usage:   "cache warm\ncache clear",
+			Description: `Vendor catalogs are cached for $LLMLS_CACHE_TTL (default: 10m; 0 disables the cache).
+'cache warm' refreshes them in one go, from the shared cache server at
+$LLMLS_CACHE_URL if set, otherwise from the vendor. 'cache clear' removes them.
+`,
+			Run: cacheCommand,
+		},
+		{
+			Name:    "serve",
+			Summary: "Run an HTTP server sharing the catalog cache, a JSON API, and a web UI",
+			Usage:   "serve [--addr :8090] [--grpc-addr :9090] [--cors-origin origins] [--rate-limit limits] [--openapi]",
+			Description: "Serve the catalog cache over HTTP so a team or CI fleet shares one copy.\n" +
+				"Point clients at it with LLMLS_CACHE_URL=http://host:8090.\n" +
+				"A web UI for browsing the catalog is served at /, backed by " + modelsEndpoint + ".\n" +
+				"The API is described by an OpenAPI 3.1 document at " + openAPIEndpoint + ".\n" +
+				"--grpc-addr also serves it over gRPC, as defined in proto/llmls/v1/serve.proto.\n\n" +
+				"Set LLMLS_SERVE_TOKEN to require it as a bearer token on every route but\n" +
+				"/healthz; clients send it from LLMLS_CACHE_TOKEN.\n",
+			Flags: new(serveFlags),
+			Run:   serveCommand,
+		},
+		{
+			Name:    "ask",
+			Summary: "Send a one-shot prompt to a model and stream the reply",
+			Usage:   "ask [options] <model-id> [prompt]",
+			Description: `Send a single prompt to a model and stream the reply to stdout. Without a
+prompt (or with -), the prompt is read from stdin. Statistics go to stderr.
+
+Requests go to the first-party API when its key is set (OPENAI_API_KEY,
+ANTHROPIC_API_KEY, MISTRAL_API_KEY), otherwise to OpenRouter (OPENROUTER_API_KEY).
+ollama/ models are sent to the Ollama server.
+`,
+			Flags: new(askFlags),
+			Run:   askCommand,
+		},
+		{
+			Name:    "test",
+			Summary: "Run a prompt suite against matching models and print a pass/fail matrix",
+			Usage:   "test [options] [source options] <pattern>",
+			Description: `Run a built-in prompt suite against the models matching pattern and print a
+pass/fail matrix with latency and cost. Exits with status 1 if any case fails.
+
+The basic suite checks JSON mode, a tool call, retrieval from a long prompt,
+and a Japanese reply. Requests are routed as in 'llmls ask'.
+
+With --type embedding, the matching embedding models embed sample texts and
+the dimensionality, latency, and price per 1M tokens are reported.
+`,
+			Flags: new(testFlags),
+			Run:   testCommand,
+		},
+		{
+			Name:    "duel",
+			Summary: "Send the same prompts to two models and compare the replies",
+			Usage:   "duel [options] <model-a> <model-b> [prompt]",
+			Description: `Send the same prompts to two models, show the replies side by side, and total
+latency, tokens, and cost for each. Prompts come from --prompt-file, one per
+line (blank lines and lines starting with # are skipped), or the arguments.
+Requests are routed as in 'llmls ask'.
+`,
+			Flags: new(duelFlags),
+			Run:   duelCommand,
+		},
+		{
+			Name:    "cheapest",
+			Summary: "Show where a model is cheapest to run across all sources",
+			Usage:   "cheapest [--blend IN:OUT] [--system-file FILE] [--images N] [--audio-minutes M]\n         [source options] <model-name>",
+			Description: `Show every source of a model, cheapest first, with its prices. Sources are
+OpenRouter, its routing variants, and local servers, matched as with --dedupe;
+the name may be an ID (meta-llama/llama-3.1-8b-instruct), an Ollama name
+(llama3.1:8b), or a plain name (llama-3.1-8b).
+`,
+			Flags: new(cheapestFlags),
+			Notes: `With --images or --audio-minutes, sources rank by the media cost per request, and
+those whose model does not take the media show unsupported and rank last
+`,
+			Run: cheapestCommand,
+		},
+		{
+			Name:    "graph",
+			Summary: "Plot matching models on two axes, e.g. price against context",
+			Usage:   "graph [--x context] [--y price] [options] [source options] [pattern]",
+			Description: `Plot the models matching a pattern as a scatter plot of braille dots, labeling
+the outliers farthest from the crowd. Context and price (blended $/1M tokens)
+use log scales, so free and unpriced models are left out unless --linear.
+`,
+			Flags: new(graphFlags),
+			Run:   graphCommand,
+		},
+		{
+			Name:    "timeline",
+			Summary: "Chart model releases per provider and month",
+			Usage:   "timeline [--since YYYY-MM] [--top N] [options] [source options] [pattern]",
+			Description: `Chart the creation dates of the models matching a pattern: one row per
+provider, one bar per month sized by the number of releases, to show the
+release cadence of each provider.
+`,
+			Flags: new(timelineFlags),
+			Run:   timelineCommand,
+		},
+		{
+			Name:    "schema",
+			Summary: "Print the JSON Schema of an llmls JSON output",
+			Usage:   "schema [--list] [output]",
+			Description: "Print the JSON Schema (draft 2020-12) of a JSON document llmls writes or serves,\n" +
+				"for generating types and validating integrations. Outputs: " + strings.Join(OutputSchemaNames(), ", ") + "\n" +
+				"(default: models).\n",
+			Flags: new(schemaFlags),
+			Run:   schemaCommand,
+		},
+		{
+			Name:    "matrix",
+			Summary: "Print a ✓/✗ matrix of features (tools, JSON mode, vision, ...) per model",
+			Usage:   "matrix [--features list] [options] [source options] [pattern]",
+			Description: `Print which features each model matching a pattern supports: ✓ supported,
+✗ not supported, ? not reported by the model's source (e.g. local servers).
+
+` + matrixFeatureHelp(),
+			Flags: new(matrixFlags),
+			Run:   matrixCommand,
+		},
+		{
+			Name:    "help",
+			Summary: "Show this help, or the help of a subcommand or topic (help <name>)",
+			Usage:   "help [subcommand|topic]\nhelp --man",
+			Description: `Show the help of llmls, a subcommand, or a help topic. --man writes the
+llmls(1) man page in roff, e.g. llmls help --man > llmls.1
+`,
+			Run: helpCommand,
+		},
+	}
+}
+
+// FindCommand returns the subcommand of a name
+func FindCommand(name string) (Command, bool) {
+	for _, command := range Commands() {
+		if command.Name == name {
+			return command, true
+		}
+	}
+	return Command{}, false
+}
+
+// FindCommandUsage returns the command documenting a FlagSet name: an action
+// such as "manifest verify", else the subcommand of the first word, which
+// covers forms like "policy check" that share the subcommand's flags
+func FindCommandUsage(name string) (Command, bool) {
+	first, _, _ := strings.Cut(name, " ")
+	command, ok := FindCommand(first)
+	if !ok {
+		return Command{}, false
+	}
+	for _, action := range command.Actions {
+		if action.Name == name {
+			return action, true
+		}
+	}
+	return command, true
+}
+
+// NewCommandFlagSet returns the FlagSet of a subcommand with the flags of
+// flags, printing the usage generated from its Command
+func NewCommandFlagSet(name string, flags FlagRegisterer) *flag.FlagSet {
+	fs := NewFlagSet(name, flags)
+	fs.Usage = func() {
+		if command, ok := FindCommandUsage(name); ok {
+			DisplayCommandUsage(os.Stderr, command, fs)
+		}
+	}
+	return fs
+}
+
+// DisplayCommandUsage prints the help of a command whose flags are on fs
+func DisplayCommandUsage(w io.Writer, command Command, fs *flag.FlagSet) {
+	for i, usage := range strings.Split(command.Usage, "\n") {
+		label := "Usage:"
+		if i > 0 {
+			label = "      "
+		}
+		fmt.Fprintf(w, "%s llmls %s\n", label, usage)
+	}
+	if command.Description != "" {
+		fmt.Fprintf(w, "\n%s", command.Description)
+	}
+	if options := FlagOptions(fs); len(options) > 0 {
+		fmt.Fprintf(w, "\nOptions:\n")
+		displayOptions(w, options)
+	}
+	if command.Notes != "" {
+		fmt.Fprintf(w, "\n%s", command.Notes)
+	}
+}
+
+// SuggestCommand returns the subcommand closest to a mistyped name, or "" if
+// none is within maxCommandDistance edits
+func SuggestCommand(name string) string {
+	name = strings.ToLower(name)
+	if strings.ContainsAny(name, "/*?:") {
+		return "" // A pattern, not a subcommand
+	}
+	best, bestDistance := "", maxCommandDistance+1
+	for _, command := range Commands() {
+		// Short names are only suggested for one-letter typos
+		limit := min(maxCommandDistance, len(command.Name)-1)
+		if d := editDistance(name, command.Name); d <= limit && d < bestDistance {
+			best, bestDistance = command.Name, d
+		}
+	}
+	return best
+}
+
+// displayCommandSummaries prints the subcommands section of the help
+func displayCommandSummaries() {
+	for _, command := range Commands() {
+		for i, line := range strings.Split(command.Summary, "\n") {
+			name := ""
+			if i == 0 {
+				name = command.Name
+			}
+			fmt.Fprintf(os.Stderr, "  %-16s %s\n", name, line)
+		}
+	}
+}
+
+// isHelpFlag reports whether an argument asks for help
+func isHelpFlag(arg string) bool {
+	return arg == "-h" || arg == "--help" || arg == "-help"
+}
+
+// helpCommand shows the help, the help of a subcommand or action, e.g.
+// help manifest verify, or a help topic; help --man writes the man page
+func helpCommand() {
+	if len(os.Args) < 3 || os.Args[2] == "help" || isHelpFlag(os.Args[2]) {
+		showHelp()
+		return
+	}
+	name := os.Args[2]
+	if name == "--man" {
+		if err := WriteManPage(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
+		}
+		return
+	}
+	if topic, ok := FindHelpTopic(name); ok {
+		DisplayHelpTopic(os.Stdout, topic)
+		return
+	}
+	command, ok := FindCommandUsage(strings.Join(os.Args[2:], " "))
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: unknown subcommand or help topic: %s\n", name)
+		if suggestion := suggestHelpName(name); suggestion != "" {
+			fmt.Fprintf(os.Stderr, "Did you mean: llmls help %s\n", suggestion)
+		}
+		exit(1)
+	}
+	DisplayCommandUsage(os.Stdout, command, NewFlagSet(command.Name, command.Flags))
+}
+
+// suggestHelpName returns the subcommand or help topic closest to a mistyped
+// name, or "" if none is within maxCommandDistance edits
+func suggestHelpName(name string) string {
+	if command := SuggestCommand(name); command != "" && command != "help" {
+		return command
+	}
+	best, bestDistance := "", maxCommandDistance+1
+	for _, topic := range HelpTopics() {
+		if d := editDistance(strings.ToLower(name), topic.Name); d < bestDistance {
+			best, bestDistance = topic.Name, d
+		}
+	}
+	return best
+}
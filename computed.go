@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// computedColumns are the loaded columns, usable in --where and --columns
+var computedColumns *catalog.Columns
+
+// InitComputedColumns compiles the columns of the config file (see InitConfig)
+func InitComputedColumns() error {
+	var err error
+	computedColumns, err = catalog.ParseColumns(config.Columns)
+	return err
+}
+
+// ParseColumnNames parses --columns, e.g. "score,cheap"
+func ParseColumnNames(s string) ([]string, error) {
+	var names []string
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := computedColumns.Lookup(name); !ok {
+			if len(computedColumns.Names()) == 0 {
+				return nil, fmt.Errorf("unknown column %q (define it with column NAME = expression in the config file)", name)
+			}
+			return nil, fmt.Errorf("unknown column %q (defined: %s)", name, strings.Join(computedColumns.Names(), ", "))
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// FormatComputedValue formats a column value for the listing: numbers with
+// up to 4 decimals, "-" for null
+func FormatComputedValue(v interface{}) string {
+	if f, ok := v.(float64); ok {
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return "-"
+		}
+		return strconv.FormatFloat(math.Round(f*10000)/10000, 'f', -1, 64)
+	}
+	if s := catalog.FormatFieldValue(v); s != "" {
+		return s
+	}
+	return "-"
+}
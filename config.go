@@ -0,0 +1,283 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// Config is the default invocation from the config file, with the selected
+// profile applied
+type Config struct {
+	Args    []string            // Listing flags added before those on the command line
+	Pattern string              // Listing pattern used when the command line gives none
+	Env     map[string]string   // Environment variables set unless already set
+	Columns []catalog.ColumnDef // Computed columns, in definition order
+}
+
+// configSection is the settings of the top of the config file or of a profile
+type configSection struct {
+	Config
+	Profile string // Profile used without --profile (top of the file only)
+}
+
+// config is the loaded config (see InitConfig)
+var config Config
+
+// profileFlag is set by the global --profile flag
+var profileFlag string
+
+// GetConfigFile returns the path of the config file from env var or the user
+// config directory
+func GetConfigFile() (string, error) {
+	if path := os.Getenv("LLMLS_CONFIG"); path != "" {
+		return path, nil
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(configDir, "llmls", "config"), nil
+}
+
+// InitConfig loads the config file and applies a profile: the one given by
+// --profile, else $LLMLS_PROFILE, else the file's profile setting
+// Profile settings add to the top of the file: args are appended, and
+// pattern and env entries override, and columns are defined after the top
+// ones; environment variables already set win
+// A missing file configures nothing, unless a profile was asked for
+func InitConfig(profile string) error {
+	path, err := GetConfigFile()
+	if err != nil {
+		return err
+	}
+	if profile == "" {
+		profile = os.Getenv("LLMLS_PROFILE")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) && profile == "" {
+			return nil
+		}
+		if os.IsNotExist(err) {
+			return fmt.Errorf("unknown profile: %s (no config file %s)", profile, path)
+		}
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	defer f.Close()
+
+	top, profiles, err := parseConfig(bufio.NewScanner(f), path)
+	if err != nil {
+		return err
+	}
+	config = top.Config
+	if profile == "" {
+		profile = top.Profile
+	}
+	if profile != "" {
+		section, ok := profiles[profile]
+		if !ok {
+			return fmt.Errorf("unknown profile: %s (expected %s)", profile, strings.Join(configProfileNames(profiles), ", "))
+		}
+		config.Args = append(config.Args, section.Args...)
+		if section.Pattern != "" {
+			config.Pattern = section.Pattern
+		}
+		for name, value := range section.Env {
+			config.Env[name] = value
+		}
+		config.Columns = append(config.Columns, section.Columns...)
+	}
+
+	for name, value := range config.Env {
+		if _, ok := os.LookupEnv(name); !ok {
+			os.Setenv(name, value)
+		}
+	}
+	return nil
+}
+
+// parseConfig parses the config file: "key = value" lines at the top, then
+// profiles each headed by "[name]"; "#" starts a comment
+// Keys are args (listing flags), pattern, env NAME, column NAME (a Starlark
+// expression), and at the top, profile
+func parseConfig(scanner *bufio.Scanner, path string) (configSection, map[string]configSection, error) {
+	top := configSection{Config: Config{Env: map[string]string{}}}
+	profiles := make(map[string]configSection)
+	section, name := &top, ""
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			if name != "" {
+				profiles[name] = *section
+			}
+			name = strings.TrimSpace(line[1 : len(line)-1])
+			if name == "" {
+				return top, nil, fmt.Errorf("%s:%d: missing profile name", path, n)
+			}
+			if _, ok := profiles[name]; ok {
+				return top, nil, fmt.Errorf("%s:%d: profile %s is defined twice", path, n, name)
+			}
+			section = &configSection{Config: Config{Env: map[string]string{}}}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok {
+			return top, nil, fmt.Errorf("%s:%d: expected key = value", path, n)
+		}
+		switch {
+		case key == "args":
+			args, err := splitWords(value)
+			if err != nil {
+				return top, nil, fmt.Errorf("%s:%d: %v", path, n, err)
+			}
+			section.Args = append(section.Args, args...)
+		case key == "pattern":
+			section.Pattern = value
+		case key == "profile" && name == "":
+			section.Profile = value
+		case strings.HasPrefix(key, "env "):
+			section.Env[strings.TrimSpace(strings.TrimPrefix(key, "env "))] = value
+		case strings.HasPrefix(key, "column "):
+			section.Columns = append(section.Columns, catalog.ColumnDef{
+				Name:   strings.TrimSpace(strings.TrimPrefix(key, "column ")),
+				Source: value,
+				Pos:    fmt.Sprintf("%s:%d", path, n),
+			})
+		default:
+			return top, nil, fmt.Errorf("%s:%d: unknown key %q", path, n, key)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return top, nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	if name != "" {
+		profiles[name] = *section
+	}
+	return top, profiles, nil
+}
+
+// SetConfigEnv writes "env NAME = value" entries to the top of the config
+// file, replacing entries of the same names there and keeping the rest of
+// the file; the file and its directory are created when missing
+func SetConfigEnv(path string, env map[string]string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var lines []string
+	if len(data) > 0 {
+		lines = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	}
+	// The top section ends at the first profile
+	top := len(lines)
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			top = i
+			break
+		}
+	}
+
+	written := make(map[string]bool)
+	var out []string
+	for _, line := range lines[:top] {
+		key, _, ok := strings.Cut(strings.TrimSpace(line), "=")
+		key = strings.TrimSpace(key)
+		if name := strings.TrimSpace(strings.TrimPrefix(key, "env ")); ok && strings.HasPrefix(key, "env ") {
+			if value, set := env[name]; set {
+				if !written[name] {
+					out = append(out, "env "+name+" = "+value)
+					written[name] = true
+				}
+				continue
+			}
+		}
+		out = append(out, line)
+	}
+	// New entries go after the existing top settings, before a blank line
+	// separating them from the profiles
+	end := len(out)
+	for end > 0 && strings.TrimSpace(out[end-1]) == "" {
+		end--
+	}
+	var added []string
+	for name, value := range env {
+		if !written[name] {
+			added = append(added, "env "+name+" = "+value)
+		}
+	}
+	sort.Strings(added)
+	out = append(out[:end:end], append(added, out[end:]...)...)
+	out = append(out, lines[top:]...)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil && !dryRun {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return writeFileAtomic(path, []byte(strings.Join(out, "\n")+"\n"))
+}
+
+// configProfileNames returns the defined profile names, sorted
+func configProfileNames(profiles map[string]configSection) []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// splitWords splits a line into words as a shell does: at spaces, except
+// within single or double quotes, with backslash escaping the next character
+// outside single quotes
+func splitWords(s string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inWord = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
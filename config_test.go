@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bufio"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+func TestParseConfig(t *testing.T) {
+	in := `# defaults
+args = --sort provider "--where=ctx > 8000"
+pattern = anthropic/*
+env OLLAMA_HOST = http://gpu:11434
+column score = ctx/1000 - price*2
+profile = work
+
+[work]
+args = --dedupe
+env OPENROUTER_API_KEY = sk-work
+column cheap = price != None and price < 1
+
+[home]
+pattern = ollama/*
+`
+	top, profiles, err := parseConfig(bufio.NewScanner(strings.NewReader(in)), "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantTop := configSection{
+		Config: Config{
+			Args:    []string{"--sort", "provider", "--where=ctx > 8000"},
+			Pattern: "anthropic/*",
+			Env:     map[string]string{"OLLAMA_HOST": "http://gpu:11434"},
+			Columns: []catalog.ColumnDef{{Name: "score", Source: "ctx/1000 - price*2", Pos: "config:5"}},
+		},
+		Profile: "work",
+	}
+	if !reflect.DeepEqual(top, wantTop) {
+		t.Errorf("top = %+v, want %+v", top, wantTop)
+	}
+	wantProfiles := map[string]configSection{
+		"work": {Config: Config{
+			Args:    []string{"--dedupe"},
+			Env:     map[string]string{"OPENROUTER_API_KEY": "sk-work"},
+			Columns: []catalog.ColumnDef{{Name: "cheap", Source: "price != None and price < 1", Pos: "config:11"}},
+		}},
+		"home": {Config: Config{Pattern: "ollama/*", Env: map[string]string{}}},
+	}
+	if !reflect.DeepEqual(profiles, wantProfiles) {
+		t.Errorf("profiles = %+v, want %+v", profiles, wantProfiles)
+	}
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"sort provider\n", "config:1: expected key = value"},
+		{"color = always\n", `config:1: unknown key "color"`},
+		{"[]\n", "config:1: missing profile name"},
+		{"[a]\n[a]\n", "config:2: profile a is defined twice"},
+		{"[a]\nprofile = b\n", `config:2: unknown key "profile"`},
+		{"\nargs = \"--sort\n", "config:2: "},
+	}
+	for _, tt := range tests {
+		_, _, err := parseConfig(bufio.NewScanner(strings.NewReader(tt.in)), "config")
+		if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("parseConfig(%q) error = %v, want %q", tt.in, err, tt.want)
+		}
+	}
+}
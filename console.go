@@ -0,0 +1,49 @@
+package main
+
+import (
+	"os"
+	"strconv"
+
+	"golang.org/x/term"
+)
+
+// defaultTerminalWidth is used when the width cannot be determined, e.g. when
+// output is redirected to a file or pipe
+const defaultTerminalWidth = 120
+
+// consoleColor records whether the console renders ANSI escape sequences; set by InitConsole
+var consoleColor = true
+
+// InitConsole prepares the console for output: on Windows it enables ANSI
+// escape sequences and UTF-8 output, which older consoles do not default to
+func InitConsole() {
+	consoleColor = enableVirtualTerminal()
+}
+
+// RestoreConsole undoes InitConsole, leaving the console as llmls found it
+func RestoreConsole() {
+	restoreConsole()
+}
+
+// GetTerminalWidth returns the width of the terminal on stdout, or $COLUMNS
+// when stdout is not a console (e.g. redirected, or a mintty window on Windows),
+// or a default value if neither is available
+func GetTerminalWidth() int {
+	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && width > 0 {
+		return width
+	}
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+	return defaultTerminalWidth
+}
+
+// StdoutIsTerminal reports whether standard output is a terminal
+func StdoutIsTerminal() bool {
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// StdinIsTerminal reports whether standard input is a terminal
+func StdinIsTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
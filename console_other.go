@@ -0,0 +1,12 @@
+//go:build !windows
+
+package main
+
+// enableVirtualTerminal reports that the terminal renders ANSI escape
+// sequences, which POSIX terminals always do
+func enableVirtualTerminal() bool {
+	return true
+}
+
+// restoreConsole does nothing, since enableVirtualTerminal changes nothing
+func restoreConsole() {}
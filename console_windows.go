@@ -0,0 +1,63 @@
+//go:build windows
+
+package main
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// cpUTF8 is the UTF-8 console code page
+const cpUTF8 = 65001
+
+// savedConsole is the console state changed by enableVirtualTerminal, put
+// back by restoreConsole so the shell is left as it was
+var savedConsole struct {
+	outputCP uint32 // 0 when unchanged
+	modes    map[windows.Handle]uint32
+}
+
+// enableVirtualTerminal turns on ANSI escape processing for stdout and stderr
+// (Windows 10 and later, and ConPTY hosts such as Windows Terminal) and
+// switches console output to UTF-8 so symbols like ⚠ render
+// It returns false if stdout is a console that cannot render escape sequences
+func enableVirtualTerminal() bool {
+	supported := true
+	savedConsole.modes = make(map[windows.Handle]uint32)
+	for _, f := range []*os.File{os.Stdout, os.Stderr} {
+		handle := windows.Handle(f.Fd())
+		var mode uint32
+		if windows.GetConsoleMode(handle, &mode) != nil {
+			// Not a console: redirected, or a pty such as mintty that handles escapes itself
+			continue
+		}
+		err := windows.SetConsoleMode(handle, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING)
+		if err != nil && f == os.Stdout {
+			supported = false
+		}
+		if err == nil {
+			savedConsole.modes[handle] = mode
+		}
+	}
+	if cp, err := windows.GetConsoleOutputCP(); err == nil && cp != cpUTF8 {
+		if windows.SetConsoleOutputCP(cpUTF8) == nil {
+			savedConsole.outputCP = cp
+		}
+	}
+	return supported
+}
+
+// restoreConsole puts back the console modes and output code page changed
+// by enableVirtualTerminal; the code page outlives llmls otherwise, and
+// programs run later in the same console would print in UTF-8
+func restoreConsole() {
+	for handle, mode := range savedConsole.modes {
+		windows.SetConsoleMode(handle, mode)
+	}
+	savedConsole.modes = nil
+	if savedConsole.outputCP != 0 {
+		windows.SetConsoleOutputCP(savedConsole.outputCP)
+		savedConsole.outputCP = 0
+	}
+}
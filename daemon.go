@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// launchdLabel identifies the llmls launch agent
+const launchdLabel = "com.github.mkyutani.llmls"
+
+// DaemonArgs returns the command line the service manager runs: the current
+// executable, "daemon", and every flag explicitly set on fs except skip
+func DaemonArgs(fs *flag.FlagSet, skip ...string) ([]string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate llmls executable: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+
+	args := []string{exe, "daemon"}
+	fs.Visit(func(f *flag.Flag) {
+		for _, name := range skip {
+			if f.Name == name {
+				return
+			}
+		}
+		args = append(args, "--"+f.Name+"="+f.Value.String())
+	})
+	return args, nil
+}
+
+// ServiceFile returns the path and contents of the user service definition for this OS
+func ServiceFile(args []string) (path string, content string, err error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to locate home directory: %w", err)
+	}
+
+	switch runtime.GOOS {
+	case "linux":
+		configDir, err := os.UserConfigDir()
+		if err != nil {
+			return "", "", fmt.Errorf("failed to locate config directory: %w", err)
+		}
+		return filepath.Join(configDir, "systemd", "user", "llmls.service"), SystemdUnit(args), nil
+	case "darwin":
+		return filepath.Join(home, "Library", "LaunchAgents", launchdLabel+".plist"), LaunchdPlist(args), nil
+	}
+	return "", "", fmt.Errorf("daemon install is not supported on %s", runtime.GOOS)
+}
+
+// SystemdUnit renders a systemd user unit running args
+func SystemdUnit(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = systemdQuote(arg)
+	}
+
+	var b strings.Builder
+	b.WriteString("[Unit]\n")
+	b.WriteString("Description=llmls catalog watcher\n")
+	b.WriteString("After=network-online.target\n")
+	b.WriteString("Wants=network-online.target\n\n")
+	b.WriteString("[Service]\n")
+	fmt.Fprintf(&b, "ExecStart=%s\n", strings.Join(quoted, " "))
+	b.WriteString("Restart=on-failure\n")
+	b.WriteString("RestartSec=60\n")
+	b.WriteString(serviceEnvironment(func(name, value string) string {
+		return fmt.Sprintf("Environment=%s\n", systemdQuote(name+"="+value))
+	}))
+	b.WriteString("\n[Install]\n")
+	b.WriteString("WantedBy=default.target\n")
+	return b.String()
+}
+
+// systemdQuote double-quotes s if it contains characters systemd would split or expand
+func systemdQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\"'\\$%") {
+		return s
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", "$$", "%", "%%")
+	return `"` + r.Replace(s) + `"`
+}
+
+// LaunchdPlist renders a launchd agent plist running args
+func LaunchdPlist(args []string) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n")
+	b.WriteString("<plist version=\"1.0\">\n<dict>\n")
+	fmt.Fprintf(&b, "  <key>Label</key>\n  <string>%s</string>\n", launchdLabel)
+	b.WriteString("  <key>ProgramArguments</key>\n  <array>\n")
+	for _, arg := range args {
+		fmt.Fprintf(&b, "    <string>%s</string>\n", xmlEscape(arg))
+	}
+	b.WriteString("  </array>\n")
+	if env := serviceEnvironment(func(name, value string) string {
+		return fmt.Sprintf("    <key>%s</key>\n    <string>%s</string>\n", xmlEscape(name), xmlEscape(value))
+	}); env != "" {
+		b.WriteString("  <key>EnvironmentVariables</key>\n  <dict>\n")
+		b.WriteString(env)
+		b.WriteString("  </dict>\n")
+	}
+	b.WriteString("  <key>RunAtLoad</key>\n  <true/>\n")
+	b.WriteString("  <key>KeepAlive</key>\n  <true/>\n")
+	home, _ := os.UserHomeDir()
+	logPath := filepath.Join(home, "Library", "Logs", "llmls.log")
+	fmt.Fprintf(&b, "  <key>StandardOutPath</key>\n  <string>%s</string>\n", xmlEscape(logPath))
+	fmt.Fprintf(&b, "  <key>StandardErrorPath</key>\n  <string>%s</string>\n", xmlEscape(logPath))
+	b.WriteString("</dict>\n</plist>\n")
+	return b.String()
+}
+
+// xmlEscape escapes s for use as XML character data
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// serviceEnvVars are carried into the service so it sees the same sources as the shell
+var serviceEnvVars = []string{
+	"OLLAMA_HOST",
+	"LLAMACPP_HOST",
+	"TGI_HOST",
+	"LLMLS_REMOTE",
+	"LLMLS_REMOTE_TOKEN",
+	"REPLICATE_API_TOKEN",
+	"LLMLS_SNAPSHOT_DIR",
+	"LLMLS_CACHE_URL",
+	"LLMLS_CACHE_TOKEN",
+	"LLMLS_CACHE_TTL",
+	"LLMLS_MAIL_FROM",
+	"LLMLS_SMTP_USER",
+	"LLMLS_SMTP_PASSWORD",
+}
+
+// serviceEnvironment formats each set variable in serviceEnvVars with format
+func serviceEnvironment(format func(name, value string) string) string {
+	var b strings.Builder
+	for _, name := range serviceEnvVars {
+		if value := os.Getenv(name); value != "" {
+			b.WriteString(format(name, value))
+		}
+	}
+	return b.String()
+}
+
+// InstallService writes the service definition to path
+// Dry runs leave path untouched
+func InstallService(path, content string) error {
+	if dryRun {
+		fmt.Fprintf(os.Stderr, "[dry-run] write %s (%d bytes)\n", path, len(content))
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create service directory: %w", err)
+	}
+	// The file may hold tokens from the environment, so keep it private
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		return fmt.Errorf("failed to write service file: %w", err)
+	}
+	return nil
+}
+
+// ServiceEnableHint returns the command that starts the installed service
+func ServiceEnableHint(path string) string {
+	if runtime.GOOS == "darwin" {
+		return "launchctl load -w " + path
+	}
+	return "systemctl --user daemon-reload && systemctl --user enable --now llmls"
+}
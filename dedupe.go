@@ -0,0 +1,154 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// dedupeStopTokens are name tokens that do not distinguish the underlying
+// model: vendor prefixes, chat tuning markers, and packaging
+var dedupeStopTokens = map[string]bool{
+	"meta": true, "instruct": true, "chat": true, "it": true, "hf": true,
+	"latest": true, "gguf": true, "awq": true, "gptq": true, "fp8": true, "fp16": true, "bf16": true,
+}
+
+// dedupeTokenSplit splits a model name into tokens, keeping versions such as 3.1 whole
+var dedupeTokenSplit = regexp.MustCompile(`[-_/\s]+`)
+
+// parameterSize matches a parameter count token such as 8b or 0.5b
+var parameterSize = regexp.MustCompile(`^\d+(\.\d+)?[bm]$`)
+
+// quantToken matches a GGUF quantization token such as q4, q4_k_m, or iq3
+var quantToken = regexp.MustCompile(`^i?q\d`)
+
+// DedupeKey normalizes a model ID to its underlying model, so that
+// meta-llama/llama-3.1-8b-instruct, ollama/llama3.1:8b, and
+// tgi/meta-llama/Meta-Llama-3.1-8B-Instruct share the key llama-3.1-8b
+// A hosted model with a hugging_face_id is keyed by its open-weights repo,
+// which names the model as local servers do
+func DedupeKey(model catalog.Model) string {
+	name := strings.ToLower(catalog.BaseModelID(model.ID))
+	size := ""
+	switch source := catalog.ModelSourceName(model); {
+	case source == "openrouter" && model.HuggingFaceID != "":
+		name = catalog.ModelSuffix(strings.ToLower(model.HuggingFaceID)) // Drop the organization
+	case source == "ollama":
+		name = catalog.OllamaModelName(model.ID)
+		if idx := strings.LastIndex(name, "/"); idx >= 0 {
+			name = name[idx+1:] // Drop a namespace (user/model)
+		}
+		var tag string
+		name, tag, _ = strings.Cut(strings.ToLower(name), ":")
+		size = ollamaTagSize.FindString(tag)
+		if size == "" && model.OllamaDetails != nil {
+			// Ollama reports "8.0B" where names say "8b"
+			size = strings.Replace(strings.ToLower(model.OllamaDetails.ParameterSize), ".0b", "b", 1)
+		}
+	case source == "tgi" || source == "replicate":
+		name = catalog.ModelSuffix(catalog.ModelSuffix(name)) // Drop the source prefix and the organization
+	default:
+		name = catalog.ModelSuffix(name)
+	}
+	name = letterDigit.ReplaceAllString(name, "$1-$2")
+
+	var tokens []string
+	for _, token := range dedupeTokenSplit.Split(name, -1) {
+		if token == "" || dedupeStopTokens[token] || quantToken.MatchString(token) {
+			continue
+		}
+		tokens = append(tokens, token)
+	}
+	if size != "" && (len(tokens) == 0 || !parameterSize.MatchString(tokens[len(tokens)-1])) {
+		tokens = append(tokens, size)
+	}
+	return strings.Join(tokens, "-")
+}
+
+// DedupeModels merges models that are the same underlying model (see
+// DedupeKey) into one entry per model, listing every source of it in all in
+// Sources, so filtering by pattern still shows where else a model is offered
+// The entry keeps the details of its preferred selected source: a priced
+// hosted model over a free variant over a local copy
+func DedupeModels(selected, all []catalog.Model) []catalog.Model {
+	groups := make(map[string][]catalog.Model)
+	var order []string
+	for _, model := range selected {
+		key := DedupeKey(model)
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], model)
+	}
+
+	sources := make(map[string][]catalog.ModelSource)
+	for _, model := range all {
+		key := DedupeKey(model)
+		if _, ok := groups[key]; ok {
+			sources[key] = append(sources[key], catalog.ModelSource{
+				ID:      model.ID,
+				Source:  catalog.ModelSourceName(model),
+				Pricing: model.Pricing,
+			})
+		}
+	}
+
+	deduped := make([]catalog.Model, 0, len(order))
+	for _, key := range order {
+		members := groups[key]
+		merged := members[0]
+		for _, model := range members[1:] {
+			if sourceRank(model) < sourceRank(merged) {
+				merged = model
+			}
+		}
+		merged.Sources = nil
+		if len(sources[key]) > 1 {
+			merged.Sources = sources[key]
+		}
+		deduped = append(deduped, merged)
+	}
+	return deduped
+}
+
+// sourceRank orders the sources of a model for DedupeModels; lower is preferred
+func sourceRank(model catalog.Model) int {
+	switch {
+	case catalog.IsLocalSource(catalog.ModelSourceName(model)):
+		return 3
+	case catalog.ModelVariant(model.ID) != "":
+		return 2
+	case model.Pricing.Prompt == "":
+		return 1
+	}
+	return 0
+}
+
+// FormatSourcePrice returns the price of one source: per 1K prompt/completion
+// tokens, free, or local
+func FormatSourcePrice(source catalog.ModelSource) string {
+	switch {
+	case catalog.IsLocalSource(source.Source):
+		return "local"
+	case source.Pricing.Prompt == "":
+		return "-"
+	case source.Pricing.Prompt == "0" && source.Pricing.Completion == "0":
+		return "free"
+	}
+	return FormatModelPrice(catalog.Model{Pricing: source.Pricing})
+}
+
+// FormatModelSources summarizes the sources of a merged model, e.g.
+// "3 sources: openrouter $0.0001/$0.0003 · openrouter:free free · ollama local"
+func FormatModelSources(model catalog.Model) string {
+	parts := make([]string, len(model.Sources))
+	for i, source := range model.Sources {
+		label := source.Source
+		if variant := catalog.ModelVariant(source.ID); variant != "" {
+			label += ":" + variant
+		}
+		parts[i] = label + " " + FormatSourcePrice(source)
+	}
+	return FormatNumber(len(model.Sources)) + " sources: " + strings.Join(parts, " · ")
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+func TestDedupeKey(t *testing.T) {
+	tests := []struct {
+		model catalog.Model
+		want  string
+	}{
+		{catalog.Model{ID: "meta-llama/llama-3.1-8b-instruct"}, "llama-3.1-8b"},
+		{catalog.Model{ID: "meta-llama/llama-3.1-8b-instruct:free"}, "llama-3.1-8b"},
+		{catalog.Model{ID: "ollama/llama3.1:8b"}, "llama-3.1-8b"},
+		{catalog.Model{ID: "ollama/llama3.1:latest", OllamaDetails: &catalog.OllamaDetails{ParameterSize: "8.0B"}}, "llama-3.1-8b"},
+		{catalog.Model{ID: "tgi/meta-llama/Meta-Llama-3.1-8B-Instruct"}, "llama-3.1-8b"},
+		{catalog.Model{ID: "qwen/qwen-2.5-7b-instruct", HuggingFaceID: "Qwen/Qwen2.5-7B-Instruct"}, "qwen-2.5-7b"},
+		{catalog.Model{ID: "ollama/qwen2.5:7b-instruct-q4_K_M"}, "qwen-2.5-7b"},
+		{catalog.Model{ID: "meta-llama/llama-3.1-70b-instruct"}, "llama-3.1-70b"},
+	}
+	for _, tt := range tests {
+		if got := DedupeKey(tt.model); got != tt.want {
+			t.Errorf("DedupeKey(%q) = %q, want %q", tt.model.ID, got, tt.want)
+		}
+	}
+}
+
+func TestDedupeModels(t *testing.T) {
+	hosted := catalog.Model{ID: "meta-llama/llama-3.1-8b-instruct", Pricing: catalog.Pricing{Prompt: "0.00000002", Completion: "0.00000005"}}
+	free := catalog.Model{ID: "meta-llama/llama-3.1-8b-instruct:free", Pricing: catalog.Pricing{Prompt: "0", Completion: "0"}}
+	local := catalog.Model{ID: "ollama/llama3.1:8b"}
+	other := catalog.Model{ID: "meta-llama/llama-3.1-70b-instruct", Pricing: catalog.Pricing{Prompt: "0.0000001", Completion: "0.0000003"}}
+	all := []catalog.Model{local, free, hosted, other}
+
+	// The priced hosted model is kept, listing every source of the model
+	got := DedupeModels(all, all)
+	if len(got) != 2 {
+		t.Fatalf("got %d models, want 2", len(got))
+	}
+	if got[0].ID != hosted.ID {
+		t.Errorf("merged entry = %q, want %q", got[0].ID, hosted.ID)
+	}
+	var ids []string
+	for _, source := range got[0].Sources {
+		ids = append(ids, source.ID)
+	}
+	if want := []string{local.ID, free.ID, hosted.ID}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("sources = %v, want %v", ids, want)
+	}
+	if got[1].ID != other.ID || got[1].Sources != nil {
+		t.Errorf("single-source entry = %q with %d sources, want %q with none", got[1].ID, len(got[1].Sources), other.ID)
+	}
+
+	// Filtering keeps the sources the selection does not include
+	got = DedupeModels([]catalog.Model{local}, all)
+	if len(got) != 1 || got[0].ID != local.ID || len(got[0].Sources) != 3 {
+		t.Errorf("DedupeModels(local) = %+v, want %q with 3 sources", got, local.ID)
+	}
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// discoveryPorts are the default ports of common local inference servers
+var discoveryPorts = []int{11434, 1234, 8000, 8080}
+
+// mdnsServices are the DNS-SD service types browsed to find LAN hosts
+var mdnsServices = []string{
+	"_ollama._tcp.local",
+	"_http._tcp.local",
+	"_workstation._tcp.local",
+}
+
+// DiscoveredServer represents an inference server found on the network
+type DiscoveredServer struct {
+	URL        string
+	Kind       string // ollama, lmstudio, vllm, llamacpp, openai
+	ModelCount int
+}
+
+// DiscoverHosts returns candidate hosts: localhost, mDNS responders, and
+// optionally every address on the local /24 networks
+func DiscoverHosts(timeout time.Duration, scan bool) []string {
+	seen := map[string]bool{"127.0.0.1": true}
+	hosts := []string{"127.0.0.1"}
+
+	add := func(ip string) {
+		if !seen[ip] {
+			seen[ip] = true
+			hosts = append(hosts, ip)
+		}
+	}
+
+	for _, ip := range browseMDNS(timeout) {
+		add(ip)
+	}
+
+	if scan {
+		for _, ip := range localSubnetHosts() {
+			add(ip)
+		}
+	}
+
+	return hosts
+}
+
+// browseMDNS sends DNS-SD PTR queries and collects the addresses of responders
+func browseMDNS(timeout time.Duration) []string {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4zero, Port: 0})
+	if err != nil {
+		return nil
+	}
+	defer conn.Close()
+
+	group := &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: 5353}
+	for _, service := range mdnsServices {
+		conn.WriteToUDP(buildMDNSQuery(service), group)
+	}
+
+	var responders []string
+	seen := make(map[string]bool)
+	buf := make([]byte, 9000)
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	for {
+		_, addr, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			// Deadline reached
+			break
+		}
+		ip := addr.IP.String()
+		if !seen[ip] {
+			seen[ip] = true
+			responders = append(responders, ip)
+		}
+	}
+
+	return responders
+}
+
+// buildMDNSQuery encodes a PTR question for name with the unicast-response bit set
+func buildMDNSQuery(name string) []byte {
+	// Header: ID 0, flags 0, 1 question, no answer/authority/additional records
+	msg := []byte{0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0}
+	for _, label := range strings.Split(name, ".") {
+		msg = append(msg, byte(len(label)))
+		msg = append(msg, label...)
+	}
+	msg = append(msg, 0)
+	// QTYPE PTR (12), QCLASS IN (1) with the QU bit so replies come back unicast
+	msg = append(msg, 0, 12, 0x80, 1)
+	return msg
+}
+
+// localSubnetHosts lists every host address in the /24 of each private IPv4 interface
+func localSubnetHosts() []string {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil
+	}
+
+	var hosts []string
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		ip := ipNet.IP.To4()
+		if ip == nil || ip.IsLoopback() || !ip.IsPrivate() {
+			continue
+		}
+		for i := 1; i < 255; i++ {
+			hosts = append(hosts, fmt.Sprintf("%d.%d.%d.%d", ip[0], ip[1], ip[2], i))
+		}
+	}
+	return hosts
+}
+
+// ProbeServers checks each host on the common inference ports concurrently
+func ProbeServers(hosts []string, timeout time.Duration) []DiscoveredServer {
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		servers []DiscoveredServer
+	)
+	sem := make(chan struct{}, 64) // Limit concurrent connections
+
+	client := &http.Client{Timeout: timeout}
+	for _, host := range hosts {
+		for _, port := range discoveryPorts {
+			wg.Add(1)
+			go func(host string, port int) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				if server, ok := identifyServer(client, host, port); ok {
+					mu.Lock()
+					servers = append(servers, server)
+					mu.Unlock()
+				}
+			}(host, port)
+		}
+	}
+	wg.Wait()
+
+	sort.Slice(servers, func(i, j int) bool {
+		return servers[i].URL < servers[j].URL
+	})
+	return servers
+}
+
+// identifyServer determines which kind of inference server listens on host:port
+func identifyServer(client *http.Client, host string, port int) (DiscoveredServer, bool) {
+	base := fmt.Sprintf("http://%s", net.JoinHostPort(host, fmt.Sprint(port)))
+
+	// Ollama native API
+	var tags catalog.OllamaModelsResponse
+	if getJSON(client, base+"/api/tags", &tags) == nil && tags.Models != nil {
+		return DiscoveredServer{URL: base, Kind: "ollama", ModelCount: len(tags.Models)}, true
+	}
+
+	// OpenAI-compatible API (LM Studio, vLLM, llama.cpp)
+	var list struct {
+		Data []struct {
+			OwnedBy string `json:"owned_by"`
+		} `json:"data"`
+	}
+	if getJSON(client, base+"/v1/models", &list) != nil || list.Data == nil {
+		return DiscoveredServer{}, false
+	}
+
+	kind := "openai"
+	owner := ""
+	if len(list.Data) > 0 {
+		owner = list.Data[0].OwnedBy
+	}
+	switch {
+	case owner == "vllm":
+		kind = "vllm"
+	case owner == "llamacpp" || owner == "koboldcpp":
+		kind = "llamacpp"
+	case port == 1234:
+		kind = "lmstudio"
+	case port == 8000:
+		kind = "vllm"
+	}
+
+	return DiscoveredServer{URL: base, Kind: kind, ModelCount: len(list.Data)}, true
+}
+
+// DisplayDiscoveredServers prints discovered servers and how to use them with llmls
+func DisplayDiscoveredServers(servers []DiscoveredServer) {
+	if len(servers) == 0 {
+		return
+	}
+
+	maxURLWidth := 0
+	maxKindWidth := 0
+	for _, server := range servers {
+		if len(server.URL) > maxURLWidth {
+			maxURLWidth = len(server.URL)
+		}
+		if len(server.Kind) > maxKindWidth {
+			maxKindWidth = len(server.Kind)
+		}
+	}
+
+	for _, server := range servers {
+		fmt.Printf("%-*s %-*s %d models\n",
+			maxURLWidth, server.URL,
+			maxKindWidth, server.Kind,
+			server.ModelCount)
+	}
+
+	// Suggest settings for servers that llmls has a source for
+	env := DiscoveredEnv(servers)
+	if len(env) > 0 {
+		var suggestions []string
+		for name, value := range env {
+			suggestions = append(suggestions, "export "+name+"="+value)
+		}
+		sort.Strings(suggestions)
+		fmt.Println()
+		fmt.Println("To include these servers in llmls, add to your shell profile:")
+		for _, s := range suggestions {
+			fmt.Printf("  %s\n", s)
+		}
+	}
+}
+
+// discoveredHostEnv maps the kinds of servers llmls has a source for to the
+// environment variable of their URL
+var discoveredHostEnv = map[string]string{
+	"ollama":   "OLLAMA_HOST",
+	"llamacpp": "LLAMACPP_HOST",
+}
+
+// DiscoveredEnv returns the environment variables that point llmls at the
+// discovered servers; the first server of each kind is used
+func DiscoveredEnv(servers []DiscoveredServer) map[string]string {
+	env := make(map[string]string)
+	for _, server := range servers {
+		name, ok := discoveredHostEnv[server.Kind]
+		if _, seen := env[name]; ok && !seen {
+			env[name] = server.URL
+		}
+	}
+	return env
+}
+
+// OfferConfigEnv writes the environment of the discovered servers to the
+// config file as env entries, asking first unless save is set; without a
+// terminal to ask on, nothing is written
+func OfferConfigEnv(servers []DiscoveredServer, save bool) error {
+	env := DiscoveredEnv(servers)
+	if len(env) == 0 {
+		return nil
+	}
+	path, err := GetConfigFile()
+	if err != nil {
+		return err
+	}
+	if !save {
+		if !StdinIsTerminal() || !StdoutIsTerminal() {
+			return nil
+		}
+		fmt.Printf("\nAdd them to %s instead? [y/N] ", path)
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+			return nil
+		}
+	}
+	if err := SetConfigEnv(path, env); err != nil {
+		return err
+	}
+	if !dryRun {
+		fmt.Printf("Saved to %s\n", path)
+	}
+	return nil
+}
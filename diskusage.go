@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// FamilyUsage is the disk usage of one Ollama model family
+type FamilyUsage struct {
+	Family string
+	Models int
+	Size   int64
+}
+
+// OllamaDiskUsage sums model sizes by family, largest first
+// Each manifest digest is counted once, since copies share their blobs
+func OllamaDiskUsage(models []catalog.Model) (usage []FamilyUsage, total int64) {
+	byFamily := make(map[string]*FamilyUsage)
+	seen := make(map[string]bool)
+	for _, model := range models {
+		details := model.OllamaDetails
+		if details == nil {
+			continue
+		}
+		family := details.Family
+		if family == "" {
+			family = "unknown"
+		}
+		if byFamily[family] == nil {
+			byFamily[family] = &FamilyUsage{Family: family}
+		}
+		byFamily[family].Models++
+
+		if details.Digest == "" || !seen[details.Digest] {
+			seen[details.Digest] = true
+			byFamily[family].Size += details.Size
+			total += details.Size
+		}
+	}
+
+	for _, u := range byFamily {
+		usage = append(usage, *u)
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Size != usage[j].Size {
+			return usage[i].Size > usage[j].Size
+		}
+		return usage[i].Family < usage[j].Family
+	})
+	return usage, total
+}
+
+// formatGB formats a byte count in gigabytes
+func formatGB(size int64) string {
+	return fmt.Sprintf("%.2f GB", float64(size)/(1024*1024*1024))
+}
+
+// DisplayDiskUsage prints family usage as an aligned table with a total line
+func DisplayDiskUsage(usage []FamilyUsage, total int64) {
+	maxFamilyWidth := len("FAMILY")
+	models := 0
+	for _, u := range usage {
+		if len(u.Family) > maxFamilyWidth {
+			maxFamilyWidth = len(u.Family)
+		}
+		models += u.Models
+	}
+
+	fmt.Printf("%-*s %6s %10s\n", maxFamilyWidth, "FAMILY", "MODELS", "SIZE")
+	for _, u := range usage {
+		fmt.Printf("%-*s %6d %10s\n", maxFamilyWidth, u.Family, u.Models, formatGB(u.Size))
+	}
+	fmt.Printf("%-*s %6d %10s\n", maxFamilyWidth, "total", models, formatGB(total))
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+)
+
+// dryRun is set by the global --dry-run flag: HTTP requests and SSH tunnels
+// are printed to stderr instead of being made
+var dryRun bool
+
+// errDryRun is returned for every HTTP request in dry-run mode
+var errDryRun = errors.New("dry run: request not sent")
+
+// secretHeaderWords mark headers whose values are redacted in dry-run output
+var secretHeaderWords = []string{"auth", "key", "token", "secret", "cookie"}
+
+// secretQueryParams are query parameters whose values are redacted
+var secretQueryParams = []string{"key", "api_key", "apikey", "token", "access_token"}
+
+// dryRunTransport prints requests instead of sending them
+type dryRunTransport struct{}
+
+// RoundTrip prints the request with secrets redacted and fails it
+func (dryRunTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	fmt.Fprintf(os.Stderr, "[dry-run] %s %s\n", req.Method, redactURL(req.URL))
+
+	var names []string
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range req.Header[name] {
+			fmt.Fprintf(os.Stderr, "[dry-run]   %s: %s\n", name, redactHeader(name, value))
+		}
+	}
+	if req.ContentLength > 0 {
+		fmt.Fprintf(os.Stderr, "[dry-run]   (%d-byte body)\n", req.ContentLength)
+	}
+	return nil, errDryRun
+}
+
+// redactHeader hides the value of credential headers, keeping an
+// authorization scheme such as Bearer
+func redactHeader(name, value string) string {
+	lower := strings.ToLower(name)
+	for _, word := range secretHeaderWords {
+		if strings.Contains(lower, word) {
+			if scheme, _, ok := strings.Cut(value, " "); ok {
+				return scheme + " [redacted]"
+			}
+			return "[redacted]"
+		}
+	}
+	return value
+}
+
+// redactURL hides credentials in the user info and query of u
+func redactURL(u *url.URL) string {
+	redacted := *u
+	if redacted.User != nil {
+		redacted.User = url.User(redacted.User.Username())
+	}
+	query := redacted.Query()
+	changed := false
+	for _, param := range secretQueryParams {
+		if query.Has(param) {
+			query.Set(param, "redacted")
+			changed = true
+		}
+	}
+	if changed {
+		redacted.RawQuery = query.Encode()
+	}
+	return redacted.String()
+}
+
+// EnableDryRun routes all HTTP requests through the dry-run transport
+func EnableDryRun() {
+	dryRun = true
+	http.DefaultTransport = dryRunTransport{}
+}
+
+// globalFlags are the flags accepted before or after any subcommand
+type globalFlags struct {
+	dryRun  bool
+	profile string
+	record  string
+	replay  string
+}
+
+// RegisterFlags declares the global flags on fs
+func (f *globalFlags) RegisterFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&f.dryRun, "dry-run", false, "Print the HTTP requests (secrets redacted) and SSH tunnels that would be\nmade instead of making them")
+	fs.StringVar(&f.profile, "profile", "", "Apply the config file profile `NAME` (default: $LLMLS_PROFILE)")
+	fs.StringVar(&f.record, "record", "", "Save every HTTP response of the run to a session `FILE` (no request headers)")
+	fs.StringVar(&f.replay, "replay", "", "Answer HTTP requests from a recorded session `FILE` instead of the network")
+}
+
+// ParseGlobalFlags removes the global flags from args and applies them;
+// --profile is applied by InitConfig
+func ParseGlobalFlags(args []string) ([]string, error) {
+	var flags globalFlags
+	fs := NewFlagSet("llmls", &flags)
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		f := fs.Lookup(name)
+		if !strings.HasPrefix(arg, "-") || f == nil {
+			rest = append(rest, arg)
+			continue
+		}
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+			if !hasValue {
+				value = "true"
+			}
+		} else if !hasValue {
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("--%s requires a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		if err := fs.Set(name, value); err != nil {
+			return nil, fmt.Errorf("invalid value %q for --%s: %v", value, name, err)
+		}
+	}
+	profileFlag = flags.profile
+
+	switch {
+	case flags.record != "" && flags.replay != "":
+		return nil, fmt.Errorf("--record and --replay cannot be combined")
+	case flags.dryRun && (flags.record != "" || flags.replay != ""):
+		return nil, fmt.Errorf("--dry-run cannot be combined with --record or --replay")
+	case flags.dryRun:
+		EnableDryRun()
+	case flags.record != "":
+		StartRecording(flags.record)
+	case flags.replay != "":
+		if err := StartReplay(flags.replay); err != nil {
+			return nil, err
+		}
+	}
+	return rest, nil
+}
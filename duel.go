@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// DuelContender is one model of a duel with its route and running totals
+type DuelContender struct {
+	Model        catalog.Model
+	Backend      ChatBackend
+	Replies      int
+	Errors       int
+	Latency      time.Duration
+	InputTokens  int
+	OutputTokens int
+	Cost         float64
+	CostOK       bool // false if the model has no catalog pricing
+}
+
+// DuelRound is one prompt of a duel and the replies of both models
+type DuelRound struct {
+	Prompt  string
+	Results [2]ChatResult
+	Errors  [2]error
+}
+
+// ReadPrompts reads a prompt file: one prompt per line, skipping blank lines
+// and lines starting with #
+func ReadPrompts(r io.Reader) ([]string, error) {
+	var prompts []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		prompts = append(prompts, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read prompts: %w", err)
+	}
+	return prompts, nil
+}
+
+// RunDuelRound sends a request to both contenders concurrently and adds the
+// replies to their totals
+func RunDuelRound(contenders *[2]DuelContender, req ChatRequest) DuelRound {
+	round := DuelRound{Prompt: req.Messages[len(req.Messages)-1].Content}
+	var wg sync.WaitGroup
+	for i := range contenders {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			r := req
+			r.Model = contenders[i].Model.ID
+			scheduler.Wait(contenders[i].Backend.Name)
+			round.Results[i], round.Errors[i] = contenders[i].Backend.Chat(r, nil)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range contenders {
+		c := &contenders[i]
+		if round.Errors[i] != nil {
+			c.Errors++
+			continue
+		}
+		result := round.Results[i]
+		c.Replies++
+		c.Latency += result.Latency
+		c.InputTokens += result.InputTokens
+		c.OutputTokens += result.OutputTokens
+		cost, ok := ChatCost(c.Model, result)
+		c.Cost += cost
+		c.CostOK = ok
+	}
+	return round
+}
+
+// duelReplyLines returns a reply, or its error, as lines wrapped to width,
+// keeping the reply's own line breaks
+func duelReplyLines(result ChatResult, err error, width int) []string {
+	if err != nil {
+		return WrapText("Error: "+err.Error(), width)
+	}
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(result.Text), "\n") {
+		if strings.TrimSpace(line) == "" {
+			lines = append(lines, "")
+			continue
+		}
+		lines = append(lines, WrapText(line, width)...)
+	}
+	return lines
+}
+
+// duelReplyHeader labels a reply with its model, latency, and cost
+func duelReplyHeader(c DuelContender, result ChatResult, err error) string {
+	if err != nil {
+		return c.Model.ID + " (failed)"
+	}
+	header := fmt.Sprintf("%s (%.2fs", c.Model.ID, result.Latency.Seconds())
+	if cost, ok := ChatCost(c.Model, result); ok {
+		header += ", " + FormatCost(cost)
+	}
+	return header + ")"
+}
+
+// displayWidth returns the terminal columns of s, counting East Asian wide
+// characters (CJK, Hangul, fullwidth forms) as two
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		switch {
+		case r >= 0x1100 && r <= 0x115F, r >= 0x2E80 && r <= 0xA4CF, r >= 0xAC00 && r <= 0xD7A3,
+			r >= 0xF900 && r <= 0xFAFF, r >= 0xFE30 && r <= 0xFE4F, r >= 0xFF00 && r <= 0xFF60,
+			r >= 0xFFE0 && r <= 0xFFE6, r >= 0x20000:
+			width += 2
+		default:
+			width++
+		}
+	}
+	return width
+}
+
+// padRight pads s with spaces to width terminal columns
+func padRight(s string, width int) string {
+	if n := displayWidth(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+// DisplayDuelRound prints the prompt and both replies side by side, or as a
+// line diff of the second reply against the first
+func DisplayDuelRound(round DuelRound, index, total int, contenders [2]DuelContender, diff bool) {
+	termWidth := GetTerminalWidth()
+	fmt.Printf("=== Prompt %d/%d: %s\n", index, total, TruncateDescription(round.Prompt, termWidth-20))
+
+	if diff {
+		fmt.Printf("--- %s\n", duelReplyHeader(contenders[0], round.Results[0], round.Errors[0]))
+		fmt.Printf("+++ %s\n", duelReplyHeader(contenders[1], round.Results[1], round.Errors[1]))
+		a := duelReplyLines(round.Results[0], round.Errors[0], termWidth-2)
+		b := duelReplyLines(round.Results[1], round.Errors[1], termWidth-2)
+		for _, line := range DiffLines(a, b) {
+			fmt.Println(line)
+		}
+		fmt.Println()
+		return
+	}
+
+	width := (termWidth - 3) / 2
+	var columns [2][]string
+	for i := range contenders {
+		header := TruncateDescription(duelReplyHeader(contenders[i], round.Results[i], round.Errors[i]), width)
+		columns[i] = append([]string{header, strings.Repeat("-", width)}, duelReplyLines(round.Results[i], round.Errors[i], width)...)
+	}
+	rows := max(len(columns[0]), len(columns[1]))
+	for row := 0; row < rows; row++ {
+		var left, right string
+		if row < len(columns[0]) {
+			left = columns[0][row]
+		}
+		if row < len(columns[1]) {
+			right = columns[1][row]
+		}
+		fmt.Println(strings.TrimRight(padRight(left, width)+" | "+right, " "))
+	}
+	fmt.Println()
+}
+
+// DiffLines returns a unified-style line diff from a to b: unchanged lines
+// are prefixed with two spaces, removed lines with "- ", added lines with "+ "
+func DiffLines(a, b []string) []string {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out []string
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			out = append(out, "  "+a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, "- "+a[i])
+			i++
+		default:
+			out = append(out, "+ "+b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		out = append(out, "- "+a[i])
+	}
+	for ; j < len(b); j++ {
+		out = append(out, "+ "+b[j])
+	}
+	return out
+}
+
+// DisplayDuelTotals prints the replies, latency, tokens, and cost of each model
+func DisplayDuelTotals(contenders [2]DuelContender) {
+	modelWidth := len("MODEL")
+	for _, c := range contenders {
+		modelWidth = max(modelWidth, len(c.Model.ID))
+	}
+	fmt.Printf("%-*s %-10s %7s %6s %9s %9s %9s %10s\n", modelWidth, "MODEL", "VIA", "REPLIES", "ERRORS", "LATENCY", "IN", "OUT", "COST")
+	for _, c := range contenders {
+		cost := "-"
+		if c.CostOK {
+			cost = FormatCost(c.Cost)
+		}
+		fmt.Printf("%-*s %-10s %7d %6d %8.2fs %9s %9s %10s\n", modelWidth, c.Model.ID, c.Backend.Name,
+			c.Replies, c.Errors, c.Latency.Seconds(), FormatNumber(c.InputTokens), FormatNumber(c.OutputTokens), cost)
+	}
+}
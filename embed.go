@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// DefaultEmbedModel is the Ollama model used for semantic search
+const DefaultEmbedModel = "nomic-embed-text"
+
+// embedBatchSize limits how many texts are sent per embedding request
+const embedBatchSize = 64
+
+// Embedder computes text embeddings with an Ollama embedding model, caching
+// vectors on disk keyed by the hash of the embedded text
+type Embedder struct {
+	Host  string // Ollama server URL
+	Model string // Embedding model name
+	cache map[string][]float64
+	dirty bool
+}
+
+// embedText is the text embedded for a model
+func embedText(model catalog.Model) string {
+	return model.Name + ". " + model.Description
+}
+
+// textHash returns the cache key of a text
+func textHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// embedCachePath returns the cache file of the embedding model
+func (e *Embedder) embedCachePath() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+	name := strings.NewReplacer("/", "_", ":", "_").Replace(e.Model)
+	return filepath.Join(cacheDir, "llmls", "embeddings", name+".json"), nil
+}
+
+// loadCache reads cached embeddings; a missing or unreadable cache starts empty
+func (e *Embedder) loadCache() {
+	e.cache = make(map[string][]float64)
+	path, err := e.embedCachePath()
+	if err != nil {
+		return
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &e.cache)
+	}
+}
+
+// saveCache writes the embeddings cache if new vectors were added
+func (e *Embedder) saveCache() error {
+	if !e.dirty {
+		return nil
+	}
+	path, err := e.embedCachePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create embeddings cache directory: %w", err)
+	}
+	data, err := json.Marshal(e.cache)
+	if err != nil {
+		return fmt.Errorf("failed to encode embeddings cache: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write embeddings cache: %w", err)
+	}
+	e.dirty = false
+	return nil
+}
+
+// requestEmbeddings asks Ollama to embed texts
+func (e *Embedder) requestEmbeddings(texts []string) ([][]float64, error) {
+	body, err := json.Marshal(map[string]interface{}{"model": e.Model, "input": texts})
+	if err != nil {
+		return nil, err
+	}
+
+	// Embedding a whole catalog on first use can take a while on CPU
+	client := &http.Client{Timeout: 5 * time.Minute}
+	resp, err := client.Post(e.Host+"/api/embed", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach Ollama for embeddings: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		if apiErr.Error != "" {
+			return nil, fmt.Errorf("ollama embeddings failed: %s (try 'ollama pull %s')", apiErr.Error, e.Model)
+		}
+		return nil, fmt.Errorf("ollama embeddings returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Embeddings [][]float64 `json:"embeddings"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse embeddings: %w", err)
+	}
+	if len(result.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("ollama returned %d embeddings for %d texts", len(result.Embeddings), len(texts))
+	}
+	return result.Embeddings, nil
+}
+
+// Embed returns embeddings for texts, requesting only those not in the cache
+func (e *Embedder) Embed(texts []string) ([][]float64, error) {
+	if e.cache == nil {
+		e.loadCache()
+	}
+
+	var missing []string
+	pending := make(map[string]bool)
+	for _, text := range texts {
+		key := textHash(text)
+		if _, ok := e.cache[key]; !ok && !pending[key] {
+			pending[key] = true
+			missing = append(missing, text)
+		}
+	}
+
+	for start := 0; start < len(missing); start += embedBatchSize {
+		end := start + embedBatchSize
+		if end > len(missing) {
+			end = len(missing)
+		}
+		vectors, err := e.requestEmbeddings(missing[start:end])
+		if err != nil {
+			// Keep what was embedded so far for the next run
+			e.saveCache()
+			return nil, err
+		}
+		for i, text := range missing[start:end] {
+			e.cache[textHash(text)] = vectors[i]
+		}
+		e.dirty = true
+	}
+
+	if err := e.saveCache(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	result := make([][]float64, len(texts))
+	for i, text := range texts {
+		result[i] = e.cache[textHash(text)]
+	}
+	return result, nil
+}
+
+// cosineSimilarity returns the cosine of the angle between a and b
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// SemanticSearch ranks models by cosine similarity between the query and their
+// embedded name and description, returning up to limit results, best first
+func SemanticSearch(embedder *Embedder, models []catalog.Model, query string, limit int) ([]SearchResult, error) {
+	texts := make([]string, 0, len(models)+1)
+	texts = append(texts, query)
+	for _, model := range models {
+		texts = append(texts, embedText(model))
+	}
+
+	vectors, err := embedder.Embed(texts)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]SearchResult, len(models))
+	for i, model := range models {
+		results[i] = SearchResult{Model: model, Score: cosineSimilarity(vectors[0], vectors[i+1])}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// embeddingSampleTexts are embedded by the embedding check; the first two are
+// paraphrases and the third is unrelated, so a working model ranks the
+// paraphrase as more similar
+var embeddingSampleTexts = []string{
+	"The cat is sleeping on the warm windowsill.",
+	"A cat naps on a sunny window ledge.",
+	"Quarterly revenue rose by twelve percent after the merger.",
+}
+
+// EmbeddingBackend is the embeddings API of a model
+type EmbeddingBackend struct {
+	Name    string
+	URL     string
+	Headers map[string]string
+	Model   string // Model ID as the API expects it
+	Ollama  bool   // Ollama's /api/embed instead of an OpenAI-compatible API
+}
+
+// ResolveEmbeddingBackend chooses the embeddings API of a model with the same
+// routing as chat (see ResolveChatBackend)
+func ResolveEmbeddingBackend(modelID, via, ollamaHost string) (EmbeddingBackend, error) {
+	chat, err := ResolveChatBackend(modelID, via, ollamaHost)
+	if err != nil {
+		return EmbeddingBackend{}, err
+	}
+	backend := EmbeddingBackend{Name: chat.Name, Headers: chat.Headers, Model: chat.Model}
+	switch chat.Format {
+	case chatFormatOllama:
+		backend.URL = strings.TrimSuffix(chat.URL, "/api/chat") + "/api/embed"
+		backend.Ollama = true
+	case chatFormatOpenAI:
+		backend.URL = strings.TrimSuffix(chat.URL, "/chat/completions") + "/embeddings"
+	default:
+		return EmbeddingBackend{}, fmt.Errorf("%s has no embeddings API", chat.Name)
+	}
+	return backend, nil
+}
+
+// EmbeddingResult is the reply of an embeddings request
+type EmbeddingResult struct {
+	Vectors     [][]float64
+	InputTokens int
+	Latency     time.Duration
+}
+
+// Embed requests embeddings for texts
+func (b EmbeddingBackend) Embed(texts []string) (EmbeddingResult, error) {
+	var result EmbeddingResult
+	start := time.Now()
+	if b.Ollama {
+		var reply struct {
+			Embeddings      [][]float64 `json:"embeddings"`
+			PromptEvalCount int         `json:"prompt_eval_count"`
+		}
+		if err := postJSON(b.URL, b.Headers, map[string]interface{}{"model": b.Model, "input": texts}, &reply); err != nil {
+			return result, fmt.Errorf("%s embeddings failed: %w", b.Name, err)
+		}
+		result.Vectors, result.InputTokens = reply.Embeddings, reply.PromptEvalCount
+	} else {
+		var reply struct {
+			Data []struct {
+				Index     int       `json:"index"`
+				Embedding []float64 `json:"embedding"`
+			} `json:"data"`
+			Usage struct {
+				PromptTokens int `json:"prompt_tokens"`
+			} `json:"usage"`
+		}
+		if err := postJSON(b.URL, b.Headers, map[string]interface{}{"model": b.Model, "input": texts}, &reply); err != nil {
+			return result, fmt.Errorf("%s embeddings failed: %w", b.Name, err)
+		}
+		result.Vectors = make([][]float64, len(reply.Data))
+		for _, d := range reply.Data {
+			if d.Index >= 0 && d.Index < len(result.Vectors) {
+				result.Vectors[d.Index] = d.Embedding
+			}
+		}
+		result.InputTokens = reply.Usage.PromptTokens
+	}
+	result.Latency = time.Since(start)
+
+	if len(result.Vectors) != len(texts) {
+		return result, fmt.Errorf("%s returned %d embeddings for %d texts", b.Name, len(result.Vectors), len(texts))
+	}
+	return result, nil
+}
+
+// EmbeddingCheck is the outcome of the embedding check against one model
+type EmbeddingCheck struct {
+	Model       string
+	Backend     string
+	Outcome     string // TestPass, TestFail, or TestError
+	Detail      string
+	Dimensions  int
+	InputTokens int
+	Latency     time.Duration
+	PricePerM   string // Catalog input price per 1M tokens, "" if unknown
+}
+
+// CheckEmbeddings embeds the sample texts with a model and checks that the
+// vectors have one dimensionality, are not all zero, and rank the paraphrase
+// closer than the unrelated text
+func CheckEmbeddings(model catalog.Model, backend EmbeddingBackend) EmbeddingCheck {
+	check := EmbeddingCheck{Model: model.ID, Backend: backend.Name}
+	if model.Pricing.Prompt != "" {
+		check.PricePerM = FormatUSD(fmt.Sprintf("%.4f", catalog.ParsePrice(model.Pricing.Prompt)*1e6))
+	}
+
+	scheduler.Wait(backend.Name)
+	result, err := backend.Embed(embeddingSampleTexts)
+	check.Latency = result.Latency
+	check.InputTokens = result.InputTokens
+	if err != nil {
+		check.Outcome, check.Detail = TestError, err.Error()
+		return check
+	}
+
+	check.Dimensions = len(result.Vectors[0])
+	for _, v := range result.Vectors {
+		if len(v) != check.Dimensions || len(v) == 0 {
+			check.Outcome, check.Detail = TestFail, "vectors have different dimensions"
+			return check
+		}
+	}
+	similar := cosineSimilarity(result.Vectors[0], result.Vectors[1])
+	unrelated := cosineSimilarity(result.Vectors[0], result.Vectors[2])
+	switch {
+	case similar == 0 && unrelated == 0:
+		check.Outcome, check.Detail = TestFail, "vectors are all zero"
+	case similar <= unrelated:
+		check.Outcome = TestFail
+		check.Detail = fmt.Sprintf("paraphrase similarity %.3f is not above unrelated %.3f", similar, unrelated)
+	default:
+		check.Outcome = TestPass
+	}
+	return check
+}
+
+// DisplayEmbeddingChecks prints the dimensionality, latency, price, and
+// outcome of each model, followed by the reasons for failures
+func DisplayEmbeddingChecks(checks []EmbeddingCheck) {
+	modelWidth := len("MODEL")
+	for _, c := range checks {
+		modelWidth = max(modelWidth, len(c.Model))
+	}
+	fmt.Printf("%-*s %-10s %6s %6s %8s %12s %s\n", modelWidth, "MODEL", "VIA", "DIM", "TOKENS", "LATENCY", "PRICE/1M", "CHECK")
+
+	var details []string
+	for _, c := range checks {
+		dim, tokens, price := "-", "-", "-"
+		if c.Dimensions > 0 {
+			dim = FormatNumber(c.Dimensions)
+		}
+		if c.InputTokens > 0 {
+			tokens = FormatNumber(c.InputTokens)
+		}
+		if c.PricePerM != "" {
+			price = c.PricePerM
+		}
+		fmt.Printf("%-*s %-10s %6s %6s %7.2fs %12s %s\n", modelWidth, c.Model, c.Backend, dim, tokens, c.Latency.Seconds(), price, c.Outcome)
+		if c.Detail != "" {
+			details = append(details, fmt.Sprintf("%s: %s: %s", c.Model, c.Outcome, c.Detail))
+		}
+	}
+
+	if len(details) > 0 {
+		fmt.Println()
+		for _, d := range details {
+			fmt.Println(d)
+		}
+	}
+}
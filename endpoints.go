@@ -0,0 +1,198 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// endpointSortKeys are the accepted --sort values
+var endpointSortKeys = []string{"price", "latency", "throughput", "uptime", "context"}
+
+// Endpoint is one upstream provider serving an OpenRouter model
+type Endpoint struct {
+	ProviderName        string          `json:"provider_name"`
+	Tag                 string          `json:"tag"`
+	ContextLength       int             `json:"context_length"`
+	MaxCompletionTokens int             `json:"max_completion_tokens"`
+	Quantization        string          `json:"quantization"`
+	Pricing             catalog.Pricing `json:"pricing"`
+	Status              int             `json:"status"`
+	Uptime              *float64        `json:"uptime_last_30m"`
+	RawLatency          json.RawMessage `json:"latency_last_30m"`
+	RawThroughput       json.RawMessage `json:"throughput_last_30m"`
+}
+
+// EndpointsResponse is the OpenRouter model endpoints API response
+type EndpointsResponse struct {
+	Data struct {
+		ID        string     `json:"id"`
+		Endpoints []Endpoint `json:"endpoints"`
+	} `json:"data"`
+}
+
+// statValue reads a stat reported either as a number or as percentiles
+// ({"p50": ...}), returning the median; ok is false when absent
+func statValue(raw json.RawMessage) (float64, bool) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return 0, false
+	}
+	var n float64
+	if err := json.Unmarshal(raw, &n); err == nil {
+		return n, true
+	}
+	var percentiles map[string]float64
+	if err := json.Unmarshal(raw, &percentiles); err == nil {
+		if p50, ok := percentiles["p50"]; ok {
+			return p50, true
+		}
+	}
+	return 0, false
+}
+
+// Latency returns the median latency in milliseconds over the last 30 minutes
+func (e Endpoint) Latency() (float64, bool) {
+	return statValue(e.RawLatency)
+}
+
+// Throughput returns the median tokens per second over the last 30 minutes
+func (e Endpoint) Throughput() (float64, bool) {
+	return statValue(e.RawThroughput)
+}
+
+// FetchModelEndpoints retrieves the upstream providers serving an OpenRouter model
+func FetchModelEndpoints(modelID string) ([]Endpoint, error) {
+	url := catalog.OpenRouterModelsURL + "/" + modelID + "/endpoints"
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch endpoints: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("model not found: %s", modelID)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var endpointsResp EndpointsResponse
+	if err := json.Unmarshal(body, &endpointsResp); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	return endpointsResp.Data.Endpoints, nil
+}
+
+// SortEndpoints orders endpoints by key: price and latency ascending, throughput,
+// uptime, and context descending; endpoints missing the stat sort last
+func SortEndpoints(endpoints []Endpoint, key string) error {
+	var value func(Endpoint) (float64, bool)
+	descending := false
+	switch key {
+	case "price":
+		value = func(e Endpoint) (float64, bool) {
+			return catalog.ParsePrice(e.Pricing.Prompt) + catalog.ParsePrice(e.Pricing.Completion), e.Pricing.Prompt != ""
+		}
+	case "latency":
+		value = Endpoint.Latency
+	case "throughput":
+		value = Endpoint.Throughput
+		descending = true
+	case "uptime":
+		value = func(e Endpoint) (float64, bool) {
+			if e.Uptime == nil {
+				return 0, false
+			}
+			return *e.Uptime, true
+		}
+		descending = true
+	case "context":
+		value = func(e Endpoint) (float64, bool) { return float64(e.ContextLength), true }
+		descending = true
+	default:
+		return fmt.Errorf("invalid sort key: %s (expected %s)", key, strings.Join(endpointSortKeys, ", "))
+	}
+
+	sort.SliceStable(endpoints, func(i, j int) bool {
+		a, aok := value(endpoints[i])
+		b, bok := value(endpoints[j])
+		if aok != bok {
+			return aok
+		}
+		if descending {
+			return a > b
+		}
+		return a < b
+	})
+	return nil
+}
+
+// formatStat formats an optional stat with the given format, or "-" if absent
+func formatStat(format string, v float64, ok bool) string {
+	if !ok {
+		return "-"
+	}
+	return fmt.Sprintf(format, v)
+}
+
+// DisplayEndpoints prints endpoints as an aligned table
+func DisplayEndpoints(endpoints []Endpoint) {
+	if len(endpoints) == 0 {
+		return
+	}
+
+	maxProviderWidth := len("PROVIDER")
+	for _, e := range endpoints {
+		if len(e.ProviderName) > maxProviderWidth {
+			maxProviderWidth = len(e.ProviderName)
+		}
+	}
+
+	fmt.Printf("%-*s %-7s %8s %10s %10s %7s %8s %7s\n",
+		maxProviderWidth, "PROVIDER",
+		"QUANT", "CONTEXT", "PROMPT/1K", "COMPL/1K", "UPTIME", "LATENCY", "TOK/S")
+
+	for _, e := range endpoints {
+		quant := e.Quantization
+		if quant == "" || quant == "unknown" {
+			quant = "-"
+		}
+		uptime := "-"
+		if e.Uptime != nil {
+			uptime = fmt.Sprintf("%.1f%%", math.Min(*e.Uptime, 100))
+		}
+		latency, latencyOK := e.Latency()
+		throughput, throughputOK := e.Throughput()
+
+		fmt.Printf("%-*s %-7s %8s %10s %10s %7s %8s %7s\n",
+			maxProviderWidth, e.ProviderName,
+			quant,
+			FormatContextLength(e.ContextLength),
+			FormatUSD(FormatPrice(e.Pricing.Prompt)),
+			FormatUSD(FormatPrice(e.Pricing.Completion)),
+			uptime,
+			formatStat("%.0fms", latency, latencyOK),
+			formatStat("%.0f", throughput, throughputOK))
+	}
+}
+
+// DisplayEndpointsJSON prints endpoints as a JSON array
+func DisplayEndpointsJSON(endpoints []Endpoint) error {
+	data, err := json.MarshalIndent(endpoints, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
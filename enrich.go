@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// enrichTimeout bounds the run time of one enrichment plugin
+const enrichTimeout = 30 * time.Second
+
+// GetEnrichDir returns the directory of enrichment plugins from env var or
+// the user config directory
+func GetEnrichDir() (string, error) {
+	if dir := os.Getenv("LLMLS_ENRICH_DIR"); dir != "" {
+		return dir, nil
+	}
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(configDir, "llmls", "enrich.d"), nil
+}
+
+// EnrichPlugins returns the executables in the plugin directory in name order
+// Files starting with "." are skipped; a missing directory has no plugins
+func EnrichPlugins() ([]string, error) {
+	dir, err := GetEnrichDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read enrichment plugins: %w", err)
+	}
+
+	var plugins []string
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		// Windows has no executable bit
+		if runtime.GOOS != "windows" && info.Mode().Perm()&0o111 == 0 {
+			continue
+		}
+		plugins = append(plugins, filepath.Join(dir, entry.Name()))
+	}
+	sort.Strings(plugins)
+	return plugins, nil
+}
+
+// RunEnrichPlugin runs a plugin with the JSON array of models on stdin and
+// returns its stdout: a JSON object mapping model IDs to objects of extra fields
+func RunEnrichPlugin(plugin string, input []byte) (map[string]map[string]interface{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), enrichTimeout)
+	defer cancel()
+
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, plugin)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("plugin %s failed: %w", filepath.Base(plugin), err)
+	}
+
+	// Keep numbers as written, as --field does
+	decoder := json.NewDecoder(&stdout)
+	decoder.UseNumber()
+	var fields map[string]map[string]interface{}
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, fmt.Errorf("plugin %s returned invalid JSON: %w", filepath.Base(plugin), err)
+	}
+	return fields, nil
+}
+
+// EnrichModels runs every plugin over models and merges the returned fields
+// into Extra; later plugins override fields of earlier ones
+// A failing plugin is skipped; the first error is returned after all have run
+func EnrichModels(models []catalog.Model) error {
+	plugins, err := EnrichPlugins()
+	if err != nil || len(plugins) == 0 || len(models) == 0 {
+		return err
+	}
+	if dryRun {
+		for _, plugin := range plugins {
+			fmt.Fprintf(os.Stderr, "[dry-run] enrich %s\n", plugin)
+		}
+		return nil
+	}
+
+	catalog, err := CatalogJSON(models)
+	if err != nil {
+		return err
+	}
+	input, err := json.Marshal(catalog)
+	if err != nil {
+		return fmt.Errorf("failed to encode models: %w", err)
+	}
+
+	index := make(map[string]int, len(models))
+	for i, model := range models {
+		index[model.ID] = i
+	}
+
+	var firstErr error
+	for _, plugin := range plugins {
+		fields, err := RunEnrichPlugin(plugin, input)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		for id, extra := range fields {
+			i, ok := index[id]
+			if !ok {
+				continue
+			}
+			if models[i].Extra == nil {
+				models[i].Extra = make(map[string]interface{})
+			}
+			for key, value := range extra {
+				models[i].Extra[key] = value
+			}
+		}
+	}
+	return firstErr
+}
+
+// FormatModelExtra formats the extra fields of a model as "key=value" pairs in key order
+func FormatModelExtra(model catalog.Model) string {
+	keys := make([]string, 0, len(model.Extra))
+	for key := range model.Extra {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + "=" + catalog.FormatFieldValue(model.Extra[key])
+	}
+	return strings.Join(pairs, " ")
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// ExplainFilter filters models by pattern like FilterModels, recording the
+// match criterion on each result and printing a summary of the filter to stderr
+func ExplainFilter(models []catalog.Model, pattern string) []catalog.Model {
+	if pattern == "" {
+		fmt.Fprintf(os.Stderr, "No pattern: all %d models listed\n", len(models))
+		return models
+	}
+
+	counts := make(map[string]int)
+	var filtered []catalog.Model
+	for _, model := range models {
+		reason := catalog.MatchReason(model, pattern)
+		if reason == "" {
+			continue
+		}
+		model.MatchedBy = reason
+		counts[reason]++
+		filtered = append(filtered, model)
+	}
+
+	fmt.Fprintf(os.Stderr, "Pattern %q: %d models -> %d matched, %d excluded\n",
+		pattern, len(models), len(filtered), len(models)-len(filtered))
+	for _, reason := range []string{catalog.MatchIDGlob, catalog.MatchNameGlob, catalog.MatchProviderExact} {
+		if counts[reason] > 0 {
+			fmt.Fprintf(os.Stderr, "  %-16s %d\n", reason+":", counts[reason])
+		}
+	}
+
+	return filtered
+}
+
+// FilterStep is a filter of the listing and how many models it excluded
+type FilterStep struct {
+	Name     string // The flag of the filter, e.g. --type
+	Excluded int
+}
+
+// FilterChain records how many models each filter of the listing excludes,
+// for --explain
+type FilterChain struct {
+	Steps []FilterStep
+}
+
+// Apply records how many models a filter excluded and returns what it kept
+func (c *FilterChain) Apply(name string, before, after []catalog.Model) []catalog.Model {
+	if excluded := len(before) - len(after); excluded > 0 {
+		c.Steps = append(c.Steps, FilterStep{Name: name, Excluded: excluded})
+	}
+	return after
+}
+
+// Display prints the models each filter excluded to stderr, in chain order
+func (c *FilterChain) Display(matched, listed int) {
+	if len(c.Steps) == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Filters: %d models -> %d listed\n", matched, listed)
+	width := 0
+	for _, step := range c.Steps {
+		width = max(width, len(step.Name)+1)
+	}
+	for _, step := range c.Steps {
+		fmt.Fprintf(os.Stderr, "  %-*s %d excluded\n", width, step.Name+":", step.Excluded)
+	}
+}
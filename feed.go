@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// Feed event kinds
+const (
+	EventAdded        = "added"
+	EventPriceChanged = "price-changed"
+)
+
+// CatalogEvent is a catalog change between two consecutive snapshots
+type CatalogEvent struct {
+	Time       time.Time
+	Kind       string
+	Model      catalog.Model
+	OldPricing catalog.Pricing // Set for price changes
+}
+
+// CatalogEvents lists added models and price changes across snapshots (oldest first),
+// newest event first
+func CatalogEvents(snapshots []*Snapshot) []CatalogEvent {
+	var events []CatalogEvent
+	for i := 1; i < len(snapshots); i++ {
+		prev, curr := snapshots[i-1], snapshots[i]
+		// A source that was down in either snapshot has no events between them
+		both := commonSources(prev, curr)
+
+		oldByID := make(map[string]catalog.Model, len(prev.Models))
+		for _, model := range modelsFromSources(prev.Models, both) {
+			oldByID[model.ID] = model
+		}
+
+		for _, model := range modelsFromSources(curr.Models, both) {
+			old, ok := oldByID[model.ID]
+			switch {
+			case !ok:
+				events = append(events, CatalogEvent{Time: curr.TakenAt, Kind: EventAdded, Model: model})
+			case old.Pricing.Prompt != model.Pricing.Prompt || old.Pricing.Completion != model.Pricing.Completion:
+				events = append(events, CatalogEvent{Time: curr.TakenAt, Kind: EventPriceChanged, Model: model, OldPricing: old.Pricing})
+			}
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.After(events[j].Time)
+	})
+	return events
+}
+
+// atomFeed is the Atom 1.0 feed document
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+// atomEntry is a single Atom feed entry
+type atomEntry struct {
+	ID      string    `xml:"id"`
+	Title   string    `xml:"title"`
+	Updated string    `xml:"updated"`
+	Link    *atomLink `xml:"link,omitempty"`
+	Content string    `xml:"content"`
+}
+
+// atomLink is an Atom link element
+type atomLink struct {
+	Href string `xml:"href,attr"`
+}
+
+// WriteAtomFeed writes up to limit events as an Atom feed
+func WriteAtomFeed(w io.Writer, events []CatalogEvent, updated time.Time, limit int) error {
+	if limit > 0 && len(events) > limit {
+		events = events[:limit]
+	}
+
+	feed := atomFeed{
+		ID:      "tag:llmls,2025:catalog",
+		Title:   "llmls catalog changes",
+		Updated: updated.UTC().Format(time.RFC3339),
+	}
+
+	for _, event := range events {
+		stamp := event.Time.UTC().Format(time.RFC3339)
+		entry := atomEntry{
+			ID:      fmt.Sprintf("tag:llmls,2025:%s:%s:%s", event.Kind, event.Model.ID, stamp),
+			Updated: stamp,
+		}
+		if url := catalog.ModelURL(event.Model); url != "" {
+			entry.Link = &atomLink{Href: url}
+		}
+
+		switch event.Kind {
+		case EventAdded:
+			entry.Title = "New model: " + event.Model.ID
+			entry.Content = event.Model.Description
+		case EventPriceChanged:
+			entry.Title = "Price change: " + event.Model.ID
+			entry.Content = fmt.Sprintf("Prompt $%s -> $%s, completion $%s -> $%s per 1K tokens",
+				FormatPrice(event.OldPricing.Prompt), FormatPrice(event.Model.Pricing.Prompt),
+				FormatPrice(event.OldPricing.Completion), FormatPrice(event.Model.Pricing.Completion))
+		}
+
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(feed); err != nil {
+		return fmt.Errorf("failed to encode feed: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
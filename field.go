@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/itchyny/gojq"
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// FieldsUse reports whether any dotted path is in a top-level field, e.g. for
+// filling in fields only looked up on demand
+func FieldsUse(paths []string, root string) bool {
+	for _, path := range paths {
+		if r, _, _ := strings.Cut(path, "."); r == root {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateFieldPaths checks that each dotted path names a known model field
+func ValidateFieldPaths(paths []string) error {
+	for _, path := range paths {
+		if !catalog.IsModelField(path) {
+			return fmt.Errorf("unknown field: %s", path)
+		}
+	}
+	return nil
+}
+
+// DisplayModelFields prints model ID and the requested field values, tab-separated
+func DisplayModelFields(models []catalog.Model, paths []string) error {
+	for _, model := range models {
+		m, err := catalog.ModelJSONMap(model)
+		if err != nil {
+			return fmt.Errorf("failed to encode model %s: %w", model.ID, err)
+		}
+
+		values := []string{model.ID}
+		for _, path := range paths {
+			value, _ := catalog.LookupPath(m, path)
+			values = append(values, catalog.FormatFieldValue(value))
+		}
+		fmt.Println(strings.Join(values, "\t"))
+	}
+	return nil
+}
+
+// GetModelField returns a single field value of the model with exactly the given ID
+func GetModelField(models []catalog.Model, modelID, path string) (string, error) {
+	if err := ValidateFieldPaths([]string{path}); err != nil {
+		return "", err
+	}
+
+	for _, model := range models {
+		if model.ID != modelID {
+			continue
+		}
+		m, err := catalog.ModelJSONMap(model)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode model %s: %w", model.ID, err)
+		}
+		value, _ := catalog.LookupPath(m, path)
+		return catalog.FormatFieldValue(value), nil
+	}
+
+	return "", fmt.Errorf("model not found: %s", modelID)
+}
+
+// CatalogJSON converts models to the JSON array given to --jq and enrichment
+// plugins, with the same field names as --field
+func CatalogJSON(models []catalog.Model) ([]interface{}, error) {
+	entries := make([]interface{}, 0, len(models))
+	for _, model := range models {
+		m, err := catalog.ModelJSONMap(model)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, m)
+	}
+	return entries, nil
+}
+
+// JQ is a compiled --jq program over the JSON representation of the catalog
+type JQ struct {
+	code *gojq.Code
+}
+
+// ParseJQ compiles a jq program such as '.[] | select(.context_length > 100000) | .id'
+func ParseJQ(program string) (*JQ, error) {
+	query, err := gojq.Parse(program)
+	if err != nil {
+		return nil, err
+	}
+	code, err := gojq.Compile(query)
+	if err != nil {
+		return nil, err
+	}
+	return &JQ{code: code}, nil
+}
+
+// Run evaluates the program against input and writes each output to w
+// Strings are printed raw (as with jq -r) so results can be used in shell
+// scripts directly; other values are printed as indented JSON
+func (q *JQ) Run(w io.Writer, input interface{}) error {
+	iter := q.code.Run(input)
+	for {
+		out, ok := iter.Next()
+		if !ok {
+			return nil
+		}
+		if err, ok := out.(error); ok {
+			var halt *gojq.HaltError
+			if errors.As(err, &halt) && halt.Value() == nil {
+				return nil
+			}
+			return err
+		}
+		if s, ok := out.(string); ok {
+			fmt.Fprintln(w, s)
+			continue
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(out); err != nil {
+			return err
+		}
+	}
+}
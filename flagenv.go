@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// flagEnvNames returns the environment variables of a flag, most specific
+// first: LLMLS_<SUBCOMMAND>_<FLAG>, then LLMLS_<FLAG>, e.g.
+// LLMLS_ENDPOINTS_SORT and LLMLS_SORT for endpoints --sort
+// The listing has no subcommand, so only LLMLS_<FLAG> applies to it
+func flagEnvNames(fs *flag.FlagSet, name string) []string {
+	flagPart := strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+	names := []string{"LLMLS_" + flagPart}
+	if fs.Name() != "llmls" {
+		subcommand := strings.ToUpper(strings.NewReplacer(" ", "_", "-", "_").Replace(fs.Name()))
+		names = append([]string{"LLMLS_" + subcommand + "_" + flagPart}, names...)
+	}
+	return names
+}
+
+// applyFlagEnv sets flags from their environment variables (see
+// flagEnvNames); single-letter aliases share the variable of their long name
+func applyFlagEnv(fs *flag.FlagSet) {
+	fs.VisitAll(func(f *flag.Flag) {
+		if len(f.Name) == 1 {
+			return
+		}
+		for _, env := range flagEnvNames(fs, f.Name) {
+			value, ok := os.LookupEnv(env)
+			if !ok {
+				continue
+			}
+			if err := fs.Set(f.Name, value); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s: invalid value %q for --%s: %v\n", env, value, f.Name, err)
+				exit(1)
+			}
+			return
+		}
+	})
+}
+
+// ParseFlags parses a subcommand's flags: flags on the command line override
+// LLMLS_* environment variables, which override the flag defaults
+// Flags may follow positional arguments, as in llmls "anthropic/*" --detail;
+// arguments after "--" are all positional
+func ParseFlags(fs *flag.FlagSet, args []string) {
+	applyFlagEnv(fs)
+	var positional []string
+	for {
+		fs.Parse(args)
+		rest := fs.Args()
+		consumed := args[:len(args)-len(rest)]
+		if len(rest) == 0 || (len(consumed) > 0 && consumed[len(consumed)-1] == "--") {
+			positional = append(positional, rest...)
+			break
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+	// Leave the positional arguments as fs.Args()
+	fs.Parse(append([]string{"--"}, positional...))
+}
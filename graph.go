@@ -0,0 +1,382 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// graphAxisNames are the built-in axes of llmls graph; computed columns may be used too
+var graphAxisNames = []string{"context", "price", "created"}
+
+// GraphAxis is a numeric model attribute plotted on one axis
+type GraphAxis struct {
+	Name   string
+	Log    bool // Plot on a log10 scale; models with non-positive values are skipped
+	value  func(catalog.Model) (float64, bool)
+	format func(float64) string
+}
+
+// GraphAxisFor returns the axis of a name: context and price (blended USD per
+// 1M tokens) use a log scale unless linear is set, created and computed
+// columns a linear scale
+func GraphAxisFor(name string, blend catalog.Blend, linear bool) (GraphAxis, error) {
+	switch name {
+	case "context":
+		return GraphAxis{
+			Name: name,
+			Log:  !linear,
+			value: func(m catalog.Model) (float64, bool) {
+				return float64(m.ContextLength), m.ContextLength > 0
+			},
+			format: func(v float64) string { return FormatContextLength(int(math.Round(v))) },
+		}, nil
+	case "price":
+		return GraphAxis{
+			Name: name,
+			Log:  !linear,
+			value: func(m catalog.Model) (float64, bool) {
+				price, ok := blend.Price(m)
+				return price * 1000000, ok
+			},
+			format: formatAxisPrice,
+		}, nil
+	case "created":
+		return GraphAxis{
+			Name: name,
+			value: func(m catalog.Model) (float64, bool) {
+				return float64(m.Created), m.Created > 0
+			},
+			format: func(v float64) string { return FormatDate(int64(v)) },
+		}, nil
+	}
+	if column, ok := computedColumns.Lookup(name); ok {
+		return GraphAxis{
+			Name: name,
+			value: func(m catalog.Model) (float64, bool) {
+				v, ok := column.Value(m).(float64)
+				return v, ok && !math.IsInf(v, 0) && !math.IsNaN(v)
+			},
+			format: func(v float64) string { return FormatComputedValue(math.Round(v*100) / 100) },
+		}, nil
+	}
+	return GraphAxis{}, fmt.Errorf("invalid axis: %s (expected %s, or a computed column)", name, strings.Join(graphAxisNames, ", "))
+}
+
+// formatAxisPrice formats a price per 1M tokens with precision by magnitude
+func formatAxisPrice(v float64) string {
+	switch {
+	case v >= 100:
+		return FormatUSD(fmt.Sprintf("%.0f", v))
+	case v >= 1:
+		return FormatUSD(fmt.Sprintf("%.2f", v))
+	}
+	return FormatUSD(fmt.Sprintf("%.3f", v))
+}
+
+// Title returns the axis title, e.g. "price $/1M (log)"
+func (a GraphAxis) Title() string {
+	title := a.Name
+	if a.Name == "price" {
+		title += " $/1M"
+	}
+	if a.Log {
+		title += " (log)"
+	}
+	return title
+}
+
+// scale maps a value to plot space
+func (a GraphAxis) scale(v float64) float64 {
+	if a.Log {
+		return math.Log10(v)
+	}
+	return v
+}
+
+// unscale maps plot space back to a value
+func (a GraphAxis) unscale(v float64) float64 {
+	if a.Log {
+		return math.Pow(10, v)
+	}
+	return v
+}
+
+// GraphPoint is a model placed on the plot; X and Y are in plot space
+type GraphPoint struct {
+	Model   catalog.Model
+	X, Y    float64
+	Outlier bool
+}
+
+// Graph is a scatter plot of models on two axes
+type Graph struct {
+	X, Y       GraphAxis
+	Points     []GraphPoint
+	Skipped    int // Models without a value on either axis
+	minX, maxX float64
+	minY, maxY float64
+}
+
+// NewGraph places the models with values on both axes and marks the labels
+// points farthest from the median as outliers
+func NewGraph(models []catalog.Model, x, y GraphAxis, labels int) *Graph {
+	g := &Graph{X: x, Y: y}
+	for _, model := range models {
+		xv, xok := x.value(model)
+		yv, yok := y.value(model)
+		if !xok || !yok || (x.Log && xv <= 0) || (y.Log && yv <= 0) {
+			g.Skipped++
+			continue
+		}
+		g.Points = append(g.Points, GraphPoint{Model: model, X: x.scale(xv), Y: y.scale(yv)})
+	}
+	if len(g.Points) == 0 {
+		return g
+	}
+
+	g.minX, g.maxX = g.Points[0].X, g.Points[0].X
+	g.minY, g.maxY = g.Points[0].Y, g.Points[0].Y
+	for _, p := range g.Points {
+		g.minX, g.maxX = min(g.minX, p.X), max(g.maxX, p.X)
+		g.minY, g.maxY = min(g.minY, p.Y), max(g.maxY, p.Y)
+	}
+	// Give a single value on an axis some room
+	if g.minX == g.maxX {
+		g.minX, g.maxX = g.minX-0.5, g.maxX+0.5
+	}
+	if g.minY == g.maxY {
+		g.minY, g.maxY = g.minY-0.5, g.maxY+0.5
+	}
+
+	g.markOutliers(labels)
+	return g
+}
+
+// markOutliers marks the n points farthest from the median point, measured
+// in plot coordinates normalized to the unit square
+func (g *Graph) markOutliers(n int) {
+	if n <= 0 {
+		return
+	}
+	xs := make([]float64, len(g.Points))
+	ys := make([]float64, len(g.Points))
+	for i, p := range g.Points {
+		xs[i], ys[i] = g.normX(p.X), g.normY(p.Y)
+	}
+	medianX, medianY := median(xs), median(ys)
+
+	order := make([]int, len(g.Points))
+	for i := range order {
+		order[i] = i
+	}
+	distance := func(i int) float64 { return math.Hypot(xs[i]-medianX, ys[i]-medianY) }
+	sort.SliceStable(order, func(a, b int) bool { return distance(order[a]) > distance(order[b]) })
+	for _, i := range order[:min(n, len(order))] {
+		g.Points[i].Outlier = true
+	}
+}
+
+// median returns the median of values
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// normX maps an X in plot space to 0..1
+func (g *Graph) normX(x float64) float64 {
+	return (x - g.minX) / (g.maxX - g.minX)
+}
+
+// normY maps a Y in plot space to 0..1
+func (g *Graph) normY(y float64) float64 {
+	return (y - g.minY) / (g.maxY - g.minY)
+}
+
+// graphLabel returns the short label of an outlier: the model ID without its provider
+func graphLabel(model catalog.Model) string {
+	if idx := strings.Index(model.ID, "/"); idx >= 0 {
+		return model.ID[idx+1:]
+	}
+	return model.ID
+}
+
+// brailleDots are the bits of the 2x4 dots of a braille character, by [column][row]
+var brailleDots = [2][4]rune{{0x01, 0x02, 0x04, 0x40}, {0x08, 0x10, 0x20, 0x80}}
+
+// Render draws the plot with braille characters in width by height cells,
+// labeling outliers where their names fit
+func (g *Graph) Render(width, height int) string {
+	yTicks := []string{
+		g.Y.format(g.Y.unscale(g.maxY)),
+		g.Y.format(g.Y.unscale((g.minY + g.maxY) / 2)),
+		g.Y.format(g.Y.unscale(g.minY)),
+	}
+	tickWidth := 0
+	for _, tick := range yTicks {
+		tickWidth = max(tickWidth, utf8.RuneCountInString(tick))
+	}
+	plotWidth := max(width-tickWidth-2, 10)
+
+	dots := make([][]rune, height)
+	text := make([][]rune, height)
+	for row := range dots {
+		dots[row] = make([]rune, plotWidth)
+		text[row] = make([]rune, plotWidth)
+	}
+	cellOf := func(p GraphPoint) (int, int, int, int) {
+		dx := min(int(g.normX(p.X)*float64(plotWidth*2-1)+0.5), plotWidth*2-1)
+		dy := min(int((1-g.normY(p.Y))*float64(height*4-1)+0.5), height*4-1)
+		return dx / 2, dy / 4, dx % 2, dy % 4
+	}
+	for _, p := range g.Points {
+		col, row, dc, dr := cellOf(p)
+		dots[row][col] |= brailleDots[dc][dr]
+	}
+
+	// Place labels right of their point, or left if there is no room
+	free := func(row, from, to int) bool {
+		if from < 0 || to > plotWidth {
+			return false
+		}
+		for c := from; c < to; c++ {
+			if dots[row][c] != 0 || text[row][c] != 0 {
+				return false
+			}
+		}
+		return true
+	}
+	for _, p := range g.Points {
+		if !p.Outlier {
+			continue
+		}
+		col, row, _, _ := cellOf(p)
+		label := []rune(graphLabel(p.Model))
+		start := col + 2
+		if !free(row, col+1, start+len(label)) {
+			start = col - 1 - len(label)
+			if !free(row, start, col) {
+				continue
+			}
+		}
+		copy(text[row][start:], label)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n", g.Y.Title())
+	for row := 0; row < height; row++ {
+		tick := ""
+		switch row {
+		case 0:
+			tick = yTicks[0]
+		case height / 2:
+			tick = yTicks[1]
+		case height - 1:
+			tick = yTicks[2]
+		}
+		axis := "│"
+		if tick != "" {
+			axis = "┤"
+		}
+		fmt.Fprintf(&b, "%*s %s", tickWidth, tick, axis)
+		line := make([]rune, plotWidth)
+		for c := range line {
+			switch {
+			case text[row][c] != 0:
+				line[c] = text[row][c]
+			case dots[row][c] != 0:
+				line[c] = 0x2800 + dots[row][c]
+			default:
+				line[c] = ' '
+			}
+		}
+		b.WriteString(strings.TrimRight(string(line), " ") + "\n")
+	}
+	fmt.Fprintf(&b, "%*s └%s\n", tickWidth, "", strings.Repeat("─", plotWidth))
+
+	// X ticks at the left, middle, and right
+	left := g.X.format(g.X.unscale(g.minX))
+	middle := g.X.format(g.X.unscale((g.minX + g.maxX) / 2))
+	right := g.X.format(g.X.unscale(g.maxX))
+	ticks := []rune(strings.Repeat(" ", plotWidth))
+	place := func(s string, at int) {
+		r := []rune(s)
+		at = max(0, min(at, plotWidth-len(r)))
+		copy(ticks[at:], r)
+	}
+	place(left, 0)
+	place(middle, plotWidth/2-utf8.RuneCountInString(middle)/2)
+	place(right, plotWidth)
+	fmt.Fprintf(&b, "%*s  %s\n", tickWidth, "", strings.TrimRight(string(ticks), " "))
+	title := g.X.Title()
+	fmt.Fprintf(&b, "%*s  %*s\n", tickWidth, "", (plotWidth+len(title))/2, title)
+	return b.String()
+}
+
+// SVG layout of llmls graph --svg, in pixels
+const (
+	svgWidth  = 800
+	svgHeight = 500
+	svgMargin = 70
+	svgTicks  = 5
+)
+
+// SVG renders the plot as a standalone SVG image; each point has a tooltip
+// with its ID and values, and outliers are labeled
+func (g *Graph) SVG() []byte {
+	plotW := float64(svgWidth - 2*svgMargin)
+	plotH := float64(svgHeight - 2*svgMargin)
+	px := func(x float64) float64 { return svgMargin + g.normX(x)*plotW }
+	py := func(y float64) float64 { return svgMargin + (1-g.normY(y))*plotH }
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="11">`+"\n",
+		svgWidth, svgHeight, svgWidth, svgHeight)
+	fmt.Fprintf(&b, `<title>llmls: %s vs %s, %d models, %s</title>`+"\n",
+		html.EscapeString(g.Y.Name), html.EscapeString(g.X.Name), len(g.Points), time.Now().Format(time.DateOnly))
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="white"/>`+"\n")
+
+	// Axes, grid lines, and ticks
+	fmt.Fprintf(&b, `<g stroke="#999">`+"\n")
+	fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d"/>`+"\n", svgMargin, svgHeight-svgMargin, svgWidth-svgMargin, svgHeight-svgMargin)
+	fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d"/>`+"\n", svgMargin, svgMargin, svgMargin, svgHeight-svgMargin)
+	fmt.Fprintf(&b, "</g>\n")
+	for i := 0; i < svgTicks; i++ {
+		f := float64(i) / (svgTicks - 1)
+		x := g.minX + f*(g.maxX-g.minX)
+		y := g.minY + f*(g.maxY-g.minY)
+		fmt.Fprintf(&b, `<line x1="%.1f" y1="%d" x2="%.1f" y2="%d" stroke="#eee"/>`+"\n", px(x), svgMargin, px(x), svgHeight-svgMargin)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d" text-anchor="middle">%s</text>`+"\n", px(x), svgHeight-svgMargin+16, html.EscapeString(g.X.format(g.X.unscale(x))))
+		fmt.Fprintf(&b, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="#eee"/>`+"\n", svgMargin, py(y), svgWidth-svgMargin, py(y))
+		fmt.Fprintf(&b, `<text x="%d" y="%.1f" text-anchor="end" dominant-baseline="middle">%s</text>`+"\n", svgMargin-6, py(y), html.EscapeString(g.Y.format(g.Y.unscale(y))))
+	}
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="middle" font-size="13">%s</text>`+"\n", svgWidth/2, svgHeight-svgMargin/3, html.EscapeString(g.X.Title()))
+	fmt.Fprintf(&b, `<text transform="translate(%d %d) rotate(-90)" text-anchor="middle" font-size="13">%s</text>`+"\n", svgMargin/3, svgHeight/2, html.EscapeString(g.Y.Title()))
+
+	// Points, then labels on top
+	for _, p := range g.Points {
+		tooltip := fmt.Sprintf("%s: %s %s, %s %s", p.Model.ID,
+			g.X.Name, g.X.format(g.X.unscale(p.X)), g.Y.Name, g.Y.format(g.Y.unscale(p.Y)))
+		fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="3.5" fill="#3366cc" fill-opacity="0.7"><title>%s</title></circle>`+"\n",
+			px(p.X), py(p.Y), html.EscapeString(tooltip))
+	}
+	for _, p := range g.Points {
+		if p.Outlier {
+			fmt.Fprintf(&b, `<text x="%.1f" y="%.1f">%s</text>`+"\n", px(p.X)+6, py(p.Y)-6, html.EscapeString(graphLabel(p.Model)))
+		}
+	}
+	b.WriteString("</svg>\n")
+	return b.Bytes()
+}
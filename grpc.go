@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	"github.com/mkyutani/llmls/catalog"
+	llmlsv1 "github.com/mkyutani/llmls/proto/llmls/v1"
+)
+
+// grpcRoutes maps each RPC of the Catalog service to the HTTP route it
+// mirrors, whose rate limit and token requirement it shares
+var grpcRoutes = map[string]string{
+	llmlsv1.Catalog_GetHealth_FullMethodName:        "/healthz",
+	llmlsv1.Catalog_ListModels_FullMethodName:       modelsEndpoint,
+	llmlsv1.Catalog_GetCachedCatalog_FullMethodName: cacheEndpoint,
+}
+
+// grpcCatalogServer implements the Catalog service of proto/llmls/v1 with
+// the catalog client and cache of the HTTP routes
+type grpcCatalogServer struct {
+	llmlsv1.UnimplementedCatalogServer
+}
+
+// NewGRPCServer returns the gRPC server of llmls serve, checking the bearer
+// token and rate limits of opts as the HTTP routes do
+// Server reflection is on, so tools such as grpcurl need no copy of the .proto
+func NewGRPCServer(opts ServeOptions) *grpc.Server {
+	limiter := NewScheduler(opts.RateLimits)
+	server := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := checkGRPCRequest(ctx, info.FullMethod, opts, limiter); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}))
+	llmlsv1.RegisterCatalogServer(server, &grpcCatalogServer{})
+	reflection.Register(server)
+	return server
+}
+
+// checkGRPCRequest checks a call against the token and the rate limit of the
+// route its RPC mirrors; the token is sent as "authorization: Bearer TOKEN"
+// metadata
+func checkGRPCRequest(ctx context.Context, method string, opts ServeOptions, limiter *Scheduler) error {
+	path := grpcRoutes[method]
+	public := false
+	for _, route := range serveRoutes() {
+		if route.Path == path {
+			public = route.Public
+		}
+	}
+	if opts.Token != "" && !public {
+		md, _ := metadata.FromIncomingContext(ctx)
+		var given string
+		if values := md.Get("authorization"); len(values) > 0 {
+			given, _ = strings.CutPrefix(values[0], "Bearer ")
+		}
+		if subtle.ConstantTimeCompare([]byte(given), []byte(opts.Token)) != 1 {
+			return status.Error(codes.Unauthenticated, "unauthorized")
+		}
+	}
+	client := ""
+	if p, ok := peer.FromContext(ctx); ok {
+		client = p.Addr.String()
+		if host, _, err := net.SplitHostPort(client); err == nil {
+			client = host
+		}
+	}
+	if wait := limiter.Allow(path, client); wait > 0 {
+		return status.Errorf(codes.ResourceExhausted, "rate limit exceeded (retry after %ds)", int(math.Ceil(wait.Seconds())))
+	}
+	return nil
+}
+
+// ServeGRPC runs the gRPC server of llmls serve on addr until it fails
+func ServeGRPC(addr string, opts ServeOptions) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return NewGRPCServer(opts).Serve(listener)
+}
+
+// GetHealth reports that the server is up
+func (s *grpcCatalogServer) GetHealth(ctx context.Context, req *llmlsv1.GetHealthRequest) (*llmlsv1.GetHealthResponse, error) {
+	return &llmlsv1.GetHealthResponse{Status: "ok"}, nil
+}
+
+// ListModels lists the models matching the request, as GET /v1/models does
+func (s *grpcCatalogServer) ListModels(ctx context.Context, req *llmlsv1.ListModelsRequest) (*llmlsv1.ListModelsResponse, error) {
+	if req.Limit < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid limit: %d", req.Limit)
+	}
+	models, err := serveClient().Models(catalog.Query{
+		Pattern: req.Pattern,
+		Where:   req.Where,
+		Series:  req.Series,
+		Type:    req.Type,
+		Variant: req.Variant,
+		Sort:    req.Sort,
+		Limit:   int(req.Limit),
+	})
+	var invalid *catalog.InvalidQueryError
+	switch {
+	case errors.As(err, &invalid):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	return &llmlsv1.ListModelsResponse{Models: grpcModels(models)}, nil
+}
+
+// GetCachedCatalog returns a vendor catalog from the server's cache, as
+// GET /v1/cache does
+func (s *grpcCatalogServer) GetCachedCatalog(ctx context.Context, req *llmlsv1.GetCachedCatalogRequest) (*llmlsv1.GetCachedCatalogResponse, error) {
+	if !catalog.IsCacheableURL(req.Url) {
+		return nil, status.Errorf(codes.InvalidArgument, "not a cacheable catalog URL: %s", req.Url)
+	}
+	body, state, err := ServeCached(req.Url)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	var response struct {
+		Data []catalog.Model `json:"data"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to parse cached catalog: %v", err)
+	}
+	return &llmlsv1.GetCachedCatalogResponse{Data: grpcModels(response.Data), Cache: state}, nil
+}
+
+// grpcModels converts catalog models to their messages
+func grpcModels(models []catalog.Model) []*llmlsv1.Model {
+	messages := make([]*llmlsv1.Model, 0, len(models))
+	for _, model := range models {
+		messages = append(messages, grpcModel(model))
+	}
+	return messages
+}
+
+// grpcModel converts a catalog model to its message; extra fields are
+// JSON-encoded
+func grpcModel(model catalog.Model) *llmlsv1.Model {
+	m := &llmlsv1.Model{
+		Id:            model.ID,
+		Name:          model.Name,
+		Created:       model.Created,
+		Description:   model.Description,
+		ContextLength: int64(model.ContextLength),
+		Architecture: &llmlsv1.Architecture{
+			Modality:         model.Architecture.Modality,
+			InputModalities:  model.Architecture.InputModalities,
+			OutputModalities: model.Architecture.OutputModalities,
+			Tokenizer:        model.Architecture.Tokenizer,
+		},
+		Pricing: grpcPricing(model.Pricing),
+		TopProvider: &llmlsv1.TopProvider{
+			ContextLength:       int64(model.TopProvider.ContextLength),
+			MaxCompletionTokens: int64(model.TopProvider.MaxCompletionTokens),
+			IsModerated:         model.TopProvider.IsModerated,
+		},
+		ExpirationDate: model.ExpirationDate,
+		Type:           model.Type,
+		Url:            model.URL,
+	}
+	if model.HFStats != nil {
+		m.HfStats = &llmlsv1.HFStats{
+			Repo:          model.HFStats.Repo,
+			Downloads:     int64(model.HFStats.Downloads),
+			Likes:         int64(model.HFStats.Likes),
+			TrendingScore: model.HFStats.TrendingScore,
+		}
+	}
+	for _, source := range model.Sources {
+		m.Sources = append(m.Sources, &llmlsv1.ModelSource{
+			Id:      source.ID,
+			Source:  source.Source,
+			Pricing: grpcPricing(source.Pricing),
+		})
+	}
+	if len(model.Extra) > 0 {
+		m.Extra = make(map[string]string, len(model.Extra))
+		for key, value := range model.Extra {
+			data, err := json.Marshal(value)
+			if err != nil {
+				data = []byte(fmt.Sprint(value))
+			}
+			m.Extra[key] = string(data)
+		}
+	}
+	return m
+}
+
+// grpcPricing converts catalog prices to their message
+func grpcPricing(p catalog.Pricing) *llmlsv1.Pricing {
+	return &llmlsv1.Pricing{
+		Prompt:            p.Prompt,
+		Completion:        p.Completion,
+		Request:           p.Request,
+		Image:             p.Image,
+		WebSearch:         p.WebSearch,
+		InternalReasoning: p.InternalReasoning,
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	llmlsv1 "github.com/mkyutani/llmls/proto/llmls/v1"
+)
+
+// grpcTestClient serves the Catalog service with opts on a local port and
+// returns a client of it
+func grpcTestClient(t *testing.T, opts ServeOptions) llmlsv1.CatalogClient {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := NewGRPCServer(opts)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return llmlsv1.NewCatalogClient(conn)
+}
+
+func TestGRPCAuth(t *testing.T) {
+	client := grpcTestClient(t, ServeOptions{Token: "secret"})
+	ctx := context.Background()
+
+	if resp, err := client.GetHealth(ctx, &llmlsv1.GetHealthRequest{}); err != nil || resp.Status != "ok" {
+		t.Errorf("GetHealth without a token = %v, %v; want ok", resp, err)
+	}
+	tests := []struct {
+		auth string
+		code codes.Code
+	}{
+		{"", codes.Unauthenticated},
+		{"Bearer wrong", codes.Unauthenticated},
+		{"Bearer secret", codes.InvalidArgument}, // Past authentication to the URL check
+	}
+	for _, tt := range tests {
+		ctx := ctx
+		if tt.auth != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "authorization", tt.auth)
+		}
+		_, err := client.GetCachedCatalog(ctx, &llmlsv1.GetCachedCatalogRequest{Url: "https://example.com/models"})
+		if got := status.Code(err); got != tt.code {
+			t.Errorf("GetCachedCatalog with %q = %v, want %v", tt.auth, got, tt.code)
+		}
+	}
+}
+
+func TestGRPCRateLimit(t *testing.T) {
+	client := grpcTestClient(t, ServeOptions{RateLimits: map[string]float64{"/healthz": 6}}) // A burst of one
+	ctx := context.Background()
+
+	if _, err := client.GetHealth(ctx, &llmlsv1.GetHealthRequest{}); err != nil {
+		t.Fatalf("first GetHealth: %v", err)
+	}
+	if _, err := client.GetHealth(ctx, &llmlsv1.GetHealthRequest{}); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("second GetHealth = %v, want %v", err, codes.ResourceExhausted)
+	}
+	if _, err := client.ListModels(ctx, &llmlsv1.ListModelsRequest{Limit: -1}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("ListModels on an unlimited route = %v, want %v", err, codes.InvalidArgument)
+	}
+}
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// HelpOption is a flag as the help documents it
+type HelpOption struct {
+	Flags string // e.g. "-w, --wide" or "--supports LIST"
+	Help  string // A newline continues it on an indented line
+	Name  string // Long name of the flag, e.g. wide
+}
+
+// HelpTopic is a page of llmls help <topic>
+type HelpTopic struct {
+	Name    string
+	Summary string
+	Text    string   // Followed by the options of the topic
+	Flags   []string // Listing and global flags the topic lists
+}
+
+// EnvVar is an environment variable llmls reads
+type EnvVar struct {
+	Name string
+	Help string
+}
+
+// FlagRegisterer declares flags on a FlagSet, as SourceConfig does
+type FlagRegisterer interface {
+	RegisterFlags(fs *flag.FlagSet)
+}
+
+// NewFlagSet returns a FlagSet with the flags of flags, which may be nil
+func NewFlagSet(name string, flags FlagRegisterer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	if flags != nil {
+		flags.RegisterFlags(fs)
+	}
+	return fs
+}
+
+// isZeroDefault reports whether a flag default is the zero value of its type,
+// which the help does not show
+func isZeroDefault(value string) bool {
+	switch value {
+	case "", "false", "0", "0s":
+		return true
+	}
+	return false
+}
+
+// formatDefault shortens a duration default, e.g. 1h0m0s to 1h
+func formatDefault(value string) string {
+	if _, err := time.ParseDuration(value); err == nil && strings.HasSuffix(value, "0s") && value != "0s" {
+		value = strings.TrimSuffix(value, "0s")
+		value = strings.TrimSuffix(value, "0m")
+	}
+	return value
+}
+
+// FlagOptions returns the help of the flags of fs, ordered by name
+// The usage of a flag is its help: a word in backquotes names its argument,
+// a newline continues it on an indented line, and a default other than the
+// zero value is added unless the usage gives one; flags sharing a variable
+// are listed together, e.g. "-w, --wide"
+func FlagOptions(fs *flag.FlagSet) []HelpOption {
+	names := make(map[flag.Value][]string)
+	var flags []*flag.Flag
+	fs.VisitAll(func(f *flag.Flag) {
+		if names[f.Value] == nil {
+			flags = append(flags, f)
+		}
+		names[f.Value] = append(names[f.Value], f.Name)
+	})
+
+	var options []HelpOption
+	for _, f := range flags {
+		aliases := names[f.Value]
+		sort.Slice(aliases, func(i, j int) bool {
+			if len(aliases[i]) != len(aliases[j]) {
+				return len(aliases[i]) < len(aliases[j])
+			}
+			return aliases[i] < aliases[j]
+		})
+		spelled := make([]string, len(aliases))
+		for i, name := range aliases {
+			if len(name) == 1 {
+				spelled[i] = "-" + name
+			} else {
+				spelled[i] = "--" + name
+			}
+		}
+
+		option := HelpOption{Flags: strings.Join(spelled, ", "), Name: aliases[len(aliases)-1]}
+		arg, usage := flag.UnquoteUsage(f)
+		if strings.Contains(f.Usage, "`") {
+			option.Flags += " " + arg
+		}
+		if !isZeroDefault(f.DefValue) && !strings.Contains(usage, "(default") {
+			usage += " (default: " + formatDefault(f.DefValue) + ")"
+		}
+		option.Help = usage
+		options = append(options, option)
+	}
+	sort.SliceStable(options, func(i, j int) bool { return options[i].Name < options[j].Name })
+	return options
+}
+
+// ListingFlagSet returns the FlagSet of the listing, for its help
+func ListingFlagSet() *flag.FlagSet {
+	return NewFlagSet("llmls", new(listingFlags))
+}
+
+// GlobalFlagSet returns the FlagSet of the flags accepted by every subcommand
+func GlobalFlagSet() *flag.FlagSet {
+	return NewFlagSet("llmls", new(globalFlags))
+}
+
+// GlobalOptions returns the help of the global flags, followed by --help and
+// --version, which main handles before any subcommand
+func GlobalOptions() []HelpOption {
+	return append(FlagOptions(GlobalFlagSet()),
+		HelpOption{"-h, --help", "Show this help message", "help"},
+		HelpOption{"-v, --version", "Show version information", "version"},
+	)
+}
+
+// HelpTopics returns the help topics in help order
+func HelpTopics() []HelpTopic {
+	return []HelpTopic{
+		{"patterns", "How the pattern selects models", `llmls [options] [pattern] lists the models matching the pattern, or all
+models without one. A model matches when the pattern matches, in order:
+
+  1. its ID, e.g. anthropic/claude-opus-4.5 or ollama/llama3.1:8b
+  2. its name, e.g. "Anthropic: Claude Opus 4.5"
+  3. its provider exactly, ignoring case; aliases such as meta for meta-llama,
+     mistral for mistralai, or google-vertex for google are accepted
+
+Patterns are globs: * matches any sequence of characters and ? any single
+character. Without a wildcard, an ID or name must match in full, so use
+"*opus*" for IDs containing opus. Quote patterns so the shell does not expand
+them, or disable globbing for llmls in your shell (see the README).
+
+Local models are listed under their source: ollama/*, llamacpp/*, tgi/*.
+When nothing matches, llmls suggests close model IDs, and a subcommand when
+the pattern looks like a mistyped one.
+
+Examples:
+  llmls "anthropic/*"          Models with IDs starting with anthropic/
+  llmls "*gpt-4?-mini*"        A single character wildcard
+  llmls meta --explain         Provider alias; show which criterion matched
+`, []string{"explain"}},
+		{"filters", "Listing flags that narrow the models", `Filters narrow the models the pattern selects; combined filters must all
+match. Filters needing data a source does not report, such as supported
+parameters of local servers, drop the models of that source.
+
+--where is the most general filter: an expression over the fields of the
+JSON output with ==, !=, <, <=, >, >=, in, contains, &&, || and !. --added,
+--removed, and --changed compare with the latest snapshot (llmls snapshot),
+--new with your last run, and --as-of lists a saved snapshot instead of the
+catalog.
+
+Examples:
+  llmls --type chat --language ja --supports tools
+  llmls --where 'context_length >= 128000 && pricing.prompt < 0.000003'
+  llmls --open-weights-only --instruct "meta-llama/*"
+`, []string{"series", "type", "language", "instruct", "base", "variant", "category", "require-params", "open-weights-only", "unusual-defaults", "supports", "min-uptime", "where", "added", "removed", "changed", "since-run", "as-of"}},
+		{"sources", "Where llmls gets models from", `llmls merges the models of several sources:
+
+  openrouter  The OpenRouter catalog, cached for 10 minutes (LLMLS_CACHE_TTL,
+              e.g. 1h, or 0 to disable; LLMLS_CACHE_URL shares a cache
+              served by llmls serve)
+  ollama      An Ollama server, listed as ollama/<model>
+  llamacpp    A llama.cpp or KoboldCpp server, listed as llamacpp/<model>
+  tgi         Text Generation Inference servers, listed as tgi/<model>
+  remote      Shared llmls catalogs, e.g. curated by an organization
+  replicate   Replicate image and speech models, with REPLICATE_API_TOKEN,
+              only for --type image, audio, tts, or stt
+
+Local servers that are not running are skipped, and are always queried live.
+Their flags also accept ssh://user@host URLs, for which llmls opens an SSH
+port-forward with the system ssh client. llmls status shows the reachability
+and model count of each source, and llmls discover finds local servers on
+the network. Subcommands that fetch the catalog accept the same flags.
+`, []string{"ollama-host", "llamacpp-host", "tgi-host", "remote", "strict-schema"}},
+		{"config", "The config file, profiles, and environment variables", `The config file, ~/.config/llmls/config or $LLMLS_CONFIG, sets the default
+invocation with "key = value" lines; # starts a comment:
+
+  args = --show-price --sort price     Listing flags before the command line's
+  pattern = anthropic/*                Pattern used when none is given
+  env OLLAMA_HOST = http://gpu:11434   Variable set unless already set
+  column score = ctx/1000 - price*2    Computed column, a Starlark expression
+  profile = work                       Profile used without --profile
+
+Columns are shown with --columns and usable in --where and --sort; they read
+the fields of --where and the columns above them.
+
+Profiles follow under [name] headers. Their args are added after the top
+ones, their pattern and env entries replace the top ones, and their columns
+are added. --profile, else $LLMLS_PROFILE, else the profile setting selects
+one.
+
+Every flag can also be set with an environment variable: LLMLS_<FLAG>, e.g.
+LLMLS_SORT=price, or LLMLS_<SUBCOMMAND>_<FLAG>, e.g. LLMLS_ENDPOINTS_SORT,
+which wins over LLMLS_<FLAG>. The command line overrides the environment,
+which overrides the config file.
+`, []string{"profile"}},
+	}
+}
+
+// EnvVars returns the environment variables llmls reads, apart from those
+// setting flags (see flagEnvNames)
+func EnvVars() []EnvVar {
+	return []EnvVar{
+		{"OPENROUTER_API_KEY", "OpenRouter API key for ask, test, duel, and --translate via OpenRouter"},
+		{"OLLAMA_HOST", "Ollama server URL (default: http://localhost:11434)"},
+		{"LLAMACPP_HOST", "llama.cpp/KoboldCpp server URL (default: http://localhost:8080)"},
+		{"TGI_HOST", "Comma-separated TGI server URLs"},
+		{"LLMLS_REMOTE", "Comma-separated shared llmls catalog URLs (LLMLS_REMOTE_TOKEN: bearer token)"},
+		{"REPLICATE_API_TOKEN", "Replicate API token, adding its image and speech models"},
+		{"LLMLS_CONFIG", "Config file (default: ~/.config/llmls/config)"},
+		{"LLMLS_PROFILE", "Config file profile used without --profile"},
+		{"LLMLS_CACHE_TTL", "Catalog cache lifetime, e.g. 1h, or 0 to disable (default: 10m)"},
+		{"LLMLS_CACHE_URL", "Shared catalog cache served by llmls serve (LLMLS_CACHE_TOKEN: bearer token)"},
+		{"LLMLS_SNAPSHOT_DIR", "Directory of saved snapshots"},
+		{"LLMLS_CATALOG_DB", "SQLite database of llmls query (default: ~/.cache/llmls/catalog.db)"},
+		{"LLMLS_ENRICH_DIR", "Enrichment plugin directory (default: ~/.config/llmls/enrich.d)"},
+		{"LLMLS_LOCALE", "Locale of dates, numbers, and prices, e.g. ja or de_DE"},
+		{"LLMLS_TRANSLATE_MODEL", "Model for --translate (default: " + DefaultTranslateModel + ")"},
+		{"LLMLS_RPM", "Requests per minute per API, e.g. openrouter=20,anthropic=10"},
+		{"LLMLS_DEBUG", "Set to 1 to show schema differences of provider responses"},
+		{"NO_COLOR", "Disable color unless --color always is given"},
+	}
+}
+
+// FindHelpTopic returns the help topic of a name
+func FindHelpTopic(name string) (HelpTopic, bool) {
+	for _, topic := range HelpTopics() {
+		if topic.Name == name {
+			return topic, true
+		}
+	}
+	return HelpTopic{}, false
+}
+
+// TopicOptions returns the listing and global flags a help topic lists
+func TopicOptions(topic HelpTopic) []HelpOption {
+	listed := make(map[string]bool)
+	for _, name := range topic.Flags {
+		listed[name] = true
+	}
+	var options []HelpOption
+	for _, fs := range []*flag.FlagSet{ListingFlagSet(), GlobalFlagSet()} {
+		for _, option := range FlagOptions(fs) {
+			if listed[option.Name] {
+				options = append(options, option)
+			}
+		}
+	}
+	return options
+}
+
+// displayOptions prints options as in the help: flags longer than the flag
+// column get a line of their own
+func displayOptions(w io.Writer, options []HelpOption) {
+	for _, option := range options {
+		lines := strings.Split(option.Help, "\n")
+		if len(option.Flags) > 16 {
+			fmt.Fprintf(w, "  %s\n", option.Flags)
+		} else {
+			fmt.Fprintf(w, "  %-16s %s\n", option.Flags, lines[0])
+			lines = lines[1:]
+		}
+		for _, line := range lines {
+			fmt.Fprintf(w, "  %-16s %s\n", "", line)
+		}
+	}
+}
+
+// DisplayHelpTopic prints a help topic page
+func DisplayHelpTopic(w io.Writer, topic HelpTopic) {
+	fmt.Fprint(w, topic.Text)
+	if options := TopicOptions(topic); len(options) > 0 {
+		fmt.Fprintf(w, "\nOptions:\n")
+		displayOptions(w, options)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testHelpFlags declares flags of every form FlagOptions documents
+type testHelpFlags struct {
+	wide     bool
+	output   string
+	interval time.Duration
+	limit    int
+	color    string
+}
+
+func (f *testHelpFlags) RegisterFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&f.wide, "wide", false, "Do not truncate output")
+	fs.BoolVar(&f.wide, "w", false, "Do not truncate output")
+	fs.StringVar(&f.output, "output", "", "Write to `FILE`\ninstead of stdout")
+	fs.DurationVar(&f.interval, "interval", time.Hour, "Time between checks")
+	fs.IntVar(&f.limit, "limit", 0, "Maximum number of results")
+	fs.StringVar(&f.color, "color", "auto", "Colorize output (default: auto unless NO_COLOR)")
+}
+
+func TestFlagOptions(t *testing.T) {
+	got := FlagOptions(NewFlagSet("test", new(testHelpFlags)))
+	want := []HelpOption{
+		{Flags: "--color", Help: "Colorize output (default: auto unless NO_COLOR)", Name: "color"},
+		{Flags: "--interval", Help: "Time between checks (default: 1h)", Name: "interval"},
+		{Flags: "--limit", Help: "Maximum number of results", Name: "limit"},
+		{Flags: "--output FILE", Help: "Write to FILE\ninstead of stdout", Name: "output"},
+		{Flags: "-w, --wide", Help: "Do not truncate output", Name: "wide"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FlagOptions() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestCommandFlags(t *testing.T) {
+	for _, command := range Commands() {
+		for _, c := range append([]Command{command}, command.Actions...) {
+			if c.Usage == "" || c.Run == nil {
+				t.Errorf("%s: missing usage or run function", c.Name)
+			}
+			// Registering panics on a flag declared twice
+			fs := NewFlagSet(c.Name, c.Flags)
+			for _, option := range FlagOptions(fs) {
+				if option.Help == "" {
+					t.Errorf("%s: --%s has no help", c.Name, option.Name)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,8 @@
+package main
+
+import "github.com/mkyutani/llmls/catalog"
+
+// FormatHFStats renders counts as e.g. "1.2M downloads · 3,456 likes"
+func FormatHFStats(stats catalog.HFStats) string {
+	return FormatContextLength(stats.Downloads) + " downloads · " + FormatNumber(stats.Likes) + " likes"
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// ParseAsOf parses --as-of: a date (the end of that day in local time) or an
+// RFC 3339 time
+func ParseAsOf(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	day, err := time.ParseInLocation(time.DateOnly, s, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --as-of: %s (expected YYYY-MM-DD or an RFC 3339 time)", s)
+	}
+	return day.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+}
+
+// SnapshotAsOf loads the latest snapshot taken at or before t, which is the
+// catalog as llmls last saw it on that date
+func SnapshotAsOf(t time.Time) (*Snapshot, error) {
+	paths, err := ListSnapshotFiles()
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no snapshots found (run 'llmls snapshot' or 'llmls daemon' to record history)")
+	}
+
+	var found *Snapshot
+	for _, path := range paths {
+		snapshot, err := LoadSnapshot(path)
+		if err != nil {
+			return nil, err
+		}
+		if snapshot.TakenAt.After(t) {
+			if found == nil {
+				return nil, fmt.Errorf("no snapshot on or before %s; the oldest is from %s",
+					t.Local().Format(time.DateOnly), snapshot.TakenAt.Local().Format("2006-01-02 15:04"))
+			}
+			break
+		}
+		found = snapshot
+	}
+	return found, nil
+}
+
+// History event kinds of a model timeline
+const (
+	HistoryAdded   = "added"
+	HistoryChanged = "changed"
+	HistoryRemoved = "removed"
+)
+
+// HistoryEvent is a change to a model between two consecutive snapshots
+type HistoryEvent struct {
+	At     time.Time // Time of the snapshot that first showed the change
+	Kind   string
+	Detail string
+}
+
+// ModelHistory returns the timeline of a model across all snapshots: when it
+// appeared, each change to its name, context, pricing, or expiration, and
+// when it disappeared (and reappeared)
+func ModelHistory(id string) ([]HistoryEvent, error) {
+	paths, err := ListSnapshotFiles()
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no snapshots found (run 'llmls snapshot' or 'llmls daemon' to record history)")
+	}
+
+	var events []HistoryEvent
+	var prev *catalog.Model
+	seen := false
+	for _, path := range paths {
+		snapshot, err := LoadSnapshot(path)
+		if err != nil {
+			return nil, err
+		}
+		var current *catalog.Model
+		for i := range snapshot.Models {
+			if snapshot.Models[i].ID == id {
+				current = &snapshot.Models[i]
+				break
+			}
+		}
+
+		switch {
+		case current != nil && prev == nil:
+			events = append(events, HistoryEvent{At: snapshot.TakenAt, Kind: HistoryAdded, Detail: FormatModelSummary(*current)})
+			seen = true
+		case current == nil && prev != nil:
+			events = append(events, HistoryEvent{At: snapshot.TakenAt, Kind: HistoryRemoved})
+		case current != nil:
+			if changes := modelChanges(*prev, *current); len(changes) > 0 {
+				events = append(events, HistoryEvent{At: snapshot.TakenAt, Kind: HistoryChanged, Detail: strings.Join(changes, "; ")})
+			}
+		}
+		prev = current
+	}
+	if !seen {
+		return nil, fmt.Errorf("%s is not in any of the %d snapshots", id, len(paths))
+	}
+	return events, nil
+}
+
+// modelChanges describes the compared attributes that differ between two
+// versions of a model
+func modelChanges(old, new catalog.Model) []string {
+	var changes []string
+	if old.Name != new.Name {
+		changes = append(changes, fmt.Sprintf("name %q -> %q", old.Name, new.Name))
+	}
+	if old.ContextLength != new.ContextLength {
+		changes = append(changes, fmt.Sprintf("context %s -> %s", FormatNumber(old.ContextLength), FormatNumber(new.ContextLength)))
+	}
+	if old.Pricing.Prompt != new.Pricing.Prompt || old.Pricing.Completion != new.Pricing.Completion {
+		changes = append(changes, fmt.Sprintf("price %s -> %s /1K", FormatModelPrice(old), FormatModelPrice(new)))
+	}
+	if old.Architecture.Modality != new.Architecture.Modality {
+		changes = append(changes, fmt.Sprintf("modality %s -> %s", old.Architecture.Modality, new.Architecture.Modality))
+	}
+	if old.ExpirationDate != new.ExpirationDate {
+		changes = append(changes, fmt.Sprintf("expiration %q -> %q", old.ExpirationDate, new.ExpirationDate))
+	}
+	if len(changes) == 0 && attributesOf(old) != attributesOf(new) {
+		changes = append(changes, "provider limits changed")
+	}
+	return changes
+}
+
+// DisplayModelHistory prints a timeline, one event per line
+func DisplayModelHistory(events []HistoryEvent) {
+	for _, e := range events {
+		line := fmt.Sprintf("%s  %-8s", e.At.Local().Format("2006-01-02 15:04"), e.Kind)
+		if e.Detail != "" {
+			line += " " + e.Detail
+		}
+		fmt.Println(strings.TrimRight(line, " "))
+	}
+}
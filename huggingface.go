@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// ggufQuantPattern extracts a quantization tag from a GGUF file name
+var ggufQuantPattern = regexp.MustCompile(`(?i)(IQ\d_[A-Z]+(?:_[A-Z]+)?|Q\d_K(?:_[SML])?|Q\d_\d|BF16|F16|F32)`)
+
+// letterDigit splits "llama3.1" into "llama-3.1" so it matches Hugging Face repo names
+var letterDigit = regexp.MustCompile(`([a-z])(\d)`)
+
+// ollamaTagSize matches the parameter size at the start of an Ollama tag such as "8b-instruct-q4_K_M"
+var ollamaTagSize = regexp.MustCompile(`^\d+(?:\.\d+)?[bm]`)
+
+// hfModel is a Hugging Face model search result
+type hfModel struct {
+	ID        string `json:"id"`
+	Downloads int    `json:"downloads"`
+}
+
+// hfTreeEntry is a file in a Hugging Face repo
+type hfTreeEntry struct {
+	Type string `json:"type"`
+	Path string `json:"path"`
+}
+
+// ggufQuant returns the quantization tag of a GGUF file name, uppercased
+func ggufQuant(file string) string {
+	return strings.ToUpper(ggufQuantPattern.FindString(path.Base(file)))
+}
+
+// ggufSearchTerms derives a Hugging Face search query and parameter size from a local model
+func ggufSearchTerms(model catalog.Model) (query, size, installed string) {
+	switch {
+	case model.OllamaDetails != nil:
+		name := catalog.OllamaModelName(model.ID)
+		if idx := strings.LastIndex(name, "/"); idx >= 0 {
+			name = name[idx+1:]
+		}
+		name, tag, _ := strings.Cut(name, ":")
+		query = letterDigit.ReplaceAllString(strings.ToLower(name), "$1-$2")
+		// Ollama reports "8.0B" where repo names say "8B"
+		size = strings.Replace(strings.ToLower(model.OllamaDetails.ParameterSize), ".0b", "b", 1)
+		if size == "" {
+			size = ollamaTagSize.FindString(strings.ToLower(tag))
+		}
+		installed = strings.ToUpper(model.OllamaDetails.QuantizationLevel)
+	case model.LlamaCppDetails != nil:
+		file := path.Base(model.LlamaCppDetails.ModelPath)
+		if file == "." || file == "/" {
+			file = model.Name
+		}
+		installed = ggufQuant(file)
+		name := strings.TrimSuffix(file, path.Ext(file))
+		if loc := ggufQuantPattern.FindStringIndex(name); loc != nil {
+			name = name[:loc[0]]
+		}
+		query = strings.Trim(strings.ToLower(name), "-._")
+	}
+	return query, size, installed
+}
+
+// getHFJSON fetches a Hugging Face API path into v
+func getHFJSON(apiPath string, v interface{}) error {
+	client := &http.Client{Timeout: 10 * time.Second}
+	if err := getJSON(client, catalog.HuggingFaceURL+apiPath, v); err != nil {
+		return fmt.Errorf("hugging face: %w", err)
+	}
+	return nil
+}
+
+// FindGGUFBuilds searches Hugging Face for GGUF repos of a local model and
+// lists the other quantizations available in the most downloaded one
+func FindGGUFBuilds(model catalog.Model) catalog.GGUFCrossRef {
+	query, size, installed := ggufSearchTerms(model)
+	ref := catalog.GGUFCrossRef{Query: query, Installed: installed}
+	if query == "" {
+		ref.Error = "no search terms"
+		return ref
+	}
+
+	var results []hfModel
+	params := url.Values{"search": {query}, "filter": {"gguf"}, "sort": {"downloads"}, "limit": {"20"}}
+	if err := getHFJSON("/api/models?"+params.Encode(), &results); err != nil {
+		ref.Error = err.Error()
+		return ref
+	}
+
+	// Prefer repos of the same parameter size, but fall back to any size
+	// since not every repo name states it
+	for _, sized := range []bool{true, false} {
+		for _, result := range results {
+			if sized && (size == "" || !strings.Contains(strings.ToLower(result.ID), size)) {
+				continue
+			}
+			ref.Repos = append(ref.Repos, result.ID)
+			if len(ref.Repos) == 3 {
+				break
+			}
+		}
+		if len(ref.Repos) > 0 {
+			break
+		}
+	}
+	if len(ref.Repos) == 0 {
+		return ref
+	}
+
+	var tree []hfTreeEntry
+	if err := getHFJSON("/api/models/"+ref.Repos[0]+"/tree/main", &tree); err != nil {
+		ref.Error = err.Error()
+		return ref
+	}
+	for _, entry := range tree {
+		if entry.Type != "file" || !strings.HasSuffix(strings.ToLower(entry.Path), ".gguf") {
+			continue
+		}
+		quant := ggufQuant(entry.Path)
+		if quant == "" || quant == installed {
+			continue
+		}
+		ref.Builds = append(ref.Builds, catalog.GGUFBuild{
+			Repo:  ref.Repos[0],
+			File:  entry.Path,
+			Quant: quant,
+			URL:   catalog.HuggingFaceURL + "/" + ref.Repos[0] + "/resolve/main/" + entry.Path,
+		})
+	}
+	sort.SliceStable(ref.Builds, func(i, j int) bool { return ref.Builds[i].Quant < ref.Builds[j].Quant })
+	return ref
+}
+
+// FillGGUFCrossRefs looks up Hugging Face GGUF builds for every Ollama and llama.cpp model
+func FillGGUFCrossRefs(models []catalog.Model) {
+	for i, model := range models {
+		if model.OllamaDetails == nil && model.LlamaCppDetails == nil {
+			continue
+		}
+		ref := FindGGUFBuilds(model)
+		models[i].GGUFCrossRef = &ref
+	}
+}
+
+// displayGGUFCrossRef prints the Hugging Face GGUF lines of the detail view
+func displayGGUFCrossRef(ref *catalog.GGUFCrossRef) {
+	switch {
+	case ref.Error != "":
+		fmt.Printf("HF GGUF:           unavailable (%s)\n", ref.Error)
+		return
+	case len(ref.Repos) == 0:
+		fmt.Printf("HF GGUF:           no GGUF repos found for %q\n", ref.Query)
+		return
+	}
+
+	fmt.Printf("HF GGUF:           %s\n", catalog.HuggingFaceURL+"/"+ref.Repos[0])
+	for _, repo := range ref.Repos[1:] {
+		fmt.Printf("                   %s\n", catalog.HuggingFaceURL+"/"+repo)
+	}
+	for _, build := range ref.Builds {
+		fmt.Printf("  %-16s %s\n", build.Quant, build.URL)
+	}
+}
+
+// getJSON fetches url and decodes a JSON body into v
+func getJSON(client *http.Client, url string, v interface{}) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, v)
+}
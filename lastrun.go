@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// LastRun records the catalog seen by the previous successful listing, for --new
+type LastRun struct {
+	RanAt   time.Time `json:"ran_at"`
+	IDs     []string  `json:"ids"`
+	Sources []string  `json:"sources,omitempty"` // Sources that answered, as in Snapshot
+}
+
+// lastRunPath returns the file recording the last listing
+func lastRunPath() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+	return filepath.Join(cacheDir, "llmls", "last-run.json"), nil
+}
+
+// LoadLastRun reads the record of the previous listing; nil if there is none
+func LoadLastRun() (*LastRun, error) {
+	path, err := lastRunPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read last run: %w", err)
+	}
+	var run LastRun
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("failed to parse last run %s: %w", path, err)
+	}
+	return &run, nil
+}
+
+// SaveLastRun records the IDs of the fetched catalog as the latest listing
+// Dry runs fetch nothing, and record and replay sessions see a catalog of
+// another time, so both leave the record unchanged
+func SaveLastRun(current *Snapshot) error {
+	if dryRun || sessionActive {
+		return nil
+	}
+	path, err := lastRunPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	run := LastRun{RanAt: time.Now().UTC(), IDs: make([]string, len(current.Models)), Sources: current.Sources}
+	for i, model := range current.Models {
+		run.IDs[i] = model.ID
+	}
+	data, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("failed to encode last run: %w", err)
+	}
+	return writeFileAtomic(path, data)
+}
+
+// NewSinceRun returns the models that were not in the catalog at the
+// previous listing; models of a source that did not answer then are not new,
+// only unknown
+func NewSinceRun(models []catalog.Model, run *LastRun) []catalog.Model {
+	seen := make(map[string]bool, len(run.IDs))
+	for _, id := range run.IDs {
+		seen[id] = true
+	}
+	previous := Snapshot{Sources: run.Sources}
+	if run.Sources == nil {
+		// Records of older versions do not list the sources
+		for _, id := range run.IDs {
+			previous.Models = append(previous.Models, catalog.Model{ID: id})
+		}
+	}
+	reached := previous.ReachedSources()
+
+	var added []catalog.Model
+	for _, model := range models {
+		if !seen[model.ID] && reached[catalog.ModelSourceName(model)] {
+			added = append(added, model)
+		}
+	}
+	return added
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Locale controls how dates, numbers, and prices are displayed
+// Machine-readable output (--field, --jq, get, query) is never localized
+type Locale struct {
+	Name      string
+	ShortDate string // Fixed-width date of the listing's date column, as a Go time layout
+	LongDate  string // Date of the detail view and summary
+	Thousands string // Thousands separator
+	Decimal   string // Decimal separator
+	USDSuffix bool   // Write "1,50 $" instead of "$1.50"
+}
+
+// cLocale keeps the formats llmls has always used
+var cLocale = Locale{Name: "C", ShortDate: "2006-01-02", LongDate: "2006-01-02", Thousands: ",", Decimal: "."}
+
+// locales are the supported locales keyed by language, or language_REGION
+// where a region differs; English uses the C formats
+// Separators are ASCII so localized values keep their width in aligned columns
+var locales = map[string]Locale{
+	"en":    cLocale,
+	"en_GB": {ShortDate: "02/01/2006", LongDate: "2 January 2006", Thousands: ",", Decimal: "."},
+	"ja":    {ShortDate: "2006/01/02", LongDate: "2006年1月2日", Thousands: ",", Decimal: "."},
+	"zh":    {ShortDate: "2006/01/02", LongDate: "2006年1月2日", Thousands: ",", Decimal: "."},
+	"ko":    {ShortDate: "2006.01.02", LongDate: "2006년 1월 2일", Thousands: ",", Decimal: "."},
+	"de":    {ShortDate: "02.01.2006", LongDate: "2.1.2006", Thousands: ".", Decimal: ",", USDSuffix: true},
+	"de_CH": {ShortDate: "02.01.2006", LongDate: "2.1.2006", Thousands: "'", Decimal: ".", USDSuffix: true},
+	"fr":    {ShortDate: "02/01/2006", LongDate: "2/1/2006", Thousands: " ", Decimal: ",", USDSuffix: true},
+	"es":    {ShortDate: "02/01/2006", LongDate: "2/1/2006", Thousands: ".", Decimal: ",", USDSuffix: true},
+	"it":    {ShortDate: "02/01/2006", LongDate: "2/1/2006", Thousands: ".", Decimal: ",", USDSuffix: true},
+	"pt":    {ShortDate: "02/01/2006", LongDate: "2/1/2006", Thousands: ".", Decimal: ",", USDSuffix: true},
+	"nl":    {ShortDate: "02-01-2006", LongDate: "2-1-2006", Thousands: ".", Decimal: ",", USDSuffix: true},
+	"ru":    {ShortDate: "02.01.2006", LongDate: "02.01.2006", Thousands: " ", Decimal: ",", USDSuffix: true},
+}
+
+// currentLocale is the locale of displayed output; set by InitLocale and --locale
+var currentLocale = cLocale
+
+// LookupLocale resolves a locale name such as ja, de_DE, or ja_JP.UTF-8
+// C, POSIX, and "" select the default formats
+func LookupLocale(name string) (Locale, error) {
+	// Drop the encoding and modifier: de_DE.UTF-8@euro -> de_DE
+	base, _, _ := strings.Cut(name, ".")
+	base, _, _ = strings.Cut(base, "@")
+	base = strings.ReplaceAll(base, "-", "_")
+	if base == "" || base == "C" || base == "POSIX" {
+		return cLocale, nil
+	}
+
+	lang, region, _ := strings.Cut(base, "_")
+	lang = strings.ToLower(lang)
+	candidates := []string{lang}
+	if region != "" {
+		candidates = append([]string{lang + "_" + strings.ToUpper(region)}, candidates...)
+	}
+	for _, key := range candidates {
+		if locale, ok := locales[key]; ok {
+			locale.Name = base
+			return locale, nil
+		}
+	}
+	return cLocale, fmt.Errorf("unsupported locale: %s (supported: C, %s)", name, strings.Join(SupportedLocales(), ", "))
+}
+
+// SupportedLocales returns the supported locale names, sorted
+func SupportedLocales() []string {
+	var names []string
+	for name := range locales {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetLocale selects the locale of displayed output
+func SetLocale(name string) error {
+	locale, err := LookupLocale(name)
+	if err != nil {
+		return err
+	}
+	currentLocale = locale
+	return nil
+}
+
+// InitLocale selects the locale from LLMLS_LOCALE, or on a terminal from
+// LC_ALL, LC_NUMERIC, or LANG; redirected output keeps the C formats so
+// scripts parsing it are not affected by the user's environment
+// Unsupported LC_ALL/LANG values fall back to C silently
+func InitLocale() error {
+	if name := os.Getenv("LLMLS_LOCALE"); name != "" {
+		return SetLocale(name)
+	}
+	if !StdoutIsTerminal() {
+		return nil
+	}
+	for _, env := range []string{"LC_ALL", "LC_NUMERIC", "LANG"} {
+		if name := os.Getenv(env); name != "" {
+			SetLocale(name)
+			return nil
+		}
+	}
+	return nil
+}
+
+// localizeNumber rewrites a number formatted by Go (1234567.5) with the
+// locale's separators (1.234.567,5 in de)
+func (l Locale) localizeNumber(s string) string {
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	integer, fraction, hasFraction := strings.Cut(s, ".")
+
+	var b strings.Builder
+	for i, c := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteString(l.Thousands)
+		}
+		b.WriteRune(c)
+	}
+	if hasFraction {
+		b.WriteString(l.Decimal + fraction)
+	}
+	return sign + b.String()
+}
+
+// FormatUSD formats an amount already formatted as a number (e.g. "0.0050")
+// as US dollars in the current locale: $0.0050, or 0,0050 $ in de
+func FormatUSD(amount string) string {
+	localized := currentLocale.localizeNumber(amount)
+	if currentLocale.USDSuffix {
+		return localized + " $"
+	}
+	return "$" + localized
+}
+
+// FormatLongDate converts a Unix timestamp to the current locale's long date
+// format in the local timezone, e.g. 2025年6月30日 in ja
+func FormatLongDate(timestamp int64) string {
+	if timestamp == 0 {
+		return "-"
+	}
+	return time.Unix(timestamp, 0).Format(currentLocale.LongDate)
+}
@@ -1,31 +1,74 @@
 package main
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"time"
+
+	"github.com/mkyutani/llmls/catalog"
 )
 
 var version = "dev"
 
 func showHelp() {
 	fmt.Fprintf(os.Stderr, "Usage: llmls [options] [pattern]\n\n")
-	fmt.Fprintf(os.Stderr, "List LLM models from OpenRouter and Ollama.\n\n")
+	fmt.Fprintf(os.Stderr, "List LLM models from OpenRouter, Ollama, llama.cpp, and TGI.\n\n")
 	fmt.Fprintf(os.Stderr, "Options:\n")
-	fmt.Fprintf(os.Stderr, "  --detail         Show detailed model information\n")
-	fmt.Fprintf(os.Stderr, "  --ollama-host    Ollama server URL (default: $OLLAMA_HOST or http://localhost:11434)\n")
-	fmt.Fprintf(os.Stderr, "  -h, --help       Show this help message\n")
-	fmt.Fprintf(os.Stderr, "  -v, --version    Show version information\n\n")
+	displayOptions(os.Stderr, FlagOptions(ListingFlagSet()))
+	fmt.Fprintf(os.Stderr, "\n")
+	fmt.Fprintf(os.Stderr, "Global options (accepted by every subcommand):\n")
+	displayOptions(os.Stderr, GlobalOptions())
+	fmt.Fprintf(os.Stderr, "\n")
 	fmt.Fprintf(os.Stderr, "Subcommands:\n")
-	fmt.Fprintf(os.Stderr, "  providers        List all provider names\n\n")
+	displayCommandSummaries()
+	fmt.Fprintf(os.Stderr, "\n")
+	fmt.Fprintf(os.Stderr, "Help topics (llmls help <topic>):\n")
+	for _, topic := range HelpTopics() {
+		fmt.Fprintf(os.Stderr, "  %-16s %s\n", topic.Name, topic.Summary)
+	}
+	fmt.Fprintf(os.Stderr, "\n")
+	fmt.Fprintf(os.Stderr, "Every flag can also be set with an environment variable, LLMLS_<FLAG> (e.g. LLMLS_SORT,\n")
+	fmt.Fprintf(os.Stderr, "LLMLS_SHOW_PRICE=true) or LLMLS_<SUBCOMMAND>_<FLAG> (e.g. LLMLS_ENDPOINTS_SORT). The\n")
+	fmt.Fprintf(os.Stderr, "command line overrides the environment, which overrides the config file. Flags may\n")
+	fmt.Fprintf(os.Stderr, "follow positional arguments; arguments after -- are all positional.\n\n")
 	fmt.Fprintf(os.Stderr, "Examples:\n")
 	fmt.Fprintf(os.Stderr, "  llmls                   List all models\n")
 	fmt.Fprintf(os.Stderr, "  llmls \"anthropic/*\"     List Anthropic models\n")
 	fmt.Fprintf(os.Stderr, "  llmls --detail cohere   Show detailed Cohere models\n")
 	fmt.Fprintf(os.Stderr, "  llmls providers         List all providers\n")
+	fmt.Fprintf(os.Stderr, "  llmls help endpoints    Show the help of a subcommand\n")
 }
 
 func main() {
+	args, err := ParseGlobalFlags(os.Args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	os.Args = args
+	if err := InitConfig(profileFlag); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: LLMLS_CONFIG: %v\n", err)
+		exit(1)
+	}
+	InitConsole()
+	defer RestoreConsole()
+	if err := InitLocale(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: LLMLS_LOCALE: %v\n", err)
+		exit(1)
+	}
+	if err := InitScheduler(""); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: LLMLS_RPM: %v\n", err)
+		exit(1)
+	}
+	if err := InitComputedColumns(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: LLMLS_CONFIG: %v\n", err)
+		exit(1)
+	}
+
 	if len(os.Args) < 2 {
 		// Default behavior: list all models
 		listModelsCommand(nil)
@@ -36,88 +79,2667 @@ func main() {
 
 	switch subcommand {
 	case "--help", "-h":
-		showHelp()
+		// llmls --help <subcommand> is llmls help <subcommand>
+		helpCommand()
 		return
 	case "--version", "-v":
 		fmt.Printf("llmls version %s\n", version)
 		return
-	case "providers":
-		providersCommand()
 	default:
+		if command, ok := FindCommand(subcommand); ok {
+			command.Run()
+			return
+		}
 		// If not a subcommand, treat as search pattern
 		listModelsCommand(os.Args[1:])
 	}
 }
 
-func listModelsCommand(args []string) {
-	fs := flag.NewFlagSet("llmls", flag.ExitOnError)
-	detail := fs.Bool("detail", false, "Display detailed model information")
-	ollamaHost := fs.String("ollama-host", "", "Ollama server URL (default: $OLLAMA_HOST or http://localhost:11434)")
+// listingFlags are the flags of the model listing, llmls [options] [pattern]
+type listingFlags struct {
+	detail          bool
+	rateLimits      bool
+	gguf            bool
+	showCategories  bool
+	uptime          bool
+	minUptime       float64
+	hfStats         bool
+	explain         bool
+	wide            bool
+	maxWidth        string
+	truncate        string
+	ellipsis        string
+	numbered        bool
+	pick            int
+	series          string
+	modelType       string
+	language        string
+	instruct        bool
+	base            bool
+	variant         string
+	requireParams   string
+	openWeightsOnly bool
+	unusualDefaults bool
+	supports        string
+	category        string
+	showSeries      bool
+	showContext     bool
+	showPrice       bool
+	showValue       bool
+	showRank        bool
+	columns         string
+	sortKey         string
+	trending        bool
+	blend           string
+	colorMode       string
+	translate       string
+	translateModel  string
+	locale          string
+	added           bool
+	removed         bool
+	changed         bool
+	newOnly         bool
+	asOf            string
+	summary         bool
+	incidents       bool
+	field           string
+	jq              string
+	where           string
+	output          string
+	noEnrich        bool
+	dedupe          bool
+	sourceConfig    SourceConfig
+}
 
+// RegisterFlags declares the listing flags on fs
+func (f *listingFlags) RegisterFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&f.detail, "detail", false, "Show detailed model information")
+	fs.BoolVar(&f.rateLimits, "rate-limits", false, "With --detail, show OpenAI/Anthropic/Mistral rate limits for your API key")
+	fs.BoolVar(&f.gguf, "gguf", false, "With --detail, show Hugging Face GGUF builds of local models")
+	fs.BoolVar(&f.showCategories, "categories", false, "With --detail, show the use-case categories OpenRouter ranks each model in")
+	fs.BoolVar(&f.uptime, "uptime", false, "With --detail, show the last-30-minute uptime of each upstream provider")
+	fs.BoolVar(&f.hfStats, "hf-stats", false, "Add Hugging Face download and like counts of models hosted there")
+	fs.BoolVar(&f.wide, "wide", false, "Do not truncate output to the terminal width")
+	fs.BoolVar(&f.wide, "w", false, "Do not truncate output to the terminal width")
+	fs.StringVar(&f.maxWidth, "max-width", "", "Maximum column widths, e.g. id=40,provider=12,desc=60")
+	fs.StringVar(&f.truncate, "truncate", "", "Truncation side per column, e.g. id=left (default: right)")
+	fs.StringVar(&f.ellipsis, "ellipsis", "..", "Marker for truncated text")
+	fs.StringVar(&f.series, "series", "", "Only list models in a series, e.g. llama-3 or claude-3.5")
+	fs.StringVar(&f.modelType, "type", "", "Only list models of a type: chat, completion, embedding, rerank,\nimage, audio, tts, stt (image/audio/tts/stt add media sources)")
+	fs.StringVar(&f.language, "language", "", "Only list models known to handle a language, e.g. ja or japanese")
+	fs.BoolVar(&f.instruct, "instruct", false, "Only list instruction-tuned (chat) models")
+	fs.BoolVar(&f.base, "base", false, "Only list base (pretrained only) models")
+	fs.StringVar(&f.variant, "variant", "", "Only list an OpenRouter routing variant: free, nitro, floor, online,\nextended, thinking, beta, exacto, or none for base models")
+	fs.StringVar(&f.category, "category", "", "Only list models OpenRouter ranks in a use-case category: programming,\nroleplay, marketing, marketing/seo, technology, science, translation,\nlegal, finance, health, trivia, academia")
+	fs.StringVar(&f.requireParams, "require-params", "", "Only list models accepting every listed request parameter, e.g.\ntemperature,top_k,tools (models not reporting parameters are dropped)")
+	fs.BoolVar(&f.openWeightsOnly, "open-weights-only", false, "Only list models with published weights: local models and hosted\nmodels linked to a Hugging Face repo")
+	fs.BoolVar(&f.unusualDefaults, "unusual-defaults", false, "Only list models whose provider defaults temperature, top_p, or a\npenalty to a value other than most models")
+	fs.StringVar(&f.supports, "supports", "", "Only list models with every feature in a comma-separated `LIST`, e.g. caching\nor tools,vision (features as in llmls matrix; models not reporting one are dropped)")
+	fs.Float64Var(&f.minUptime, "min-uptime", 0, "Only list OpenRouter models with an upstream provider at `N`% uptime or\nmore over the last 30 minutes, e.g. 99 (one request per model)")
+	fs.StringVar(&f.where, "where", "", "Only list models matching an expression over fields, e.g.\n'context_length >= 128000 && provider in [\"anthropic\",\"openai\"]'")
+	fs.BoolVar(&f.noEnrich, "no-enrich", false, "Do not run the enrichment plugins in $LLMLS_ENRICH_DIR")
+	fs.BoolVar(&f.dedupe, "dedupe", false, "Merge the same model from several sources (OpenRouter, its variants,\nlocal servers) into one row listing each source and its price")
+	fs.BoolVar(&f.showSeries, "show-series", false, "Add a series column")
+	fs.BoolVar(&f.showContext, "show-context", false, "Add a context length column, e.g. 128K or 1M")
+	fs.BoolVar(&f.showContext, "context", false, "Add a context length column, e.g. 128K or 1M")
+	fs.BoolVar(&f.showPrice, "show-price", false, "Add a price column (per 1K prompt/completion tokens)")
+	fs.BoolVar(&f.showValue, "show-value", false, "Add a value column (tokens per dollar, context per dollar of 1M-token price)")
+	fs.BoolVar(&f.showRank, "show-rank", false, "Add a column ranking models by tokens routed on OpenRouter this week")
+	fs.StringVar(&f.columns, "columns", "", "Add a `LIST` of computed columns defined in the config file, e.g. score,cheap")
+	fs.StringVar(&f.sortKey, "sort", "created", "Sort by comma-separated keys, each with an optional :asc or :desc:\ncreated, price, value, context-value, context, provider, id, name,\ndownloads, likes, trending (Hugging Face counts, implying --hf-stats),\npopularity (OpenRouter weekly rank, most used first),\nor a computed column, e.g. provider,created:desc")
+	fs.BoolVar(&f.trending, "trending", false, "Sort by Hugging Face trending score, then downloads")
+	fs.StringVar(&f.blend, "blend", "", "Input:output token ratio for a blended $/1M price column and --sort, e.g. 3:1")
+	fs.StringVar(&f.colorMode, "color", "auto", "Colorize output: auto, always, never")
+	fs.StringVar(&f.translate, "translate", "", "Translate descriptions into a language (ja, de, ...) with a local Ollama model")
+	fs.StringVar(&f.translateModel, "translate-model", "", "Ollama model, or openrouter:<model id> with OPENROUTER_API_KEY\n(default: $LLMLS_TRANSLATE_MODEL or "+DefaultTranslateModel+"); translations are cached")
+	fs.StringVar(&f.locale, "locale", "", "Format dates, numbers, and prices for a locale, e.g. ja or de_DE\n(default: $LLMLS_LOCALE, or $LANG on a terminal; C otherwise)")
+	fs.BoolVar(&f.added, "added", false, "Only list models added since the latest snapshot")
+	fs.BoolVar(&f.removed, "removed", false, "Only list models removed since the latest snapshot")
+	fs.BoolVar(&f.changed, "changed", false, "Only list models changed since the latest snapshot")
+	fs.BoolVar(&f.newOnly, "new", false, "Only list models added since you last ran llmls (no snapshots needed)")
+	fs.BoolVar(&f.newOnly, "since-run", false, "Only list models added since you last ran llmls (no snapshots needed)")
+	fs.StringVar(&f.asOf, "as-of", "", "List the catalog as it was on a `DATE` (YYYY-MM-DD) from saved snapshots")
+	fs.BoolVar(&f.numbered, "number", false, "Number the results")
+	fs.BoolVar(&f.numbered, "n", false, "Number the results")
+	fs.IntVar(&f.pick, "pick", 0, "Print only the ID of the `N`th result")
+	fs.BoolVar(&f.summary, "summary", false, "Print a summary footer even when output is not a terminal")
+	fs.BoolVar(&f.incidents, "incidents", false, "Annotate providers with ongoing incidents (⚠ degraded) from status pages")
+	fs.BoolVar(&f.explain, "explain", false, "Show which criterion matched each model")
+	fs.StringVar(&f.field, "field", "", "Print ID and field values, tab-separated (e.g. context_length,pricing.prompt)")
+	fs.StringVar(&f.output, "output", "", "Write the listed models to a `FILE` instead of stdout, replacing it atomically;\nthe format follows the extension: .csv, .json, .md, or .html")
+	fs.StringVar(&f.jq, "jq", "", "Run a jq program over the JSON array of listed models (built in; strings\nprint raw), e.g. '.[] | select(.context_length > 100000) | .id'")
+	f.sourceConfig.RegisterFlags(fs)
+}
+
+func listModelsCommand(args []string) {
+	var flags listingFlags
+	fs := NewFlagSet("llmls", &flags)
 	fs.Usage = showHelp
 
-	if args != nil {
-		fs.Parse(args)
-	} else {
-		fs.Parse(os.Args[1:])
+	if args == nil {
+		args = os.Args[1:]
 	}
+	// Flags from the config file are parsed first, so the environment and the
+	// command line override them
+	fs.Parse(config.Args)
+	ParseFlags(fs, args)
 
-	// Get search pattern from positional argument
-	pattern := ""
+	maxWidths, err := ParseMaxWidths(flags.maxWidth)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	truncateLeft, err := ParseTruncateSides(flags.truncate)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	color, err := UseColor(flags.colorMode)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	if flags.translate != "" {
+		if err := ValidateLanguage(flags.translate); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
+		}
+	}
+	if flags.locale != "" {
+		if err := SetLocale(flags.locale); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
+		}
+	}
+	displayOptions := DisplayOptions{
+		Wide:         flags.wide,
+		MaxWidths:    maxWidths,
+		TruncateLeft: truncateLeft,
+		Ellipsis:     flags.ellipsis,
+		Numbered:     flags.numbered,
+		ShowSeries:   flags.showSeries,
+		ShowContext:  flags.showContext,
+		ShowPrice:    flags.showPrice,
+		ShowValue:    flags.showValue,
+		ShowRank:     flags.showRank,
+		Color:        color,
+	}
+	displayOptions.Columns, err = ParseColumnNames(flags.columns)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	if flags.blend != "" {
+		b, err := catalog.ParseBlend(flags.blend)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
+		}
+		displayOptions.Blend = &b
+	}
+
+	if flags.rateLimits && !flags.detail {
+		fmt.Fprintf(os.Stderr, "Error: --rate-limits requires --detail\n")
+		exit(1)
+	}
+	if flags.gguf && !flags.detail {
+		fmt.Fprintf(os.Stderr, "Error: --gguf requires --detail\n")
+		exit(1)
+	}
+	if flags.trending {
+		sortSet := false
+		fs.Visit(func(f *flag.Flag) { sortSet = sortSet || f.Name == "sort" })
+		if sortSet {
+			fmt.Fprintf(os.Stderr, "Error: --trending cannot be combined with --sort\n")
+			exit(1)
+		}
+		flags.sortKey = "trending,downloads"
+	}
+
+	if err := catalog.ValidateVariant(flags.variant); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	if err := catalog.ValidateCategory(flags.category); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	languageCode := ""
+	if flags.language != "" {
+		var err error
+		languageCode, err = catalog.ParseLanguage(flags.language)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
+		}
+	}
+	if flags.instruct && flags.base {
+		fmt.Fprintf(os.Stderr, "Error: --instruct cannot be combined with --base\n")
+		exit(1)
+	}
+	tuning := ""
+	switch {
+	case flags.instruct:
+		tuning = catalog.TuningInstruct
+	case flags.base:
+		tuning = catalog.TuningBase
+	}
+	if err := ValidateMinUptime(flags.minUptime); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	var supportedFeatures []Feature
+	if flags.supports != "" {
+		supportedFeatures, err = ParseFeatures(flags.supports)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --supports: %v\n", err)
+			exit(1)
+		}
+	}
+
+	if flags.modelType != "" && !catalog.IsModelType(flags.modelType) {
+		fmt.Fprintf(os.Stderr, "Error: unknown model type: %s\n", flags.modelType)
+		exit(1)
+	}
+	flags.sourceConfig.IncludeMedia = catalog.IsMediaType(flags.modelType)
+
+	var fields []string
+	if flags.field != "" {
+		fields = strings.Split(flags.field, ",")
+		if err := ValidateFieldPaths(fields); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
+		}
+	}
+
+	if flags.output != "" {
+		if _, err := OutputFormatFor(flags.output); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
+		}
+		if flags.detail || flags.field != "" || flags.jq != "" || flags.pick != 0 {
+			fmt.Fprintf(os.Stderr, "Error: --output cannot be combined with --detail, --field, --jq, or --pick\n")
+			exit(1)
+		}
+	}
+
+	var asOfTime time.Time
+	if flags.asOf != "" {
+		asOfTime, err = ParseAsOf(flags.asOf)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
+		}
+		if flags.added || flags.removed || flags.changed || flags.newOnly {
+			fmt.Fprintf(os.Stderr, "Error: --as-of cannot be combined with --added, --removed, --changed, or --new\n")
+			exit(1)
+		}
+	}
+
+	var whereFilter *catalog.WhereFilter
+	if flags.where != "" {
+		whereFilter, err = catalog.ParseWhere(flags.where, computedColumns)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --where expression: %v\n", err)
+			exit(1)
+		}
+	}
+
+	var jqProgram *JQ
+	if flags.jq != "" {
+		jqProgram, err = ParseJQ(flags.jq)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --jq program: %v\n", err)
+			exit(1)
+		}
+	}
+
+	// Get search pattern from positional argument, else the config file
+	pattern := config.Pattern
 	if fs.NArg() > 0 {
 		pattern = fs.Arg(0)
 	}
 
-	// Fetch models from OpenRouter
-	models, err := FetchModels()
+	// Fetch models from OpenRouter and local servers
+	// Local providers may be reached through SSH tunnels (ssh:// hosts)
+	var tunnels TunnelSet
+	defer tunnels.Close()
+
+	var current *Snapshot
+	if flags.asOf != "" {
+		// Query the stored history instead of the sources
+		current, err = SnapshotAsOf(asOfTime)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
+		}
+		if StdoutIsTerminal() {
+			fmt.Fprintf(os.Stderr, "Catalog as of %s (snapshot of %s)\n", flags.asOf, current.TakenAt.Local().Format("2006-01-02 15:04"))
+		}
+	} else {
+		current, err = flags.sourceConfig.FetchSnapshot(&tunnels)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
+		}
+	}
+	models := current.Models
+
+	// Restrict to models that differ from the latest snapshot
+	if flags.added || flags.removed || flags.changed {
+		snapshot, err := LatestSnapshot()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
+		}
+
+		diff := CompareSnapshots(snapshot, current)
+		var selected []catalog.Model
+		if flags.added {
+			selected = append(selected, diff.Added...)
+		}
+		if flags.removed {
+			selected = append(selected, diff.Removed...)
+		}
+		if flags.changed {
+			selected = append(selected, diff.Changed...)
+		}
+		models = selected
+	}
+
+	// Record this run's catalog, keeping the previous record for --new
+	if flags.asOf == "" {
+		lastRun, err := LoadLastRun()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		if err := SaveLastRun(current); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		if flags.newOnly {
+			if lastRun == nil {
+				fmt.Fprintf(os.Stderr, "No previous run recorded; --new lists models added from now on\n")
+				models = nil
+			} else {
+				models = NewSinceRun(models, lastRun)
+			}
+		}
+	}
+
+	// Filter models by pattern
+	allModels := models
+	if flags.explain {
+		models = ExplainFilter(models, pattern)
+	} else {
+		models = catalog.FilterModels(models, pattern)
+	}
+	matched := len(models)
+	client := NewCatalogClient(CachedGet) // Looks up categories, popularity counts, and rankings
+	var chain FilterChain
+	models = chain.Apply("--series", models, catalog.FilterModelsBySeries(models, flags.series))
+	models = chain.Apply("--type", models, catalog.FilterModelsByType(models, flags.modelType))
+	models = chain.Apply("--variant", models, catalog.FilterModelsByVariant(models, flags.variant))
+	models = chain.Apply("--"+tuning, models, catalog.FilterModelsByTuning(models, tuning))
+	models = chain.Apply("--language", models, catalog.FilterModelsByLanguage(models, languageCode))
+	inCategory, err := client.FilterModelsByCategory(models, flags.category)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		exit(1)
+	}
+	models = chain.Apply("--category", models, inCategory)
+	models = chain.Apply("--require-params", models, FilterModelsByParams(models, ParseRequireParams(flags.requireParams)))
+	models = chain.Apply("--supports", models, FilterModelsBySupport(models, supportedFeatures))
+	if flags.openWeightsOnly {
+		models = chain.Apply("--open-weights-only", models, catalog.FilterModelsByOpenWeights(models))
+	}
+	if flags.unusualDefaults {
+		models = chain.Apply("--unusual-defaults", models, FilterModelsByUnusualDefaults(models))
+	}
+	models = chain.Apply("--where", models, catalog.FilterModelsWhere(models, whereFilter))
+	// Uptime takes a request per model, so it is looked up after the other filters
+	if flags.minUptime > 0 {
+		FillUptime(models)
+		if failed := CountUptimeErrors(models); failed > 0 {
+			fmt.Fprintf(os.Stderr, "Warning: uptime of %d models could not be fetched; they are not listed\n", failed)
+		}
+		models = chain.Apply("--min-uptime", models, FilterModelsByUptime(models, flags.minUptime))
+	}
+	if flags.dedupe {
+		models = chain.Apply("--dedupe", models, DedupeModels(models, allModels))
+	}
+	if flags.explain {
+		chain.Display(matched, len(models))
+	}
+	// Any filter of the chain may leave nothing, not only the pattern
+	if len(models) == 0 && len(allModels) > 0 {
+		if matched == 0 {
+			DisplayNoMatch(pattern, allModels)
+		} else {
+			DisplayFilteredOut(pattern, matched, len(allModels))
+		}
+	}
+
+	// Popularity counts are looked up for the listed models only
+	if flags.hfStats || catalog.SortUsesHFStats(flags.sortKey) {
+		client.FillHFStats(models)
+	}
+	if flags.showRank || catalog.SortUsesRankings(flags.sortKey) || FieldsUse(fields, "popularity_rank") {
+		client.FillRankings(models)
+	}
+	// Enrich before sorting, since plugins may provide sort keys too
+	if !flags.noEnrich {
+		if err := EnrichModels(models); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	// Sort by creation date descending unless --sort says otherwise
+	if err := catalog.SortModels(models, flags.sortKey, displayOptions.blend(), computedColumns); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
 	}
 
-	// Fetch models from Ollama and merge
-	ollamaModels := FetchOllamaModels(GetOllamaHost(*ollamaHost))
-	models = append(models, ollamaModels...)
+	// Print only the picked model ID
+	if flags.pick != 0 {
+		if flags.pick < 1 || flags.pick > len(models) {
+			fmt.Fprintf(os.Stderr, "Error: --pick %d is out of range (%d models matched)\n", flags.pick, len(models))
+			exit(1)
+		}
+		fmt.Println(models[flags.pick-1].ID)
+		return
+	}
 
-	// Filter models by pattern
-	models = FilterModels(models, pattern)
+	if flags.translate != "" {
+		translator := &Translator{Model: GetTranslateModel(flags.translateModel), Language: flags.translate}
+		if !strings.HasPrefix(translator.Model, translateModelPrefix) {
+			translator.Host = openOllamaHost(&tunnels, flags.sourceConfig.OllamaHost)
+		}
+		if err := translator.TranslateDescriptions(models); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: some descriptions were not translated: %v\n", err)
+		}
+	}
+
+	if flags.incidents {
+		displayOptions.Incidents = DegradedProviders(FetchProviderIncidents())
+		WarnOpenRouterIncident(displayOptions.Incidents)
+	}
 
-	// Sort by creation date descending
-	SortModelsByCreatedDesc(models)
+	if flags.output != "" {
+		if err := WriteModelsFile(flags.output, models); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
+		}
+		return
+	}
 
 	// Display models
-	if *detail {
-		DisplayModelsDetailed(models)
+	if jqProgram != nil {
+		entries, err := CatalogJSON(models)
+		if err == nil {
+			err = jqProgram.Run(os.Stdout, entries)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --jq: %v\n", err)
+			exit(1)
+		}
+		return
+	}
+	if fields != nil {
+		if FieldsUse(fields, "categories") {
+			client.FillCategories(models)
+		}
+		if err := DisplayModelFields(models, fields); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
+		}
+	} else if flags.detail {
+		if flags.showCategories {
+			client.FillCategories(models)
+		}
+		if flags.rateLimits {
+			FillRateLimits(models)
+		}
+		if flags.gguf {
+			FillGGUFCrossRefs(models)
+		}
+		if flags.uptime && flags.minUptime == 0 {
+			FillUptime(models)
+		}
+		DisplayModelsDetailed(models, color)
 	} else {
-		DisplayModels(models)
+		DisplayModels(models, displayOptions)
+	}
+
+	if fields == nil && (flags.summary || StdoutIsTerminal()) {
+		DisplaySummary(models)
 	}
 }
 
+// providersFlags are the flags of llmls providers
+type providersFlags struct {
+	incidents bool
+	aliases   bool
+}
+
+// RegisterFlags declares the flags of llmls providers on fs
+func (f *providersFlags) RegisterFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&f.incidents, "incidents", false, "Annotate providers with ongoing incidents")
+	fs.BoolVar(&f.aliases, "aliases", false, "Show the other provider prefixes grouped under each provider")
+}
+
 func providersCommand() {
-	fs := flag.NewFlagSet("providers", flag.ExitOnError)
-	fs.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: llmls providers\n\n")
-		fmt.Fprintf(os.Stderr, "List all provider names.\n")
-	}
+	var flags providersFlags
+	fs := NewCommandFlagSet("providers", &flags)
 
-	fs.Parse(os.Args[2:])
+	ParseFlags(fs, os.Args[2:])
 
 	// providers subcommand does not accept arguments
 	if fs.NArg() > 0 {
 		fmt.Fprintf(os.Stderr, "Error: providers subcommand does not accept arguments\n")
 		fmt.Fprintf(os.Stderr, "Use 'llmls providers | grep pattern' to filter\n\n")
 		fs.Usage()
-		os.Exit(1)
+		exit(1)
 	}
 
 	// Fetch models from OpenRouter
 	models, err := FetchModels()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		exit(1)
+	}
+
+	var degraded map[string]ProviderIncident
+	if flags.incidents {
+		degraded = DegradedProviders(FetchProviderIncidents())
+		WarnOpenRouterIncident(degraded)
 	}
 
 	// Display all providers
-	DisplayProviders(models)
+	DisplayProviders(models, degraded, flags.aliases)
+}
+
+// discoverFlags are the flags of llmls discover
+type discoverFlags struct {
+	scan    bool
+	timeout time.Duration
+	save    bool
+}
+
+// RegisterFlags declares the flags of llmls discover on fs
+func (f *discoverFlags) RegisterFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&f.scan, "scan", false, "Also probe every host on the local /24 networks")
+	fs.DurationVar(&f.timeout, "timeout", 2*time.Second, "How long to wait for mDNS responses")
+	fs.BoolVar(&f.save, "save", false, "Write the servers to the config file as env entries without asking")
+}
+
+func discoverCommand() {
+	var flags discoverFlags
+	fs := NewCommandFlagSet("discover", &flags)
+
+	ParseFlags(fs, os.Args[2:])
+
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Error: discover subcommand does not accept arguments\n\n")
+		fs.Usage()
+		exit(1)
+	}
+
+	hosts := DiscoverHosts(flags.timeout, flags.scan)
+	servers := ProbeServers(hosts, 500*time.Millisecond)
+	if len(servers) == 0 {
+		fmt.Fprintf(os.Stderr, "No inference servers found\n")
+		return
+	}
+
+	DisplayDiscoveredServers(servers)
+	if err := OfferConfigEnv(servers, flags.save); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+}
+
+// statusFlags are the flags of llmls status
+type statusFlags struct {
+	jsonOutput   bool
+	sourceConfig SourceConfig
+}
+
+// RegisterFlags declares the flags of llmls status on fs
+func (f *statusFlags) RegisterFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&f.jsonOutput, "json", false, "Output status as JSON")
+	f.sourceConfig.RegisterFlags(fs)
+}
+
+func statusCommand() {
+	var flags statusFlags
+	fs := NewCommandFlagSet("status", &flags)
+
+	ParseFlags(fs, os.Args[2:])
+
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Error: status subcommand does not accept arguments\n\n")
+		fs.Usage()
+		exit(1)
+	}
+
+	var tunnels TunnelSet
+	defer tunnels.Close()
+
+	sources := append([]Source{OpenRouterSource()}, flags.sourceConfig.RemoteSources()...)
+	sources = append(sources, flags.sourceConfig.LocalSources(&tunnels)...)
+	statuses := CheckSources(sources)
+	incidents := FetchProviderIncidents()
+	for i := range statuses {
+		for _, incident := range incidents {
+			if statuses[i].Source == incident.Provider && incident.Degraded() {
+				statuses[i].Incident = incident.Description
+			}
+		}
+	}
+
+	if flags.jsonOutput {
+		if err := DisplaySourceStatusesJSON(statuses); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
+		}
+		return
+	}
+	DisplaySourceStatuses(statuses)
+	fmt.Println()
+	DisplayProviderIncidents(incidents)
+}
+
+// validateFlags are the flags of llmls validate
+type validateFlags struct {
+	file         string
+	format       string
+	sourceConfig SourceConfig
+}
+
+// RegisterFlags declares the flags of llmls validate on fs
+func (f *validateFlags) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&f.file, "file", "", "`FILE` with one model ID per line (default: stdin)")
+	fs.StringVar(&f.format, "format", FormatText, "Result format: text, or github for GitHub Actions annotations\npointing at lines of --file")
+	f.sourceConfig.RegisterFlags(fs)
+}
+
+func validateCommand() {
+	var flags validateFlags
+	fs := NewCommandFlagSet("validate", &flags)
+
+	ParseFlags(fs, os.Args[2:])
+
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Error: validate subcommand does not accept arguments\n\n")
+		fs.Usage()
+		exit(1)
+	}
+	if err := ValidateResultFormat(flags.format); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	input := os.Stdin
+	if flags.file != "" {
+		f, err := os.Open(flags.file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
+		}
+		defer f.Close()
+		input = f
+	}
+
+	ids, err := ReadModelIDs(input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	var tunnels TunnelSet
+	models, err := flags.sourceConfig.FetchCatalog(&tunnels)
+	tunnels.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	results := ValidateModelIDs(ids, models)
+	if flags.format == FormatGitHub {
+		PrintAnnotations(ValidationAnnotations(results, flags.file))
+	} else {
+		DisplayValidationResults(results)
+	}
+
+	for _, result := range results {
+		if result.Failed() {
+			exit(1)
+		}
+	}
+}
+
+// openOllamaHost resolves the Ollama host, opening an SSH tunnel if needed
+func openOllamaHost(tunnels *TunnelSet, flagHost string) string {
+	host, err := tunnels.Open(GetOllamaHost(flagHost), 11434)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	return host
+}
+
+// ollamaRemoveFlags are the flags of llmls rm
+type ollamaRemoveFlags struct {
+	ollamaHost string
+}
+
+// RegisterFlags declares the flags of llmls rm on fs
+func (f *ollamaRemoveFlags) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&f.ollamaHost, "ollama-host", "", "Ollama server URL (default: $OLLAMA_HOST or http://localhost:11434)")
+}
+
+func ollamaRemoveCommand() {
+	var flags ollamaRemoveFlags
+	fs := NewCommandFlagSet("rm", &flags)
+
+	ParseFlags(fs, os.Args[2:])
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		exit(1)
+	}
+
+	var tunnels TunnelSet
+	host := openOllamaHost(&tunnels, flags.ollamaHost)
+
+	failed := false
+	for _, id := range fs.Args() {
+		name := catalog.OllamaModelName(id)
+		if err := DeleteOllamaModel(host, name); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", name, err)
+			failed = true
+			continue
+		}
+		fmt.Printf("Removed ollama/%s\n", name)
+	}
+	tunnels.Close()
+
+	if failed {
+		exit(1)
+	}
+}
+
+// ollamaCopyFlags are the flags of llmls cp
+type ollamaCopyFlags struct {
+	ollamaHost string
 }
 
+// RegisterFlags declares the flags of llmls cp on fs
+func (f *ollamaCopyFlags) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&f.ollamaHost, "ollama-host", "", "Ollama server URL (default: $OLLAMA_HOST or http://localhost:11434)")
+}
+
+func ollamaCopyCommand() {
+	var flags ollamaCopyFlags
+	fs := NewCommandFlagSet("cp", &flags)
+
+	ParseFlags(fs, os.Args[2:])
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		exit(1)
+	}
+
+	var tunnels TunnelSet
+	host := openOllamaHost(&tunnels, flags.ollamaHost)
+	source, destination := catalog.OllamaModelName(fs.Arg(0)), catalog.OllamaModelName(fs.Arg(1))
+	err := CopyOllamaModel(host, source, destination)
+	tunnels.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	fmt.Printf("Copied ollama/%s to ollama/%s\n", source, destination)
+}
+
+// ollamaDiskUsageFlags are the flags of llmls du
+type ollamaDiskUsageFlags struct {
+	ollamaHost string
+}
+
+// RegisterFlags declares the flags of llmls du on fs
+func (f *ollamaDiskUsageFlags) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&f.ollamaHost, "ollama-host", "", "Ollama server URL (default: $OLLAMA_HOST or http://localhost:11434)")
+}
+
+func ollamaDiskUsageCommand() {
+	var flags ollamaDiskUsageFlags
+	fs := NewCommandFlagSet("du", &flags)
+
+	ParseFlags(fs, os.Args[2:])
+
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Error: du subcommand does not accept arguments\n\n")
+		fs.Usage()
+		exit(1)
+	}
+
+	var tunnels TunnelSet
+	host := openOllamaHost(&tunnels, flags.ollamaHost)
+	models, err := NewCatalogClient(CachedGet).FetchOllamaModels(host)
+	tunnels.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	DisplayDiskUsage(OllamaDiskUsage(models))
+}
+
+// ollamaOutdatedFlags are the flags of llmls outdated
+type ollamaOutdatedFlags struct {
+	ollamaHost string
+	pull       bool
+}
+
+// RegisterFlags declares the flags of llmls outdated on fs
+func (f *ollamaOutdatedFlags) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&f.ollamaHost, "ollama-host", "", "Ollama server URL (default: $OLLAMA_HOST or http://localhost:11434)")
+	fs.BoolVar(&f.pull, "pull", false, "Pull the latest version of every outdated model")
+}
+
+func ollamaOutdatedCommand() {
+	var flags ollamaOutdatedFlags
+	fs := NewCommandFlagSet("outdated", &flags)
+
+	ParseFlags(fs, os.Args[2:])
+
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Error: outdated subcommand does not accept arguments\n\n")
+		fs.Usage()
+		exit(1)
+	}
+
+	var tunnels TunnelSet
+	defer tunnels.Close()
+	host := openOllamaHost(&tunnels, flags.ollamaHost)
+	models, err := NewCatalogClient(CachedGet).FetchOllamaModels(host)
+	if err != nil {
+		tunnels.Close()
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	outdated := FindOutdatedModels(models, func(err error) {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	})
+	if len(outdated) == 0 {
+		fmt.Fprintf(os.Stderr, "All %d models are up to date\n", len(models))
+		return
+	}
+
+	for _, m := range outdated {
+		fmt.Printf("ollama/%s (installed %s, latest %s)\n", m.Name, shortDigest(m.LocalDigest), shortDigest(m.RemoteDigest))
+	}
+
+	if !flags.pull {
+		return
+	}
+	failed := false
+	for _, m := range outdated {
+		fmt.Fprintf(os.Stderr, "Pulling ollama/%s...\n", m.Name)
+		if err := PullOllamaModel(host, m.Name); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", m.Name, err)
+			failed = true
+		}
+	}
+	if failed {
+		tunnels.Close()
+		exit(1)
+	}
+}
+
+// openFlags are the flags of llmls open
+type openFlags struct {
+	printOnly    bool
+	page         string
+	sourceConfig SourceConfig
+}
+
+// RegisterFlags declares the flags of llmls open on fs
+func (f *openFlags) RegisterFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&f.printOnly, "print", false, "Print the URL instead of opening it")
+	fs.StringVar(&f.page, "page", "", "Provider page to open: "+strings.Join(catalog.ProviderPageKinds, ", ")+"\n(default: model page for a model ID, home for a provider)")
+	f.sourceConfig.RegisterFlags(fs)
+}
+
+func openCommand() {
+	var flags openFlags
+	fs := NewCommandFlagSet("open", &flags)
+
+	ParseFlags(fs, os.Args[2:])
+
+	if fs.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "Error: open subcommand requires a model ID or provider\n\n")
+		fs.Usage()
+		exit(1)
+	}
+	target := fs.Arg(0)
+
+	var url string
+	if flags.page != "" || !strings.Contains(target, "/") {
+		// Provider pages need no catalog; a model ID stands for its provider
+		kind := flags.page
+		if kind == "" {
+			kind = "home"
+		}
+		provider := target
+		if strings.Contains(target, "/") {
+			provider = catalog.ExtractProvider(target)
+		}
+		var err error
+		url, err = catalog.PagesForProvider(provider).Page(kind)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
+		}
+		if url == "" {
+			fmt.Fprintf(os.Stderr, "Error: no %s page for %s\n", kind, provider)
+			exit(1)
+		}
+	} else {
+		var tunnels TunnelSet
+		models, err := flags.sourceConfig.FetchCatalog(&tunnels)
+		tunnels.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
+		}
+
+		found := false
+		for _, model := range models {
+			if model.ID == target {
+				url, found = model.URL, true
+				break
+			}
+		}
+		if !found {
+			fmt.Fprintf(os.Stderr, "Error: model not found: %s\n", target)
+			if suggestions := SuggestModelIDs(target, models, 1); len(suggestions) > 0 {
+				fmt.Fprintf(os.Stderr, "Did you mean %s?\n", suggestions[0])
+			}
+			exit(1)
+		}
+		if url == "" {
+			fmt.Fprintf(os.Stderr, "Error: no web page for %s\n", target)
+			exit(1)
+		}
+	}
+
+	if flags.printOnly {
+		fmt.Println(url)
+		return
+	}
+	if err := OpenBrowser(url); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+}
+
+// endpointsFlags are the flags of llmls endpoints
+type endpointsFlags struct {
+	sortKey    string
+	jsonOutput bool
+	minUptime  float64
+}
+
+// RegisterFlags declares the flags of llmls endpoints on fs
+func (f *endpointsFlags) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&f.sortKey, "sort", "price", "Sort by: "+strings.Join(endpointSortKeys, ", "))
+	fs.BoolVar(&f.jsonOutput, "json", false, "Output as JSON")
+	fs.Float64Var(&f.minUptime, "min-uptime", 0, "Only list providers at `N`% uptime or more over the last 30 minutes,\ne.g. 99; providers not reporting uptime are dropped")
+}
+
+func endpointsCommand() {
+	var flags endpointsFlags
+	fs := NewCommandFlagSet("endpoints", &flags)
+
+	ParseFlags(fs, os.Args[2:])
+
+	if fs.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "Error: endpoints subcommand requires a model ID\n\n")
+		fs.Usage()
+		exit(1)
+	}
+
+	if err := ValidateMinUptime(flags.minUptime); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	endpoints, err := FetchModelEndpoints(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	if flags.minUptime > 0 {
+		endpoints = FilterEndpointsByUptime(endpoints, flags.minUptime)
+	}
+	if err := SortEndpoints(endpoints, flags.sortKey); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	if flags.jsonOutput {
+		if err := DisplayEndpointsJSON(endpoints); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
+		}
+		return
+	}
+	DisplayEndpoints(endpoints)
+}
+
+// policyFlags are the flags of llmls policy check
+type policyFlags struct {
+	policyFile   string
+	modelsFile   string
+	all          bool
+	format       string
+	sourceConfig SourceConfig
+}
+
+// RegisterFlags declares the flags of llmls policy check on fs
+func (f *policyFlags) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&f.policyFile, "policy", "", "Policy `FILE` (required)")
+	fs.StringVar(&f.modelsFile, "models", "", "Check the model IDs in `FILE` (- for stdin) instead of the catalog")
+	fs.BoolVar(&f.all, "all", false, "Show failing catalog models and their violations too")
+	fs.StringVar(&f.format, "format", FormatText, "Result format: text, or github for GitHub Actions annotations")
+	f.sourceConfig.RegisterFlags(fs)
+}
+
+func policyCommand() {
+	var flags policyFlags
+	fs := NewCommandFlagSet("policy check", &flags)
+	if len(os.Args) < 3 || os.Args[2] != "check" {
+		fs.Usage()
+		if len(os.Args) > 2 && isHelpFlag(os.Args[2]) {
+			return
+		}
+		exit(1)
+	}
+
+	ParseFlags(fs, os.Args[3:])
+
+	if fs.NArg() > 0 || flags.policyFile == "" {
+		fs.Usage()
+		exit(1)
+	}
+	if err := ValidateResultFormat(flags.format); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	f, err := os.Open(flags.policyFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	policy, err := ParsePolicy(f)
+	f.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s: %v\n", flags.policyFile, err)
+		exit(1)
+	}
+	if len(policy.BannedLicenses) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: banned_licenses fails every model: the catalog has no license data\n")
+	}
+
+	var ids []string
+	if flags.modelsFile != "" {
+		input := os.Stdin
+		if flags.modelsFile != "-" {
+			f, err := os.Open(flags.modelsFile)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			defer f.Close()
+			input = f
+		}
+		if ids, err = ReadModelIDs(input); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
+		}
+		if ids == nil {
+			ids = []string{}
+		}
+	}
+
+	var tunnels TunnelSet
+	models, err := flags.sourceConfig.FetchCatalog(&tunnels)
+	tunnels.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	results := CheckPolicy(policy, models, ids)
+	switch {
+	case flags.format != FormatGitHub:
+		DisplayPolicyResults(results, ids == nil && !flags.all)
+	case ids != nil || flags.all:
+		PrintAnnotations(PolicyAnnotations(results, flags.modelsFile, ids == nil))
+	}
+
+	if ids == nil {
+		passed := 0
+		for _, result := range results {
+			if result.Passed() {
+				passed++
+			}
+		}
+		fmt.Fprintf(os.Stderr, "%d of %d models pass\n", passed, len(results))
+		return
+	}
+	for _, result := range results {
+		if !result.Passed() {
+			exit(1)
+		}
+	}
+}
+
+// getFlags are the flags of llmls get
+type getFlags struct {
+	sourceConfig SourceConfig
+}
+
+// RegisterFlags declares the flags of llmls get on fs
+func (f *getFlags) RegisterFlags(fs *flag.FlagSet) {
+	f.sourceConfig.RegisterFlags(fs)
+}
+
+func getCommand() {
+	var flags getFlags
+	fs := NewCommandFlagSet("get", &flags)
+
+	ParseFlags(fs, os.Args[2:])
+
+	if fs.NArg() != 2 {
+		fmt.Fprintf(os.Stderr, "Error: get subcommand requires a model ID and a field\n\n")
+		fs.Usage()
+		exit(1)
+	}
+
+	var tunnels TunnelSet
+	models, err := flags.sourceConfig.FetchCatalog(&tunnels)
+	tunnels.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	value, err := GetModelField(models, fs.Arg(0), fs.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	fmt.Println(value)
+}
+
+// randomFlags are the flags of llmls random
+type randomFlags struct {
+	seed         int64
+	detail       bool
+	sourceConfig SourceConfig
+}
+
+// RegisterFlags declares the flags of llmls random on fs
+func (f *randomFlags) RegisterFlags(fs *flag.FlagSet) {
+	fs.Int64Var(&f.seed, "seed", 0, "Random seed for reproducible picks (default: time-based)")
+	fs.BoolVar(&f.detail, "detail", false, "Show detailed information for the picked model")
+	f.sourceConfig.RegisterFlags(fs)
+}
+
+func randomCommand() {
+	var flags randomFlags
+	fs := NewCommandFlagSet("random", &flags)
+
+	ParseFlags(fs, os.Args[2:])
+
+	pattern := ""
+	if fs.NArg() > 0 {
+		pattern = fs.Arg(0)
+	}
+
+	var tunnels TunnelSet
+	models, err := flags.sourceConfig.FetchCatalog(&tunnels)
+	tunnels.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	filtered := catalog.FilterModels(models, pattern)
+	if len(filtered) == 0 {
+		DisplayNoMatch(pattern, models)
+		exit(1)
+	}
+
+	model := PickRandomModel(filtered, flags.seed)
+	if flags.detail {
+		color, _ := UseColor("auto")
+		DisplayModelsDetailed([]catalog.Model{model}, color)
+		return
+	}
+	fmt.Println(model.ID)
+}
+
+// snapshotFlags are the flags of llmls snapshot
+type snapshotFlags struct {
+	sourceConfig SourceConfig
+}
+
+// RegisterFlags declares the flags of llmls snapshot on fs
+func (f *snapshotFlags) RegisterFlags(fs *flag.FlagSet) {
+	f.sourceConfig.RegisterFlags(fs)
+}
+
+func snapshotCommand() {
+	var flags snapshotFlags
+	fs := NewCommandFlagSet("snapshot", &flags)
+
+	ParseFlags(fs, os.Args[2:])
+
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Error: snapshot subcommand does not accept arguments\n\n")
+		fs.Usage()
+		exit(1)
+	}
+
+	var tunnels TunnelSet
+	snapshot, err := flags.sourceConfig.FetchSnapshot(&tunnels)
+	tunnels.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	path, err := SaveSnapshot(snapshot)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	switch {
+	case sessionActive:
+		fmt.Fprintf(os.Stderr, "Not saved: snapshots of --record and --replay sessions stay out of the history\n")
+	case !dryRun:
+		fmt.Fprintf(os.Stderr, "Saved %d models to %s\n", len(snapshot.Models), path)
+	}
+}
+
+// historyFlags are the flags of llmls history
+type historyFlags struct {
+	modelID string
+}
+
+// RegisterFlags declares the flags of llmls history on fs
+func (f *historyFlags) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&f.modelID, "model", "", "Model `ID` to show the timeline of")
+}
+
+func historyCommand() {
+	var flags historyFlags
+	fs := NewCommandFlagSet("history", &flags)
+
+	ParseFlags(fs, os.Args[2:])
+
+	if flags.modelID == "" || fs.NArg() > 0 {
+		fs.Usage()
+		exit(1)
+	}
+
+	events, err := ModelHistory(flags.modelID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	DisplayModelHistory(events)
+}
+
+// searchFlags are the flags of llmls search
+type searchFlags struct {
+	limit        int
+	detail       bool
+	semantic     bool
+	embedModel   string
+	sourceConfig SourceConfig
+}
+
+// RegisterFlags declares the flags of llmls search on fs
+func (f *searchFlags) RegisterFlags(fs *flag.FlagSet) {
+	fs.IntVar(&f.limit, "limit", 10, "Maximum number of results")
+	fs.BoolVar(&f.detail, "detail", false, "Show detailed information")
+	fs.BoolVar(&f.semantic, "semantic", false, "Rank by meaning using an Ollama embedding model")
+	fs.StringVar(&f.embedModel, "embed-model", DefaultEmbedModel, "Ollama embedding model for --semantic")
+	f.sourceConfig.RegisterFlags(fs)
+}
+
+func searchCommand() {
+	var flags searchFlags
+	fs := NewCommandFlagSet("search", &flags)
+
+	ParseFlags(fs, os.Args[2:])
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		exit(1)
+	}
+	query := strings.Join(fs.Args(), " ")
+
+	var tunnels TunnelSet
+	models, err := flags.sourceConfig.FetchCatalog(&tunnels)
+	tunnels.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	var results []SearchResult
+	if flags.semantic {
+		var embedTunnels TunnelSet
+		host, err := embedTunnels.Open(GetOllamaHost(flags.sourceConfig.OllamaHost), 11434)
+		if err == nil {
+			results, err = SemanticSearch(&Embedder{Host: host, Model: flags.embedModel}, models, query, flags.limit)
+		}
+		embedTunnels.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
+		}
+	} else {
+		results = NewSearchIndex(models).Search(query, flags.limit)
+	}
+	if len(results) == 0 {
+		fmt.Fprintf(os.Stderr, "No models match: %s\n", query)
+		exit(1)
+	}
+
+	ranked := make([]catalog.Model, len(results))
+	for i, result := range results {
+		ranked[i] = result.Model
+	}
+	color, _ := UseColor("auto")
+	if flags.detail {
+		DisplayModelsDetailed(ranked, color)
+		return
+	}
+	DisplayModels(ranked, DisplayOptions{Ellipsis: "..", Color: color})
+}
+
+// queryFlags are the flags of llmls query
+type queryFlags struct {
+	header       bool
+	sourceConfig SourceConfig
+}
+
+// RegisterFlags declares the flags of llmls query on fs
+func (f *queryFlags) RegisterFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&f.header, "header", false, "Print column names as the first line")
+	f.sourceConfig.RegisterFlags(fs)
+}
+
+func queryCommand() {
+	var flags queryFlags
+	fs := NewCommandFlagSet("query", &flags)
+
+	ParseFlags(fs, os.Args[2:])
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		exit(1)
+	}
+
+	var tunnels TunnelSet
+	models, err := flags.sourceConfig.FetchCatalog(&tunnels)
+	tunnels.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	columns, rows, err := RunCatalogQuery(models, fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	if flags.header {
+		fmt.Println(strings.Join(columns, "\t"))
+	}
+	for _, values := range rows {
+		fmt.Println(strings.Join(values, "\t"))
+	}
+}
+
+// watchFlags are the flags of llmls watch
+type watchFlags struct {
+	once         bool
+	watchConfig  WatchConfig
+	sourceConfig SourceConfig
+}
+
+// RegisterFlags declares the flags of llmls watch on fs
+func (f *watchFlags) RegisterFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&f.once, "once", false, "Check once and exit")
+	f.watchConfig.RegisterFlags(fs)
+	fs.StringVar(&f.watchConfig.Format, "format", FormatText, "Change format: text, or github for GitHub Actions annotations")
+	f.sourceConfig.RegisterFlags(fs)
+}
+
+func watchCommand() {
+	var flags watchFlags
+	fs := NewCommandFlagSet("watch", &flags)
+
+	ParseFlags(fs, os.Args[2:])
+
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Error: watch subcommand does not accept arguments\n\n")
+		fs.Usage()
+		exit(1)
+	}
+
+	if err := flags.watchConfig.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	if err := RunWatch(&flags.sourceConfig, flags.watchConfig, flags.once); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+}
+
+// daemonFlags are the flags of llmls daemon
+type daemonFlags struct {
+	printOnly    bool
+	watchConfig  WatchConfig
+	sourceConfig SourceConfig
+}
+
+// RegisterFlags declares the flags of llmls daemon on fs
+func (f *daemonFlags) RegisterFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&f.printOnly, "print", false, "With install, print the service definition instead of writing it")
+	f.watchConfig.RegisterFlags(fs)
+	f.sourceConfig.RegisterFlags(fs)
+}
+
+func daemonCommand() {
+	install := len(os.Args) > 2 && os.Args[2] == "install"
+	args := os.Args[2:]
+	name := "daemon"
+	if install {
+		args = os.Args[3:]
+		name = "daemon install"
+	}
+
+	var flags daemonFlags
+	fs := NewCommandFlagSet(name, &flags)
+
+	ParseFlags(fs, args)
+
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Error: unexpected argument: %s\n\n", fs.Arg(0))
+		fs.Usage()
+		exit(1)
+	}
+
+	if err := flags.watchConfig.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	if !install {
+		if flags.printOnly {
+			fmt.Fprintf(os.Stderr, "Error: --print is only valid with 'daemon install'\n")
+			exit(1)
+		}
+		RunWatch(&flags.sourceConfig, flags.watchConfig, false)
+		return
+	}
+
+	daemonArgs, err := DaemonArgs(fs, "print")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	path, content, err := ServiceFile(daemonArgs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	if flags.printOnly {
+		fmt.Print(content)
+		return
+	}
+
+	if err := InstallService(path, content); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	if dryRun {
+		return
+	}
+	fmt.Printf("Wrote %s\n", path)
+	fmt.Printf("Start it with: %s\n", ServiceEnableHint(path))
+}
+
+// feedFlags are the flags of llmls feed
+type feedFlags struct {
+	out   string
+	limit int
+}
+
+// RegisterFlags declares the flags of llmls feed on fs
+func (f *feedFlags) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&f.out, "out", "", "Write the feed to `FILE` (default: stdout)")
+	fs.IntVar(&f.limit, "limit", 50, "Maximum number of entries")
+}
+
+func feedCommand() {
+	var flags feedFlags
+	fs := NewCommandFlagSet("feed", &flags)
+
+	ParseFlags(fs, os.Args[2:])
+
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Error: feed subcommand does not accept arguments\n\n")
+		fs.Usage()
+		exit(1)
+	}
+
+	paths, err := ListSnapshotFiles()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	var snapshots []*Snapshot
+	for _, path := range paths {
+		snapshot, err := LoadSnapshot(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	updated := time.Now()
+	if len(snapshots) > 0 {
+		updated = snapshots[len(snapshots)-1].TakenAt
+	}
+
+	var feed bytes.Buffer
+	if err := WriteAtomFeed(&feed, CatalogEvents(snapshots), updated, flags.limit); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	// Feed readers polling the file never see a partial feed
+	if flags.out != "" {
+		err = writeFileAtomic(flags.out, feed.Bytes())
+	} else {
+		_, err = os.Stdout.Write(feed.Bytes())
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+}
+
+// reportFlags are the flags of llmls report
+type reportFlags struct {
+	since        time.Duration
+	mailConfig   MailConfig
+	sourceConfig SourceConfig
+}
+
+// RegisterFlags declares the flags of llmls report on fs
+func (f *reportFlags) RegisterFlags(fs *flag.FlagSet) {
+	fs.DurationVar(&f.since, "since", defaultReportPeriod, "Report changes over this period")
+	f.mailConfig.RegisterFlags(fs)
+	f.sourceConfig.RegisterFlags(fs)
+}
+
+func reportCommand() {
+	var flags reportFlags
+	fs := NewCommandFlagSet("report", &flags)
+
+	ParseFlags(fs, os.Args[2:])
+
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Error: report subcommand does not accept arguments\n\n")
+		fs.Usage()
+		exit(1)
+	}
+	if flags.since <= 0 {
+		fmt.Fprintf(os.Stderr, "Error: --since must be positive: %s\n", flags.since)
+		exit(1)
+	}
+	if err := flags.mailConfig.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	var tunnels TunnelSet
+	current, err := flags.sourceConfig.FetchSnapshot(&tunnels)
+	tunnels.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	report, err := BuildReport(current, flags.since)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	if flags.mailConfig.To == "" {
+		fmt.Print(report.Text())
+		return
+	}
+	if err := flags.mailConfig.Send(report.Subject(), report.Text()); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+}
+
+// manifestFlags are the flags of llmls manifest
+type manifestFlags struct {
+	modelsFile   string
+	out          string
+	sourceConfig SourceConfig
+}
+
+// RegisterFlags declares the flags of llmls manifest on fs
+func (f *manifestFlags) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&f.modelsFile, "models", "", "`FILE` with one model ID per line, - for stdin (required)")
+	fs.StringVar(&f.out, "output", "", "Output `FILE`, replaced atomically (default: stdout)")
+	fs.StringVar(&f.out, "o", "", "Output `FILE`, replaced atomically (default: stdout)")
+	f.sourceConfig.RegisterFlags(fs)
+}
+
+func manifestCommand() {
+	if len(os.Args) > 2 && os.Args[2] == "verify" {
+		manifestVerifyCommand()
+		return
+	}
+
+	var flags manifestFlags
+	fs := NewCommandFlagSet("manifest", &flags)
+
+	ParseFlags(fs, os.Args[2:])
+
+	if fs.NArg() > 0 || flags.modelsFile == "" {
+		fs.Usage()
+		exit(1)
+	}
+
+	input := os.Stdin
+	if flags.modelsFile != "-" {
+		f, err := os.Open(flags.modelsFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
+		}
+		defer f.Close()
+		input = f
+	}
+	ids, err := ReadModelIDs(input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	var tunnels TunnelSet
+	models, err := flags.sourceConfig.FetchCatalog(&tunnels)
+	tunnels.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	manifest, err := BuildManifest(ids, models, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	data, err := MarshalManifest(manifest)
+	if err == nil {
+		if flags.out == "" {
+			_, err = os.Stdout.Write(data)
+		} else {
+			err = writeFileAtomic(flags.out, data)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+}
+
+// Exit statuses of manifest verify
+const (
+	exitManifestDrift    = 2
+	exitManifestModified = 3
+)
+
+// manifestVerifyFlags are the flags of llmls manifest verify
+type manifestVerifyFlags struct {
+	maxIncrease  float64
+	format       string
+	sourceConfig SourceConfig
+}
+
+// RegisterFlags declares the flags of llmls manifest verify on fs
+func (f *manifestVerifyFlags) RegisterFlags(fs *flag.FlagSet) {
+	fs.Float64Var(&f.maxIncrease, "max-price-increase", 0, "Allowed price increase of `PCT` percent before failing")
+	fs.StringVar(&f.format, "format", FormatText, "Result format: text, or github for GitHub Actions annotations")
+	f.sourceConfig.RegisterFlags(fs)
+}
+
+func manifestVerifyCommand() {
+	var flags manifestVerifyFlags
+	fs := NewCommandFlagSet("manifest verify", &flags)
+
+	ParseFlags(fs, os.Args[3:])
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		exit(1)
+	}
+	if flags.maxIncrease < 0 {
+		fmt.Fprintf(os.Stderr, "Error: --max-price-increase must not be negative\n")
+		exit(1)
+	}
+	if err := ValidateResultFormat(flags.format); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	path := fs.Arg(0)
+	manifest, err := LoadManifest(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	digest, err := manifest.ComputeDigest()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	modified := digest != manifest.Digest
+
+	var tunnels TunnelSet
+	models, err := flags.sourceConfig.FetchCatalog(&tunnels)
+	tunnels.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	drifts := VerifyManifest(manifest, models, flags.maxIncrease/100)
+	if flags.format == FormatGitHub {
+		annotations := ManifestDriftAnnotations(drifts, path)
+		if modified {
+			annotations = append(annotations, Annotation{Level: AnnotationError, Title: "Manifest modified", File: path,
+				Message: "digest does not match the models array; regenerate the manifest with llmls manifest"})
+		}
+		PrintAnnotations(annotations)
+	} else {
+		DisplayManifestDrifts(drifts, len(manifest.Models))
+		if modified {
+			fmt.Fprintf(os.Stderr, "Warning: %s was edited after it was generated: its digest does not match the models array\n", path)
+		}
+	}
+
+	if modified {
+		exit(exitManifestModified)
+	}
+	for _, d := range drifts {
+		if d.Failing {
+			exit(exitManifestDrift)
+		}
+	}
+}
+
+func cacheCommand() {
+	fs := NewCommandFlagSet("cache", nil)
+
+	ParseFlags(fs, os.Args[2:])
+
+	if fs.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "Error: cache subcommand requires an action (warm or clear)\n\n")
+		fs.Usage()
+		exit(1)
+	}
+
+	switch fs.Arg(0) {
+	case "warm":
+		failed := false
+		for _, result := range WarmCache() {
+			if result.Error != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s: %v\n", result.URL, result.Error)
+				failed = true
+				continue
+			}
+			fmt.Printf("%s: %d models (from %s)\n", result.URL, result.Models, result.From)
+		}
+		if failed {
+			exit(1)
+		}
+	case "clear":
+		if err := ClearCache(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown cache action: %s\n\n", fs.Arg(0))
+		fs.Usage()
+		exit(1)
+	}
+}
+
+// serveFlags are the flags of llmls serve
+type serveFlags struct {
+	addr       string
+	grpcAddr   string
+	openAPI    bool
+	corsOrigin string
+	rateLimit  string
+}
+
+// RegisterFlags declares the flags of llmls serve on fs
+func (f *serveFlags) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&f.addr, "addr", defaultServeAddr, "Address to listen on")
+	fs.StringVar(&f.grpcAddr, "grpc-addr", "", "Address to serve the gRPC API of proto/llmls/v1 on, e.g. :9090 (default: off)")
+	fs.BoolVar(&f.openAPI, "openapi", false, "Print the OpenAPI document and exit")
+	fs.StringVar(&f.corsOrigin, "cors-origin", "", "Origins allowed to call the API from browsers, comma-separated\n(e.g. https://dash.example.com; * allows any)")
+	fs.StringVar(&f.rateLimit, "rate-limit", "", "Requests per minute per client address: a number for every route,\nor path=rpm per route (e.g. "+cacheEndpoint+"=60,"+openAPIEndpoint+"=10)")
+}
+
+func serveCommand() {
+	var flags serveFlags
+	fs := NewCommandFlagSet("serve", &flags)
+
+	ParseFlags(fs, os.Args[2:])
+
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Error: serve subcommand does not accept arguments\n\n")
+		fs.Usage()
+		exit(1)
+	}
+	if flags.openAPI {
+		doc, err := OpenAPIDocument()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
+		}
+		fmt.Println(string(doc))
+		return
+	}
+	if _, err := GetCacheTTL(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	origins, err := ParseCORSOrigins(flags.corsOrigin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	limits, err := ParseServeRateLimits(flags.rateLimit)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	opts := ServeOptions{
+		CORSOrigins: origins,
+		Token:       GetServeToken(),
+		RateLimits:  limits,
+	}
+
+	if flags.grpcAddr != "" {
+		fmt.Fprintf(os.Stderr, "Serving the gRPC API on %s\n", flags.grpcAddr)
+		go func() {
+			if err := ServeGRPC(flags.grpcAddr, opts); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+		}()
+	}
+	fmt.Fprintf(os.Stderr, "Serving the catalog cache on %s\n", flags.addr)
+	if err := Serve(flags.addr, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+}
+
+// askFlags are the flags of llmls ask
+type askFlags struct {
+	maxTokens   int
+	temperature string
+	system      string
+	via         string
+	quiet       bool
+	ollamaHost  string
+}
+
+// RegisterFlags declares the flags of llmls ask on fs
+func (f *askFlags) RegisterFlags(fs *flag.FlagSet) {
+	fs.IntVar(&f.maxTokens, "max-tokens", 0, "Maximum reply tokens (default: provider default)")
+	fs.StringVar(&f.temperature, "temperature", "", "Sampling temperature, e.g. 0.2")
+	fs.StringVar(&f.system, "system", "", "System prompt")
+	fs.StringVar(&f.via, "via", ChatViaAuto, "Route: "+strings.Join(chatVias, ", "))
+	fs.BoolVar(&f.quiet, "quiet", false, "Do not print latency, token, and cost statistics")
+	fs.StringVar(&f.ollamaHost, "ollama-host", "", "Ollama server URL (default: $OLLAMA_HOST or http://localhost:11434)")
+}
+
+func askCommand() {
+	var flags askFlags
+	fs := NewCommandFlagSet("ask", &flags)
+
+	ParseFlags(fs, os.Args[2:])
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		exit(1)
+	}
+	if err := ValidateChatVia(flags.via); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	modelID := fs.Arg(0)
+
+	temp, err := ParseTemperature(flags.temperature)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	req := ChatRequest{Model: modelID, MaxTokens: flags.maxTokens, Temperature: temp}
+
+	prompt := strings.Join(fs.Args()[1:], " ")
+	if prompt == "" || prompt == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to read prompt: %v\n", err)
+			exit(1)
+		}
+		prompt = string(data)
+	}
+	if strings.TrimSpace(prompt) == "" {
+		fmt.Fprintf(os.Stderr, "Error: empty prompt\n")
+		exit(1)
+	}
+	if flags.system != "" {
+		req.Messages = append(req.Messages, ChatMessage{Role: "system", Content: flags.system})
+	}
+	req.Messages = append(req.Messages, ChatMessage{Role: "user", Content: prompt})
+
+	var tunnels TunnelSet
+	defer tunnels.Close()
+	host := ""
+	if strings.HasPrefix(modelID, "ollama/") {
+		host = openOllamaHost(&tunnels, flags.ollamaHost)
+	}
+	backend, err := ResolveChatBackend(modelID, flags.via, host)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	// Check the ID against the catalog, which also provides prices for the cost
+	model := lookupChatModels([]string{modelID})[0]
+
+	result, err := backend.Chat(req, func(text string) { fmt.Print(text) })
+	if result.Text != "" && !strings.HasSuffix(result.Text, "\n") {
+		fmt.Println()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	if !flags.quiet {
+		cost, costOK := ChatCost(model, result)
+		fmt.Fprintf(os.Stderr, "%s\n", FormatChatStats(modelID, result, cost, costOK))
+	}
+}
+
+// testFlags are the flags of llmls test
+type testFlags struct {
+	suiteName    string
+	maxModels    int
+	via          string
+	modelType    string
+	rpm          string
+	sourceConfig SourceConfig
+}
+
+// RegisterFlags declares the flags of llmls test on fs
+func (f *testFlags) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&f.suiteName, "suite", "basic", "Prompt suite to run: "+strings.Join(SmokeTestSuiteNames(), ", "))
+	fs.IntVar(&f.maxModels, "max-models", 10, "Refuse to test more matching models than this (0 for no limit)")
+	fs.StringVar(&f.via, "via", ChatViaAuto, "Route: "+strings.Join(chatVias, ", "))
+	fs.StringVar(&f.modelType, "type", catalog.TypeChat, "Models to test: chat (prompt suite) or embedding")
+	fs.StringVar(&f.rpm, "rpm", "", "Requests per minute per API, e.g. openrouter=20,anthropic=10 (default: $LLMLS_RPM)")
+	f.sourceConfig.RegisterFlags(fs)
+}
+
+func testCommand() {
+	var flags testFlags
+	fs := NewCommandFlagSet("test", &flags)
+
+	ParseFlags(fs, os.Args[2:])
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		exit(1)
+	}
+	pattern := fs.Arg(0)
+	if flags.modelType != catalog.TypeChat && flags.modelType != catalog.TypeEmbedding {
+		fmt.Fprintf(os.Stderr, "Error: unsupported --type: %s (expected chat or embedding)\n", flags.modelType)
+		exit(1)
+	}
+	if err := InitScheduler(flags.rpm); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	suite, ok := smokeTestSuites[flags.suiteName]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: unknown suite: %s (available: %s)\n", flags.suiteName, strings.Join(SmokeTestSuiteNames(), ", "))
+		exit(1)
+	}
+	if err := ValidateChatVia(flags.via); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	var tunnels TunnelSet
+	defer tunnels.Close()
+	models, err := flags.sourceConfig.FetchCatalog(&tunnels)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	filtered := catalog.FilterModels(models, pattern)
+	if flags.modelType == catalog.TypeEmbedding {
+		filtered = catalog.FilterModelsByType(filtered, catalog.TypeEmbedding)
+	}
+	if len(filtered) == 0 {
+		DisplayNoMatch(pattern, models)
+		exit(1)
+	}
+	// Each model costs a few requests; guard against a pattern like "*"
+	if flags.maxModels > 0 && len(filtered) > flags.maxModels {
+		fmt.Fprintf(os.Stderr, "Error: %d models match %s; narrow the pattern or raise --max-models\n", len(filtered), pattern)
+		exit(1)
+	}
+
+	ollamaHost := ""
+	if flags.modelType == catalog.TypeEmbedding {
+		var checks []EmbeddingCheck
+		failed := false
+		for _, model := range filtered {
+			if strings.HasPrefix(model.ID, "ollama/") && ollamaHost == "" {
+				ollamaHost = openOllamaHost(&tunnels, flags.sourceConfig.OllamaHost)
+			}
+			backend, err := ResolveEmbeddingBackend(model.ID, flags.via, ollamaHost)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+			debugf("checking embeddings of %s via %s", model.ID, backend.Name)
+			check := CheckEmbeddings(model, backend)
+			failed = failed || check.Outcome != TestPass
+			checks = append(checks, check)
+		}
+		DisplayEmbeddingChecks(checks)
+		if failed {
+			exit(1)
+		}
+		return
+	}
+
+	var reports []SmokeTestReport
+	for _, model := range filtered {
+		if strings.HasPrefix(model.ID, "ollama/") && ollamaHost == "" {
+			ollamaHost = openOllamaHost(&tunnels, flags.sourceConfig.OllamaHost)
+		}
+		backend, err := ResolveChatBackend(model.ID, flags.via, ollamaHost)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
+		}
+		debugf("testing %s via %s", model.ID, backend.Name)
+		reports = append(reports, RunSmokeTests(model, backend, suite))
+	}
+
+	DisplaySmokeTests(reports, suite)
+	for _, r := range reports {
+		if !r.Passed() {
+			exit(1)
+		}
+	}
+}
+
+// lookupChatModels finds chat targets in the OpenRouter catalog, exiting with
+// suggestions for unknown IDs; ollama/ models are not in the catalog and get
+// a Model with only the ID
+func lookupChatModels(modelIDs []string) []catalog.Model {
+	var known []catalog.Model
+	found := make([]catalog.Model, len(modelIDs))
+	for i, id := range modelIDs {
+		found[i].ID = id
+		if strings.HasPrefix(id, "ollama/") {
+			continue
+		}
+		if known == nil {
+			var err error
+			if known, err = FetchModels(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				exit(1)
+			}
+		}
+		ok := false
+		for _, m := range known {
+			if m.ID == id {
+				found[i], ok = m, true
+				break
+			}
+		}
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Error: model not found: %s\n", id)
+			if suggestions := SuggestModelIDs(id, known, maxSuggestions); len(suggestions) > 0 {
+				fmt.Fprintf(os.Stderr, "Did you mean:\n")
+				for _, s := range suggestions {
+					fmt.Fprintf(os.Stderr, "  %s\n", s)
+				}
+			}
+			exit(1)
+		}
+	}
+	return found
+}
+
+// duelFlags are the flags of llmls duel
+type duelFlags struct {
+	promptFile  string
+	diff        bool
+	maxTokens   int
+	temperature string
+	system      string
+	via         string
+	ollamaHost  string
+	rpm         string
+}
+
+// RegisterFlags declares the flags of llmls duel on fs
+func (f *duelFlags) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&f.promptFile, "prompt-file", "", "File with one prompt per line (- for stdin)")
+	fs.BoolVar(&f.diff, "diff", false, "Show the second reply as a line diff against the first")
+	fs.IntVar(&f.maxTokens, "max-tokens", 0, "Maximum reply tokens (default: provider default)")
+	fs.StringVar(&f.temperature, "temperature", "", "Sampling temperature, e.g. 0.2")
+	fs.StringVar(&f.system, "system", "", "System prompt")
+	fs.StringVar(&f.via, "via", ChatViaAuto, "Route: "+strings.Join(chatVias, ", "))
+	fs.StringVar(&f.ollamaHost, "ollama-host", "", "Ollama server URL (default: $OLLAMA_HOST or http://localhost:11434)")
+	fs.StringVar(&f.rpm, "rpm", "", "Requests per minute per API, e.g. openrouter=20,anthropic=10 (default: $LLMLS_RPM)")
+}
+
+func duelCommand() {
+	var flags duelFlags
+	fs := NewCommandFlagSet("duel", &flags)
+
+	ParseFlags(fs, os.Args[2:])
+
+	if fs.NArg() < 2 {
+		fs.Usage()
+		exit(1)
+	}
+	if err := ValidateChatVia(flags.via); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	temp, err := ParseTemperature(flags.temperature)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	if err := InitScheduler(flags.rpm); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	var prompts []string
+	switch {
+	case flags.promptFile == "-":
+		prompts, err = ReadPrompts(os.Stdin)
+	case flags.promptFile != "":
+		f, openErr := os.Open(flags.promptFile)
+		if openErr != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", openErr)
+			exit(1)
+		}
+		prompts, err = ReadPrompts(f)
+		f.Close()
+	case fs.NArg() > 2:
+		prompts = []string{strings.Join(fs.Args()[2:], " ")}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	if len(prompts) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: no prompts; give --prompt-file or a prompt argument\n")
+		exit(1)
+	}
+
+	var tunnels TunnelSet
+	defer tunnels.Close()
+	var contenders [2]DuelContender
+	for i, model := range lookupChatModels(fs.Args()[:2]) {
+		host := ""
+		if strings.HasPrefix(model.ID, "ollama/") {
+			host = openOllamaHost(&tunnels, flags.ollamaHost)
+		}
+		backend, err := ResolveChatBackend(model.ID, flags.via, host)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
+		}
+		contenders[i] = DuelContender{Model: model, Backend: backend}
+	}
+
+	for i, prompt := range prompts {
+		req := ChatRequest{MaxTokens: flags.maxTokens, Temperature: temp}
+		if flags.system != "" {
+			req.Messages = append(req.Messages, ChatMessage{Role: "system", Content: flags.system})
+		}
+		req.Messages = append(req.Messages, ChatMessage{Role: "user", Content: prompt})
+		round := RunDuelRound(&contenders, req)
+		DisplayDuelRound(round, i+1, len(prompts), contenders, flags.diff)
+	}
+	DisplayDuelTotals(contenders)
+}
+
+// cheapestFlags are the flags of llmls cheapest
+type cheapestFlags struct {
+	blend        string
+	systemFile   string
+	images       int
+	audioMinutes float64
+	sourceConfig SourceConfig
+}
+
+// RegisterFlags declares the flags of llmls cheapest on fs
+func (f *cheapestFlags) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&f.blend, "blend", "", "Input:output token ratio for the blended price, e.g. 3:1 (default: 1:1)")
+	fs.StringVar(&f.systemFile, "system-file", "", "Show what the system prompt in `FILE`, sent with every request, costs per\n1K requests, with tokens estimated for the model's tokenizer, and rank\nsources by that cost first")
+	fs.IntVar(&f.images, "images", 0, "Show what `N` images sent with every request cost per 1K requests")
+	fs.Float64Var(&f.audioMinutes, "audio-minutes", 0, "Show what `M` minutes of audio sent with every request cost per 1K\nrequests, at ~"+FormatNumber(audioTokensPerMinute)+" audio tokens per minute")
+	f.sourceConfig.RegisterFlags(fs)
+}
+
+func cheapestCommand() {
+	var flags cheapestFlags
+	fs := NewCommandFlagSet("cheapest", &flags)
+
+	ParseFlags(fs, os.Args[2:])
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		exit(1)
+	}
+
+	ratio := catalog.DefaultBlend
+	if flags.blend != "" {
+		var err error
+		ratio, err = catalog.ParseBlend(flags.blend)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
+		}
+	}
+
+	if flags.images < 0 || flags.audioMinutes < 0 {
+		fmt.Fprintf(os.Stderr, "Error: --images and --audio-minutes must not be negative\n")
+		exit(1)
+	}
+
+	var system []byte
+	if flags.systemFile != "" {
+		var err error
+		system, err = os.ReadFile(flags.systemFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
+		}
+	}
+
+	var tunnels TunnelSet
+	models, err := flags.sourceConfig.FetchCatalog(&tunnels)
+	tunnels.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	key, err := FindModelKey(models, fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	var overhead *SystemOverhead
+	if flags.systemFile != "" {
+		var matched []catalog.Model
+		for _, model := range models {
+			if DedupeKey(model) == key {
+				matched = append(matched, model)
+			}
+		}
+		o := NewSystemOverhead(flags.systemFile, string(system), matched)
+		overhead = &o
+	}
+	media := MediaInput{Images: flags.images, AudioMinutes: flags.audioMinutes}
+	DisplayCheapest(key, CheapestSources(models, key, ratio, overhead, media), ratio, overhead, media)
+}
+
+// graphFlags are the flags of llmls graph
+type graphFlags struct {
+	xName        string
+	yName        string
+	where        string
+	blend        string
+	labels       int
+	height       int
+	linear       bool
+	svg          string
+	sourceConfig SourceConfig
+}
+
+// RegisterFlags declares the flags of llmls graph on fs
+func (f *graphFlags) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&f.xName, "x", "context", "X `AXIS`: "+strings.Join(graphAxisNames, ", ")+", or a computed column")
+	fs.StringVar(&f.yName, "y", "price", "Y `AXIS`: "+strings.Join(graphAxisNames, ", ")+", or a computed column")
+	fs.StringVar(&f.where, "where", "", "Only plot models matching an expression, as in the listing")
+	fs.StringVar(&f.blend, "blend", "", "Input:output token ratio for the blended price, e.g. 3:1 (default: 1:1)")
+	fs.IntVar(&f.labels, "labels", 5, "Number of outliers to label")
+	fs.IntVar(&f.height, "height", 20, "Plot height in lines")
+	fs.BoolVar(&f.linear, "linear", false, "Use linear scales for context and price (default: log)")
+	fs.StringVar(&f.svg, "svg", "", "Write the plot to an SVG `FILE` instead of the terminal")
+	f.sourceConfig.RegisterFlags(fs)
+}
+
+func graphCommand() {
+	var flags graphFlags
+	fs := NewCommandFlagSet("graph", &flags)
+
+	ParseFlags(fs, os.Args[2:])
+
+	if fs.NArg() > 1 || flags.height < 4 || flags.labels < 0 {
+		fs.Usage()
+		exit(1)
+	}
+
+	ratio := catalog.DefaultBlend
+	if flags.blend != "" {
+		var err error
+		ratio, err = catalog.ParseBlend(flags.blend)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
+		}
+	}
+	xAxis, err := GraphAxisFor(flags.xName, ratio, flags.linear)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	yAxis, err := GraphAxisFor(flags.yName, ratio, flags.linear)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	var whereFilter *catalog.WhereFilter
+	if flags.where != "" {
+		whereFilter, err = catalog.ParseWhere(flags.where, computedColumns)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --where expression: %v\n", err)
+			exit(1)
+		}
+	}
+
+	var tunnels TunnelSet
+	models, err := flags.sourceConfig.FetchCatalog(&tunnels)
+	tunnels.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	models = catalog.FilterModelsWhere(catalog.FilterModels(models, fs.Arg(0)), whereFilter)
+
+	graph := NewGraph(models, xAxis, yAxis, flags.labels)
+	if len(graph.Points) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: no models to plot (%d matched, none with both %s and %s)\n", len(models), flags.xName, flags.yName)
+		exit(1)
+	}
+	if graph.Skipped > 0 {
+		fmt.Fprintf(os.Stderr, "%d of %d models have no %s or %s value to plot\n", graph.Skipped, len(models), flags.xName, flags.yName)
+	}
+
+	if flags.svg != "" {
+		if err := writeFileAtomic(flags.svg, graph.SVG()); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
+		}
+		return
+	}
+	fmt.Print(graph.Render(GetTerminalWidth()-1, flags.height))
+}
+
+// timelineFlags are the flags of llmls timeline
+type timelineFlags struct {
+	since        string
+	top          int
+	where        string
+	svg          string
+	sourceConfig SourceConfig
+}
+
+// RegisterFlags declares the flags of llmls timeline on fs
+func (f *timelineFlags) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&f.since, "since", "", "First month to chart, as `YYYY-MM` (default: the first release)")
+	fs.IntVar(&f.top, "top", 12, "Number of providers to chart; the rest are grouped as other\n(0 for all)")
+	fs.StringVar(&f.where, "where", "", "Only chart models matching an expression, as in the listing")
+	fs.StringVar(&f.svg, "svg", "", "Write the chart to an SVG `FILE` instead of the terminal")
+	f.sourceConfig.RegisterFlags(fs)
+}
+
+func timelineCommand() {
+	var flags timelineFlags
+	fs := NewCommandFlagSet("timeline", &flags)
+
+	ParseFlags(fs, os.Args[2:])
+
+	if fs.NArg() > 1 || flags.top < 0 {
+		fs.Usage()
+		exit(1)
+	}
+
+	var sinceMonth time.Time
+	var err error
+	if flags.since != "" {
+		sinceMonth, err = ParseTimelineMonth(flags.since)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
+		}
+	}
+	var whereFilter *catalog.WhereFilter
+	if flags.where != "" {
+		whereFilter, err = catalog.ParseWhere(flags.where, computedColumns)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --where expression: %v\n", err)
+			exit(1)
+		}
+	}
+
+	var tunnels TunnelSet
+	models, err := flags.sourceConfig.FetchCatalog(&tunnels)
+	tunnels.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	models = catalog.FilterModelsWhere(catalog.FilterModels(models, fs.Arg(0)), whereFilter)
+
+	timeline := NewTimeline(models, sinceMonth, flags.top)
+	if len(timeline.Months) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: no releases to chart (%d models matched)\n", len(models))
+		exit(1)
+	}
+
+	if flags.svg != "" {
+		if err := writeFileAtomic(flags.svg, timeline.SVG()); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exit(1)
+		}
+		return
+	}
+	fmt.Print(timeline.Render(GetTerminalWidth()))
+}
+
+// schemaFlags are the flags of llmls schema
+type schemaFlags struct {
+	list bool
+}
+
+// RegisterFlags declares the flags of llmls schema on fs
+func (f *schemaFlags) RegisterFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&f.list, "list", false, "List the outputs with a schema")
+}
+
+func schemaCommand() {
+	var flags schemaFlags
+	fs := NewCommandFlagSet("schema", &flags)
+
+	ParseFlags(fs, os.Args[2:])
+
+	if fs.NArg() > 1 {
+		fs.Usage()
+		exit(1)
+	}
+
+	if flags.list {
+		for _, s := range outputSchemas {
+			fmt.Printf("%-12s %s\n", s.Name, s.Description)
+		}
+		return
+	}
+
+	name := "models"
+	if fs.NArg() == 1 {
+		name = fs.Arg(0)
+	}
+	output, err := FindOutputSchema(name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	schema, err := output.Schema()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	fmt.Println(string(schema))
+}
+
+// matrixFlags are the flags of llmls matrix
+type matrixFlags struct {
+	features     string
+	modelType    string
+	where        string
+	markdown     bool
+	colorMode    string
+	sourceConfig SourceConfig
+}
+
+// RegisterFlags declares the flags of llmls matrix on fs
+func (f *matrixFlags) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&f.features, "features", "", "Comma-separated `LIST` of features to compare (default: "+strings.Join(defaultMatrixFeatures, ",")+")")
+	fs.StringVar(&f.modelType, "type", "", "Only compare models of a `TYPE`, e.g. chat")
+	fs.StringVar(&f.where, "where", "", "Only compare models matching an expression, as in the listing")
+	fs.BoolVar(&f.markdown, "markdown", false, "Print a Markdown table, e.g. for a wiki")
+	fs.StringVar(&f.colorMode, "color", "auto", "Colorize output: auto, always, never")
+	f.sourceConfig.RegisterFlags(fs)
+}
+
+func matrixCommand() {
+	var flags matrixFlags
+	fs := NewCommandFlagSet("matrix", &flags)
+
+	ParseFlags(fs, os.Args[2:])
+
+	if fs.NArg() > 1 {
+		fs.Usage()
+		exit(1)
+	}
+	columns, err := ParseFeatures(flags.features)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	if flags.modelType != "" && !catalog.IsModelType(flags.modelType) {
+		fmt.Fprintf(os.Stderr, "Error: unknown model type: %s\n", flags.modelType)
+		exit(1)
+	}
+	color, err := UseColor(flags.colorMode)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	var whereFilter *catalog.WhereFilter
+	if flags.where != "" {
+		whereFilter, err = catalog.ParseWhere(flags.where, computedColumns)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --where expression: %v\n", err)
+			exit(1)
+		}
+	}
+
+	var tunnels TunnelSet
+	models, err := flags.sourceConfig.FetchCatalog(&tunnels)
+	tunnels.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+	models = catalog.FilterModelsWhere(catalog.FilterModelsByType(catalog.FilterModels(models, fs.Arg(0)), flags.modelType), whereFilter)
+	if len(models) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: no models matched\n")
+		exit(1)
+	}
+	if err := catalog.SortModels(models, "provider,id", catalog.DefaultBlend, computedColumns); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		exit(1)
+	}
+
+	if flags.markdown {
+		RenderMatrixMarkdown(os.Stdout, models, columns)
+		return
+	}
+	RenderMatrix(os.Stdout, models, columns, color)
+}
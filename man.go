@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteManPage writes the llmls(1) man page in roff, from the same command,
+// option, and topic metadata as the help
+func WriteManPage(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, ".TH LLMLS 1 \"\" \"llmls %s\" \"User Commands\"\n", roffEscape(version))
+	fmt.Fprintf(bw, ".SH NAME\nllmls \\- list LLM models from OpenRouter, Ollama, llama.cpp, and TGI\n")
+	fmt.Fprintf(bw, ".SH SYNOPSIS\n.B llmls\n[\\fIoptions\\fR] [\\fIpattern\\fR]\n.br\n.B llmls\n\\fIsubcommand\\fR [\\fIoptions\\fR] [\\fIargs\\fR]\n")
+	fmt.Fprintf(bw, ".SH DESCRIPTION\n")
+	fmt.Fprintf(bw, "Without a subcommand, llmls lists the models matching \\fIpattern\\fR from every configured source, or all models without one.\n")
+	fmt.Fprintf(bw, "Flags may follow positional arguments; arguments after \\fB\\-\\-\\fR are all positional.\n")
+
+	fmt.Fprintf(bw, ".SH OPTIONS\n")
+	writeManOptions(bw, FlagOptions(ListingFlagSet()))
+	fmt.Fprintf(bw, ".SH GLOBAL OPTIONS\n")
+	fmt.Fprintf(bw, "Accepted before or after any subcommand.\n")
+	writeManOptions(bw, GlobalOptions())
+
+	fmt.Fprintf(bw, ".SH SUBCOMMANDS\n")
+	for _, command := range Commands() {
+		writeManCommand(bw, command)
+	}
+
+	for _, topic := range HelpTopics() {
+		fmt.Fprintf(bw, ".SH %s\n.nf\n%s.fi\n", strings.ToUpper(topic.Name), roffLines(topic.Text))
+		if options := TopicOptions(topic); len(options) > 0 {
+			fmt.Fprintf(bw, ".PP\nSee also ")
+			for i, option := range options {
+				if i > 0 {
+					fmt.Fprintf(bw, ", ")
+				}
+				fmt.Fprintf(bw, "\\fB%s\\fR", roffEscape(option.Flags))
+			}
+			fmt.Fprintf(bw, " under OPTIONS.\n")
+		}
+	}
+
+	fmt.Fprintf(bw, ".SH ENVIRONMENT\n")
+	for _, env := range EnvVars() {
+		fmt.Fprintf(bw, ".TP\n.B %s\n%s\n", env.Name, roffEscape(env.Help))
+	}
+	fmt.Fprintf(bw, ".TP\n.B LLMLS_<FLAG>, LLMLS_<SUBCOMMAND>_<FLAG>\nSet a flag, e.g. LLMLS_SORT=price or LLMLS_ENDPOINTS_SORT=price; the command line overrides them\n")
+	fmt.Fprintf(bw, ".SH FILES\n")
+	fmt.Fprintf(bw, ".TP\n.I ~/.config/llmls/config\nDefault invocation, profiles, and computed columns (see CONFIG)\n")
+	fmt.Fprintf(bw, ".TP\n.I ~/.config/llmls/enrich.d/\nEnrichment plugins\n")
+	fmt.Fprintf(bw, ".TP\n.I ~/.cache/llmls/\nCatalog cache and snapshots\n")
+	return bw.Flush()
+}
+
+// writeManOptions writes options as tagged paragraphs
+func writeManOptions(w io.Writer, options []HelpOption) {
+	for _, option := range options {
+		fmt.Fprintf(w, ".TP\n.B %s\n%s\n", roffEscape(option.Flags), roffEscape(strings.ReplaceAll(option.Help, "\n", " ")))
+	}
+}
+
+// writeManCommand writes the section of a subcommand and its actions, from
+// the same metadata as its help
+func writeManCommand(w io.Writer, command Command) {
+	fmt.Fprintf(w, ".SS %s\n%s\n", command.Name, roffEscape(strings.ReplaceAll(command.Summary, "\n", " ")))
+	var usage strings.Builder
+	for _, line := range strings.Split(command.Usage, "\n") {
+		usage.WriteString("llmls " + line + "\n")
+	}
+	fmt.Fprintf(w, ".PP\n.nf\n%s.fi\n", roffLines(usage.String()))
+	if command.Description != "" {
+		fmt.Fprintf(w, ".PP\n.nf\n%s.fi\n", roffLines(command.Description))
+	}
+	writeManOptions(w, FlagOptions(NewFlagSet(command.Name, command.Flags)))
+	if command.Notes != "" {
+		fmt.Fprintf(w, ".PP\n.nf\n%s.fi\n", roffLines(command.Notes))
+	}
+	for _, action := range command.Actions {
+		writeManCommand(w, action)
+	}
+}
+
+// roffEscape escapes text for a roff line: backslashes, and hyphens so that
+// flags are not hyphenated or turned into dashes
+func roffEscape(s string) string {
+	return strings.NewReplacer(`\`, `\e`, "-", `\-`).Replace(s)
+}
+
+// roffLines escapes preformatted text line by line, so that no line starts
+// with a roff control character
+func roffLines(s string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(s, "\n"), "\n") {
+		line = roffEscape(line)
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			line = `\&` + line
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// manifestSchemaVersion is the format version of model manifests
+const manifestSchemaVersion = 1
+
+// licenseNoAssertion is recorded when a source reports no license, as in SPDX
+const licenseNoAssertion = "NOASSERTION"
+
+// Manifest is an SBOM-style record of the models an application depends on,
+// meant to be committed alongside it for audit
+// Digest is the SHA-256 of the compact JSON of Models, so the manifest can be
+// signed and checked for edits without trusting the file
+type Manifest struct {
+	SchemaVersion int             `json:"schema_version"`
+	Generator     string          `json:"generator"`
+	GeneratedAt   time.Time       `json:"generated_at"`
+	Models        []ManifestEntry `json:"models"`
+	Digest        string          `json:"digest"`
+}
+
+// ManifestEntry records one model as the catalog described it when fetched
+type ManifestEntry struct {
+	ID            string          `json:"id"`
+	Provider      string          `json:"provider"`
+	Source        string          `json:"source"`  // openrouter, ollama, tgi, llamacpp, or replicate
+	Version       string          `json:"version"` // Ollama digest, llama.cpp model file, TGI dtype, or creation date
+	ContextLength int             `json:"context_length"`
+	Pricing       catalog.Pricing `json:"pricing"`
+	License       string          `json:"license"`
+	FetchedAt     time.Time       `json:"fetched_at"`
+}
+
+// ModelVersion returns the most specific version a source reports for a
+// model: a content digest for Ollama, the model file for llama.cpp, the
+// weights dtype for TGI, and the creation date for hosted models
+func ModelVersion(model catalog.Model) string {
+	switch {
+	case model.OllamaDetails != nil && model.OllamaDetails.Digest != "":
+		digest := model.OllamaDetails.Digest
+		if !strings.Contains(digest, ":") {
+			digest = "sha256:" + digest
+		}
+		return digest
+	case model.LlamaCppDetails != nil && model.LlamaCppDetails.ModelPath != "":
+		return path.Base(model.LlamaCppDetails.ModelPath)
+	case model.TGIDetails != nil && model.TGIDetails.Dtype != "":
+		return model.TGIDetails.Dtype
+	case model.Created > 0:
+		return time.Unix(model.Created, 0).UTC().Format(time.DateOnly)
+	}
+	return ""
+}
+
+// NewManifestEntry records a catalog model
+func NewManifestEntry(model catalog.Model, fetchedAt time.Time) ManifestEntry {
+	return ManifestEntry{
+		ID:            model.ID,
+		Provider:      catalog.ModelProvider(model),
+		Source:        catalog.ModelSourceName(model),
+		Version:       ModelVersion(model),
+		ContextLength: model.ContextLength,
+		Pricing:       model.Pricing,
+		License:       licenseNoAssertion, // No source reports licenses yet
+		FetchedAt:     fetchedAt.UTC(),
+	}
+}
+
+// BuildManifest records the models with the given IDs, in order
+// Every ID must be in the catalog; unknown IDs are returned in the error
+func BuildManifest(ids []string, models []catalog.Model, fetchedAt time.Time) (*Manifest, error) {
+	byID := make(map[string]catalog.Model, len(models))
+	for _, model := range models {
+		byID[model.ID] = model
+	}
+
+	fetchedAt = fetchedAt.UTC().Truncate(time.Second)
+	manifest := &Manifest{
+		SchemaVersion: manifestSchemaVersion,
+		Generator:     "llmls " + version,
+		GeneratedAt:   fetchedAt,
+		Models:        []ManifestEntry{},
+	}
+	var unknown []string
+	for _, id := range ids {
+		model, ok := byID[id]
+		if !ok {
+			unknown = append(unknown, id)
+			continue
+		}
+		manifest.Models = append(manifest.Models, NewManifestEntry(model, fetchedAt))
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("not in the catalog: %s (see llmls validate)", strings.Join(unknown, ", "))
+	}
+
+	digest, err := manifest.ComputeDigest()
+	if err != nil {
+		return nil, err
+	}
+	manifest.Digest = digest
+	return manifest, nil
+}
+
+// ComputeDigest returns the SHA-256 of the compact JSON of the entries
+func (m *Manifest) ComputeDigest() (string, error) {
+	data, err := json.Marshal(m.Models)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	return "sha256:" + textHash(string(data)), nil
+}
+
+// MarshalManifest returns the indented JSON of a manifest
+func MarshalManifest(m *Manifest) ([]byte, error) {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// Manifest drift kinds reported by manifest verify
+const (
+	DriftRemoved = "removed"
+	DriftPrice   = "price"
+	DriftContext = "context"
+	DriftVersion = "version"
+)
+
+// driftTitles are the annotation titles of drift kinds
+var driftTitles = map[string]string{
+	DriftRemoved: "Model removed",
+	DriftPrice:   "Price increase",
+	DriftContext: "Context length changed",
+	DriftVersion: "Version changed",
+}
+
+// ManifestDrift is a difference between a manifest entry and the live catalog
+type ManifestDrift struct {
+	ID      string
+	Kind    string
+	Detail  string
+	Failing bool // Removed models, price increases beyond the threshold, and context changes fail
+}
+
+// LoadManifest reads a manifest file
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+	if m.SchemaVersion > manifestSchemaVersion {
+		return nil, fmt.Errorf("manifest %s has schema version %d; this llmls reads up to %d", path, m.SchemaVersion, manifestSchemaVersion)
+	}
+	return &m, nil
+}
+
+// priceIncrease returns the relative increase from old to new per-token
+// prices, +Inf for a free model becoming paid
+func priceIncrease(old, new string) float64 {
+	o, n := catalog.ParsePrice(old), catalog.ParsePrice(new)
+	switch {
+	case n <= o:
+		return 0
+	case o == 0:
+		return math.Inf(1)
+	}
+	return (n - o) / o
+}
+
+// formatIncrease formats a relative increase as a percentage
+func formatIncrease(increase float64) string {
+	if math.IsInf(increase, 1) {
+		return "was free"
+	}
+	return fmt.Sprintf("+%.0f%%", increase*100)
+}
+
+// VerifyManifest re-resolves every manifest entry against the catalog and
+// reports removed models, prompt or completion price increases above
+// maxIncrease (a fraction, e.g. 0.1 for 10%), changed context lengths, and
+// changed versions, which do not fail
+func VerifyManifest(m *Manifest, models []catalog.Model, maxIncrease float64) []ManifestDrift {
+	byID := make(map[string]catalog.Model, len(models))
+	for _, model := range models {
+		byID[model.ID] = model
+	}
+
+	var drifts []ManifestDrift
+	for _, entry := range m.Models {
+		model, ok := byID[entry.ID]
+		if !ok {
+			drifts = append(drifts, ManifestDrift{ID: entry.ID, Kind: DriftRemoved, Detail: "no longer in the catalog", Failing: true})
+			continue
+		}
+
+		prices := []struct{ name, old, new string }{
+			{"prompt", entry.Pricing.Prompt, model.Pricing.Prompt},
+			{"completion", entry.Pricing.Completion, model.Pricing.Completion},
+		}
+		for _, p := range prices {
+			if increase := priceIncrease(p.old, p.new); increase > maxIncrease {
+				drifts = append(drifts, ManifestDrift{
+					ID:   entry.ID,
+					Kind: DriftPrice,
+					Detail: fmt.Sprintf("%s %s -> %s /1K (%s)", p.name,
+						FormatUSD(FormatPrice(p.old)), FormatUSD(FormatPrice(p.new)), formatIncrease(increase)),
+					Failing: true,
+				})
+			}
+		}
+
+		if model.ContextLength != entry.ContextLength {
+			drifts = append(drifts, ManifestDrift{
+				ID:      entry.ID,
+				Kind:    DriftContext,
+				Detail:  fmt.Sprintf("%s -> %s tokens", FormatNumber(entry.ContextLength), FormatNumber(model.ContextLength)),
+				Failing: true,
+			})
+		}
+		if current := ModelVersion(model); entry.Version != "" && current != entry.Version {
+			drifts = append(drifts, ManifestDrift{ID: entry.ID, Kind: DriftVersion, Detail: entry.Version + " -> " + current})
+		}
+	}
+	return drifts
+}
+
+// DisplayManifestDrifts prints one line per drift and a summary
+func DisplayManifestDrifts(drifts []ManifestDrift, total int) {
+	idWidth := 0
+	for _, d := range drifts {
+		idWidth = max(idWidth, len(d.ID))
+	}
+	drifted := make(map[string]bool)
+	for _, d := range drifts {
+		fmt.Printf("%-8s %-*s  %s\n", d.Kind, idWidth, d.ID, d.Detail)
+		if d.Failing {
+			drifted[d.ID] = true
+		}
+	}
+	fmt.Printf("%d of %d models drifted\n", len(drifted), total)
+}
+
+// ManifestDriftAnnotations annotates failing drifts as errors and version
+// changes as notices on the manifest file
+func ManifestDriftAnnotations(drifts []ManifestDrift, file string) []Annotation {
+	annotations := make([]Annotation, 0, len(drifts))
+	for _, d := range drifts {
+		level := AnnotationNotice
+		if d.Failing {
+			level = AnnotationError
+		}
+		annotations = append(annotations, Annotation{
+			Level:   level,
+			Title:   driftTitles[d.Kind],
+			File:    file,
+			Message: d.ID + ": " + d.Detail,
+		})
+	}
+	return annotations
+}
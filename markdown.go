@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// ANSI styles of rendered Markdown
+const (
+	ansiBold      = "\x1b[1m"
+	ansiBoldOff   = "\x1b[22m"
+	ansiUnderline = "\x1b[4m"
+	ansiUnderOff  = "\x1b[24m"
+)
+
+// Inline Markdown syntax handled by renderInline
+var (
+	mdLink      = regexp.MustCompile(`\[([^\]]+)\]\((https?://[^)\s]+)\)`)
+	mdAutolink  = regexp.MustCompile(`<(https?://[^>\s]+)>`)
+	mdCode      = regexp.MustCompile("`([^`]+)`")
+	mdStrong    = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	mdEmphasis  = regexp.MustCompile(`(^|[^\w*])[*_]([^*_\s][^*_]*?)[*_]($|[^\w*])`)
+	mdHeading   = regexp.MustCompile(`^#{1,6}\s+(.*?)\s*#*$`)
+	mdListItem  = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	ansiEscapes = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+)
+
+// visibleWidth returns the number of runes of s shown on a terminal,
+// ignoring ANSI escape sequences
+func visibleWidth(s string) int {
+	return utf8.RuneCountInString(ansiEscapes.ReplaceAllString(s, ""))
+}
+
+// markdownRenderer renders Markdown text for the terminal, collecting link
+// targets as numbered references
+type markdownRenderer struct {
+	color bool
+	refs  []string
+}
+
+// style wraps s in an ANSI style when color is enabled
+func (r *markdownRenderer) style(s, on, off string) string {
+	if !r.color {
+		return s
+	}
+	return on + s + off
+}
+
+// renderInline renders links, code spans, strong, and emphasis in a line
+// Links become their text and a reference number, e.g. "docs [1]"
+func (r *markdownRenderer) renderInline(text string) string {
+	// Keep code spans verbatim: swap them out before styling
+	var codes []string
+	text = mdCode.ReplaceAllStringFunc(text, func(m string) string {
+		codes = append(codes, mdCode.FindStringSubmatch(m)[1])
+		return fmt.Sprintf("\x00%d\x00", len(codes)-1)
+	})
+
+	text = mdLink.ReplaceAllStringFunc(text, func(m string) string {
+		parts := mdLink.FindStringSubmatch(m)
+		label, url := parts[1], parts[2]
+		if label == url {
+			return url
+		}
+		r.refs = append(r.refs, url)
+		return fmt.Sprintf("%s [%d]", r.style(label, ansiUnderline, ansiUnderOff), len(r.refs))
+	})
+	text = mdAutolink.ReplaceAllString(text, "$1")
+	text = mdStrong.ReplaceAllStringFunc(text, func(m string) string {
+		parts := mdStrong.FindStringSubmatch(m)
+		return r.style(parts[1]+parts[2], ansiBold, ansiBoldOff)
+	})
+	text = mdEmphasis.ReplaceAllStringFunc(text, func(m string) string {
+		parts := mdEmphasis.FindStringSubmatch(m)
+		return parts[1] + r.style(parts[2], ansiUnderline, ansiUnderOff) + parts[3]
+	})
+
+	for i, code := range codes {
+		text = strings.Replace(text, fmt.Sprintf("\x00%d\x00", i), code, 1)
+	}
+	return text
+}
+
+// markdownBlock is a paragraph, heading, or list item of the source text
+type markdownBlock struct {
+	text    string
+	heading bool
+	marker  string // List marker, "•" or "1.", for list items
+	indent  int    // Nesting level of list items
+}
+
+// parseMarkdownBlocks splits text into blocks; blank lines end paragraphs and
+// list items, and other lines continue the current block
+func parseMarkdownBlocks(text string) []markdownBlock {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	var blocks []markdownBlock
+	var current *markdownBlock
+	flush := func() {
+		if current != nil && strings.TrimSpace(current.text) != "" {
+			blocks = append(blocks, *current)
+		}
+		current = nil
+	}
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			flush()
+		case mdHeading.MatchString(trimmed):
+			flush()
+			blocks = append(blocks, markdownBlock{text: mdHeading.FindStringSubmatch(trimmed)[1], heading: true})
+		case mdListItem.MatchString(line) && !strings.HasPrefix(trimmed, "**"):
+			flush()
+			parts := mdListItem.FindStringSubmatch(line)
+			marker := parts[2]
+			if strings.ContainsAny(marker, "-*+") {
+				marker = "•"
+			}
+			current = &markdownBlock{text: parts[3], marker: marker, indent: len(parts[1]) / 2}
+		case current != nil:
+			current.text += " " + trimmed
+		default:
+			current = &markdownBlock{text: trimmed}
+		}
+	}
+	flush()
+	return blocks
+}
+
+// RenderMarkdown renders Markdown text as terminal lines of at most width
+// columns: paragraphs are wrapped and separated by blank lines, headings and
+// strong text are bold, emphasis and link text underlined (with color), list
+// items are bulleted with hanging indentation, and link targets are listed
+// as numbered references at the end
+func RenderMarkdown(text string, width int, color bool) []string {
+	r := &markdownRenderer{color: color}
+	var lines []string
+	previousItem := false
+	for i, block := range parseMarkdownBlocks(text) {
+		item := block.marker != ""
+		// Blank lines between blocks, except between items of one list
+		if i > 0 && !(item && previousItem) {
+			lines = append(lines, "")
+		}
+		previousItem = item
+
+		inline := r.renderInline(block.text)
+		switch {
+		case block.heading:
+			lines = append(lines, r.style(inline, ansiBold, ansiBoldOff))
+		case item:
+			// WrapText hangs continuation lines under the marker
+			indent := strings.Repeat("  ", block.indent)
+			lines = append(lines, WrapText(indent+block.marker+" "+inline, width)...)
+		default:
+			lines = append(lines, WrapText(inline, width)...)
+		}
+	}
+
+	if len(r.refs) > 0 {
+		lines = append(lines, "")
+		for i, url := range r.refs {
+			lines = append(lines, fmt.Sprintf("[%d] %s", i+1, url))
+		}
+	}
+	return lines
+}
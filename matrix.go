@@ -0,0 +1,238 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// Support is whether a model has a feature, as far as its source reports
+type Support int
+
+const (
+	SupportUnknown Support = iota // The source does not report it, e.g. local servers
+	SupportYes
+	SupportNo
+)
+
+// supportMarks are the matrix cells of each Support
+var supportMarks = map[Support]string{SupportUnknown: "?", SupportYes: "✓", SupportNo: "✗"}
+
+// supportColors are the ANSI 256-color codes of the matrix cells
+var supportColors = map[Support]int{SupportUnknown: 244, SupportYes: 46, SupportNo: 196}
+
+// Feature is a capability compared by llmls matrix
+type Feature struct {
+	Name        string
+	Description string
+	Check       func(catalog.Model) Support
+}
+
+// matrixFeatures are the features llmls matrix knows, in the default order
+var matrixFeatures = []Feature{
+	{"tools", "Tool (function) calling", paramFeature("tools")},
+	{"json_mode", "JSON mode (response_format)", paramFeature("response_format")},
+	{"structured_outputs", "JSON Schema constrained output", paramFeature("structured_outputs")},
+	{"reasoning", "Reasoning tokens", paramFeature("reasoning", "include_reasoning")},
+	{"vision", "Image input", modalityFeature("image")},
+	{"audio", "Audio input", modalityFeature("audio")},
+	{"streaming", "Streamed responses", streamingFeature},
+	{"caching", "Prompt caching (priced cache reads or writes)", cachingFeature},
+}
+
+// defaultMatrixFeatures are the columns of llmls matrix without --features
+var defaultMatrixFeatures = []string{"tools", "json_mode", "structured_outputs", "vision", "streaming"}
+
+// paramFeature checks for any of the request parameters in a model's
+// supported parameters
+func paramFeature(params ...string) func(catalog.Model) Support {
+	return func(model catalog.Model) Support {
+		if len(model.SupportedParameters) == 0 {
+			return SupportUnknown
+		}
+		for _, supported := range model.SupportedParameters {
+			for _, param := range params {
+				if supported == param {
+					return SupportYes
+				}
+			}
+		}
+		return SupportNo
+	}
+}
+
+// modalityFeature checks for an input modality, from input_modalities or the
+// input side of modality (e.g. text+image->text)
+func modalityFeature(modality string) func(catalog.Model) Support {
+	return func(model catalog.Model) Support {
+		arch := model.Architecture
+		if len(arch.InputModalities) == 0 && arch.Modality == "" {
+			return SupportUnknown
+		}
+		for _, m := range arch.InputModalities {
+			if m == modality {
+				return SupportYes
+			}
+		}
+		input, _, _ := strings.Cut(arch.Modality, "->")
+		for _, m := range strings.Split(input, "+") {
+			if m == modality {
+				return SupportYes
+			}
+		}
+		return SupportNo
+	}
+}
+
+// streamingFeature reports streaming for text generation models: every
+// source llmls lists streams chat and completion responses
+func streamingFeature(model catalog.Model) Support {
+	switch model.Type {
+	case catalog.TypeChat, catalog.TypeCompletion:
+		return SupportYes
+	case "":
+		return SupportUnknown
+	}
+	return SupportNo
+}
+
+// cachingFeature reports prompt caching from cache read/write pricing, which
+// only priced sources report
+func cachingFeature(model catalog.Model) Support {
+	if SupportsCaching(model) {
+		return SupportYes
+	}
+	if model.Pricing.Prompt == "" {
+		return SupportUnknown
+	}
+	return SupportNo
+}
+
+// ParseFeatures parses --features, e.g. "tools,json_mode,vision"
+func ParseFeatures(spec string) ([]Feature, error) {
+	names := defaultMatrixFeatures
+	if spec != "" {
+		names = strings.Split(spec, ",")
+	}
+
+	var features []Feature
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		feature, ok := findFeature(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown feature: %s (expected %s)", name, strings.Join(FeatureNames(), ", "))
+		}
+		features = append(features, feature)
+	}
+	if len(features) == 0 {
+		return nil, fmt.Errorf("no features given")
+	}
+	return features, nil
+}
+
+// findFeature returns the feature of a name
+func findFeature(name string) (Feature, bool) {
+	for _, feature := range matrixFeatures {
+		if feature.Name == name {
+			return feature, true
+		}
+	}
+	return Feature{}, false
+}
+
+// FeatureNames returns the names accepted by --features
+func FeatureNames() []string {
+	names := make([]string, len(matrixFeatures))
+	for i, feature := range matrixFeatures {
+		names[i] = feature.Name
+	}
+	return names
+}
+
+// matrixFeatureHelp returns the features section of the help of llmls matrix
+func matrixFeatureHelp() string {
+	var b strings.Builder
+	b.WriteString("Features:\n")
+	for _, feature := range matrixFeatures {
+		fmt.Fprintf(&b, "  %-18s %s\n", feature.Name, feature.Description)
+	}
+	return b.String()
+}
+
+// FilterModelsBySupport returns models supporting every one of features (see
+// --supports); models whose source does not report a feature are dropped
+// If features is empty, returns all models
+func FilterModelsBySupport(models []catalog.Model, features []Feature) []catalog.Model {
+	if len(features) == 0 {
+		return models
+	}
+
+	var filtered []catalog.Model
+	for _, model := range models {
+		supported := true
+		for _, feature := range features {
+			if feature.Check(model) != SupportYes {
+				supported = false
+				break
+			}
+		}
+		if supported {
+			filtered = append(filtered, model)
+		}
+	}
+	return filtered
+}
+
+// RenderMatrix writes a ✓/✗ table of features per model, with ? where the
+// model's source does not report a feature
+func RenderMatrix(w io.Writer, models []catalog.Model, features []Feature, color bool) {
+	idWidth := len("MODEL")
+	for _, model := range models {
+		idWidth = max(idWidth, len([]rune(model.ID)))
+	}
+
+	header := fmt.Sprintf("%-*s", idWidth, "MODEL")
+	for _, feature := range features {
+		header += "  " + feature.Name
+	}
+	fmt.Fprintln(w, strings.TrimRight(header, " "))
+
+	for _, model := range models {
+		row := fmt.Sprintf("%-*s", idWidth, model.ID)
+		for _, feature := range features {
+			support := feature.Check(model)
+			mark := supportMarks[support]
+			if color {
+				mark = colorize(mark, supportColors[support])
+			}
+			// Center the mark under the feature name
+			pad := len(feature.Name) - 1
+			row += "  " + strings.Repeat(" ", pad/2) + mark + strings.Repeat(" ", pad-pad/2)
+		}
+		fmt.Fprintln(w, strings.TrimRight(row, " "))
+	}
+}
+
+// RenderMatrixMarkdown writes the matrix as a Markdown table, e.g. for a wiki
+func RenderMatrixMarkdown(w io.Writer, models []catalog.Model, features []Feature) {
+	header := "| Model |"
+	rule := "| --- |"
+	for _, feature := range features {
+		header += " " + feature.Name + " |"
+		rule += " :---: |"
+	}
+	fmt.Fprintln(w, header)
+	fmt.Fprintln(w, rule)
+	for _, model := range models {
+		row := "| `" + model.ID + "` |"
+		for _, feature := range features {
+			row += " " + supportMarks[feature.Check(model)] + " |"
+		}
+		fmt.Fprintln(w, row)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// audioTokensPerMinute converts audio minutes to input tokens: OpenRouter
+// prices audio per input token, and Gemini, which most audio-priced models
+// are, counts 32 tokens per second of audio
+const audioTokensPerMinute = 32 * 60
+
+// MediaInput is the images and audio sent with every request, for costing
+// multimodal workloads with llmls cheapest
+type MediaInput struct {
+	Images       int
+	AudioMinutes float64
+}
+
+// Empty reports whether the input has no media
+func (m MediaInput) Empty() bool {
+	return m.Images == 0 && m.AudioMinutes == 0
+}
+
+// Cost returns the media price of one request; ok is false when the source
+// does not price a kind of media the input includes
+func (m MediaInput) Cost(pricing catalog.Pricing) (cost float64, ok bool) {
+	if m.Images > 0 {
+		if pricing.Image == "" {
+			return 0, false
+		}
+		cost += float64(m.Images) * catalog.ParsePrice(pricing.Image)
+	}
+	if m.AudioMinutes > 0 {
+		if pricing.Audio == "" {
+			return 0, false
+		}
+		cost += m.AudioMinutes * audioTokensPerMinute * catalog.ParsePrice(pricing.Audio)
+	}
+	return cost, true
+}
+
+// Support reports whether a model takes every kind of media in the input:
+// SupportNo if it lacks one, SupportUnknown if its source does not say
+func (m MediaInput) Support(model catalog.Model) Support {
+	var modalities []string
+	if m.Images > 0 {
+		modalities = append(modalities, "image")
+	}
+	if m.AudioMinutes > 0 {
+		modalities = append(modalities, "audio")
+	}
+	support := SupportYes
+	for _, modality := range modalities {
+		switch modalityFeature(modality)(model) {
+		case SupportNo:
+			return SupportNo
+		case SupportUnknown:
+			support = SupportUnknown
+		}
+	}
+	return support
+}
+
+// String describes the input, e.g. "2 images, 1.5 audio minutes"
+func (m MediaInput) String() string {
+	var parts []string
+	if m.Images > 0 {
+		parts = append(parts, FormatNumber(m.Images)+" image"+plural(m.Images))
+	}
+	if m.AudioMinutes > 0 {
+		minutes := "minutes"
+		if m.AudioMinutes == 1 {
+			minutes = "minute"
+		}
+		parts = append(parts, strconv.FormatFloat(m.AudioMinutes, 'f', -1, 64)+" audio "+minutes)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// plural returns "s" unless n is 1
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
+
+// HasMediaPricing reports whether a model prices image or audio input
+func HasMediaPricing(pricing catalog.Pricing) bool {
+	return catalog.ParsePrice(pricing.Image) > 0 || catalog.ParsePrice(pricing.Audio) > 0
+}
+
+// FormatMediaPricing renders image and audio input pricing, e.g.
+// "$0.0048 / image, $0.0768 / audio minute (~1,920 tokens)"
+func FormatMediaPricing(pricing catalog.Pricing) string {
+	var parts []string
+	if p := catalog.ParsePrice(pricing.Image); p > 0 {
+		parts = append(parts, FormatUSD(fmt.Sprintf("%.4f", p))+" / image")
+	}
+	if p := catalog.ParsePrice(pricing.Audio); p > 0 {
+		parts = append(parts, fmt.Sprintf("%s / audio minute (~%s tokens)",
+			FormatUSD(fmt.Sprintf("%.4f", p*audioTokensPerMinute)), FormatNumber(audioTokensPerMinute)))
+	}
+	return strings.Join(parts, ", ")
+}
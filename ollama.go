@@ -1,34 +1,14 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
 	"time"
 )
 
-// OllamaModel represents a model from Ollama API
-type OllamaModel struct {
-	Name       string    `json:"name"`
-	ModifiedAt time.Time `json:"modified_at"`
-	Size       int64     `json:"size"`
-	Digest     string    `json:"digest"`
-	Details    struct {
-		Format            string   `json:"format"`
-		Family            string   `json:"family"`
-		Families          []string `json:"families"`
-		ParameterSize     string   `json:"parameter_size"`
-		QuantizationLevel string   `json:"quantization_level"`
-	} `json:"details"`
-}
-
-// OllamaModelsResponse represents the Ollama API response
-type OllamaModelsResponse struct {
-	Models []OllamaModel `json:"models"`
-}
-
 // GetOllamaHost returns the Ollama host URL from flag, env var, or default
 func GetOllamaHost(flagHost string) string {
 	// Priority: 1. Flag, 2. Env var, 3. Default
@@ -41,94 +21,72 @@ func GetOllamaHost(flagHost string) string {
 	return "http://localhost:11434"
 }
 
-// FetchOllamaModels retrieves models from Ollama API
-// Returns empty slice if server is unavailable (silent fail)
-func FetchOllamaModels(host string) []Model {
-	url := host + "/api/tags"
-
-	client := &http.Client{
-		Timeout: 3 * time.Second, // Short timeout for local server
-	}
-
-	resp, err := client.Get(url)
+// ollamaRequest sends a JSON request to the Ollama API and checks the status
+func ollamaRequest(method, url string, payload interface{}) error {
+	data, err := json.Marshal(payload)
 	if err != nil {
-		// Silent fail - server not available
-		return []Model{}
+		return err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		// Silent fail - API error
-		return []Model{}
-	}
-
-	body, err := io.ReadAll(resp.Body)
+	req, err := http.NewRequest(method, url, bytes.NewReader(data))
 	if err != nil {
-		return []Model{}
+		return err
 	}
+	req.Header.Set("Content-Type", "application/json")
 
-	var ollamaResp OllamaModelsResponse
-	if err := json.Unmarshal(body, &ollamaResp); err != nil {
-		return []Model{}
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach Ollama: %w", err)
 	}
+	defer resp.Body.Close()
 
-	// Convert Ollama models to unified Model format
-	models := make([]Model, 0, len(ollamaResp.Models))
-	for _, om := range ollamaResp.Models {
-		model := Model{
-			ID:          "ollama/" + om.Name,
-			Name:        om.Name,
-			Created:     om.ModifiedAt.Unix(),
-			Description: buildOllamaDescription(om),
-			// Store Ollama-specific data for detailed view
-			OllamaDetails: &OllamaDetails{
-				Size:              om.Size,
-				Format:            om.Details.Format,
-				Family:            om.Details.Family,
-				ParameterSize:     om.Details.ParameterSize,
-				QuantizationLevel: om.Details.QuantizationLevel,
-			},
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		if apiErr.Error != "" {
+			return fmt.Errorf("ollama: %s", apiErr.Error)
 		}
-		models = append(models, model)
+		return fmt.Errorf("API returned status %d", resp.StatusCode)
 	}
+	return nil
+}
 
-	return models
+// DeleteOllamaModel removes a model from an Ollama server
+func DeleteOllamaModel(host, name string) error {
+	return ollamaRequest(http.MethodDelete, host+"/api/delete", map[string]string{"model": name, "name": name})
 }
 
-// buildOllamaDescription creates a description from Ollama model details
-func buildOllamaDescription(om OllamaModel) string {
-	desc := ""
+// CopyOllamaModel copies a model on an Ollama server under a new name
+func CopyOllamaModel(host, source, destination string) error {
+	return ollamaRequest(http.MethodPost, host+"/api/copy", map[string]string{"source": source, "destination": destination})
+}
 
-	if om.Details.Family != "" {
-		desc = om.Details.Family
+// PullOllamaModel downloads the latest version of a model to an Ollama server
+func PullOllamaModel(host, name string) error {
+	data, err := json.Marshal(map[string]interface{}{"model": name, "stream": false})
+	if err != nil {
+		return err
 	}
 
-	if om.Details.ParameterSize != "" {
-		if desc != "" {
-			desc += " "
-		}
-		desc += om.Details.ParameterSize
+	// Pulls download gigabytes, so no client timeout
+	resp, err := http.Post(host+"/api/pull", "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to reach Ollama: %w", err)
 	}
+	defer resp.Body.Close()
 
-	if om.Details.QuantizationLevel != "" {
-		if desc != "" {
-			desc += " "
-		}
-		desc += "(" + om.Details.QuantizationLevel + ")"
+	var result struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
 	}
-
-	// Add size information
-	sizeGB := float64(om.Size) / (1024 * 1024 * 1024)
-	if sizeGB > 0 {
-		if desc != "" {
-			desc += " - "
-		}
-		desc += fmt.Sprintf("%.1f GB", sizeGB)
+	json.NewDecoder(resp.Body).Decode(&result)
+	if result.Error != "" {
+		return fmt.Errorf("ollama: %s", result.Error)
 	}
-
-	if desc == "" {
-		desc = "Ollama local model"
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API returned status %d", resp.StatusCode)
 	}
-
-	return desc
+	return nil
 }
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// openAPIVersion is the OpenAPI version of the serve API document; 3.1 uses
+// the same JSON Schema dialect as llmls schema
+const openAPIVersion = "3.1.0"
+
+// openAPIDocument is the top level of an OpenAPI document, keeping the
+// metadata first
+type openAPIDocument struct {
+	OpenAPI    string                 `json:"openapi"`
+	Info       map[string]interface{} `json:"info"`
+	Paths      map[string]interface{} `json:"paths"`
+	Components map[string]interface{} `json:"components"`
+}
+
+// OpenAPIDocument returns the OpenAPI document of llmls serve, generated from
+// its routes and the schemas of their responses
+func OpenAPIDocument() ([]byte, error) {
+	schemas := make(map[string]interface{})
+	paths := make(map[string]interface{})
+	for _, route := range serveRoutes() {
+		content := map[string]interface{}{}
+		switch {
+		case route.Schema != "":
+			output, err := FindOutputSchema(route.Schema)
+			if err != nil {
+				return nil, err
+			}
+			content["schema"] = output.build(schemas, "#/components/schemas/")
+		case route.ContentType != "application/json":
+			content["schema"] = map[string]interface{}{"type": "string"}
+		default:
+			content["schema"] = map[string]interface{}{"type": "object"}
+		}
+
+		var parameters []interface{}
+		for _, param := range route.Params {
+			schema := map[string]interface{}{"type": "string"}
+			if param.Type != "" {
+				schema["type"] = param.Type
+			}
+			if len(param.Enum) > 0 {
+				schema["enum"] = param.Enum
+			}
+			parameters = append(parameters, map[string]interface{}{
+				"name":        param.Name,
+				"in":          "query",
+				"description": param.Description,
+				"required":    param.Required,
+				"schema":      schema,
+			})
+		}
+
+		responses := map[string]interface{}{
+			"200": map[string]interface{}{
+				"description": "OK",
+				"content":     map[string]interface{}{route.ContentType: content},
+			},
+			"405": errorResponse(http.StatusMethodNotAllowed),
+			"429": errorResponse(http.StatusTooManyRequests),
+		}
+		if !route.Public {
+			responses["401"] = errorResponse(http.StatusUnauthorized)
+		}
+		if len(route.Params) > 0 {
+			responses["400"] = errorResponse(http.StatusBadRequest)
+		}
+		for _, status := range route.Errors {
+			responses[strconv.Itoa(status)] = errorResponse(status)
+		}
+
+		operation := map[string]interface{}{
+			"operationId": route.OperationID,
+			"summary":     route.Summary,
+			"responses":   responses,
+		}
+		if len(parameters) > 0 {
+			operation["parameters"] = parameters
+		}
+		if !route.Public {
+			// The token is required only when the server sets LLMLS_SERVE_TOKEN
+			operation["security"] = []interface{}{
+				map[string]interface{}{"bearerAuth": []string{}},
+				map[string]interface{}{},
+			}
+		}
+		paths[route.Path] = map[string]interface{}{"get": operation}
+	}
+
+	doc := openAPIDocument{
+		OpenAPI: openAPIVersion,
+		Info: map[string]interface{}{
+			"title":   "llmls serve",
+			"version": version,
+		},
+		Paths: paths,
+		Components: map[string]interface{}{
+			"schemas": schemas,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{"type": "http", "scheme": "bearer"},
+			},
+		},
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// errorResponse describes a plain-text error response
+func errorResponse(status int) map[string]interface{} {
+	return map[string]interface{}{
+		"description": http.StatusText(status),
+		"content": map[string]interface{}{
+			"text/plain": map[string]interface{}{"schema": map[string]interface{}{"type": "string"}},
+		},
+	}
+}
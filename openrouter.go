@@ -1,158 +1,34 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
-	"os"
 	"regexp"
 	"sort"
 	"strings"
 	"time"
+	"unicode/utf8"
 
-	"golang.org/x/term"
+	"github.com/mkyutani/llmls/catalog"
 )
 
-const openRouterModelsURL = "https://openrouter.ai/api/v1/models"
-
-// Model represents an OpenRouter model
-type Model struct {
-	ID             string       `json:"id"`
-	Name           string       `json:"name"`
-	Created        int64        `json:"created"`
-	Description    string       `json:"description"`
-	ContextLength  int          `json:"context_length"`
-	Architecture   Architecture `json:"architecture"`
-	Pricing        Pricing      `json:"pricing"`
-	TopProvider    TopProvider  `json:"top_provider"`
-	OllamaDetails  *OllamaDetails `json:"-"` // Ollama-specific details (not from JSON)
+// FetchModels retrieves models from OpenRouter API, through the catalog cache
+func FetchModels() ([]catalog.Model, error) {
+	return NewCatalogClient(CachedGet).FetchOpenRouterModels()
 }
 
-// Architecture represents model architecture details
-type Architecture struct {
-	Modality        string   `json:"modality"`
-	InputModalities []string `json:"input_modalities"`
-	OutputModalities []string `json:"output_modalities"`
-	Tokenizer       string   `json:"tokenizer"`
+// FetchModelsLive retrieves models from OpenRouter API, bypassing the catalog cache
+func FetchModelsLive() ([]catalog.Model, error) {
+	return NewCatalogClient(fetchBody).FetchOpenRouterModels()
 }
 
-// Pricing represents model pricing information
-type Pricing struct {
-	Prompt            string `json:"prompt"`
-	Completion        string `json:"completion"`
-	Request           string `json:"request"`
-	Image             string `json:"image"`
-	WebSearch         string `json:"web_search"`
-	InternalReasoning string `json:"internal_reasoning"`
-}
-
-// TopProvider represents top provider details
-type TopProvider struct {
-	ContextLength        int  `json:"context_length"`
-	MaxCompletionTokens  int  `json:"max_completion_tokens"`
-	IsModerated          bool `json:"is_moderated"`
-}
-
-// OllamaDetails represents Ollama-specific model details
-type OllamaDetails struct {
-	Size              int64
-	Format            string
-	Family            string
-	ParameterSize     string
-	QuantizationLevel string
-}
-
-// ModelsResponse represents the API response structure
-type ModelsResponse struct {
-	Data []Model `json:"data"`
-}
-
-// globMatch performs case-insensitive glob pattern matching
-// Supports * (any sequence including /) and ? (single character including /)
-func globMatch(pattern, str string) bool {
-	// Convert pattern to regex
-	// Escape special regex characters except * and ?
-	regexPattern := regexp.QuoteMeta(pattern)
-	// Replace escaped glob wildcards with regex equivalents
-	regexPattern = strings.ReplaceAll(regexPattern, "\\*", ".*")
-	regexPattern = strings.ReplaceAll(regexPattern, "\\?", ".")
-	// Anchor pattern to match entire string
-	regexPattern = "^" + regexPattern + "$"
-
-	// Case-insensitive match
-	re, err := regexp.Compile("(?i)" + regexPattern)
-	if err != nil {
-		// If pattern is invalid, fall back to case-insensitive exact match
-		return strings.EqualFold(pattern, str)
-	}
-	return re.MatchString(str)
-}
-
-// FetchModels retrieves models from OpenRouter API
-func FetchModels() ([]Model, error) {
-	resp, err := http.Get(openRouterModelsURL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch models: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
-
-	var modelsResp ModelsResponse
-	if err := json.Unmarshal(body, &modelsResp); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON: %w", err)
-	}
-
-	return modelsResp.Data, nil
-}
-
-// FilterModels filters models by model ID using glob patterns
-// Supports * (any sequence) and ? (single character) in patterns
-// Also supports exact match against provider name (case-insensitive)
-// If pattern is empty, returns all models
-func FilterModels(models []Model, pattern string) []Model {
-	// If no pattern, return all models
-	if pattern == "" {
-		return models
-	}
-
-	var filtered []Model
-	for _, model := range models {
-		provider := ExtractProvider(model.ID)
-
-		// Match against:
-		// 1. Model ID (glob pattern)
-		// 2. Model name (glob pattern)
-		// 3. Provider name (exact match, case-insensitive)
-		if globMatch(pattern, model.ID) ||
-		   globMatch(pattern, model.Name) ||
-		   strings.EqualFold(pattern, provider) {
-			filtered = append(filtered, model)
-		}
-	}
-
-	return filtered
-}
-
-// SortModelsByCreatedDesc sorts models by creation date in descending order
-func SortModelsByCreatedDesc(models []Model) {
-	sort.Slice(models, func(i, j int) bool {
-		return models[i].Created > models[j].Created
-	})
-}
-
-// FormatDate converts Unix timestamp to YYYY-MM-DD format in local timezone
+// FormatDate converts Unix timestamp to the current locale's fixed-width date
+// format (YYYY-MM-DD by default) in local timezone, or "-" for 0
 func FormatDate(timestamp int64) string {
+	if timestamp == 0 {
+		return "-" // Not reported by the source
+	}
 	t := time.Unix(timestamp, 0)
-	return t.Format("2006-01-02")
+	return t.Format(currentLocale.ShortDate)
 }
 
 // TruncateDescription truncates description to maxLen characters, adding ".." if truncated
@@ -171,24 +47,6 @@ func TruncateDescription(desc string, maxLen int) string {
 	return string(runes[:maxLen]) + ".."
 }
 
-// ExtractProvider extracts provider name from model ID
-func ExtractProvider(modelID string) string {
-	if idx := strings.Index(modelID, "/"); idx > 0 {
-		return modelID[:idx]
-	}
-	return "Unknown"
-}
-
-// GetTerminalWidth returns the terminal width, or a default value if unavailable
-func GetTerminalWidth() int {
-	width, _, err := term.GetSize(int(os.Stdout.Fd()))
-	if err != nil {
-		// Default to 120 if terminal size cannot be determined
-		return 120
-	}
-	return width
-}
-
 // CalculateDescriptionWidth calculates the available width for description
 func CalculateDescriptionWidth(termWidth, modelWidth, providerWidth int) int {
 	// Column layout: modelID (1 space) provider (1 space) date (1 space) description
@@ -208,8 +66,52 @@ func CalculateDescriptionWidth(termWidth, modelWidth, providerWidth int) int {
 	return descWidth
 }
 
+// DisplayOptions controls the layout of the model list
+type DisplayOptions struct {
+	Wide         bool                        // Do not truncate descriptions to the terminal width
+	MaxWidths    map[string]int              // Maximum width per column (id, provider, desc)
+	TruncateLeft map[string]bool             // Columns truncated from the left, keeping the end visible
+	Ellipsis     string                      // Marker for truncated text (default "..")
+	Numbered     bool                        // Prefix each row with its 1-based position
+	ShowSeries   bool                        // Add a series column after the provider
+	ShowContext  bool                        // Add a context length column after the date
+	ShowPrice    bool                        // Add a price column (per 1K prompt/completion tokens) after the context
+	ShowValue    bool                        // Add a value column (tokens and context per dollar) after the price
+	ShowRank     bool                        // Add a popularity rank column after the value
+	Columns      []string                    // Computed columns (see computed.go) added after the value
+	Blend        *catalog.Blend              // Show a blended $/1M price instead of prompt/completion prices
+	Color        bool                        // Shade the price column from green (cheap) to red (expensive)
+	Incidents    map[string]ProviderIncident // Degraded providers annotated in the provider column
+}
+
+// blend returns the --blend ratio, or DefaultBlend when none was given
+func (o DisplayOptions) blend() catalog.Blend {
+	if o.Blend != nil {
+		return *o.Blend
+	}
+	return catalog.DefaultBlend
+}
+
+// truncate shortens a column value according to the options
+func (o DisplayOptions) truncate(column, s string, width int) string {
+	ellipsis := o.Ellipsis
+	if ellipsis == "" {
+		ellipsis = ".."
+	}
+	return TruncateColumn(s, width, o.TruncateLeft[column], ellipsis)
+}
+
+// ellipsisWidth returns the display width of the ellipsis marker
+func (o DisplayOptions) ellipsisWidth() int {
+	if o.Ellipsis == "" {
+		return 2
+	}
+	return len([]rune(o.Ellipsis))
+}
+
 // DisplayModels prints models in formatted output with dynamic column widths
-func DisplayModels(models []Model) {
+// On a narrow terminal each model takes two lines, with context and pricing on the second
+func DisplayModels(models []catalog.Model, opts DisplayOptions) {
 	if len(models) == 0 {
 		return
 	}
@@ -217,39 +119,287 @@ func DisplayModels(models []Model) {
 	// Get terminal width
 	termWidth := GetTerminalWidth()
 
+	// Apply per-column maximum widths to the model and provider columns
+	ids := make([]string, len(models))
+	providers := make([]string, len(models))
+	for i, model := range models {
+		ids[i] = opts.truncate("id", model.ID, opts.MaxWidths["id"])
+		provider := catalog.ModelProvider(model)
+		providers[i] = opts.truncate("provider", provider, opts.MaxWidths["provider"])
+		if incident, ok := opts.Incidents[provider]; ok {
+			providers[i] += " " + incident.Label()
+		}
+	}
+
 	// Calculate maximum widths for model and provider columns
 	maxModelWidth := 0
 	maxProviderWidth := 0
 
-	for _, model := range models {
-		if len(model.ID) > maxModelWidth {
-			maxModelWidth = len(model.ID)
+	for i := range models {
+		if w := utf8.RuneCountInString(ids[i]); w > maxModelWidth {
+			maxModelWidth = w
 		}
-		provider := ExtractProvider(model.ID)
-		if len(provider) > maxProviderWidth {
-			maxProviderWidth = len(provider)
+		if w := utf8.RuneCountInString(providers[i]); w > maxProviderWidth {
+			maxProviderWidth = w
+		}
+	}
+
+	// Series column (optional)
+	series := make([]string, len(models))
+	maxSeriesWidth := 0
+	if opts.ShowSeries {
+		for i, model := range models {
+			series[i] = catalog.ModelSeries(model)
+			if series[i] == "" {
+				series[i] = "-"
+			}
+			if len(series[i]) > maxSeriesWidth {
+				maxSeriesWidth = len(series[i])
+			}
+		}
+	}
+
+	// Price column (optional)
+	prices := make([]string, len(models))
+	maxPriceWidth := 0
+	var heat map[string]int
+	if opts.ShowPrice {
+		for i, model := range models {
+			if opts.Blend != nil {
+				prices[i] = FormatBlendedPrice(model, *opts.Blend)
+			} else {
+				prices[i] = FormatModelPrice(model)
+			}
+			if len(prices[i]) > maxPriceWidth {
+				maxPriceWidth = len(prices[i])
+			}
+		}
+		if opts.Color {
+			heat = PriceHeat(models)
+		}
+	}
+
+	// Context column (optional)
+	contexts := make([]string, len(models))
+	maxContextWidth := 0
+	if opts.ShowContext {
+		for i, model := range models {
+			contexts[i] = FormatContextLength(model.ContextLength)
+			maxContextWidth = max(maxContextWidth, len(contexts[i]))
+		}
+	}
+
+	// Value column (optional)
+	values := make([]string, len(models))
+	maxValueWidth := 0
+	if opts.ShowValue {
+		for i, model := range models {
+			values[i] = FormatModelValue(model, opts.blend())
+			if len(values[i]) > maxValueWidth {
+				maxValueWidth = len(values[i])
+			}
+		}
+	}
+
+	// Rank column (optional)
+	ranks := make([]string, len(models))
+	maxRankWidth := 0
+	if opts.ShowRank {
+		for i, model := range models {
+			ranks[i] = FormatRank(model.PopularityRank)
+			maxRankWidth = max(maxRankWidth, len(ranks[i]))
+		}
+	}
+
+	// Computed columns (optional)
+	computed := make([][]string, len(opts.Columns))
+	computedWidths := make([]int, len(opts.Columns))
+	for c, name := range opts.Columns {
+		column, _ := computedColumns.Lookup(name)
+		computed[c] = make([]string, len(models))
+		computedWidths[c] = len(name)
+		for i, model := range models {
+			computed[c][i] = FormatComputedValue(column.Value(model))
+			computedWidths[c] = max(computedWidths[c], len(computed[c][i]))
 		}
 	}
 
 	// Calculate available width for description
 	descWidth := CalculateDescriptionWidth(termWidth, maxModelWidth, maxProviderWidth)
+	if opts.ShowSeries {
+		descWidth -= maxSeriesWidth + 1
+	}
+	if opts.ShowContext {
+		descWidth -= maxContextWidth + 1
+	}
+	if opts.ShowPrice {
+		descWidth -= maxPriceWidth + 1
+	}
+	if opts.ShowValue {
+		descWidth -= maxValueWidth + 1
+	}
+	if opts.ShowRank {
+		descWidth -= maxRankWidth + 1
+	}
+	for _, width := range computedWidths {
+		descWidth -= width + 1
+	}
+	numberWidth := len(fmt.Sprint(len(models)))
+	if opts.Numbered {
+		descWidth -= numberWidth + 1
+	}
+	if opts.Wide {
+		descWidth = 0 // No limit
+	}
+	// The computed width may extend by the ellipsis width (see CalculateDescriptionWidth),
+	// but --max-width desc=N is exact
+	descLimit, descLimited := opts.MaxWidths["desc"]
+	if descLimited && (descWidth == 0 || descLimit < descWidth) {
+		descWidth = descLimit
+	} else if descWidth > 0 {
+		descWidth += opts.ellipsisWidth()
+	}
+
+	// Unknown dates ("-") are padded to the width of a known one
+	dateWidth := len([]rune(FormatDate(1)))
+
+	// Switch to two lines per model when a row would wrap on the terminal
+	rowWidth := maxModelWidth + maxProviderWidth + 10 + 3 + descWidth
+	twoLine := !opts.Wide && StdoutIsTerminal() && rowWidth > termWidth
 
 	// Display each model with dynamic column widths
-	for _, model := range models {
-		provider := ExtractProvider(model.ID)
-		date := FormatDate(model.Created)
-		desc := TruncateDescription(model.Description, descWidth)
+	for i, model := range models {
+		if opts.Numbered {
+			fmt.Printf("%*d ", numberWidth, i+1)
+		}
+
+		date := fmt.Sprintf("%-*s", dateWidth, FormatDate(model.Created))
+		if opts.ShowContext {
+			date += fmt.Sprintf(" %*s", maxContextWidth, contexts[i])
+		}
+		if opts.ShowPrice {
+			// Pad before coloring so escape sequences do not affect alignment
+			price := fmt.Sprintf("%-*s", maxPriceWidth, prices[i])
+			if color, ok := heat[model.ID]; ok {
+				price = colorize(price, color)
+			}
+			date += " " + price
+		}
+		if opts.ShowValue {
+			date += fmt.Sprintf(" %-*s", maxValueWidth, values[i])
+		}
+		if opts.ShowRank {
+			date += fmt.Sprintf(" %*s", maxRankWidth, ranks[i])
+		}
+		for c := range computed {
+			date += fmt.Sprintf(" %*s", computedWidths[c], computed[c][i])
+		}
+		desc := model.Description
+		if len(model.Sources) > 0 {
+			desc = FormatModelSources(model) + " · " + desc
+		}
+		if len(model.Extra) > 0 {
+			desc = "[" + FormatModelExtra(model) + "] " + desc
+		}
+		if model.HFStats != nil {
+			desc = "[" + FormatHFStats(*model.HFStats) + "] " + desc
+		}
+		if model.MatchedBy != "" {
+			desc = "[" + model.MatchedBy + "] " + desc
+		}
+		// Flatten newlines before truncating
+		desc = TruncateDescription(desc, len([]rune(desc)))
+
+		if twoLine {
+			if opts.ShowSeries {
+				fmt.Printf("%s %s %s %s\n", ids[i], providers[i], series[i], date)
+			} else {
+				fmt.Printf("%s %s %s\n", ids[i], providers[i], date)
+			}
+			summary := FormatModelSummary(model)
+			if summary != "" {
+				desc = summary + " · " + desc
+			}
+			width := termWidth - 6
+			if descLimited {
+				width = min(width, descLimit)
+			}
+			fmt.Printf("    %s\n", opts.truncate("desc", desc, width))
+			continue
+		}
 
-		// Format with dynamic widths: model_id | provider | date | description
+		if descWidth > 0 {
+			desc = opts.truncate("desc", desc, descWidth)
+		}
+
+		// Format with dynamic widths: model_id | provider | [series] | date | description
+		if opts.ShowSeries {
+			fmt.Printf("%-*s %-*s %-*s %s %s\n",
+				maxModelWidth, ids[i],
+				maxProviderWidth, providers[i],
+				maxSeriesWidth, series[i],
+				date, desc)
+			continue
+		}
 		fmt.Printf("%-*s %-*s %s %s\n",
-			maxModelWidth, model.ID,
-			maxProviderWidth, provider,
+			maxModelWidth, ids[i],
+			maxProviderWidth, providers[i],
 			date, desc)
 	}
 }
 
-// DisplayProviders prints unique provider names
-func DisplayProviders(models []Model) {
+// FormatModelPrice formats prompt and completion prices per 1K tokens, or "-" if unpriced
+func FormatModelPrice(model catalog.Model) string {
+	if model.Pricing.Prompt == "" {
+		return "-"
+	}
+	return FormatUSD(FormatPrice(model.Pricing.Prompt)) + "/" + FormatUSD(FormatPrice(model.Pricing.Completion))
+}
+
+// FormatModelSummary returns a short context and pricing summary for a model
+func FormatModelSummary(model catalog.Model) string {
+	var parts []string
+	if model.ContextLength > 0 {
+		parts = append(parts, "ctx "+FormatContextLength(model.ContextLength))
+	}
+	if model.Pricing.Prompt != "" {
+		parts = append(parts, FormatModelPrice(model)+" per 1K")
+	}
+	return strings.Join(parts, " · ")
+}
+
+// IsFree reports whether a model has zero prompt and completion pricing
+func IsFree(model catalog.Model) bool {
+	return model.Pricing.Prompt == "0" && model.Pricing.Completion == "0"
+}
+
+// DisplaySummary prints a one-line footer with model, provider, and free counts
+// and the newest creation date
+func DisplaySummary(models []catalog.Model) {
+	if len(models) == 0 {
+		return
+	}
+
+	providerSet := make(map[string]bool)
+	free := 0
+	var newest int64
+	for _, model := range models {
+		providerSet[catalog.ModelProvider(model)] = true
+		if IsFree(model) {
+			free++
+		}
+		if model.Created > newest {
+			newest = model.Created
+		}
+	}
+
+	fmt.Printf("%d models · %d providers · %d free · newest: %s\n",
+		len(models), len(providerSet), free, FormatLongDate(newest))
+}
+
+// DisplayProviders prints unique canonical provider names, annotating degraded
+// providers; with showAliases, the other prefixes grouped under a provider follow it
+func DisplayProviders(models []catalog.Model, incidents map[string]ProviderIncident, showAliases bool) {
 	if len(models) == 0 {
 		return
 	}
@@ -257,9 +407,10 @@ func DisplayProviders(models []Model) {
 	// Extract unique providers
 	providerSet := make(map[string]bool)
 	for _, model := range models {
-		provider := ExtractProvider(model.ID)
+		provider := catalog.ModelProvider(model)
 		providerSet[provider] = true
 	}
+	spellings := catalog.ProviderSpellings(models)
 
 	// Convert to slice
 	var providers []string
@@ -272,23 +423,42 @@ func DisplayProviders(models []Model) {
 
 	// Display providers (one per line)
 	for _, provider := range providers {
-		fmt.Println(provider)
+		line := provider
+		if showAliases && len(spellings[provider]) > 0 {
+			line += " (" + strings.Join(spellings[provider], ", ") + ")"
+		}
+		if incident, ok := incidents[provider]; ok {
+			line += " " + incident.Label()
+		}
+		fmt.Println(line)
 	}
 }
 
 // DisplayModelsDetailed prints comprehensive information for each model
-func DisplayModelsDetailed(models []Model) {
+// Descriptions are rendered from Markdown, styled when color is set
+func DisplayModelsDetailed(models []catalog.Model, color bool) {
 	if len(models) == 0 {
 		return
 	}
 
+	// Scale capacity bars to the largest context among the displayed models
+	maxContext := 0
+	for _, model := range models {
+		if model.ContextLength > maxContext {
+			maxContext = model.ContextLength
+		}
+	}
+
 	for i, model := range models {
 		if i > 0 {
 			fmt.Println() // Blank line between models
 		}
 
-		provider := ExtractProvider(model.ID)
-		date := FormatDate(model.Created)
+		provider := catalog.ModelProvider(model)
+		if raw := catalog.ExtractProvider(model.ID); raw != provider {
+			provider += " (as " + raw + ")"
+		}
+		date := FormatLongDate(model.Created)
 
 		// Print separator line
 		fmt.Println(strings.Repeat("=", 80))
@@ -297,27 +467,78 @@ func DisplayModelsDetailed(models []Model) {
 		fmt.Printf("Model ID:          %s\n", model.ID)
 		fmt.Printf("Name:              %s\n", model.Name)
 		fmt.Printf("Provider:          %s\n", provider)
+		if model.Type != "" {
+			fmt.Printf("Type:              %s\n", model.Type)
+		}
+		if series := catalog.ModelSeries(model); series != "" {
+			fmt.Printf("Series:            %s\n", series)
+		}
+		if variant := catalog.ModelVariant(model.ID); variant != "" {
+			fmt.Printf("Variant:           %s - %s (base: %s)\n", variant, catalog.VariantDescriptions[variant], catalog.BaseModelID(model.ID))
+		}
 		fmt.Printf("Created:           %s\n", date)
+		if model.URL != "" {
+			fmt.Printf("URL:               %s\n", model.URL)
+		}
+		if model.MatchedBy != "" {
+			fmt.Printf("Matched By:        %s\n", model.MatchedBy)
+		}
 
 		// Technical details
 		if model.ContextLength > 0 {
-			fmt.Printf("Context Length:    %s tokens\n", FormatNumber(model.ContextLength))
+			fmt.Printf("Context Length:    %-18s %s\n", FormatNumber(model.ContextLength)+" tokens",
+				CapacityBar(model.ContextLength, maxContext))
 		}
 		if model.TopProvider.MaxCompletionTokens > 0 {
-			fmt.Printf("Max Completion:    %s tokens\n", FormatNumber(model.TopProvider.MaxCompletionTokens))
+			fmt.Printf("Max Completion:    %-18s %s\n", FormatNumber(model.TopProvider.MaxCompletionTokens)+" tokens",
+				CapacityBar(model.TopProvider.MaxCompletionTokens, maxContext))
 		}
 
 		// Architecture
 		if model.Architecture.Modality != "" {
 			fmt.Printf("Modality:          %s\n", model.Architecture.Modality)
 		}
+		if tuning, reason := catalog.ModelTuning(model); tuning != "" {
+			fmt.Printf("Tuning:            %s (%s)\n", tuning, reason)
+		}
 
 		// Pricing information
 		if model.Pricing.Prompt != "" && model.Pricing.Prompt != "0" {
 			promptPrice := FormatPrice(model.Pricing.Prompt)
 			completionPrice := FormatPrice(model.Pricing.Completion)
-			fmt.Printf("Pricing:           $%s / 1K prompt tokens, $%s / 1K completion tokens\n",
-				promptPrice, completionPrice)
+			fmt.Printf("Pricing:           %s / 1K prompt tokens, %s / 1K completion tokens\n",
+				FormatUSD(promptPrice), FormatUSD(completionPrice))
+			fmt.Printf("Value:             %s (prompt:completion %s)\n", FormatModelValue(model, catalog.DefaultBlend), catalog.DefaultBlend)
+		}
+		if HasMediaPricing(model.Pricing) {
+			fmt.Printf("Media Pricing:     %s\n", FormatMediaPricing(model.Pricing))
+		}
+		if SupportsCaching(model) {
+			fmt.Printf("Prompt Cache:      %s\n", FormatCachePricing(model.Pricing))
+		}
+
+		// Sources merged by --dedupe
+		for j, source := range model.Sources {
+			label := "Sources:"
+			if j > 0 {
+				label = ""
+			}
+			fmt.Printf("%-18s %s (%s)\n", label, source.ID, FormatSourcePrice(source))
+		}
+
+		// Fields added by enrichment plugins
+		if len(model.Extra) > 0 {
+			fmt.Printf("Extra:             %s\n", FormatModelExtra(model))
+		}
+
+		// Rate limits (--rate-limits)
+		if model.RateLimits != nil {
+			fmt.Printf("Rate Limits:       %s\n", FormatRateLimits(*model.RateLimits))
+		}
+
+		// Upstream provider uptimes (--uptime, --min-uptime)
+		if model.Uptime != nil {
+			fmt.Printf("Uptime (30 min):   %s\n", FormatModelUptime(*model.Uptime))
 		}
 
 		// Moderation
@@ -325,6 +546,11 @@ func DisplayModelsDetailed(models []Model) {
 			fmt.Println("Moderation:        Enabled")
 		}
 
+		// Deprecation
+		if model.ExpirationDate != "" {
+			fmt.Printf("Expires:           %s\n", model.ExpirationDate)
+		}
+
 		// Ollama-specific details
 		if model.OllamaDetails != nil {
 			if model.OllamaDetails.Family != "" {
@@ -345,6 +571,78 @@ func DisplayModelsDetailed(models []Model) {
 			}
 		}
 
+		// llama.cpp-specific details
+		if model.LlamaCppDetails != nil {
+			fmt.Printf("Server:            %s\n", model.LlamaCppDetails.Server)
+			if model.LlamaCppDetails.ModelPath != "" {
+				fmt.Printf("Model Path:        %s\n", model.LlamaCppDetails.ModelPath)
+			}
+			if model.LlamaCppDetails.GPULayers > 0 {
+				fmt.Printf("GPU Layers:        %d\n", model.LlamaCppDetails.GPULayers)
+			}
+			if model.LlamaCppDetails.Size > 0 {
+				sizeGB := float64(model.LlamaCppDetails.Size) / (1024 * 1024 * 1024)
+				fmt.Printf("Model Size:        %.2f GB\n", sizeGB)
+			}
+		}
+
+		// Accepted request parameters
+		displaySupportedParameters(model)
+		displayDefaultParameters(model)
+
+		// OpenRouter weekly popularity (--show-rank, --sort popularity)
+		if model.PopularityRank > 0 {
+			fmt.Printf("Popularity:        %s by tokens routed on OpenRouter this week\n", FormatRank(model.PopularityRank))
+		}
+
+		// Languages from family support and the description
+		if len(model.Languages) > 0 {
+			fmt.Printf("Languages:         %s\n", strings.Join(model.Languages, ", "))
+		}
+
+		// OpenRouter use-case categories
+		if len(model.Categories) > 0 {
+			fmt.Printf("Categories:        %s\n", strings.Join(model.Categories, ", "))
+		}
+
+		// Open-weights counterpart of a hosted model
+		if model.HuggingFaceID != "" {
+			fmt.Printf("Open Weights:      %s/%s\n", catalog.HuggingFaceURL, model.HuggingFaceID)
+		}
+
+		// Hugging Face popularity (--hf-stats)
+		if model.HFStats != nil {
+			fmt.Printf("HF Stats:          %s\n", FormatHFStats(*model.HFStats))
+		}
+
+		// Hugging Face GGUF builds (--gguf)
+		if model.GGUFCrossRef != nil {
+			displayGGUFCrossRef(model.GGUFCrossRef)
+		}
+
+		// Quantization sizes of open models
+		displayQuantTable(model)
+
+		// TGI-specific details
+		if model.TGIDetails != nil {
+			fmt.Printf("Server:            %s\n", model.TGIDetails.Server)
+			if model.TGIDetails.MaxInputTokens > 0 {
+				fmt.Printf("Max Input:         %s tokens\n", FormatNumber(model.TGIDetails.MaxInputTokens))
+			}
+			if model.TGIDetails.Quantization != "" {
+				fmt.Printf("Quantization:      %s\n", model.TGIDetails.Quantization)
+			}
+			if model.TGIDetails.Dtype != "" {
+				fmt.Printf("Dtype:             %s\n", model.TGIDetails.Dtype)
+			}
+			if model.TGIDetails.DeviceType != "" {
+				fmt.Printf("Device:            %s\n", model.TGIDetails.DeviceType)
+			}
+			if model.TGIDetails.Version != "" {
+				fmt.Printf("TGI Version:       %s\n", model.TGIDetails.Version)
+			}
+		}
+
 		// Description (full, not truncated)
 		if model.Description != "" {
 			fmt.Println("Description:")
@@ -354,8 +652,11 @@ func DisplayModelsDetailed(models []Model) {
 			if descWidth < 40 {
 				descWidth = 40
 			}
-			wrappedDesc := WrapText(model.Description, descWidth)
-			for _, line := range wrappedDesc {
+			for _, line := range RenderMarkdown(model.Description, descWidth, color) {
+				if line == "" {
+					fmt.Println()
+					continue
+				}
 				fmt.Printf("  %s\n", line)
 			}
 		}
@@ -364,17 +665,55 @@ func DisplayModelsDetailed(models []Model) {
 	}
 }
 
-// FormatNumber formats a number with thousand separators
+// FormatNumber formats a number with the current locale's thousand separators
 func FormatNumber(n int) string {
-	str := fmt.Sprintf("%d", n)
-	result := ""
-	for i, c := range str {
-		if i > 0 && (len(str)-i)%3 == 0 {
-			result += ","
-		}
-		result += string(c)
+	return currentLocale.localizeNumber(fmt.Sprintf("%d", n))
+}
+
+// capacityBarWidth is the number of cells in a capacity bar
+const capacityBarWidth = 20
+
+// CapacityBar renders value as a bar proportional to max, followed by a short
+// token count, e.g. "██████████░░░░░░░░░░ 200K"
+func CapacityBar(value, max int) string {
+	if max <= 0 {
+		return ""
+	}
+
+	filled := value * capacityBarWidth / max
+	if filled == 0 && value > 0 {
+		filled = 1 // Keep small values visible
+	}
+	if filled > capacityBarWidth {
+		filled = capacityBarWidth
 	}
-	return result
+
+	return strings.Repeat("█", filled) + strings.Repeat("░", capacityBarWidth-filled) +
+		" " + FormatContextLength(value)
+}
+
+// FormatContextLength formats a context length for table columns, e.g. 131072
+// as "128K", 200000 as "200K", and 1048576 as "1M"; "-" if unknown
+// Lengths in whole thousands count in those, else lengths in whole KiB or
+// MiB count in those units, as model cards do: 128000 is "128K", not "125K"
+func FormatContextLength(n int) string {
+	switch {
+	case n <= 0:
+		return "-"
+	case n%1000000 == 0:
+		return fmt.Sprintf("%dM", n/1000000)
+	case n%(1<<20) == 0:
+		return fmt.Sprintf("%dM", n>>20)
+	case n >= 1000000:
+		return strings.TrimSuffix(fmt.Sprintf("%.1f", float64(n)/1000000), ".0") + "M"
+	case n%1000 == 0:
+		return fmt.Sprintf("%dK", n/1000)
+	case n%1024 == 0:
+		return fmt.Sprintf("%dK", n>>10)
+	case n >= 1000:
+		return fmt.Sprintf("%dK", (n+500)/1000)
+	}
+	return fmt.Sprintf("%d", n)
 }
 
 // FormatPrice formats a price string to a readable format
@@ -401,31 +740,84 @@ func FormatPrice(price string) string {
 	}
 }
 
+// Word wrapping of WrapText
+var (
+	// Words, keeping a backticked code span with spaces in one word
+	wrapWords = regexp.MustCompile("(?:[^\\s`]*`[^`]*`)+[^\\s`]*|\\S+")
+	// Blank lines between paragraphs
+	wrapParagraphBreak = regexp.MustCompile(`\n[ \t]*\n(?:[ \t]*\n)*`)
+	// List markers that continuation lines hang under
+	wrapListMarker = regexp.MustCompile(`^([-*+•]|\d{1,3}[.)])$`)
+)
+
 // WrapText wraps text to specified width, breaking at word boundaries
+// Width counts visible characters, so ANSI styles do not shorten lines
+// Blank lines separate paragraphs and are kept, other line breaks are joined
+// URLs and backticked code spans are never broken, even when longer than
+// width, and continuation lines hang under the first line's indentation and
+// list marker, e.g. "  - " indents them by four spaces
 func WrapText(text string, width int) []string {
-	// Replace newline characters with spaces
-	text = strings.ReplaceAll(text, "\r\n", " ")
-	text = strings.ReplaceAll(text, "\r", " ")
-	text = strings.ReplaceAll(text, "\n", " ")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	lines := []string{}
+	for _, paragraph := range wrapParagraphBreak.Split(strings.Trim(text, "\n"), -1) {
+		wrapped := wrapParagraph(paragraph, width)
+		if len(wrapped) == 0 {
+			continue
+		}
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, wrapped...)
+	}
+	return lines
+}
 
-	words := strings.Fields(text)
+// wrapParagraph wraps a paragraph without blank lines for WrapText; a line
+// starting with a list marker begins a new line
+func wrapParagraph(paragraph string, width int) []string {
+	var lines []string
+	var item string
+	for _, line := range strings.Split(paragraph, "\n") {
+		words := strings.Fields(line)
+		if item != "" && len(words) > 0 && wrapListMarker.MatchString(words[0]) {
+			lines = append(lines, wrapLine(item, width)...)
+			item = ""
+		}
+		if item == "" {
+			item = line
+		} else {
+			item += " " + line
+		}
+	}
+	return append(lines, wrapLine(item, width)...)
+}
+
+// wrapLine wraps a line, hanging continuation lines under its indentation
+// and list marker
+func wrapLine(line string, width int) []string {
+	words := wrapWords.FindAllString(line, -1)
 	if len(words) == 0 {
-		return []string{}
+		return nil
+	}
+	indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+	hang := indent
+	if len(words) > 1 && wrapListMarker.MatchString(words[0]) {
+		hang += strings.Repeat(" ", visibleWidth(words[0])+1)
 	}
 
 	var lines []string
-	currentLine := words[0]
-
+	currentLine := indent + words[0]
 	for _, word := range words[1:] {
-		if len(currentLine)+1+len(word) <= width {
+		if visibleWidth(currentLine)+1+visibleWidth(word) <= width {
 			currentLine += " " + word
 		} else {
 			lines = append(lines, currentLine)
-			currentLine = word
+			currentLine = hang + word
 		}
 	}
 	lines = append(lines, currentLine)
 
 	return lines
 }
-
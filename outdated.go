@@ -0,0 +1,123 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// ollamaRegistryURL is the public Ollama model registry
+const ollamaRegistryURL = "https://registry.ollama.ai"
+
+// errNotOnRegistry reports a model that was created locally or whose tag was removed
+var errNotOnRegistry = errors.New("not on the Ollama registry")
+
+// OutdatedModel is an installed Ollama model whose registry tag has moved
+type OutdatedModel struct {
+	Name         string
+	LocalDigest  string
+	RemoteDigest string
+}
+
+// registryPath splits an Ollama model name into its registry repository and tag
+// Names without a namespace live under library/; ok is false for models from other registries
+func registryPath(name string) (repo, tag string, ok bool) {
+	repo, tag = name, "latest"
+	if idx := strings.LastIndex(name, ":"); idx >= 0 {
+		repo, tag = name[:idx], name[idx+1:]
+	}
+	switch strings.Count(repo, "/") {
+	case 0:
+		repo = "library/" + repo
+	case 1:
+		// user/model on the Ollama registry
+	default:
+		// host/user/model, e.g. hf.co/...
+		if !strings.HasPrefix(repo, "registry.ollama.ai/") {
+			return "", "", false
+		}
+		repo = strings.TrimPrefix(repo, "registry.ollama.ai/")
+	}
+	return repo, tag, true
+}
+
+// FetchRegistryDigest returns the digest of a tag's manifest on the Ollama registry,
+// in the same form Ollama reports for installed models
+func FetchRegistryDigest(name string) (string, error) {
+	repo, tag, ok := registryPath(name)
+	if !ok {
+		return "", fmt.Errorf("%s: %w", name, errNotOnRegistry)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, ollamaRegistryURL+"/v2/"+repo+"/manifests/"+tag, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.docker.distribution.manifest.v2+json")
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to reach registry: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("%s: %w", name, errNotOnRegistry)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("registry returned status %d for %s", resp.StatusCode, name)
+	}
+
+	// Ollama identifies installed models by the SHA-256 of the manifest as downloaded
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read manifest: %w", err)
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// FindOutdatedModels compares installed Ollama models with the registry
+// Local-only models are skipped; other failures are reported through warn
+func FindOutdatedModels(models []catalog.Model, warn func(error)) []OutdatedModel {
+	var outdated []OutdatedModel
+	for _, model := range models {
+		if model.OllamaDetails == nil || model.OllamaDetails.Digest == "" {
+			continue
+		}
+		name := catalog.OllamaModelName(model.ID)
+		if _, _, ok := registryPath(name); !ok {
+			continue
+		}
+
+		remote, err := FetchRegistryDigest(name)
+		if errors.Is(err, errNotOnRegistry) {
+			continue
+		}
+		if err != nil {
+			warn(err)
+			continue
+		}
+		local := strings.TrimPrefix(model.OllamaDetails.Digest, "sha256:")
+		if remote != local {
+			outdated = append(outdated, OutdatedModel{Name: name, LocalDigest: local, RemoteDigest: remote})
+		}
+	}
+	return outdated
+}
+
+// shortDigest abbreviates a digest for display
+func shortDigest(digest string) string {
+	if len(digest) > 12 {
+		return digest[:12]
+	}
+	return digest
+}
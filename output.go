@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// Output file formats of --output, chosen by file extension
+const (
+	OutputCSV      = "csv"
+	OutputJSON     = "json"
+	OutputMarkdown = "md"
+	OutputHTML     = "html"
+)
+
+// outputExtensions map file extensions to output formats
+var outputExtensions = map[string]string{
+	".csv":      OutputCSV,
+	".json":     OutputJSON,
+	".md":       OutputMarkdown,
+	".markdown": OutputMarkdown,
+	".html":     OutputHTML,
+	".htm":      OutputHTML,
+}
+
+// outputColumns are the columns of the CSV, Markdown, and HTML tables
+var outputColumns = []string{"ID", "Name", "Provider", "Created", "Context", "Prompt/1K", "Completion/1K", "Description"}
+
+// OutputFormatFor returns the output format for a file name from its extension
+func OutputFormatFor(path string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if format, ok := outputExtensions[ext]; ok {
+		return format, nil
+	}
+	return "", fmt.Errorf("cannot infer output format of %s (use .csv, .json, .md, or .html)", path)
+}
+
+// WriteModelsFile writes models to path in the format of its extension,
+// replacing the file atomically so a cron job never leaves a partial report
+func WriteModelsFile(path string, models []catalog.Model) error {
+	format, err := OutputFormatFor(path)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	switch format {
+	case OutputCSV:
+		err = writeModelsCSV(&buf, models)
+	case OutputJSON:
+		err = writeModelsJSON(&buf, models)
+	case OutputMarkdown:
+		writeModelsMarkdown(&buf, models)
+	case OutputHTML:
+		err = writeModelsHTML(&buf, models)
+	}
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, buf.Bytes())
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it over path, so readers see either the old or the new file
+// Dry runs leave path untouched
+func writeFileAtomic(path string, data []byte) error {
+	if dryRun {
+		fmt.Fprintf(os.Stderr, "[dry-run] write %s (%d bytes)\n", path, len(data))
+		return nil
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	// CreateTemp makes the file private; reports are meant to be shared
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// outputRow returns the table cells of a model formatted for reading
+func outputRow(model catalog.Model) []string {
+	prompt, completion := "-", "-"
+	if model.Pricing.Prompt != "" {
+		prompt = FormatUSD(FormatPrice(model.Pricing.Prompt))
+		completion = FormatUSD(FormatPrice(model.Pricing.Completion))
+	}
+	return []string{
+		model.ID,
+		model.Name,
+		catalog.ModelProvider(model),
+		FormatDate(model.Created),
+		FormatContextLength(model.ContextLength),
+		prompt,
+		completion,
+		strings.Join(strings.Fields(model.Description), " "),
+	}
+}
+
+// writeModelsCSV writes models as CSV with plain numbers, ISO dates, and
+// prices in USD per 1K tokens, for spreadsheets
+func writeModelsCSV(buf *bytes.Buffer, models []catalog.Model) error {
+	w := csv.NewWriter(buf)
+	w.Write([]string{"id", "name", "provider", "created", "context_length", "prompt_per_1k", "completion_per_1k", "description"})
+	for _, model := range models {
+		var prompt, completion string
+		if model.Pricing.Prompt != "" {
+			prompt = FormatPrice(model.Pricing.Prompt)
+			completion = FormatPrice(model.Pricing.Completion)
+		}
+		w.Write([]string{
+			model.ID,
+			model.Name,
+			catalog.ModelProvider(model),
+			isoDate(model.Created),
+			strconv.Itoa(model.ContextLength),
+			prompt,
+			completion,
+			model.Description,
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("failed to encode CSV: %w", err)
+	}
+	return nil
+}
+
+// isoDate formats a timestamp as an ISO date in UTC, or "" when unknown
+func isoDate(timestamp int64) string {
+	if timestamp == 0 {
+		return ""
+	}
+	return time.Unix(timestamp, 0).UTC().Format(time.DateOnly)
+}
+
+// writeModelsJSON writes models as the indented JSON array of the catalog
+func writeModelsJSON(buf *bytes.Buffer, models []catalog.Model) error {
+	if models == nil {
+		models = []catalog.Model{}
+	}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(models); err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	return nil
+}
+
+// writeModelsMarkdown writes models as a Markdown table
+func writeModelsMarkdown(buf *bytes.Buffer, models []catalog.Model) {
+	escape := strings.NewReplacer("|", `\|`)
+	fmt.Fprintf(buf, "| %s |\n", strings.Join(outputColumns, " | "))
+	fmt.Fprintf(buf, "|%s\n", strings.Repeat(" --- |", len(outputColumns)))
+	for _, model := range models {
+		row := outputRow(model)
+		for i, cell := range row {
+			row[i] = escape.Replace(cell)
+		}
+		fmt.Fprintf(buf, "| %s |\n", strings.Join(row, " | "))
+	}
+}
+
+// modelsHTML is the page written for .html output
+var modelsHTML = template.Must(template.New("models").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>llmls: {{len .Rows}} models</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+</style>
+</head>
+<body>
+<p>{{len .Rows}} models · generated {{.Generated}}</p>
+<table>
+<tr>{{range .Columns}}<th>{{.}}</th>{{end}}</tr>
+{{range .Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// writeModelsHTML writes models as a standalone HTML page with a table
+func writeModelsHTML(buf *bytes.Buffer, models []catalog.Model) error {
+	rows := make([][]string, len(models))
+	for i, model := range models {
+		rows[i] = outputRow(model)
+	}
+	data := struct {
+		Columns   []string
+		Rows      [][]string
+		Generated string
+	}{outputColumns, rows, time.Now().Format(time.RFC3339)}
+	if err := modelsHTML.Execute(buf, data); err != nil {
+		return fmt.Errorf("failed to render HTML: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// jsonSchemaDialect is the JSON Schema version of llmls schema
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// OutputSchema is a JSON document llmls writes, described by llmls schema
+type OutputSchema struct {
+	Name        string
+	Description string
+	value       interface{} // Zero value of the Go type encoded
+	loose       bool        // Fields may be missing, e.g. in vendor catalogs passed through
+}
+
+// outputSchemas are the JSON outputs of llmls and its serve API
+var outputSchemas = []OutputSchema{
+	{Name: "models", Description: "Models written by --output *.json and given to --jq", value: []catalog.Model{}},
+	{Name: "snapshot", Description: "Catalog snapshot saved by llmls snapshot", value: Snapshot{}},
+	{Name: "status", Description: "Source health printed by llmls status --json", value: []SourceStatus{}},
+	{Name: "endpoints", Description: "Upstream providers printed by llmls endpoints --json", value: []Endpoint{}},
+	{Name: "manifest", Description: "Pinned models written by llmls manifest", value: Manifest{}},
+	{Name: "webhook", Description: "Catalog change payload posted by llmls watch --webhook-format json", value: ChangeNotification{}},
+	{Name: "serve-cache", Description: "Vendor catalog served by llmls serve at " + cacheEndpoint, value: struct {
+		Data []catalog.Model `json:"data"`
+	}{}, loose: true},
+}
+
+// OutputSchemaNames returns the names accepted by llmls schema
+func OutputSchemaNames() []string {
+	names := make([]string, len(outputSchemas))
+	for i, s := range outputSchemas {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// FindOutputSchema returns the output of a name
+func FindOutputSchema(name string) (OutputSchema, error) {
+	for _, s := range outputSchemas {
+		if s.Name == name {
+			return s, nil
+		}
+	}
+	return OutputSchema{}, fmt.Errorf("unknown output: %s (expected %s)", name, strings.Join(OutputSchemaNames(), ", "))
+}
+
+// schemaBuilder derives JSON Schemas from Go types as encoding/json encodes
+// them, collecting named structs in defs and referring to them under refBase
+type schemaBuilder struct {
+	defs    map[string]interface{}
+	refBase string // e.g. "#/$defs/"
+	prefix  string // Prepended to definition names, keeping loose ones apart
+	loose   bool
+}
+
+// build returns the schema of the output, adding its definitions to defs
+// Definitions of loose outputs are prefixed with "Vendor", since their fields
+// may be missing where the same type in other outputs has them
+func (s OutputSchema) build(defs map[string]interface{}, refBase string) map[string]interface{} {
+	b := &schemaBuilder{defs: defs, refBase: refBase, loose: s.loose}
+	if s.loose {
+		b.prefix = "Vendor"
+	}
+	return b.schema(reflect.TypeOf(s.value))
+}
+
+// schemaDocument is a top-level JSON Schema, keeping the metadata first
+type schemaDocument struct {
+	Schema      string                 `json:"$schema"`
+	Title       string                 `json:"title"`
+	Description string                 `json:"description"`
+	Ref         string                 `json:"$ref,omitempty"`
+	Type        interface{}            `json:"type,omitempty"`
+	Items       interface{}            `json:"items,omitempty"`
+	Properties  interface{}            `json:"properties,omitempty"`
+	Required    interface{}            `json:"required,omitempty"`
+	Defs        map[string]interface{} `json:"$defs,omitempty"`
+}
+
+// Schema returns the JSON Schema document of the output
+func (s OutputSchema) Schema() ([]byte, error) {
+	defs := make(map[string]interface{})
+	root := s.build(defs, "#/$defs/")
+	ref, _ := root["$ref"].(string)
+	doc := schemaDocument{
+		Schema:      jsonSchemaDialect,
+		Title:       "llmls " + s.Name,
+		Description: s.Description,
+		Ref:         ref,
+		Type:        root["type"],
+		Items:       root["items"],
+		Properties:  root["properties"],
+		Required:    root["required"],
+		Defs:        defs,
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// Types encoded specially by encoding/json
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// schema returns the schema of a type
+func (b *schemaBuilder) schema(t reflect.Type) map[string]interface{} {
+	switch t {
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case rawMessageType:
+		return map[string]interface{}{} // Any JSON value
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return nullable(b.schema(t.Elem()))
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]interface{}{"type": "array", "items": b.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": b.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.object(t)
+		}
+		name := b.prefix + t.Name()
+		if _, ok := b.defs[name]; !ok {
+			b.defs[name] = nil // Reserve the name for recursive types
+			b.defs[name] = b.object(t)
+		}
+		return map[string]interface{}{"$ref": b.refBase + name}
+	}
+	return map[string]interface{}{} // Interfaces hold any JSON value
+}
+
+// object returns the schema of a struct's JSON object: fields without
+// omitempty are always present, though nil slices, maps, and pointers are
+// encoded as null
+func (b *schemaBuilder) object(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var required []string
+	b.fields(t, properties, &required)
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 && !b.loose {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema
+}
+
+// fields adds the JSON fields of a struct, including those of embedded structs
+func (b *schemaBuilder) fields(t reflect.Type, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			b.fields(field.Type, properties, required)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema := b.schema(field.Type)
+		omitEmpty := strings.Contains(","+options+",", ",omitempty,")
+		switch field.Type.Kind() {
+		case reflect.Slice, reflect.Map:
+			if !omitEmpty && field.Type != rawMessageType {
+				schema = nullable(schema)
+			}
+		}
+		properties[name] = schema
+		if !omitEmpty {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// nullable allows null besides the values of a schema
+func nullable(schema map[string]interface{}) map[string]interface{} {
+	if _, ok := schema["type"].(string); !ok {
+		return map[string]interface{}{"anyOf": []interface{}{schema, map[string]interface{}{"type": "null"}}}
+	}
+	nullable := make(map[string]interface{}, len(schema))
+	for key, value := range schema {
+		nullable[key] = value
+	}
+	nullable["type"] = []string{schema["type"].(string), "null"}
+	return nullable
+}
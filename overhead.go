@@ -0,0 +1,72 @@
+package main
+
+import (
+	"math"
+	"unicode/utf8"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// charsPerToken is the average number of English characters per token of
+// each OpenRouter tokenizer family, for estimating prompt sizes offline
+var charsPerToken = map[string]float64{
+	"GPT":      4.0,
+	"Claude":   3.5,
+	"Gemini":   4.0,
+	"Llama2":   3.5,
+	"Llama3":   4.0,
+	"Llama4":   4.0,
+	"Mistral":  3.6,
+	"Qwen":     3.9,
+	"Qwen3":    3.9,
+	"DeepSeek": 3.9,
+	"Cohere":   4.0,
+	"Grok":     4.0,
+}
+
+// defaultCharsPerToken is used for tokenizers missing from charsPerToken
+const defaultCharsPerToken = 3.8
+
+// EstimateTokens estimates the number of tokens of text under a tokenizer
+// family; characters outside ASCII, such as CJK text, count as a token each
+func EstimateTokens(text, tokenizer string) int {
+	perToken, ok := charsPerToken[tokenizer]
+	if !ok {
+		perToken = defaultCharsPerToken
+	}
+	ascii := 0
+	for i := 0; i < len(text); i++ {
+		if text[i] < utf8.RuneSelf {
+			ascii++
+		}
+	}
+	other := utf8.RuneCountInString(text) - ascii
+	return int(math.Ceil(float64(ascii)/perToken)) + other
+}
+
+// SystemOverhead is the fixed prompt sent with every request of a model
+type SystemOverhead struct {
+	File      string
+	Tokenizer string // Tokenizer family the estimate is for, "" if unknown
+	Tokens    int
+}
+
+// NewSystemOverhead estimates the tokens of a system prompt for the first
+// model of models that names its tokenizer
+func NewSystemOverhead(file, text string, models []catalog.Model) SystemOverhead {
+	overhead := SystemOverhead{File: file}
+	for _, model := range models {
+		if model.Architecture.Tokenizer != "" {
+			overhead.Tokenizer = model.Architecture.Tokenizer
+			break
+		}
+	}
+	overhead.Tokens = EstimateTokens(text, overhead.Tokenizer)
+	return overhead
+}
+
+// Cost returns the price of sending the system prompt once at a per-token
+// prompt price
+func (o SystemOverhead) Cost(promptPrice float64) float64 {
+	return float64(o.Tokens) * promptPrice
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// usualDefaults are the sampling defaults most models share; a model with
+// other defaults behaves differently when a request leaves them unset
+var usualDefaults = map[string]float64{
+	"temperature":        1,
+	"top_p":              1,
+	"frequency_penalty":  0,
+	"presence_penalty":   0,
+	"repetition_penalty": 1,
+}
+
+// DefaultParameter is a request parameter default set by a model's provider
+type DefaultParameter struct {
+	Name    string
+	Value   float64
+	Unusual bool // Differs from usualDefaults
+}
+
+// ParseRequireParams parses --require-params, e.g. "temperature,top_k"
+func ParseRequireParams(spec string) []string {
+	var params []string
+	for _, param := range strings.Split(spec, ",") {
+		if param = strings.ToLower(strings.TrimSpace(param)); param != "" {
+			params = append(params, param)
+		}
+	}
+	return params
+}
+
+// MissingParams returns the params a model does not list in its supported
+// parameters, in the order given
+func MissingParams(model catalog.Model, params []string) []string {
+	supported := make(map[string]bool, len(model.SupportedParameters))
+	for _, param := range model.SupportedParameters {
+		supported[param] = true
+	}
+	var missing []string
+	for _, param := range params {
+		if !supported[param] {
+			missing = append(missing, param)
+		}
+	}
+	return missing
+}
+
+// FilterModelsByParams returns models supporting every one of params; models
+// whose source does not report supported parameters, such as local servers,
+// are dropped since their support cannot be checked
+// If params is empty, returns all models
+func FilterModelsByParams(models []catalog.Model, params []string) []catalog.Model {
+	if len(params) == 0 {
+		return models
+	}
+
+	var filtered []catalog.Model
+	for _, model := range models {
+		if len(model.SupportedParameters) > 0 && len(MissingParams(model, params)) == 0 {
+			filtered = append(filtered, model)
+		}
+	}
+	return filtered
+}
+
+// displaySupportedParameters prints the Parameters line of the detail view,
+// wrapped under its label
+func displaySupportedParameters(model catalog.Model) {
+	if len(model.SupportedParameters) == 0 {
+		return
+	}
+	const label = "Parameters:        "
+	lines := WrapText(strings.Join(model.SupportedParameters, ", "), max(40, GetTerminalWidth()-len(label)))
+	for i, line := range lines {
+		if i == 0 {
+			fmt.Printf("%s%s\n", label, line)
+		} else {
+			fmt.Printf("%*s%s\n", len(label), "", line)
+		}
+	}
+}
+
+// ModelDefaults returns the numeric default parameters of a model,
+// temperature and top_p first; null defaults are left to the provider and
+// omitted
+func ModelDefaults(model catalog.Model) []DefaultParameter {
+	var defaults []DefaultParameter
+	for name, value := range model.DefaultParameters {
+		v, ok := value.(float64)
+		if !ok {
+			continue
+		}
+		usual, known := usualDefaults[name]
+		defaults = append(defaults, DefaultParameter{Name: name, Value: v, Unusual: known && v != usual})
+	}
+	rank := func(name string) int {
+		switch name {
+		case "temperature":
+			return 0
+		case "top_p":
+			return 1
+		}
+		return 2
+	}
+	sort.Slice(defaults, func(i, j int) bool {
+		if ri, rj := rank(defaults[i].Name), rank(defaults[j].Name); ri != rj {
+			return ri < rj
+		}
+		return defaults[i].Name < defaults[j].Name
+	})
+	return defaults
+}
+
+// HasUnusualDefaults reports whether a model defaults a parameter to a value
+// other than most models
+func HasUnusualDefaults(model catalog.Model) bool {
+	for _, d := range ModelDefaults(model) {
+		if d.Unusual {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterModelsByUnusualDefaults returns models with an unusual default
+// parameter (see HasUnusualDefaults)
+func FilterModelsByUnusualDefaults(models []catalog.Model) []catalog.Model {
+	var filtered []catalog.Model
+	for _, model := range models {
+		if HasUnusualDefaults(model) {
+			filtered = append(filtered, model)
+		}
+	}
+	return filtered
+}
+
+// displayDefaultParameters prints the Defaults line of the detail view,
+// flagging unusual defaults with the value most models use
+func displayDefaultParameters(model catalog.Model) {
+	defaults := ModelDefaults(model)
+	if len(defaults) == 0 {
+		return
+	}
+	parts := make([]string, len(defaults))
+	for i, d := range defaults {
+		parts[i] = d.Name + " " + strconv.FormatFloat(d.Value, 'f', -1, 64)
+		if d.Unusual {
+			parts[i] += " (unusual; most models use " + strconv.FormatFloat(usualDefaults[d.Name], 'f', -1, 64) + ")"
+		}
+	}
+	fmt.Printf("Defaults:          %s\n", strings.Join(parts, ", "))
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// Policy defines which catalog models an organization allows
+// Prices are USD per 1K tokens, matching the list and detail views
+type Policy struct {
+	AllowedProviders        []string
+	AllowedModels           []string // Glob patterns; empty allows any model of an allowed provider
+	BannedModels            []string // Glob patterns
+	AllowedTypes            []string
+	MaxPromptPrice          float64 // 0 means no ceiling
+	MaxCompletionPrice      float64
+	MinContext              int
+	RequiredInputModalities []string // e.g. image, audio, file
+	BannedLicenses          []string
+	AllowDeprecated         bool
+}
+
+// policyKeys lists the keys accepted in a policy file
+var policyKeys = map[string]bool{
+	"allowed_providers":         true,
+	"allowed_models":            true,
+	"banned_models":             true,
+	"allowed_types":             true,
+	"max_prompt_price":          true,
+	"max_completion_price":      true,
+	"min_context":               true,
+	"required_input_modalities": true,
+	"banned_licenses":           true,
+	"allow_deprecated":          true,
+}
+
+// parseYAMLScalar strips quotes from a YAML scalar
+func parseYAMLScalar(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// stripYAMLComment removes a trailing # comment outside quotes
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// parseSimpleYAML reads a flat YAML mapping whose values are scalars, inline
+// lists ([a, b]), or block lists ("- a" lines); nested mappings are not supported
+func parseSimpleYAML(r io.Reader) (map[string][]string, error) {
+	values := make(map[string][]string)
+	var listKey string
+	lineNum := 0
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimRight(stripYAMLComment(scanner.Text()), " \t")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed == "---" {
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "- ") || trimmed == "-" {
+			if listKey == "" || line == trimmed {
+				return nil, fmt.Errorf("line %d: list item without a key", lineNum)
+			}
+			values[listKey] = append(values[listKey], parseYAMLScalar(strings.TrimPrefix(trimmed, "-")))
+			continue
+		}
+
+		if line != trimmed {
+			return nil, fmt.Errorf("line %d: nested mappings are not supported", lineNum)
+		}
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key: value", lineNum)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		listKey = ""
+
+		switch {
+		case value == "":
+			// A block list may follow
+			listKey = key
+			values[key] = nil
+		case strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]"):
+			var items []string
+			for _, item := range strings.Split(value[1:len(value)-1], ",") {
+				if item = parseYAMLScalar(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			values[key] = items
+		default:
+			values[key] = []string{parseYAMLScalar(value)}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read policy: %w", err)
+	}
+	return values, nil
+}
+
+// ParsePolicy reads a policy file
+func ParsePolicy(r io.Reader) (*Policy, error) {
+	values, err := parseSimpleYAML(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var policy Policy
+	for key, items := range values {
+		if !policyKeys[key] {
+			return nil, fmt.Errorf("unknown policy key: %s", key)
+		}
+
+		single := ""
+		if len(items) > 0 {
+			single = items[0]
+		}
+		switch key {
+		case "allowed_providers":
+			policy.AllowedProviders = items
+		case "allowed_models":
+			policy.AllowedModels = items
+		case "banned_models":
+			policy.BannedModels = items
+		case "allowed_types":
+			for _, t := range items {
+				if !catalog.IsModelType(t) {
+					return nil, fmt.Errorf("allowed_types: unknown model type: %s", t)
+				}
+			}
+			policy.AllowedTypes = items
+		case "required_input_modalities":
+			policy.RequiredInputModalities = items
+		case "banned_licenses":
+			policy.BannedLicenses = items
+		case "max_prompt_price", "max_completion_price":
+			price, err := strconv.ParseFloat(single, 64)
+			if err != nil || price < 0 {
+				return nil, fmt.Errorf("%s: invalid price: %s", key, single)
+			}
+			if key == "max_prompt_price" {
+				policy.MaxPromptPrice = price
+			} else {
+				policy.MaxCompletionPrice = price
+			}
+		case "min_context":
+			n, err := strconv.Atoi(single)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("min_context: invalid token count: %s", single)
+			}
+			policy.MinContext = n
+		case "allow_deprecated":
+			b, err := strconv.ParseBool(single)
+			if err != nil {
+				return nil, fmt.Errorf("allow_deprecated: invalid boolean: %s", single)
+			}
+			policy.AllowDeprecated = b
+		}
+	}
+	return &policy, nil
+}
+
+// matchesAny reports whether s matches one of the glob patterns
+func matchesAny(patterns []string, s string) bool {
+	for _, pattern := range patterns {
+		if catalog.GlobMatch(pattern, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold reports whether list contains s, ignoring case
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsCanonicalProvider reports whether list names provider under any of its aliases
+func containsCanonicalProvider(list []string, provider string) bool {
+	for _, item := range list {
+		if catalog.CanonicalProvider(item) == provider {
+			return true
+		}
+	}
+	return false
+}
+
+// Violations returns the reasons model breaks the policy; none means it passes
+func (p *Policy) Violations(model catalog.Model) []string {
+	var violations []string
+
+	provider := catalog.ModelProvider(model)
+	if len(p.AllowedProviders) > 0 && !containsCanonicalProvider(p.AllowedProviders, provider) {
+		violations = append(violations, "provider "+provider+" not allowed")
+	}
+	if len(p.AllowedModels) > 0 && !matchesAny(p.AllowedModels, model.ID) {
+		violations = append(violations, "model not in allowed_models")
+	}
+	if matchesAny(p.BannedModels, model.ID) {
+		violations = append(violations, "model is banned")
+	}
+	if len(p.AllowedTypes) > 0 && !containsFold(p.AllowedTypes, model.Type) {
+		violations = append(violations, "type "+model.Type+" not allowed")
+	}
+
+	// Local models have no price and cannot exceed a ceiling
+	if model.Pricing.Prompt != "" {
+		if prompt := catalog.ParsePrice(model.Pricing.Prompt) * 1000; p.MaxPromptPrice > 0 && prompt > p.MaxPromptPrice {
+			violations = append(violations, fmt.Sprintf("prompt price $%s/1K exceeds $%g", FormatPrice(model.Pricing.Prompt), p.MaxPromptPrice))
+		}
+		if completion := catalog.ParsePrice(model.Pricing.Completion) * 1000; p.MaxCompletionPrice > 0 && completion > p.MaxCompletionPrice {
+			violations = append(violations, fmt.Sprintf("completion price $%s/1K exceeds $%g", FormatPrice(model.Pricing.Completion), p.MaxCompletionPrice))
+		}
+	}
+
+	if p.MinContext > 0 && model.ContextLength < p.MinContext {
+		violations = append(violations, fmt.Sprintf("context %d below %d", model.ContextLength, p.MinContext))
+	}
+	for _, modality := range p.RequiredInputModalities {
+		if !containsFold(model.Architecture.InputModalities, modality) {
+			violations = append(violations, "no "+modality+" input")
+		}
+	}
+	if !p.AllowDeprecated && model.ExpirationDate != "" {
+		violations = append(violations, "deprecated (expires "+model.ExpirationDate+")")
+	}
+	// No source reports licenses yet, so a banned license cannot be ruled out
+	if len(p.BannedLicenses) > 0 {
+		violations = append(violations, "license unknown")
+	}
+
+	return violations
+}
+
+// PolicyResult is the policy outcome for one model ID
+type PolicyResult struct {
+	ID         string
+	Found      bool
+	Violations []string
+}
+
+// Passed reports whether the model exists and satisfies the policy
+func (r PolicyResult) Passed() bool {
+	return r.Found && len(r.Violations) == 0
+}
+
+// CheckPolicy evaluates every catalog model, or only ids when given
+func CheckPolicy(policy *Policy, models []catalog.Model, ids []string) []PolicyResult {
+	if ids == nil {
+		results := make([]PolicyResult, 0, len(models))
+		for _, model := range models {
+			results = append(results, PolicyResult{ID: model.ID, Found: true, Violations: policy.Violations(model)})
+		}
+		return results
+	}
+
+	byID := make(map[string]catalog.Model, len(models))
+	for _, model := range models {
+		byID[model.ID] = model
+	}
+	results := make([]PolicyResult, 0, len(ids))
+	for _, id := range ids {
+		model, ok := byID[id]
+		result := PolicyResult{ID: id, Found: ok}
+		if ok {
+			result.Violations = policy.Violations(model)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// DisplayPolicyResults prints one line per result; with passedOnly, only
+// the IDs of passing models are printed
+func DisplayPolicyResults(results []PolicyResult, passedOnly bool) {
+	for _, result := range results {
+		switch {
+		case passedOnly:
+			if result.Passed() {
+				fmt.Println(result.ID)
+			}
+		case !result.Found:
+			fmt.Printf("%-10s %s\n", "unknown", result.ID)
+		case result.Passed():
+			fmt.Printf("%-10s %s\n", "pass", result.ID)
+		default:
+			fmt.Printf("%-10s %s (%s)\n", "fail", result.ID, strings.Join(result.Violations, "; "))
+		}
+	}
+}
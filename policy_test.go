@@ -0,0 +1,96 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSimpleYAML(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want map[string][]string
+	}{
+		{"scalar", "min_context: 8000\n", map[string][]string{"min_context": {"8000"}}},
+		{"quoted", "key: 'a # b'\nother: \"c\"\n", map[string][]string{"key": {"a # b"}, "other": {"c"}}},
+		{"comment", "---\n# policy\nkey: value # note\n", map[string][]string{"key": {"value"}}},
+		{"inline list", "key: [a, \"b\", ]\n", map[string][]string{"key": {"a", "b"}}},
+		{"block list", "key:\n  - a\n  - 'b'\nnext: c\n", map[string][]string{"key": {"a", "b"}, "next": {"c"}}},
+		{"empty list", "key:\n", map[string][]string{"key": nil}},
+	}
+	for _, tt := range tests {
+		got, err := parseSimpleYAML(strings.NewReader(tt.in))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseSimpleYAMLErrors(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"- a\n", "line 1: list item without a key"},
+		{"key: a\n- b\n", "line 2: list item without a key"},
+		{"key:\n  nested: a\n", "line 2: nested mappings are not supported"},
+		{"key\n", "line 1: expected key: value"},
+	}
+	for _, tt := range tests {
+		_, err := parseSimpleYAML(strings.NewReader(tt.in))
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("parseSimpleYAML(%q) error = %v, want %q", tt.in, err, tt.want)
+		}
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	in := `allowed_providers: [anthropic, openai]
+banned_models:
+  - "*:free"
+allowed_types: [chat]
+max_prompt_price: 0.01
+min_context: 32000
+allow_deprecated: true
+`
+	want := &Policy{
+		AllowedProviders: []string{"anthropic", "openai"},
+		BannedModels:     []string{"*:free"},
+		AllowedTypes:     []string{"chat"},
+		MaxPromptPrice:   0.01,
+		MinContext:       32000,
+		AllowDeprecated:  true,
+	}
+	got, err := ParsePolicy(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestParsePolicyErrors(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"allowed_provider: [openai]\n", "unknown policy key: allowed_provider"},
+		{"allowed_types: [chat, robot]\n", "allowed_types: unknown model type: robot"},
+		{"max_prompt_price: -1\n", "max_prompt_price: invalid price: -1"},
+		{"max_completion_price: cheap\n", "max_completion_price: invalid price: cheap"},
+		{"min_context: 1.5\n", "min_context: invalid token count: 1.5"},
+		{"allow_deprecated: maybe\n", "allow_deprecated: invalid boolean: maybe"},
+	}
+	for _, tt := range tests {
+		_, err := ParsePolicy(strings.NewReader(tt.in))
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("ParsePolicy(%q) error = %v, want %q", tt.in, err, tt.want)
+		}
+	}
+}
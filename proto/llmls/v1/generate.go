@@ -0,0 +1,6 @@
+// Package llmlsv1 is the gRPC API of llmls serve, generated from serve.proto
+package llmlsv1
+
+// Regenerate with protoc, protoc-gen-go, and protoc-gen-go-grpc on PATH, and
+// the googleapis protos (for google/api/annotations.proto) in $GOOGLEAPIS
+//go:generate protoc -I ../.. -I ${GOOGLEAPIS} --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative llmls/v1/serve.proto
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// statusFeedProviders are the providers whose status pages publish a
+// Statuspage-compatible feed at /api/v2/status.json
+var statusFeedProviders = []string{"openrouter", "openai", "anthropic"}
+
+// ProviderIncident is the current state reported by a provider's status page
+type ProviderIncident struct {
+	Provider    string `json:"provider"`
+	Indicator   string `json:"indicator"` // none, minor, major, critical, or maintenance
+	Description string `json:"description"`
+	Error       string `json:"error,omitempty"`
+}
+
+// statusPageResponse is the Statuspage /api/v2/status.json response
+type statusPageResponse struct {
+	Status struct {
+		Indicator   string `json:"indicator"`
+		Description string `json:"description"`
+	} `json:"status"`
+}
+
+// Degraded reports whether the provider has an ongoing incident
+func (i ProviderIncident) Degraded() bool {
+	switch i.Indicator {
+	case "minor", "major", "critical":
+		return true
+	}
+	return false
+}
+
+// Label returns the annotation shown next to a degraded provider
+func (i ProviderIncident) Label() string {
+	if !i.Degraded() {
+		return ""
+	}
+	return "⚠ degraded"
+}
+
+// fetchProviderIncident reads one provider's status feed
+func fetchProviderIncident(provider string) ProviderIncident {
+	incident := ProviderIncident{Provider: provider}
+
+	var resp statusPageResponse
+	client := &http.Client{Timeout: 5 * time.Second}
+	if err := getJSON(client, catalog.PagesForProvider(provider).Status+"/api/v2/status.json", &resp); err != nil {
+		incident.Error = err.Error()
+		return incident
+	}
+	incident.Indicator = resp.Status.Indicator
+	incident.Description = resp.Status.Description
+	return incident
+}
+
+// FetchProviderIncidents queries every status feed concurrently
+// Results are returned in the order of statusFeedProviders
+func FetchProviderIncidents() []ProviderIncident {
+	incidents := make([]ProviderIncident, len(statusFeedProviders))
+
+	var wg sync.WaitGroup
+	for i, provider := range statusFeedProviders {
+		wg.Add(1)
+		go func(i int, provider string) {
+			defer wg.Done()
+			incidents[i] = fetchProviderIncident(provider)
+		}(i, provider)
+	}
+	wg.Wait()
+
+	return incidents
+}
+
+// DegradedProviders returns the providers with an ongoing incident, keyed by provider
+func DegradedProviders(incidents []ProviderIncident) map[string]ProviderIncident {
+	degraded := make(map[string]ProviderIncident)
+	for _, incident := range incidents {
+		if incident.Degraded() {
+			degraded[incident.Provider] = incident
+		}
+	}
+	return degraded
+}
+
+// WarnOpenRouterIncident prints a warning when OpenRouter itself is degraded,
+// since that affects every model it routes rather than one provider column
+func WarnOpenRouterIncident(degraded map[string]ProviderIncident) {
+	if incident, ok := degraded["openrouter"]; ok {
+		fmt.Fprintf(os.Stderr, "Warning: openrouter: %s\n", incident.Description)
+	}
+}
+
+// DisplayProviderIncidents prints provider status feeds as an aligned table
+func DisplayProviderIncidents(incidents []ProviderIncident) {
+	if len(incidents) == 0 {
+		return
+	}
+
+	maxProviderWidth := len("PROVIDER")
+	for _, incident := range incidents {
+		if len(incident.Provider) > maxProviderWidth {
+			maxProviderWidth = len(incident.Provider)
+		}
+	}
+
+	fmt.Printf("%-*s %-11s %s\n", maxProviderWidth, "PROVIDER", "STATE", "DESCRIPTION")
+	for _, incident := range incidents {
+		state, description := incident.Indicator, incident.Description
+		switch {
+		case incident.Error != "":
+			state, description = "unknown", incident.Error
+		case incident.Degraded():
+			state = incident.Label()
+		case state == "none":
+			state = "ok"
+		}
+		fmt.Printf("%-*s %-11s %s\n", maxProviderWidth, incident.Provider, state, description)
+	}
+}
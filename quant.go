@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// QuantLevel is a common GGUF quantization with its average size and quality
+type QuantLevel struct {
+	Name          string
+	BitsPerWeight float64 // Average over all tensors, as reported by llama.cpp
+	Quality       string
+}
+
+// quantLevels are the quantizations shown in the detail view, smallest first
+var quantLevels = []QuantLevel{
+	{"Q2_K", 3.35, "poor, noticeably degraded"},
+	{"Q3_K_M", 3.91, "low"},
+	{"Q4_0", 4.55, "fair"},
+	{"Q4_K_M", 4.85, "good, recommended"},
+	{"Q5_K_M", 5.69, "very good"},
+	{"Q6_K", 6.59, "near lossless"},
+	{"Q8_0", 8.50, "near lossless"},
+	{"F16", 16, "lossless"},
+}
+
+// quantMemoryOverhead is the share of memory needed beyond the weights for
+// the context cache and runtime buffers at a default context size
+const quantMemoryOverhead = 0.2
+
+// ModelParameterCount returns the number of parameters of an open model, in
+// billions, from the size Ollama reports or a size in the model name (8b, 0.5b)
+// ok is false when neither states it, e.g. for proprietary models
+func ModelParameterCount(model catalog.Model) (float64, bool) {
+	if model.OllamaDetails != nil {
+		if n, ok := parseParameterSize(model.OllamaDetails.ParameterSize); ok {
+			return n, true
+		}
+	}
+	for _, token := range strings.Split(DedupeKey(model), "-") {
+		if parameterSize.MatchString(token) {
+			return parseParameterSize(token)
+		}
+	}
+	return 0, false
+}
+
+// parseParameterSize parses a size such as 8B, 70.6b, or 137M into billions
+func parseParameterSize(s string) (float64, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	scale := 1.0
+	switch {
+	case strings.HasSuffix(s, "b"):
+	case strings.HasSuffix(s, "m"):
+		scale = 0.001
+	default:
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(s[:len(s)-1], 64)
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	return n * scale, true
+}
+
+// installedQuant returns the quantization of a local model, if known
+func installedQuant(model catalog.Model) string {
+	switch {
+	case model.OllamaDetails != nil:
+		return strings.ToUpper(model.OllamaDetails.QuantizationLevel)
+	case model.LlamaCppDetails != nil:
+		return ggufQuant(model.LlamaCppDetails.ModelPath)
+	}
+	return ""
+}
+
+// displayQuantTable prints the estimated file size and memory of each common
+// quantization of an open model in the detail view, marking the installed one
+func displayQuantTable(model catalog.Model) {
+	params, ok := ModelParameterCount(model)
+	if !ok {
+		return
+	}
+	installed := installedQuant(model)
+
+	fmt.Printf("Quantizations:     estimated for %s parameters\n", strings.TrimSuffix(strconv.FormatFloat(params, 'f', 1, 64), ".0")+"B")
+	fmt.Printf("  %-8s %5s %9s %9s  %s\n", "QUANT", "BITS", "FILE", "MEMORY", "QUALITY")
+	for _, level := range quantLevels {
+		size := int64(params * 1e9 * level.BitsPerWeight / 8)
+		quality := level.Quality
+		if level.Name == installed {
+			quality += " (installed)"
+		}
+		fmt.Printf("  %-8s %5.2f %9s %9s  %s\n",
+			level.Name, level.BitsPerWeight, formatGB(size), formatGB(int64(float64(size)*(1+quantMemoryOverhead))), quality)
+	}
+}
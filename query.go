@@ -0,0 +1,321 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// queryColumn is a column of the models and snapshots tables
+type queryColumn struct {
+	Name string
+	Type string // SQLite column type
+}
+
+// queryColumns are the columns of the models table, in SELECT * order
+// The snapshots table has taken_at first, then the same columns
+var queryColumns = []queryColumn{
+	{"id", "TEXT"}, {"name", "TEXT"}, {"provider", "TEXT"}, {"type", "TEXT"},
+	{"series", "TEXT"}, {"variant", "TEXT"}, {"created", "INTEGER"}, {"context_length", "INTEGER"},
+	{"max_completion_tokens", "INTEGER"}, {"pricing_prompt", "REAL"}, {"pricing_completion", "REAL"},
+	{"pricing_request", "REAL"}, {"pricing_image", "REAL"}, {"pricing_audio", "REAL"},
+	{"modality", "TEXT"}, {"tokenizer", "TEXT"}, {"is_moderated", "INTEGER"},
+	{"expiration_date", "TEXT"}, {"url", "TEXT"}, {"description", "TEXT"},
+}
+
+// QueryColumnNames returns the column names of the models table
+func QueryColumnNames() []string {
+	names := make([]string, len(queryColumns))
+	for i, column := range queryColumns {
+		names[i] = column.Name
+	}
+	return names
+}
+
+// priceValue converts a per-token price string to a number, or nil if unpriced
+func priceValue(price string) interface{} {
+	if price == "" {
+		return nil
+	}
+	return catalog.ParsePrice(price)
+}
+
+// stringValue returns s, or nil if empty
+func stringValue(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// modelQueryValues flattens a model into the values of a models table row,
+// in queryColumns order
+func modelQueryValues(model catalog.Model) []interface{} {
+	moderated := 0
+	if model.TopProvider.IsModerated {
+		moderated = 1
+	}
+	return []interface{}{
+		model.ID,
+		model.Name,
+		catalog.ModelProvider(model),
+		stringValue(model.Type),
+		stringValue(catalog.ModelSeries(model)),
+		stringValue(catalog.ModelVariant(model.ID)),
+		model.Created,
+		model.ContextLength,
+		model.TopProvider.MaxCompletionTokens,
+		priceValue(model.Pricing.Prompt),
+		priceValue(model.Pricing.Completion),
+		priceValue(model.Pricing.Request),
+		priceValue(model.Pricing.Image),
+		priceValue(model.Pricing.Audio),
+		stringValue(model.Architecture.Modality),
+		stringValue(model.Architecture.Tokenizer),
+		moderated,
+		stringValue(model.ExpirationDate),
+		stringValue(catalog.ModelURL(model)),
+		model.Description,
+	}
+}
+
+// GetCatalogDBPath returns the catalog database from env var or the user cache directory
+func GetCatalogDBPath() (string, error) {
+	if path := os.Getenv("LLMLS_CATALOG_DB"); path != "" {
+		return path, nil
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+	return filepath.Join(cacheDir, "llmls", "catalog.db"), nil
+}
+
+// CatalogDB is the SQLite database queried by `llmls query`: the models
+// table holds the catalog of the last query and the snapshots table every
+// saved snapshot, so the two can be joined
+type CatalogDB struct {
+	db *sql.DB
+}
+
+// OpenCatalogDB opens the catalog database, creating it when missing
+// Dry runs and recorded or replayed sessions use a database in memory, so
+// they leave the persisted catalog as it was
+func OpenCatalogDB() (*CatalogDB, error) {
+	path, err := GetCatalogDBPath()
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		fmt.Fprintf(os.Stderr, "[dry-run] write catalog database %s\n", path)
+	}
+	if dryRun || sessionActive {
+		path = ":memory:"
+	} else if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create catalog database directory: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open catalog database: %w", err)
+	}
+	// One connection, so an in-memory database and PRAGMA query_only apply to every statement
+	db.SetMaxOpenConns(1)
+
+	columns := make([]string, len(queryColumns))
+	for i, column := range queryColumns {
+		columns[i] = column.Name + " " + column.Type
+	}
+	schema := []string{
+		"CREATE TABLE IF NOT EXISTS models (" + strings.Join(columns, ", ") + ")",
+		"CREATE TABLE IF NOT EXISTS snapshots (taken_at TEXT NOT NULL, " + strings.Join(columns, ", ") + ")",
+		"CREATE INDEX IF NOT EXISTS snapshots_id ON snapshots (id)",
+		"CREATE INDEX IF NOT EXISTS snapshots_taken_at ON snapshots (taken_at)",
+	}
+	for _, statement := range schema {
+		if _, err := db.Exec(statement); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to create catalog database %s: %w", path, err)
+		}
+	}
+	return &CatalogDB{db: db}, nil
+}
+
+// Close closes the database
+func (c *CatalogDB) Close() error {
+	return c.db.Close()
+}
+
+// insertModels adds models to table within tx; taken_at is prepended to
+// every row unless empty
+func insertModels(tx *sql.Tx, table, takenAt string, models []catalog.Model) error {
+	columns := QueryColumnNames()
+	if takenAt != "" {
+		columns = append([]string{"taken_at"}, columns...)
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	stmt, err := tx.Prepare("INSERT INTO " + table + " (" + strings.Join(columns, ", ") + ") VALUES (" + placeholders + ")")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, model := range models {
+		values := modelQueryValues(model)
+		if takenAt != "" {
+			values = append([]interface{}{takenAt}, values...)
+		}
+		if _, err := stmt.Exec(values...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StoreCatalog replaces the models table with models
+func (c *CatalogDB) StoreCatalog(models []catalog.Model) error {
+	tx, err := c.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to store catalog: %w", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec("DELETE FROM models"); err != nil {
+		return fmt.Errorf("failed to store catalog: %w", err)
+	}
+	if err := insertModels(tx, "models", "", models); err != nil {
+		return fmt.Errorf("failed to store catalog: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to store catalog: %w", err)
+	}
+	return nil
+}
+
+// ImportSnapshots adds the saved snapshots not yet in the snapshots table
+func (c *CatalogDB) ImportSnapshots() error {
+	paths, err := ListSnapshotFiles()
+	if err != nil {
+		return err
+	}
+
+	stored := make(map[string]bool)
+	rows, err := c.db.Query("SELECT DISTINCT taken_at FROM snapshots")
+	if err != nil {
+		return fmt.Errorf("failed to read snapshots table: %w", err)
+	}
+	for rows.Next() {
+		var takenAt string
+		if err := rows.Scan(&takenAt); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to read snapshots table: %w", err)
+		}
+		stored[takenAt] = true
+	}
+	rows.Close()
+
+	tx, err := c.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to store snapshots: %w", err)
+	}
+	defer tx.Rollback()
+	for _, path := range paths {
+		// Files are named by when they were taken, so stored ones need not be read
+		name := strings.TrimSuffix(filepath.Base(path), ".json")
+		if t, err := time.Parse(snapshotTimeFormat, name); err == nil && stored[t.UTC().Format(time.RFC3339)] {
+			continue
+		}
+		snapshot, err := LoadSnapshot(path)
+		if err != nil {
+			return err
+		}
+		takenAt := snapshot.TakenAt.UTC().Format(time.RFC3339)
+		if stored[takenAt] {
+			continue
+		}
+		if err := insertModels(tx, "snapshots", takenAt, snapshot.Models); err != nil {
+			return fmt.Errorf("failed to store snapshot %s: %w", path, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to store snapshots: %w", err)
+	}
+	return nil
+}
+
+// formatQueryValue formats a value for output: NULL as empty, numbers without exponent
+func formatQueryValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	}
+	return fmt.Sprint(v)
+}
+
+// Query runs a read-only SQL statement and returns the result column names
+// and rows as formatted values; whitespace in values is collapsed so rows
+// stay on one line
+func (c *CatalogDB) Query(statement string) ([]string, [][]string, error) {
+	if _, err := c.db.Exec("PRAGMA query_only = ON"); err != nil {
+		return nil, nil, fmt.Errorf("failed to open catalog database read-only: %w", err)
+	}
+	rows, err := c.db.Query(statement)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	header, err := rows.Columns()
+	if err != nil {
+		return nil, nil, err
+	}
+	var result [][]string
+	values := make([]interface{}, len(header))
+	pointers := make([]interface{}, len(header))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, nil, err
+		}
+		row := make([]string, len(values))
+		for i, v := range values {
+			row[i] = strings.Join(strings.Fields(formatQueryValue(v)), " ")
+		}
+		result = append(result, row)
+	}
+	return header, result, rows.Err()
+}
+
+// RunCatalogQuery stores the catalog and any new snapshots in the catalog
+// database and runs a statement over them
+func RunCatalogQuery(models []catalog.Model, statement string) ([]string, [][]string, error) {
+	db, err := OpenCatalogDB()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer db.Close()
+	if err := db.StoreCatalog(models); err != nil {
+		return nil, nil, err
+	}
+	if err := db.ImportSnapshots(); err != nil {
+		return nil, nil, err
+	}
+	return db.Query(statement)
+}
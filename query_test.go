@@ -0,0 +1,90 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+func TestCatalogDBQuery(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("LLMLS_CATALOG_DB", filepath.Join(dir, "catalog.db"))
+	t.Setenv("LLMLS_SNAPSHOT_DIR", filepath.Join(dir, "snapshots"))
+
+	old := []catalog.Model{{ID: "openai/gpt-4o", Name: "OpenAI: GPT-4o", ContextLength: 128000, Pricing: catalog.Pricing{Prompt: "0.000005", Completion: "0.000015"}}}
+	if _, err := SaveSnapshot(&Snapshot{TakenAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), Models: old}); err != nil {
+		t.Fatal(err)
+	}
+	models := []catalog.Model{
+		{ID: "openai/gpt-4o", Name: "OpenAI: GPT-4o", ContextLength: 128000, Pricing: catalog.Pricing{Prompt: "0.0000025", Completion: "0.00001"}},
+		{ID: "meta-llama/llama-3.1-8b-instruct:free", Name: "Meta: Llama 3.1 8B\nInstruct", ContextLength: 8192, Pricing: catalog.Pricing{Prompt: "0", Completion: "0"}},
+		{ID: "ollama/qwen2.5:7b", Name: "qwen2.5:7b", ContextLength: 32768},
+	}
+
+	tests := []struct {
+		sql     string
+		columns []string
+		rows    [][]string
+	}{
+		{
+			"SELECT id, pricing_prompt FROM models WHERE context_length > 10000 ORDER BY pricing_prompt",
+			[]string{"id", "pricing_prompt"},
+			[][]string{{"ollama/qwen2.5:7b", ""}, {"openai/gpt-4o", "0.0000025"}},
+		},
+		{
+			"SELECT COUNT(*) AS n FROM models WHERE variant = 'free' OR pricing_prompt IS NULL",
+			[]string{"n"},
+			[][]string{{"2"}},
+		},
+		{
+			"SELECT name FROM models WHERE id LIKE 'META-%'",
+			[]string{"name"},
+			[][]string{{"Meta: Llama 3.1 8B Instruct"}},
+		},
+		{
+			"SELECT s.taken_at, s.pricing_prompt, m.pricing_prompt FROM models m JOIN snapshots s USING (id)",
+			[]string{"taken_at", "pricing_prompt", "pricing_prompt"},
+			[][]string{{"2025-01-02T03:04:05Z", "0.000005", "0.0000025"}},
+		},
+	}
+	for _, tt := range tests {
+		columns, rows, err := RunCatalogQuery(models, tt.sql)
+		if err != nil {
+			t.Errorf("%s: %v", tt.sql, err)
+			continue
+		}
+		if !reflect.DeepEqual(columns, tt.columns) || !reflect.DeepEqual(rows, tt.rows) {
+			t.Errorf("%s: got %v %v, want %v %v", tt.sql, columns, rows, tt.columns, tt.rows)
+		}
+	}
+
+	// Snapshots are imported once however often the catalog is queried
+	_, rows, err := RunCatalogQuery(models, "SELECT COUNT(*) FROM snapshots")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]string{{"1"}}; !reflect.DeepEqual(rows, want) {
+		t.Errorf("snapshot rows = %v, want %v", rows, want)
+	}
+}
+
+func TestCatalogDBQueryErrors(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("LLMLS_CATALOG_DB", filepath.Join(dir, "catalog.db"))
+	t.Setenv("LLMLS_SNAPSHOT_DIR", filepath.Join(dir, "snapshots"))
+
+	tests := []string{
+		"SELEC id FROM models",
+		"SELECT nope FROM models",
+		"DELETE FROM models",
+		"DROP TABLE snapshots",
+	}
+	for _, sql := range tests {
+		if _, _, err := RunCatalogQuery(nil, sql); err == nil {
+			t.Errorf("%s succeeded, want an error", sql)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// PickRandomModel returns a uniformly random model
+// Models are ordered by ID first so that a given seed picks the same model
+// regardless of source order; a seed of 0 uses the current time
+func PickRandomModel(models []catalog.Model, seed int64) catalog.Model {
+	sorted := make([]catalog.Model, len(models))
+	copy(sorted, models)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	r := rand.New(rand.NewSource(seed))
+	return sorted[r.Intn(len(sorted))]
+}
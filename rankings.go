@@ -0,0 +1,9 @@
+package main
+
+// FormatRank formats a popularity rank as e.g. "#3", or "-" for unranked models
+func FormatRank(rank int) string {
+	if rank == 0 {
+		return "-"
+	}
+	return "#" + FormatNumber(rank)
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// rateLimitTTL is how long probed rate limits are reused
+const rateLimitTTL = 24 * time.Hour
+
+// rateLimitProvider describes how to probe one first-party API
+type rateLimitProvider struct {
+	KeyEnv         string
+	URL            string
+	Headers        func(key string) map[string]string
+	NativeID       func(id string) string
+	MaxTokensField string // Request field limiting the reply length
+	// Response headers per limit, first present wins
+	RequestHeaders     []string
+	TokenHeaders       []string
+	InputTokenHeaders  []string
+	OutputTokenHeaders []string
+}
+
+// dateSuffix matches model IDs that already end in a version or date
+var dateSuffix = regexp.MustCompile(`\d$`)
+
+// rateLimitProviders are the providers whose limits can be probed, keyed by provider prefix
+var rateLimitProviders = map[string]rateLimitProvider{
+	"openai": {
+		KeyEnv:         "OPENAI_API_KEY",
+		URL:            "https://api.openai.com/v1/chat/completions",
+		Headers:        func(key string) map[string]string { return map[string]string{"Authorization": "Bearer " + key} },
+		NativeID:       catalog.ModelSuffix,
+		MaxTokensField: "max_completion_tokens",
+		RequestHeaders: []string{"x-ratelimit-limit-requests"},
+		TokenHeaders:   []string{"x-ratelimit-limit-tokens"},
+	},
+	"anthropic": {
+		KeyEnv: "ANTHROPIC_API_KEY",
+		URL:    "https://api.anthropic.com/v1/messages",
+		Headers: func(key string) map[string]string {
+			return map[string]string{"x-api-key": key, "anthropic-version": "2023-06-01"}
+		},
+		// OpenRouter writes versions with dots (claude-opus-4.5), Anthropic with dashes
+		NativeID:           func(id string) string { return strings.ReplaceAll(catalog.ModelSuffix(id), ".", "-") },
+		MaxTokensField:     "max_tokens",
+		RequestHeaders:     []string{"anthropic-ratelimit-requests-limit"},
+		TokenHeaders:       []string{"anthropic-ratelimit-tokens-limit"},
+		InputTokenHeaders:  []string{"anthropic-ratelimit-input-tokens-limit"},
+		OutputTokenHeaders: []string{"anthropic-ratelimit-output-tokens-limit"},
+	},
+	"mistralai": {
+		KeyEnv:  "MISTRAL_API_KEY",
+		URL:     "https://api.mistral.ai/v1/chat/completions",
+		Headers: func(key string) map[string]string { return map[string]string{"Authorization": "Bearer " + key} },
+		NativeID: func(id string) string {
+			name := catalog.ModelSuffix(id)
+			if !dateSuffix.MatchString(name) {
+				name += "-latest"
+			}
+			return name
+		},
+		MaxTokensField: "max_tokens",
+		RequestHeaders: []string{"x-ratelimit-limit-req-minute", "x-ratelimit-limit-requests"},
+		TokenHeaders:   []string{"x-ratelimitbysize-limit-minute", "x-ratelimit-limit-tokens"},
+	},
+}
+
+// rateLimitCachePath returns the file caching probed limits
+func rateLimitCachePath() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+	return filepath.Join(cacheDir, "llmls", "ratelimits.json"), nil
+}
+
+// loadRateLimitCache reads cached limits; a missing or unreadable cache starts empty
+func loadRateLimitCache() map[string]catalog.RateLimits {
+	cache := make(map[string]catalog.RateLimits)
+	if path, err := rateLimitCachePath(); err == nil {
+		if data, err := os.ReadFile(path); err == nil {
+			json.Unmarshal(data, &cache)
+		}
+	}
+	return cache
+}
+
+// saveRateLimitCache writes cached limits
+func saveRateLimitCache(cache map[string]catalog.RateLimits) error {
+	path, err := rateLimitCachePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return fmt.Errorf("failed to encode rate limit cache: %w", err)
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// headerLimit returns the first present header among names as a number
+func headerLimit(h http.Header, names []string) int64 {
+	for _, name := range names {
+		if v := h.Get(name); v != "" {
+			if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+				return n
+			}
+		}
+	}
+	return 0
+}
+
+// probeRateLimits sends a one-token request and reads the limit headers of the response
+// Limits are tied to the account tier, so a real request is the only reliable source
+func probeRateLimits(p rateLimitProvider, key, nativeID string) (catalog.RateLimits, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"model":          nativeID,
+		p.MaxTokensField: 1,
+		"messages":       []map[string]string{{"role": "user", "content": "hi"}},
+	})
+	if err != nil {
+		return catalog.RateLimits{}, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.URL, bytes.NewReader(body))
+	if err != nil {
+		return catalog.RateLimits{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range p.Headers(key) {
+		req.Header.Set(name, value)
+	}
+
+	client := &http.Client{Timeout: 15 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return catalog.RateLimits{}, fmt.Errorf("request failed: %w", err)
+	}
+	resp.Body.Close()
+
+	limits := catalog.RateLimits{
+		Requests:     headerLimit(resp.Header, p.RequestHeaders),
+		Tokens:       headerLimit(resp.Header, p.TokenHeaders),
+		InputTokens:  headerLimit(resp.Header, p.InputTokenHeaders),
+		OutputTokens: headerLimit(resp.Header, p.OutputTokenHeaders),
+		CheckedAt:    time.Now().UTC(),
+	}
+	if limits.Requests == 0 && limits.Tokens == 0 && limits.InputTokens == 0 && limits.OutputTokens == 0 {
+		if resp.StatusCode != http.StatusOK {
+			return catalog.RateLimits{}, fmt.Errorf("status %d for %s", resp.StatusCode, nativeID)
+		}
+		return catalog.RateLimits{}, fmt.Errorf("no rate limit headers returned")
+	}
+	return limits, nil
+}
+
+// FillRateLimits sets RateLimits on models from first-party providers with an
+// API key in the environment, reusing limits probed within the last day
+func FillRateLimits(models []catalog.Model) {
+	cache := loadRateLimitCache()
+	updated := false
+
+	for i, model := range models {
+		p, ok := rateLimitProviders[catalog.ExtractProvider(model.ID)]
+		if !ok {
+			continue
+		}
+		key := os.Getenv(p.KeyEnv)
+		if key == "" {
+			continue
+		}
+
+		// Limits depend on the account, so cache them per API key
+		cacheKey := textHash(key)[:12] + " " + model.ID
+		if cached, ok := cache[cacheKey]; ok && time.Since(cached.CheckedAt) < rateLimitTTL {
+			models[i].RateLimits = &cached
+			continue
+		}
+
+		scheduler.Wait(firstPartyNames[catalog.ExtractProvider(model.ID)])
+		limits, err := probeRateLimits(p, key, p.NativeID(model.ID))
+		if err != nil {
+			models[i].RateLimits = &catalog.RateLimits{Error: err.Error()}
+			continue
+		}
+		cache[cacheKey] = limits
+		models[i].RateLimits = &limits
+		updated = true
+	}
+
+	if updated {
+		if err := saveRateLimitCache(cache); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+}
+
+// FormatRateLimits renders limits as e.g. "500 RPM · 30,000 TPM"
+func FormatRateLimits(limits catalog.RateLimits) string {
+	if limits.Error != "" {
+		return "unavailable (" + limits.Error + ")"
+	}
+	var parts []string
+	if limits.Requests > 0 {
+		parts = append(parts, FormatNumber(int(limits.Requests))+" RPM")
+	}
+	if limits.Tokens > 0 {
+		parts = append(parts, FormatNumber(int(limits.Tokens))+" TPM")
+	}
+	if limits.InputTokens > 0 {
+		parts = append(parts, FormatNumber(int(limits.InputTokens))+" input TPM")
+	}
+	if limits.OutputTokens > 0 {
+		parts = append(parts, FormatNumber(int(limits.OutputTokens))+" output TPM")
+	}
+	return strings.Join(parts, " · ")
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// defaultReportPeriod is the period of the catalog report: one week
+const defaultReportPeriod = 7 * 24 * time.Hour
+
+// lastReportFile records when the daemon last mailed a report, in the snapshot directory
+const lastReportFile = "last-report"
+
+// MailConfig holds the recipients of the report and how it is delivered
+type MailConfig struct {
+	To       string // Comma-separated recipients
+	SMTP     string // SMTP server host:port; sendmail is used when empty
+	From     string
+	Sendmail string // sendmail-compatible program
+}
+
+// RegisterFlags adds the mail flags to fs
+func (c *MailConfig) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.To, "email", "", "Comma-separated addresses to mail the report to")
+	fs.StringVar(&c.SMTP, "smtp", "", "SMTP server `host:port` (default: deliver with sendmail); credentials\nare read from LLMLS_SMTP_USER and LLMLS_SMTP_PASSWORD")
+	fs.StringVar(&c.From, "mail-from", "", "Sender address (default: $LLMLS_MAIL_FROM or llmls@<hostname>)")
+	fs.StringVar(&c.Sendmail, "sendmail", "sendmail", "sendmail-compatible program used without --smtp")
+}
+
+// Validate checks the mail settings
+func (c *MailConfig) Validate() error {
+	for _, addr := range c.Recipients() {
+		if !strings.Contains(addr, "@") {
+			return fmt.Errorf("invalid email address: %s", addr)
+		}
+	}
+	if c.SMTP != "" {
+		if _, _, err := net.SplitHostPort(c.SMTP); err != nil {
+			return fmt.Errorf("invalid --smtp server (expected host:port): %s", c.SMTP)
+		}
+	}
+	return nil
+}
+
+// Recipients returns the addresses of --email
+func (c *MailConfig) Recipients() []string {
+	var addrs []string
+	for _, addr := range strings.Split(c.To, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+// sender returns the From address
+func (c *MailConfig) sender() string {
+	if c.From != "" {
+		return c.From
+	}
+	if from := os.Getenv("LLMLS_MAIL_FROM"); from != "" {
+		return from
+	}
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "localhost"
+	}
+	return "llmls@" + host
+}
+
+// Send mails a plain-text message to the recipients, through the SMTP
+// server if configured and sendmail otherwise
+// SMTP credentials are read from LLMLS_SMTP_USER and LLMLS_SMTP_PASSWORD
+func (c *MailConfig) Send(subject, body string) error {
+	to := c.Recipients()
+	from := c.sender()
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\n", from)
+	fmt.Fprintf(&msg, "To: %s\n", strings.Join(to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\n")
+	msg.WriteString("Content-Transfer-Encoding: 8bit\n\n")
+	msg.WriteString(body)
+
+	if dryRun {
+		fmt.Fprintf(os.Stderr, "[dry-run] mail %q to %s\n", subject, strings.Join(to, ", "))
+		return nil
+	}
+
+	if c.SMTP == "" {
+		cmd := exec.Command(c.Sendmail, "-t", "-i")
+		cmd.Stdin = &msg
+		cmd.Stdout = os.Stderr
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("sendmail failed: %w", err)
+		}
+		return nil
+	}
+
+	var auth smtp.Auth
+	if user := os.Getenv("LLMLS_SMTP_USER"); user != "" {
+		host, _, _ := net.SplitHostPort(c.SMTP)
+		auth = smtp.PlainAuth("", user, os.Getenv("LLMLS_SMTP_PASSWORD"), host)
+	}
+	// SMTP requires CRLF line endings
+	data := bytes.ReplaceAll(msg.Bytes(), []byte("\n"), []byte("\r\n"))
+	if err := smtp.SendMail(c.SMTP, auth, from, to, data); err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	return nil
+}
+
+// Report is a digest of catalog changes over a period and the current catalog
+type Report struct {
+	GeneratedAt time.Time
+	Baseline    *Snapshot // Latest snapshot taken before the period, nil if none
+	Diff        CatalogDiff
+	Models      []catalog.Model
+}
+
+// BuildReport compares the current catalog with the snapshot of the start of
+// the period, or the oldest snapshot when the history is shorter
+func BuildReport(current *Snapshot, period time.Duration) (Report, error) {
+	report := Report{GeneratedAt: time.Now(), Models: current.Models}
+	paths, err := ListSnapshotFiles()
+	if err != nil {
+		return report, err
+	}
+
+	start := report.GeneratedAt.Add(-period)
+	for i, path := range paths {
+		snapshot, err := LoadSnapshot(path)
+		if err != nil {
+			return report, err
+		}
+		if i > 0 && snapshot.TakenAt.After(start) {
+			break
+		}
+		report.Baseline = snapshot
+	}
+	if report.Baseline != nil {
+		report.Diff = CompareSnapshots(report.Baseline, current)
+	}
+	return report, nil
+}
+
+// Subject returns the mail subject summarizing the changes
+func (r Report) Subject() string {
+	return fmt.Sprintf("llmls report %s: %d added, %d removed, %d changed",
+		r.GeneratedAt.Format("2006-01-02"), len(r.Diff.Added), len(r.Diff.Removed), len(r.Diff.Changed))
+}
+
+// Text renders the report: the changes since the baseline, with old and new
+// prices of repriced models, followed by a table of the current catalog
+func (r Report) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "llmls catalog report · %s\n\n", r.GeneratedAt.Format("2006-01-02 15:04"))
+
+	if r.Baseline == nil {
+		b.WriteString("No snapshots yet; run 'llmls snapshot' or 'llmls daemon' to track changes.\n")
+	} else {
+		fmt.Fprintf(&b, "Changes since %s: %d added, %d removed, %d changed\n",
+			r.Baseline.TakenAt.Local().Format("2006-01-02 15:04"), len(r.Diff.Added), len(r.Diff.Removed), len(r.Diff.Changed))
+		oldByID := make(map[string]catalog.Model, len(r.Baseline.Models))
+		for _, model := range r.Baseline.Models {
+			oldByID[model.ID] = model
+		}
+		for _, model := range r.Diff.Added {
+			fmt.Fprintf(&b, "  + %s  %s\n", model.ID, FormatModelPrice(model))
+		}
+		for _, model := range r.Diff.Removed {
+			fmt.Fprintf(&b, "  - %s\n", model.ID)
+		}
+		for _, model := range r.Diff.Changed {
+			old := oldByID[model.ID]
+			if old.Pricing.Prompt != model.Pricing.Prompt || old.Pricing.Completion != model.Pricing.Completion {
+				fmt.Fprintf(&b, "  ~ %s  %s -> %s\n", model.ID, FormatModelPrice(old), FormatModelPrice(model))
+			} else {
+				fmt.Fprintf(&b, "  ~ %s\n", model.ID)
+			}
+		}
+	}
+
+	providers := make(map[string]bool)
+	idWidth := len("MODEL")
+	for _, model := range r.Models {
+		providers[catalog.ModelProvider(model)] = true
+		idWidth = max(idWidth, len(model.ID))
+	}
+	fmt.Fprintf(&b, "\nCurrent catalog: %s models · %s providers\n\n", FormatNumber(len(r.Models)), FormatNumber(len(providers)))
+	fmt.Fprintf(&b, "%-*s %12s  %s\n", idWidth, "MODEL", "CONTEXT", "PRICE/1K")
+	for _, model := range r.Models {
+		fmt.Fprintf(&b, "%-*s %12s  %s\n", idWidth, model.ID, FormatNumber(model.ContextLength), FormatModelPrice(model))
+	}
+	return b.String()
+}
+
+// ReportDue reports whether a scheduled report is due every period
+// The first call records the current time, so a new daemon waits a full
+// period before its first report
+func ReportDue(period time.Duration) (bool, error) {
+	dir, err := GetSnapshotDir()
+	if err != nil {
+		return false, err
+	}
+	info, err := os.Stat(filepath.Join(dir, lastReportFile))
+	if os.IsNotExist(err) {
+		return false, MarkReportSent()
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read report time: %w", err)
+	}
+	return time.Since(info.ModTime()) >= period, nil
+}
+
+// MarkReportSent records that a scheduled report was sent now
+// Dry runs leave the record untouched
+func MarkReportSent() error {
+	dir, err := GetSnapshotDir()
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		fmt.Fprintf(os.Stderr, "[dry-run] write %s\n", filepath.Join(dir, lastReportFile))
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	stamp := []byte(time.Now().UTC().Format(time.RFC3339) + "\n")
+	if err := os.WriteFile(filepath.Join(dir, lastReportFile), stamp, 0o644); err != nil {
+		return fmt.Errorf("failed to record report time: %w", err)
+	}
+	return nil
+}
+
+// SendScheduledReport mails the report when one is due
+func SendScheduledReport(sourceConfig *SourceConfig, c WatchConfig) error {
+	due, err := ReportDue(c.ReportEvery)
+	if err != nil || !due {
+		return err
+	}
+
+	var tunnels TunnelSet
+	current, err := sourceConfig.FetchSnapshot(&tunnels)
+	tunnels.Close()
+	if err != nil {
+		return err
+	}
+	report, err := BuildReport(current, c.ReportEvery)
+	if err != nil {
+		return err
+	}
+	if err := c.Mail.Send(report.Subject(), report.Text()); err != nil {
+		return err
+	}
+	return MarkReportSent()
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultRPM are the request-per-minute limits of bulk requests (test, duel,
+// --rate-limits) per API, keyed by lowercase API name; conservative enough
+// for entry-level accounts; 0 or a missing entry means no limit
+var defaultRPM = map[string]float64{
+	"openrouter": 60,
+	"openai":     60,
+	"anthropic":  50,
+	"mistral":    60,
+	"ollama":     0, // Local; requests queue on the server
+}
+
+// Scheduler spaces requests to each API with a token bucket, so bulk
+// probing stays under the API's rate limit; safe for concurrent use
+type Scheduler struct {
+	mu      sync.Mutex
+	rpm     map[string]float64
+	buckets map[string]*tokenBucket
+	swept   time.Time // Last eviction of idle client buckets
+}
+
+// sweepInterval is how often Allow evicts the buckets of idle clients
+const sweepInterval = time.Minute
+
+// tokenBucket holds the requests an API can take right now; tokens go
+// negative when requests are reserved ahead of time
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// scheduler is shared by all requests of a run; configured by InitScheduler
+var scheduler = NewScheduler(defaultRPM)
+
+// NewScheduler returns a scheduler with the given per-API RPM limits
+func NewScheduler(rpm map[string]float64) *Scheduler {
+	limits := make(map[string]float64, len(rpm))
+	for api, limit := range rpm {
+		limits[api] = limit
+	}
+	return &Scheduler{rpm: limits, buckets: make(map[string]*tokenBucket)}
+}
+
+// burst returns how many requests may be sent at once: ten seconds' worth
+func burst(rpm float64) float64 {
+	return max(1, rpm/6)
+}
+
+// reserve takes a token from the API's bucket and returns how long to wait
+// before the request may be sent
+func (s *Scheduler) reserve(api string) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rpm := s.rpm[api]
+	if rpm <= 0 {
+		return 0
+	}
+	b := s.refill(api, rpm)
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / (rpm / 60) * float64(time.Second))
+}
+
+// refill returns the bucket of key, topped up for the time since its last use
+func (s *Scheduler) refill(key string, rpm float64) *tokenBucket {
+	now := time.Now()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: burst(rpm), last: now}
+		s.buckets[key] = b
+	}
+	b.tokens = min(burst(rpm), b.tokens+now.Sub(b.last).Seconds()*rpm/60)
+	b.last = now
+	return b
+}
+
+// Allow takes a token from client's bucket under the limit of api without
+// waiting, as a server does; when the bucket is empty, nothing is taken and
+// Allow returns how long until a token is available
+func (s *Scheduler) Allow(api, client string) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rpm := s.rpm[api]
+	if rpm <= 0 {
+		return 0
+	}
+	s.sweep()
+	b := s.refill(api+" "+client, rpm)
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / (rpm / 60) * float64(time.Second))
+	}
+	b.tokens--
+	return 0
+}
+
+// sweep evicts the client buckets that have refilled to a full burst, which
+// a new bucket starts with anyway, so a server's memory does not grow with
+// every client it has seen
+func (s *Scheduler) sweep() {
+	now := time.Now()
+	if now.Sub(s.swept) < sweepInterval {
+		return
+	}
+	s.swept = now
+	for key, b := range s.buckets {
+		api, _, ok := strings.Cut(key, " ")
+		if !ok {
+			continue // An API bucket of Wait
+		}
+		rpm := s.rpm[api]
+		if rpm <= 0 || b.tokens+now.Sub(b.last).Seconds()*rpm/60 >= burst(rpm) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// Wait blocks until a request to the named API (e.g. OpenRouter or
+// Anthropic, as in ChatBackend.Name) fits within its rate limit
+func (s *Scheduler) Wait(api string) {
+	api = strings.ToLower(api)
+	if delay := s.reserve(api); delay > 0 {
+		debugf("waiting %s for the %s rate limit", delay.Round(time.Millisecond), api)
+		time.Sleep(delay)
+	}
+}
+
+// ParseRPM parses RPM limits such as "openrouter=20,anthropic=10"; a
+// limit of 0 disables rate limiting for that API
+func ParseRPM(spec string) (map[string]float64, error) {
+	limits := make(map[string]float64)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		api, value, ok := strings.Cut(entry, "=")
+		api = strings.ToLower(strings.TrimSpace(api))
+		if !ok {
+			return nil, fmt.Errorf("invalid rate limit: %s (expected api=rpm, e.g. openrouter=20)", entry)
+		}
+		if _, known := defaultRPM[api]; !known {
+			return nil, fmt.Errorf("unknown API in rate limit: %s (expected %s)", api, strings.Join(rpmAPIs(), ", "))
+		}
+		rpm, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || rpm < 0 {
+			return nil, fmt.Errorf("invalid rate limit for %s: %s", api, value)
+		}
+		limits[api] = rpm
+	}
+	return limits, nil
+}
+
+// rpmAPIs returns the names accepted in RPM limits, sorted
+func rpmAPIs() []string {
+	var names []string
+	for api := range defaultRPM {
+		names = append(names, api)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// InitScheduler applies RPM limits from LLMLS_RPM and then the --rpm flag
+// over the defaults
+func InitScheduler(flagRPM string) error {
+	limits := make(map[string]float64)
+	for api, rpm := range defaultRPM {
+		limits[api] = rpm
+	}
+	for _, spec := range []string{os.Getenv("LLMLS_RPM"), flagRPM} {
+		overrides, err := ParseRPM(spec)
+		if err != nil {
+			return err
+		}
+		for api, rpm := range overrides {
+			limits[api] = rpm
+		}
+	}
+	scheduler = NewScheduler(limits)
+	return nil
+}
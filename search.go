@@ -0,0 +1,134 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// BM25 ranking parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// nameBoost is how many times ID and name terms count relative to description terms
+const nameBoost = 3
+
+// searchStopWords are ignored in documents and queries
+var searchStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"by": true, "for": true, "from": true, "in": true, "is": true, "it": true, "its": true,
+	"of": true, "on": true, "or": true, "that": true, "the": true, "this": true, "to": true,
+	"with": true,
+}
+
+// searchTerms lowercases text and splits it into terms, dropping stop words and
+// a plural "s" so "tools" matches "tool"
+func searchTerms(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := fields[:0]
+	for _, term := range fields {
+		if searchStopWords[term] {
+			continue
+		}
+		if len(term) > 3 && strings.HasSuffix(term, "s") && !strings.HasSuffix(term, "ss") {
+			term = strings.TrimSuffix(term, "s")
+		}
+		terms = append(terms, term)
+	}
+	return terms
+}
+
+// SearchIndex is an inverted index over model IDs, names, and descriptions
+type SearchIndex struct {
+	models    []catalog.Model
+	postings  map[string]map[int]int // term -> document -> term frequency
+	docLength []int
+	avgLength float64
+}
+
+// NewSearchIndex indexes models for ranked keyword search
+func NewSearchIndex(models []catalog.Model) *SearchIndex {
+	idx := &SearchIndex{
+		models:    models,
+		postings:  make(map[string]map[int]int),
+		docLength: make([]int, len(models)),
+	}
+
+	total := 0
+	for doc, model := range models {
+		add := func(text string, weight int) {
+			for _, term := range searchTerms(text) {
+				if idx.postings[term] == nil {
+					idx.postings[term] = make(map[int]int)
+				}
+				idx.postings[term][doc] += weight
+				idx.docLength[doc] += weight
+			}
+		}
+		add(model.ID, nameBoost)
+		add(model.Name, nameBoost)
+		add(model.Description, 1)
+		total += idx.docLength[doc]
+	}
+	if len(models) > 0 {
+		idx.avgLength = float64(total) / float64(len(models))
+	}
+	return idx
+}
+
+// SearchResult is a model with its relevance score
+type SearchResult struct {
+	Model catalog.Model
+	Score float64
+}
+
+// Search ranks models against the query with BM25 and returns up to limit
+// matches, best first; models matching no query term are omitted
+func (idx *SearchIndex) Search(query string, limit int) []SearchResult {
+	scores := make(map[int]float64)
+	n := float64(len(idx.models))
+
+	seen := make(map[string]bool)
+	for _, term := range searchTerms(query) {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+
+		postings := idx.postings[term]
+		df := float64(len(postings))
+		if df == 0 {
+			continue
+		}
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for doc, tf := range postings {
+			f := float64(tf)
+			norm := 1 - bm25B + bm25B*float64(idx.docLength[doc])/idx.avgLength
+			scores[doc] += idf * f * (bm25K1 + 1) / (f + bm25K1*norm)
+		}
+	}
+
+	results := make([]SearchResult, 0, len(scores))
+	for doc, score := range scores {
+		results = append(results, SearchResult{Model: idx.models[doc], Score: score})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Model.ID < results[j].Model.ID
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
@@ -0,0 +1,386 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// defaultServeAddr is the listen address of llmls serve
+const defaultServeAddr = ":8090"
+
+// modelsEndpoint is the path of the model listing API of llmls serve
+const modelsEndpoint = "/v1/models"
+
+// openAPIEndpoint is the path of the OpenAPI document of llmls serve
+const openAPIEndpoint = "/openapi.json"
+
+// serveParam is a query parameter of a serve route
+type serveParam struct {
+	Name        string
+	Description string
+	Required    bool
+	Type        string   // JSON Schema type of the value, "" for string
+	Enum        []string // Accepted values, if restricted
+}
+
+// serveRoute is an endpoint of llmls serve
+// The routes are the single source of the mux and the OpenAPI document, and
+// requests are checked against their methods and required parameters before
+// the handler runs, so the served API cannot drift from the published one
+type serveRoute struct {
+	Path        string
+	OperationID string
+	Summary     string
+	Params      []serveParam
+	ContentType string // Of successful responses
+	Schema      string // OutputSchema of the response body; "" for plain text or any JSON
+	Errors      []int  // Statuses the handler may answer besides those checked here
+	Public      bool   // Answered without the bearer token, e.g. for load balancer probes
+	handler     http.HandlerFunc
+}
+
+// ServeOptions secure llmls serve for use by browsers and other teams
+type ServeOptions struct {
+	CORSOrigins []string           // Origins allowed to call the API from browsers; "*" allows any
+	Token       string             // Bearer token required on non-public routes, "" for none
+	RateLimits  map[string]float64 // Requests per minute per client, keyed by route path
+}
+
+// GetServeToken returns the bearer token of llmls serve from LLMLS_SERVE_TOKEN;
+// it is read from the environment so it does not show in process listings
+func GetServeToken() string {
+	return os.Getenv("LLMLS_SERVE_TOKEN")
+}
+
+// ParseCORSOrigins parses comma-separated origins such as
+// "https://dash.example.com"; "*" allows any origin
+func ParseCORSOrigins(spec string) ([]string, error) {
+	var origins []string
+	for _, origin := range strings.Split(spec, ",") {
+		origin = strings.TrimSuffix(strings.TrimSpace(origin), "/")
+		if origin == "" {
+			continue
+		}
+		if origin != "*" {
+			u, err := url.Parse(origin)
+			if err != nil || u.Scheme == "" || u.Host == "" || u.Path != "" {
+				return nil, fmt.Errorf("invalid CORS origin: %s (expected scheme://host[:port], e.g. https://dash.example.com)", origin)
+			}
+		}
+		origins = append(origins, origin)
+	}
+	return origins, nil
+}
+
+// ParseServeRateLimits parses per-route limits such as "/v1/cache=60,/openapi.json=10"
+// in requests per minute per client; a bare number limits every route
+func ParseServeRateLimits(spec string) (map[string]float64, error) {
+	limits := make(map[string]float64)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		path, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			path, value = "", entry
+		}
+		path = strings.TrimSpace(path)
+		rpm, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || rpm < 0 {
+			return nil, fmt.Errorf("invalid rate limit: %s (expected rpm or path=rpm, e.g. %s=60)", entry, cacheEndpoint)
+		}
+		if path != "" && !isServePath(path) {
+			return nil, fmt.Errorf("unknown route in rate limit: %s (expected %s)", path, strings.Join(servePaths(), ", "))
+		}
+		if path != "" {
+			limits[path] = rpm
+			continue
+		}
+		for _, p := range servePaths() {
+			if _, set := limits[p]; !set {
+				limits[p] = rpm
+			}
+		}
+	}
+	return limits, nil
+}
+
+// servePaths returns the paths of the routes of llmls serve
+func servePaths() []string {
+	var paths []string
+	for _, route := range serveRoutes() {
+		paths = append(paths, route.Path)
+	}
+	return paths
+}
+
+// isServePath reports whether path is a route of llmls serve
+func isServePath(path string) bool {
+	for _, p := range servePaths() {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+// serveRoutes returns the endpoints of llmls serve; all answer GET and HEAD
+func serveRoutes() []serveRoute {
+	return []serveRoute{
+		{
+			Path:        "/",
+			OperationID: "getWebUI",
+			Summary:     "Browse the catalog in the web UI, backed by " + modelsEndpoint,
+			ContentType: "text/html",
+			Public:      true, // The page asks for the token and sends it to the API
+			handler:     handleWebUI,
+		},
+		{
+			Path:        "/healthz",
+			OperationID: "getHealth",
+			Summary:     "Report that the server is up",
+			ContentType: "text/plain",
+			Public:      true,
+			handler:     handleHealthz,
+		},
+		{
+			Path:        cacheEndpoint,
+			OperationID: "getCachedCatalog",
+			Summary:     "Return a vendor catalog from the server's cache, refreshing it in the background when stale",
+			Params: []serveParam{{
+				Name:        "url",
+				Description: "Vendor catalog URL",
+				Required:    true,
+				Enum:        catalog.CacheableURLs(),
+			}},
+			ContentType: "application/json",
+			Schema:      "serve-cache",
+			Errors:      []int{http.StatusBadGateway},
+			handler:     handleCache,
+		},
+		{
+			Path:        modelsEndpoint,
+			OperationID: "listModels",
+			Summary:     "List models from every source, filtered and sorted as by the listing flags of the same names",
+			Params: []serveParam{
+				{Name: "pattern", Description: "Glob over ID or name, or a provider, e.g. anthropic/*"},
+				{Name: "where", Description: "Filter expression, as --where, e.g. context >= 128000"},
+				{Name: "series", Description: "Model series, as --series"},
+				{Name: "type", Description: "Model type", Enum: catalog.ModelTypes},
+				{Name: "variant", Description: "Routing variant, as --variant", Enum: append(append([]string{}, catalog.ModelVariants...), catalog.VariantNone)},
+				{Name: "sort", Description: "Sort keys, as --sort, e.g. provider,created:desc"},
+				{Name: "limit", Description: "Maximum number of models", Type: "integer"},
+			},
+			ContentType: "application/json",
+			Schema:      "models",
+			Errors:      []int{http.StatusBadGateway},
+			handler:     handleModels,
+		},
+		{
+			Path:        openAPIEndpoint,
+			OperationID: "getOpenAPI",
+			Summary:     "Return this OpenAPI document",
+			ContentType: "application/json",
+			Errors:      []int{http.StatusInternalServerError},
+			handler:     handleOpenAPI,
+		},
+	}
+}
+
+// NewServeMux returns the HTTP handlers of llmls serve
+func NewServeMux(opts ServeOptions) *http.ServeMux {
+	limiter := NewScheduler(opts.RateLimits)
+	mux := http.NewServeMux()
+	for _, route := range serveRoutes() {
+		mux.HandleFunc(route.Path, func(w http.ResponseWriter, r *http.Request) {
+			route.serve(w, r, opts, limiter)
+		})
+	}
+	return mux
+}
+
+// serve checks a request against the route and the options before handling it:
+// CORS preflights are answered before authentication, since browsers send
+// them without credentials, and rate limits apply per client address
+func (route serveRoute) serve(w http.ResponseWriter, r *http.Request, opts ServeOptions, limiter *Scheduler) {
+	allowCORS(w, r, opts.CORSOrigins)
+	if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD, OPTIONS")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if opts.Token != "" && !route.Public && !validBearer(r, opts.Token) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="llmls"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if wait := limiter.Allow(route.Path, clientAddr(r)); wait > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+		return
+	}
+	query := r.URL.Query()
+	for _, param := range route.Params {
+		if param.Required && query.Get(param.Name) == "" {
+			http.Error(w, "missing parameter: "+param.Name, http.StatusBadRequest)
+			return
+		}
+	}
+	route.handler(w, r)
+}
+
+// allowCORS adds CORS headers to responses to an allowed origin
+func allowCORS(w http.ResponseWriter, r *http.Request, origins []string) {
+	origin := r.Header.Get("Origin")
+	if origin == "" || len(origins) == 0 {
+		return
+	}
+	w.Header().Add("Vary", "Origin")
+	for _, allowed := range origins {
+		if allowed == "*" || allowed == origin {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization")
+			w.Header().Set("Access-Control-Expose-Headers", "X-Cache, Retry-After")
+			w.Header().Set("Access-Control-Max-Age", "600")
+			return
+		}
+	}
+}
+
+// validBearer reports whether a request carries the bearer token, compared
+// in constant time
+func validBearer(r *http.Request, token string) bool {
+	given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
+
+// clientAddr returns the address rate limits are counted by; forwarded
+// headers are ignored since any client could set them
+func clientAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// Serve runs the llmls HTTP server on addr until it fails
+func Serve(addr string, opts ServeOptions) error {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           NewServeMux(opts),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return server.ListenAndServe()
+}
+
+// handleHealthz reports that the server is up
+func handleHealthz(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprintln(w, "ok")
+}
+
+// handleCache serves a vendor catalog from the server's cache (see ServeCached);
+// only CacheableURLs are served so the server cannot be used as an open proxy
+func handleCache(w http.ResponseWriter, r *http.Request) {
+	vendorURL := r.URL.Query().Get("url")
+	if !catalog.IsCacheableURL(vendorURL) {
+		http.Error(w, "not a cacheable catalog URL: "+vendorURL, http.StatusBadRequest)
+		return
+	}
+
+	// Never forward to another shared cache, which could loop back here
+	body, state, err := ServeCached(vendorURL)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Cache", state)
+	w.Write(body)
+}
+
+// serveTunnels are the SSH port-forwards of serveClient, open while the
+// server runs
+var serveTunnels TunnelSet
+
+// serveClient is the catalog client of llmls serve, created on first use
+var serveClient = sync.OnceValue(func() *catalog.Client {
+	ttl, _ := GetCacheTTL() // Validated by serveCommand
+	return catalog.NewClient(
+		catalog.WithCacheTTL(ttl),
+		catalog.WithSources((&SourceConfig{}).Sources(&serveTunnels)),
+		catalog.WithColumns(computedColumns),
+	)
+})
+
+// handleModels lists the models matching the query parameters as JSON
+func handleModels(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := catalog.Query{
+		Pattern: query.Get("pattern"),
+		Where:   query.Get("where"),
+		Series:  query.Get("series"),
+		Type:    query.Get("type"),
+		Variant: query.Get("variant"),
+		Sort:    query.Get("sort"),
+	}
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			http.Error(w, "invalid limit: "+limit, http.StatusBadRequest)
+			return
+		}
+		q.Limit = n
+	}
+
+	models, err := serveClient().Models(q)
+	var invalid *catalog.InvalidQueryError
+	switch {
+	case errors.As(err, &invalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	if models == nil {
+		models = []catalog.Model{}
+	}
+	body, err := json.Marshal(models)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+// handleOpenAPI serves the OpenAPI document of the routes
+func handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	doc, err := OpenAPIDocument()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(doc)
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// serveRequest sends a request to a mux of llmls serve and returns the response
+func serveRequest(mux http.Handler, method, path string, header map[string]string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, nil)
+	for name, value := range header {
+		r.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	return w
+}
+
+func TestServeAuth(t *testing.T) {
+	mux := NewServeMux(ServeOptions{Token: "secret"})
+	tests := []struct {
+		path   string
+		auth   string
+		status int
+	}{
+		{"/healthz", "", http.StatusOK},
+		{openAPIEndpoint, "", http.StatusUnauthorized},
+		{openAPIEndpoint, "Bearer wrong", http.StatusUnauthorized},
+		{openAPIEndpoint, "secret", http.StatusUnauthorized},
+		{openAPIEndpoint, "Bearer secret", http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := serveRequest(mux, http.MethodGet, tt.path, map[string]string{"Authorization": tt.auth})
+		if w.Code != tt.status {
+			t.Errorf("GET %s with %q = %d, want %d", tt.path, tt.auth, w.Code, tt.status)
+		}
+		if w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("GET %s with %q: no WWW-Authenticate header", tt.path, tt.auth)
+		}
+	}
+}
+
+func TestServeCORS(t *testing.T) {
+	mux := NewServeMux(ServeOptions{CORSOrigins: []string{"https://dash.example.com"}, Token: "secret"})
+
+	// Preflights are answered without the token
+	w := serveRequest(mux, http.MethodOptions, modelsEndpoint, map[string]string{
+		"Origin":                        "https://dash.example.com",
+		"Access-Control-Request-Method": "GET",
+	})
+	if w.Code != http.StatusNoContent {
+		t.Errorf("preflight = %d, want %d", w.Code, http.StatusNoContent)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://dash.example.com" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want the origin", got)
+	}
+
+	w = serveRequest(mux, http.MethodGet, "/healthz", map[string]string{"Origin": "https://evil.example.com"})
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Access-Control-Allow-Origin for another origin = %q, want none", got)
+	}
+
+	w = serveRequest(NewServeMux(ServeOptions{CORSOrigins: []string{"*"}}), http.MethodGet, "/healthz", map[string]string{"Origin": "https://any.example.com"})
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://any.example.com" {
+		t.Errorf("Access-Control-Allow-Origin with * = %q, want the origin", got)
+	}
+}
+
+func TestServeRateLimit(t *testing.T) {
+	mux := NewServeMux(ServeOptions{RateLimits: map[string]float64{"/healthz": 6}}) // A burst of one
+
+	if w := serveRequest(mux, http.MethodGet, "/healthz", nil); w.Code != http.StatusOK {
+		t.Fatalf("first request = %d, want %d", w.Code, http.StatusOK)
+	}
+	w := serveRequest(mux, http.MethodGet, "/healthz", nil)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+	if got := w.Header().Get("Retry-After"); got != "10" {
+		t.Errorf("Retry-After = %q, want 10", got)
+	}
+
+	// Other routes and other clients have their own buckets
+	if w := serveRequest(mux, http.MethodGet, openAPIEndpoint, nil); w.Code != http.StatusOK {
+		t.Errorf("unlimited route = %d, want %d", w.Code, http.StatusOK)
+	}
+	r := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	r.RemoteAddr = "198.51.100.7:4321"
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Errorf("another client = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestParseCORSOrigins(t *testing.T) {
+	got, err := ParseCORSOrigins("https://dash.example.com/, http://localhost:3000,*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"https://dash.example.com", "http://localhost:3000", "*"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	for _, spec := range []string{"dash.example.com", "https://dash.example.com/app"} {
+		if _, err := ParseCORSOrigins(spec); err == nil {
+			t.Errorf("ParseCORSOrigins(%q): expected error", spec)
+		}
+	}
+}
+
+func TestParseServeRateLimits(t *testing.T) {
+	got, err := ParseServeRateLimits("/v1/cache=60, 30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range servePaths() {
+		want := 30.0
+		if path == cacheEndpoint {
+			want = 60
+		}
+		if got[path] != want {
+			t.Errorf("limit of %s = %v, want %v", path, got[path], want)
+		}
+	}
+	for _, spec := range []string{"/v1/nope=10", "/healthz=fast", "-1"} {
+		if _, err := ParseServeRateLimits(spec); err == nil {
+			t.Errorf("ParseServeRateLimits(%q): expected error", spec)
+		}
+	}
+}
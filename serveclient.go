@@ -0,0 +1,52 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ServeClient calls the llmls serve API at a base URL, one method per
+// operation of its OpenAPI document
+type ServeClient struct {
+	BaseURL string
+	Token   string // Bearer token of a server run with LLMLS_SERVE_TOKEN
+}
+
+// NewServeClient returns a client of the llmls serve API at baseURL
+func NewServeClient(baseURL string) ServeClient {
+	return ServeClient{BaseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// GetHealth checks that the server is up (getHealth)
+func (c ServeClient) GetHealth() error {
+	_, err := c.get("/healthz", nil)
+	return err
+}
+
+// GetCachedCatalog returns a vendor catalog from the server's cache
+// (getCachedCatalog); vendorURL must be one of CacheableURLs
+func (c ServeClient) GetCachedCatalog(vendorURL string) ([]byte, error) {
+	return c.get(cacheEndpoint, url.Values{"url": {vendorURL}})
+}
+
+// GetOpenAPI returns the OpenAPI document of the server (getOpenAPI)
+func (c ServeClient) GetOpenAPI() ([]byte, error) {
+	return c.get(openAPIEndpoint, nil)
+}
+
+// get retrieves a path of the API
+func (c ServeClient) get(path string, query url.Values) ([]byte, error) {
+	target := c.BaseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	return fetchRequest(req)
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Session is a file of recorded HTTP exchanges written by --record and served
+// by --replay, so a run can be reproduced without the network
+// Request headers are not recorded, so sessions carry no API keys
+type Session struct {
+	RecordedAt time.Time         `json:"recorded_at"`
+	Version    string            `json:"version"`
+	Exchanges  []SessionExchange `json:"exchanges"`
+}
+
+// SessionExchange is one recorded request and its response
+type SessionExchange struct {
+	Method     string            `json:"method"`
+	URL        string            `json:"url"`                   // Credentials in the query are redacted
+	BodyHash   string            `json:"body_sha256,omitempty"` // Of the request body
+	StatusCode int               `json:"status"`
+	Header     map[string]string `json:"header,omitempty"`
+	Body       string            `json:"body"`
+}
+
+// key identifies the request of an exchange
+func (e SessionExchange) key() string {
+	return e.Method + " " + e.URL + " " + e.BodyHash
+}
+
+// sessionActive is set when --record or --replay is given; the catalog cache
+// is bypassed so every response comes from, or goes into, the session
+var sessionActive bool
+
+// readRequestBody reads and restores a request body, returning its hash
+func readRequestBody(req *http.Request) (string, error) {
+	if req.Body == nil {
+		return "", nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return "", err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	if len(body) == 0 {
+		return "", nil
+	}
+	return textHash(string(body)), nil
+}
+
+// recordTransport sends requests and appends each exchange to a session file
+type recordTransport struct {
+	next    http.RoundTripper
+	path    string
+	mu      sync.Mutex
+	session Session
+}
+
+// RoundTrip sends the request and records the response
+// The whole response is read before it is returned, so streamed replies
+// arrive at once while recording
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	bodyHash, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	exchange := SessionExchange{
+		Method:     req.Method,
+		URL:        redactURL(req.URL),
+		BodyHash:   bodyHash,
+		StatusCode: resp.StatusCode,
+		Header:     make(map[string]string),
+		Body:       string(body),
+	}
+	for name := range resp.Header {
+		if !strings.EqualFold(name, "Set-Cookie") {
+			exchange.Header[name] = resp.Header.Get(name)
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.session.Exchanges = append(t.session.Exchanges, exchange)
+	// Save after every exchange; commands exit with os.Exit, which skips deferred saves
+	if err := t.save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	return resp, nil
+}
+
+// save writes the session file
+func (t *recordTransport) save() error {
+	data, err := json.MarshalIndent(t.session, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+	if err := os.WriteFile(t.path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	return nil
+}
+
+// replayTransport answers requests from a session file
+// Repeated requests get the recorded responses in order
+type replayTransport struct {
+	path    string
+	mu      sync.Mutex
+	pending map[string][]SessionExchange
+}
+
+// RoundTrip returns the recorded response of the request
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	bodyHash, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	key := SessionExchange{Method: req.Method, URL: redactURL(req.URL), BodyHash: bodyHash}.key()
+
+	t.mu.Lock()
+	queue := t.pending[key]
+	if len(queue) == 0 {
+		t.mu.Unlock()
+		return nil, fmt.Errorf("no recorded response in %s", t.path)
+	}
+	exchange := queue[0]
+	if len(queue) > 1 {
+		t.pending[key] = queue[1:]
+	}
+	// The last response of a request is kept for any further repeats
+	t.mu.Unlock()
+
+	header := make(http.Header)
+	for name, value := range exchange.Header {
+		header.Set(name, value)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", exchange.StatusCode, http.StatusText(exchange.StatusCode)),
+		StatusCode:    exchange.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(exchange.Body)),
+		ContentLength: int64(len(exchange.Body)),
+		Request:       req,
+	}, nil
+}
+
+// StartRecording records all HTTP exchanges of the run to path
+func StartRecording(path string) {
+	sessionActive = true
+	http.DefaultTransport = &recordTransport{
+		next:    http.DefaultTransport,
+		path:    path,
+		session: Session{RecordedAt: time.Now().UTC(), Version: version},
+	}
+}
+
+// StartReplay answers all HTTP requests of the run from the session at path
+func StartReplay(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read session: %w", err)
+	}
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return fmt.Errorf("failed to parse session %s: %w", path, err)
+	}
+
+	t := &replayTransport{path: path, pending: make(map[string][]SessionExchange)}
+	for _, exchange := range session.Exchanges {
+		t.pending[exchange.key()] = append(t.pending[exchange.key()], exchange)
+	}
+	sessionActive = true
+	http.DefaultTransport = t
+	return nil
+}
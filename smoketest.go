@@ -0,0 +1,285 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// Smoke test outcomes
+const (
+	TestPass  = "pass"
+	TestFail  = "fail"
+	TestError = "error" // The request failed, e.g. the model does not support tools
+	TestSkip  = "skip"  // The model cannot run the case, e.g. too little context
+)
+
+// SmokeTestCase is one prompt of a suite and the check of its reply
+type SmokeTestCase struct {
+	Name       string
+	MinContext int // Skip models with a smaller context window
+	Request    func() ChatRequest
+	Check      func(ChatResult) error // nil error means pass
+}
+
+// smokeTestSuites are the built-in suites, keyed by name
+var smokeTestSuites = map[string][]SmokeTestCase{
+	"basic": {jsonModeCase, toolCallCase, longContextCase, multilingualCase},
+}
+
+// SmokeTestSuiteNames returns the names of the built-in suites, sorted
+func SmokeTestSuiteNames() []string {
+	var names []string
+	for name := range smokeTestSuites {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// userPrompt returns a request with a single user message
+func userPrompt(prompt string) ChatRequest {
+	return ChatRequest{Messages: []ChatMessage{{Role: "user", Content: prompt}}, MaxTokens: 256}
+}
+
+// stripCodeFence removes a Markdown code fence around a reply, which models
+// often add to JSON even when asked not to
+func stripCodeFence(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "```") {
+		return text
+	}
+	text = strings.TrimPrefix(text, "```")
+	if newline := strings.Index(text, "\n"); newline >= 0 {
+		text = text[newline+1:] // Drop the language tag
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text), "```"))
+}
+
+// jsonModeCase checks that the model returns a requested JSON object
+var jsonModeCase = SmokeTestCase{
+	Name: "json",
+	Request: func() ChatRequest {
+		req := userPrompt(`Return a JSON object with the key "name" set to the string "llmls" and the key "count" set to the number 3. Reply with the JSON object only.`)
+		req.JSONMode = true
+		return req
+	},
+	Check: func(r ChatResult) error {
+		var reply struct {
+			Name  string  `json:"name"`
+			Count float64 `json:"count"`
+		}
+		if err := json.Unmarshal([]byte(stripCodeFence(r.Text)), &reply); err != nil {
+			return fmt.Errorf("reply is not a JSON object: %q", truncateReply(r.Text))
+		}
+		if reply.Name != "llmls" || reply.Count != 3 {
+			return fmt.Errorf("unexpected values: %q", truncateReply(r.Text))
+		}
+		return nil
+	},
+}
+
+// toolCallNonce is the text the tool call case expects echoed back
+const toolCallNonce = "ping-4821"
+
+// toolCallCase checks that the model calls a tool with the requested argument
+var toolCallCase = SmokeTestCase{
+	Name: "tools",
+	Request: func() ChatRequest {
+		req := userPrompt(`Call the echo tool with the text "` + toolCallNonce + `".`)
+		req.Tools = []ChatTool{{
+			Name:        "echo",
+			Description: "Echo a text back to the user",
+			Parameters: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{"text": map[string]string{"type": "string"}},
+				"required":   []string{"text"},
+			},
+		}}
+		return req
+	},
+	Check: func(r ChatResult) error {
+		for _, call := range r.ToolCalls {
+			var args struct {
+				Text string `json:"text"`
+			}
+			if call.Name == "echo" && json.Unmarshal([]byte(call.Arguments), &args) == nil && args.Text == toolCallNonce {
+				return nil
+			}
+		}
+		if len(r.ToolCalls) == 0 {
+			return fmt.Errorf("no tool call; replied %q", truncateReply(r.Text))
+		}
+		return fmt.Errorf("unexpected tool call %s(%s)", r.ToolCalls[0].Name, r.ToolCalls[0].Arguments)
+	},
+}
+
+// longContextPassphrase is hidden in the filler text of the long-context case
+const longContextPassphrase = "violet-otter-97"
+
+// longContextWords is the filler length, about 8k tokens
+const longContextWords = 6000
+
+// longContextCase checks that the model finds a fact in the middle of a long prompt
+var longContextCase = SmokeTestCase{
+	Name:       "long-context",
+	MinContext: 16000,
+	Request: func() ChatRequest {
+		sentences := []string{
+			"The committee reviewed the quarterly figures and adjourned without comment.",
+			"Rain was expected in the northern districts later in the week.",
+			"The library extended its opening hours for the examination period.",
+			"Several trains were delayed because of maintenance on the main line.",
+		}
+		var b strings.Builder
+		words := 0
+		for i := 0; words < longContextWords; i++ {
+			if words >= longContextWords/2 && !strings.Contains(b.String(), longContextPassphrase) {
+				b.WriteString("The secret passphrase is " + longContextPassphrase + ". ")
+			}
+			sentence := sentences[i%len(sentences)]
+			b.WriteString(sentence + " ")
+			words += len(strings.Fields(sentence))
+		}
+		return userPrompt(b.String() + "\n\nWhat is the secret passphrase mentioned in the text above? Reply with the passphrase only.")
+	},
+	Check: func(r ChatResult) error {
+		if !strings.Contains(r.Text, longContextPassphrase) {
+			return fmt.Errorf("passphrase not found in reply %q", truncateReply(r.Text))
+		}
+		return nil
+	},
+}
+
+// multilingualCase checks that the model answers in Japanese
+var multilingualCase = SmokeTestCase{
+	Name: "multilingual",
+	Request: func() ChatRequest {
+		return userPrompt(`Translate "Good morning" into Japanese. Reply with the translation only, in Japanese script.`)
+	},
+	Check: func(r ChatResult) error {
+		if !strings.Contains(r.Text, "おはよう") {
+			return fmt.Errorf("expected おはよう, got %q", truncateReply(r.Text))
+		}
+		return nil
+	},
+}
+
+// truncateReply shortens a reply for error messages
+func truncateReply(text string) string {
+	return TruncateDescription(strings.TrimSpace(text), 60)
+}
+
+// SmokeTestResult is the outcome of one case against one model
+type SmokeTestResult struct {
+	Case    string        `json:"case"`
+	Outcome string        `json:"outcome"`
+	Detail  string        `json:"detail,omitempty"`
+	Latency time.Duration `json:"latency_ns"`
+	Cost    float64       `json:"cost"`
+}
+
+// SmokeTestReport is the outcome of a suite against one model
+type SmokeTestReport struct {
+	Model   string            `json:"model"`
+	Backend string            `json:"backend,omitempty"`
+	Results []SmokeTestResult `json:"results"`
+}
+
+// Passed reports whether no case failed or errored
+func (r SmokeTestReport) Passed() bool {
+	for _, result := range r.Results {
+		if result.Outcome == TestFail || result.Outcome == TestError {
+			return false
+		}
+	}
+	return true
+}
+
+// Latency returns the total latency of the cases that ran
+func (r SmokeTestReport) Latency() time.Duration {
+	var total time.Duration
+	for _, result := range r.Results {
+		total += result.Latency
+	}
+	return total
+}
+
+// Cost returns the total cost of the cases that ran
+func (r SmokeTestReport) Cost() float64 {
+	total := 0.0
+	for _, result := range r.Results {
+		total += result.Cost
+	}
+	return total
+}
+
+// RunSmokeTests runs each case of a suite against a model through backend
+func RunSmokeTests(model catalog.Model, backend ChatBackend, suite []SmokeTestCase) SmokeTestReport {
+	report := SmokeTestReport{Model: model.ID, Backend: backend.Name}
+	for _, c := range suite {
+		result := SmokeTestResult{Case: c.Name}
+		if c.MinContext > 0 && model.ContextLength > 0 && model.ContextLength < c.MinContext {
+			result.Outcome = TestSkip
+			result.Detail = fmt.Sprintf("context %s < %s", FormatNumber(model.ContextLength), FormatNumber(c.MinContext))
+			report.Results = append(report.Results, result)
+			continue
+		}
+
+		req := c.Request()
+		req.Model = model.ID
+		scheduler.Wait(backend.Name)
+		reply, err := backend.Chat(req, nil)
+		result.Latency = reply.Latency
+		result.Cost, _ = ChatCost(model, reply)
+		if err != nil {
+			result.Outcome, result.Detail = TestError, err.Error()
+		} else if err := c.Check(reply); err != nil {
+			result.Outcome, result.Detail = TestFail, err.Error()
+		} else {
+			result.Outcome = TestPass
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report
+}
+
+// DisplaySmokeTests prints a pass/fail matrix with latency and cost per model,
+// followed by the reasons for failures
+func DisplaySmokeTests(reports []SmokeTestReport, suite []SmokeTestCase) {
+	maxModelWidth := len("MODEL")
+	for _, r := range reports {
+		if len(r.Model) > maxModelWidth {
+			maxModelWidth = len(r.Model)
+		}
+	}
+
+	header := fmt.Sprintf("%-*s", maxModelWidth, "MODEL")
+	for _, c := range suite {
+		header += fmt.Sprintf(" %-*s", max(len(c.Name), 5), strings.ToUpper(c.Name))
+	}
+	fmt.Println(header + fmt.Sprintf(" %8s %10s", "LATENCY", "COST"))
+
+	var details []string
+	for _, r := range reports {
+		line := fmt.Sprintf("%-*s", maxModelWidth, r.Model)
+		for i, result := range r.Results {
+			line += fmt.Sprintf(" %-*s", max(len(suite[i].Name), 5), result.Outcome)
+			if result.Detail != "" {
+				details = append(details, fmt.Sprintf("%s %s: %s: %s", r.Model, result.Case, result.Outcome, result.Detail))
+			}
+		}
+		fmt.Println(line + fmt.Sprintf(" %7.2fs %10s", r.Latency().Seconds(), FormatCost(r.Cost())))
+	}
+
+	if len(details) > 0 {
+		fmt.Println()
+		for _, d := range details {
+			fmt.Println(d)
+		}
+	}
+}
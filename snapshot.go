@@ -0,0 +1,226 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// snapshotTimeFormat names snapshot files so they sort chronologically
+const snapshotTimeFormat = "20060102T150405Z"
+
+// Snapshot is a saved copy of the catalog at a point in time
+type Snapshot struct {
+	TakenAt time.Time       `json:"taken_at"`
+	Models  []catalog.Model `json:"models"`
+	Sources []string        `json:"sources,omitempty"` // Sources that answered (see ModelSourceName)
+}
+
+// ReachedSources returns the sources that answered when the snapshot was
+// taken; snapshots of older versions do not record them, so the sources of
+// their models are used
+func (s *Snapshot) ReachedSources() map[string]bool {
+	reached := make(map[string]bool)
+	if s.Sources != nil {
+		for _, source := range s.Sources {
+			reached[source] = true
+		}
+		return reached
+	}
+	for _, model := range s.Models {
+		reached[catalog.ModelSourceName(model)] = true
+	}
+	return reached
+}
+
+// modelsFromSources returns the models of the given sources
+func modelsFromSources(models []catalog.Model, sources map[string]bool) []catalog.Model {
+	var kept []catalog.Model
+	for _, model := range models {
+		if sources[catalog.ModelSourceName(model)] {
+			kept = append(kept, model)
+		}
+	}
+	return kept
+}
+
+// commonSources returns the sources that answered for both snapshots
+func commonSources(old, new *Snapshot) map[string]bool {
+	both := old.ReachedSources()
+	reached := new.ReachedSources()
+	for source := range both {
+		if !reached[source] {
+			delete(both, source)
+		}
+	}
+	return both
+}
+
+// GetSnapshotDir returns the snapshot directory from env var or the user cache directory
+func GetSnapshotDir() (string, error) {
+	if dir := os.Getenv("LLMLS_SNAPSHOT_DIR"); dir != "" {
+		return dir, nil
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+	return filepath.Join(cacheDir, "llmls", "snapshots"), nil
+}
+
+// SaveSnapshot writes a fetched catalog to a new timestamped snapshot file
+// and returns its path
+// Dry runs fetch nothing, so the file is only printed, never written
+// Catalogs of --record and --replay sessions are not saved either, since a
+// replayed catalog would enter the history with the time of the rerun; the
+// returned path is then ""
+func SaveSnapshot(snapshot *Snapshot) (string, error) {
+	if sessionActive {
+		return "", nil
+	}
+	dir, err := GetSnapshotDir()
+	if err != nil {
+		return "", err
+	}
+	if dryRun {
+		path := filepath.Join(dir, snapshot.TakenAt.Format(snapshotTimeFormat)+".json")
+		fmt.Fprintf(os.Stderr, "[dry-run] write snapshot %s\n", path)
+		return path, nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	path := filepath.Join(dir, snapshot.TakenAt.Format(snapshotTimeFormat)+".json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return path, nil
+}
+
+// ListSnapshotFiles returns snapshot file paths, oldest first
+func ListSnapshotFiles() ([]string, error) {
+	dir, err := GetSnapshotDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read snapshot directory: %w", err)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
+			paths = append(paths, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// LoadSnapshot reads a snapshot file
+func LoadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot %s: %w", path, err)
+	}
+	return &snapshot, nil
+}
+
+// LatestSnapshot loads the most recent snapshot
+func LatestSnapshot() (*Snapshot, error) {
+	paths, err := ListSnapshotFiles()
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no snapshots found (run 'llmls snapshot' first)")
+	}
+	return LoadSnapshot(paths[len(paths)-1])
+}
+
+// CatalogDiff holds the differences between two catalogs
+type CatalogDiff struct {
+	Added   []catalog.Model // In the new catalog only
+	Removed []catalog.Model // In the old catalog only, as they were
+	Changed []catalog.Model // In both with different attributes, as they are now
+}
+
+// modelAttributes are the fields compared when detecting changed models
+type modelAttributes struct {
+	Name           string
+	ContextLength  int
+	Pricing        catalog.Pricing
+	TopProvider    catalog.TopProvider
+	Modality       string
+	ExpirationDate string
+}
+
+// attributesOf extracts the compared fields of a model
+func attributesOf(model catalog.Model) modelAttributes {
+	return modelAttributes{
+		Name:           model.Name,
+		ContextLength:  model.ContextLength,
+		Pricing:        model.Pricing,
+		TopProvider:    model.TopProvider,
+		Modality:       model.Architecture.Modality,
+		ExpirationDate: model.ExpirationDate,
+	}
+}
+
+// CompareSnapshots computes the changes from old to new over the sources
+// that answered for both, so the models of a server that was down are not
+// reported as removed, nor as added again once it is back
+func CompareSnapshots(old, new *Snapshot) CatalogDiff {
+	both := commonSources(old, new)
+	return CompareCatalogs(modelsFromSources(old.Models, both), modelsFromSources(new.Models, both))
+}
+
+// CompareCatalogs computes the models added, removed, and changed from old to new
+func CompareCatalogs(old, new []catalog.Model) CatalogDiff {
+	oldByID := make(map[string]catalog.Model, len(old))
+	for _, model := range old {
+		oldByID[model.ID] = model
+	}
+	newIDs := make(map[string]bool, len(new))
+
+	var diff CatalogDiff
+	for _, model := range new {
+		newIDs[model.ID] = true
+		prev, ok := oldByID[model.ID]
+		if !ok {
+			diff.Added = append(diff.Added, model)
+		} else if !reflect.DeepEqual(attributesOf(prev), attributesOf(model)) {
+			diff.Changed = append(diff.Changed, model)
+		}
+	}
+	for _, model := range old {
+		if !newIDs[model.ID] {
+			diff.Removed = append(diff.Removed, model)
+		}
+	}
+
+	return diff
+}
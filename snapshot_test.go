@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+func TestCompareSnapshots(t *testing.T) {
+	old := &Snapshot{
+		Models: []catalog.Model{
+			{ID: "openai/gpt-4o", ContextLength: 128000, Pricing: catalog.Pricing{Prompt: "0.0000025"}},
+			{ID: "openai/gpt-4-turbo", ContextLength: 128000},
+			{ID: "ollama/llama3.1:8b"},
+		},
+		Sources: []string{"openrouter", "ollama"},
+	}
+	new := &Snapshot{
+		Models: []catalog.Model{
+			{ID: "openai/gpt-4o", ContextLength: 128000, Pricing: catalog.Pricing{Prompt: "0.000002"}},
+			{ID: "openai/gpt-4.1", ContextLength: 1047576},
+		},
+		Sources: []string{"openrouter"}, // Ollama was down
+	}
+
+	diff := CompareSnapshots(old, new)
+	if got := modelIDs(diff.Added); len(got) != 1 || got[0] != "openai/gpt-4.1" {
+		t.Errorf("Added = %v, want [openai/gpt-4.1]", got)
+	}
+	if got := modelIDs(diff.Removed); len(got) != 1 || got[0] != "openai/gpt-4-turbo" {
+		t.Errorf("Removed = %v, want [openai/gpt-4-turbo] (models of unreached sources are not removed)", got)
+	}
+	if got := diff.Changed; len(got) != 1 || got[0].Pricing.Prompt != "0.000002" {
+		t.Errorf("Changed = %+v, want openai/gpt-4o with its new price", got)
+	}
+}
+
+func TestSnapshotReachedSources(t *testing.T) {
+	// Snapshots of older versions do not record sources; their models tell
+	s := &Snapshot{Models: []catalog.Model{{ID: "openai/gpt-4o"}, {ID: "tgi/meta-llama/Llama-3.1-8B"}}}
+	reached := s.ReachedSources()
+	if len(reached) != 2 || !reached["openrouter"] || !reached["tgi"] {
+		t.Errorf("ReachedSources() = %v, want openrouter and tgi", reached)
+	}
+
+	s.Sources = []string{"openrouter", "ollama"}
+	reached = s.ReachedSources()
+	if len(reached) != 2 || !reached["openrouter"] || !reached["ollama"] {
+		t.Errorf("ReachedSources() = %v, want the recorded sources", reached)
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// Source is a configured model source that can be queried for models
+type Source struct {
+	Name  string // Provider prefix of the models it returns
+	URL   string // As configured, before any SSH tunneling
+	Fetch func() ([]catalog.Model, error)
+	url   string // After SSH tunneling
+}
+
+// SourceConfig holds the source host settings shared by subcommands
+type SourceConfig struct {
+	OllamaHost   string
+	LlamaCppHost string
+	TGIHosts     string
+	RemoteURLs   string
+	IncludeMedia bool // Also fetch image and speech models
+	StrictSchema bool // Fail when a provider response does not match its expected schema
+}
+
+// RegisterFlags adds the source host flags to fs
+func (c *SourceConfig) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.OllamaHost, "ollama-host", "", "Ollama server URL (default: $OLLAMA_HOST or http://localhost:11434)")
+	fs.StringVar(&c.LlamaCppHost, "llamacpp-host", "", "llama.cpp/KoboldCpp server URL (default: $LLAMACPP_HOST or http://localhost:8080)")
+	fs.StringVar(&c.TGIHosts, "tgi-host", "", "Comma-separated TGI server URLs (default: $TGI_HOST)")
+	fs.StringVar(&c.RemoteURLs, "remote", "", "Comma-separated shared llmls catalog URLs (default: $LLMLS_REMOTE)")
+	fs.BoolVar(&c.StrictSchema, "strict-schema", false, "Fail when a provider response has new or missing fields (LLMLS_DEBUG=1 shows them)")
+}
+
+// OpenRouterSource returns the OpenRouter catalog source
+// It bypasses the catalog cache so health checks measure the live API
+func OpenRouterSource() Source {
+	return Source{Name: "openrouter", URL: catalog.OpenRouterModelsURL, Fetch: FetchModelsLive}
+}
+
+// GetRemoteURLs returns the shared catalog URLs from flag or env var (comma-separated)
+func GetRemoteURLs(flagURLs string) []string {
+	// Priority: 1. Flag, 2. Env var
+	urls := flagURLs
+	if urls == "" {
+		urls = os.Getenv("LLMLS_REMOTE")
+	}
+
+	var result []string
+	for _, url := range strings.Split(urls, ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			result = append(result, url)
+		}
+	}
+	return result
+}
+
+// GetLlamaCppHost returns the llama.cpp host URL from flag, env var, or default
+func GetLlamaCppHost(flagHost string) string {
+	// Priority: 1. Flag, 2. Env var, 3. Default
+	if flagHost != "" {
+		return flagHost
+	}
+	if envHost := os.Getenv("LLAMACPP_HOST"); envHost != "" {
+		return envHost
+	}
+	return "http://localhost:8080"
+}
+
+// GetTGIHosts returns the TGI base URLs from flag or env var
+// Multiple URLs may be given as a comma-separated list; there is no default
+func GetTGIHosts(flagHosts string) []string {
+	// Priority: 1. Flag, 2. Env var
+	hosts := flagHosts
+	if hosts == "" {
+		hosts = os.Getenv("TGI_HOST")
+	}
+
+	var result []string
+	for _, host := range strings.Split(hosts, ",") {
+		host = strings.TrimSpace(host)
+		if host != "" {
+			result = append(result, strings.TrimSuffix(host, "/"))
+		}
+	}
+	return result
+}
+
+// debugf prints a diagnostic message to stderr when LLMLS_DEBUG is set
+func debugf(format string, args ...interface{}) {
+	if os.Getenv("LLMLS_DEBUG") != "" {
+		fmt.Fprintf(os.Stderr, "Debug: "+format+"\n", args...)
+	}
+}
+
+// NewCatalogClient returns a catalog client configured as the command line
+// is, with opts applied last: OpenRouter pages are retrieved with get, the
+// computed columns of the config file are defined, schema drift is shown
+// with LLMLS_DEBUG, and --dry-run requests every source
+func NewCatalogClient(get func(string) ([]byte, error), opts ...catalog.Option) *catalog.Client {
+	defaults := []catalog.Option{catalog.WithFetcher(get), catalog.WithColumns(computedColumns)}
+	if os.Getenv("LLMLS_DEBUG") != "" {
+		defaults = append(defaults, catalog.WithDebugLogger(log.New(os.Stderr, "Debug: ", 0)))
+	}
+	if dryRun {
+		defaults = append(defaults, catalog.WithDryRun())
+	}
+	return catalog.NewClient(append(defaults, opts...)...)
+}
+
+// sources returns the configured sources without local servers, with the
+// tokens of the environment
+func (c *SourceConfig) sources() catalog.Sources {
+	return catalog.Sources{
+		RemoteURLs:     GetRemoteURLs(c.RemoteURLs),
+		RemoteToken:    os.Getenv("LLMLS_REMOTE_TOKEN"),
+		ReplicateToken: os.Getenv("REPLICATE_API_TOKEN"),
+		IncludeMedia:   c.IncludeMedia,
+		StrictSchema:   c.StrictSchema,
+	}
+}
+
+// Sources resolves the configured sources, opening SSH tunnels to local
+// servers as needed
+// Hosts whose tunnel cannot be opened are reported as warnings and skipped
+func (c *SourceConfig) Sources(tunnels *TunnelSet) catalog.Sources {
+	sources := c.sources()
+	for _, source := range c.LocalSources(tunnels) {
+		switch source.Name {
+		case "ollama":
+			sources.OllamaHost = source.url
+		case "llamacpp":
+			sources.LlamaCppHost = source.url
+		case "tgi":
+			sources.TGIHosts = append(sources.TGIHosts, source.url)
+		}
+	}
+	return sources
+}
+
+// Client returns a catalog client of the configured sources (see Sources)
+func (c *SourceConfig) Client(tunnels *TunnelSet) *catalog.Client {
+	return NewCatalogClient(CachedGet, catalog.WithSources(c.Sources(tunnels)))
+}
+
+// RemoteSources returns the configured shared catalogs
+func (c *SourceConfig) RemoteSources() []Source {
+	client := NewCatalogClient(CachedGet, catalog.WithSources(c.sources()))
+	var sources []Source
+	for _, url := range GetRemoteURLs(c.RemoteURLs) {
+		sources = append(sources, Source{
+			Name:  "remote",
+			URL:   url,
+			Fetch: func() ([]catalog.Model, error) { return client.FetchRemoteCatalog(url) },
+		})
+	}
+	return sources
+}
+
+// LocalSources resolves the configured local servers, opening SSH tunnels as needed
+// Hosts whose tunnel cannot be opened are reported as warnings and skipped
+func (c *SourceConfig) LocalSources(tunnels *TunnelSet) []Source {
+	client := NewCatalogClient(CachedGet, catalog.WithSources(c.sources()))
+	var sources []Source
+
+	add := func(name, host string, defaultPort int, fetch func(string) ([]catalog.Model, error)) {
+		url, err := tunnels.Open(host, defaultPort)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			return
+		}
+		sources = append(sources, Source{
+			Name:  name,
+			URL:   host,
+			Fetch: func() ([]catalog.Model, error) { return fetch(url) },
+			url:   url,
+		})
+	}
+
+	add("ollama", GetOllamaHost(c.OllamaHost), 11434, client.FetchOllamaModels)
+	add("llamacpp", GetLlamaCppHost(c.LlamaCppHost), 8080, client.FetchLlamaCppModels)
+	for _, host := range GetTGIHosts(c.TGIHosts) {
+		add("tgi", host, 8080, client.FetchTGIModels)
+	}
+
+	return sources
+}
+
+// FetchCatalog retrieves models from OpenRouter, shared remote catalogs, and every reachable local source
+// OpenRouter errors are returned; remote failures are warnings; unavailable local servers are skipped silently
+// With StrictSchema, schema drift in any response is returned as an error
+// With IncludeMedia, image and speech models from OpenRouter and Replicate are included
+// With --dry-run, the OpenRouter error is ignored so the other sources' requests are printed too
+func (c *SourceConfig) FetchCatalog(tunnels *TunnelSet) ([]catalog.Model, error) {
+	snapshot, err := c.FetchSnapshot(tunnels)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot.Models, nil
+}
+
+// FetchSnapshot is FetchCatalog recording which sources answered, so that
+// comparisons with it can tell a server that was down from one whose models
+// were removed
+func (c *SourceConfig) FetchSnapshot(tunnels *TunnelSet) (*Snapshot, error) {
+	models, sources, err := c.Client(tunnels).FetchCatalog()
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{TakenAt: time.Now().UTC(), Models: models, Sources: sources}, nil
+}
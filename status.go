@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const openRouterKeyURL = "https://openrouter.ai/api/v1/key"
+
+// SourceStatus represents the health of a single model source
+type SourceStatus struct {
+	Source    string `json:"source"`
+	URL       string `json:"url"`
+	Reachable bool   `json:"reachable"`
+	LatencyMs int64  `json:"latency_ms"`
+	Auth      string `json:"auth"` // valid, invalid, none, or n/a
+	Models    int    `json:"models"`
+	Error     string `json:"error,omitempty"`
+	Incident  string `json:"incident,omitempty"` // Ongoing incident on the source's status page
+}
+
+// CheckSources queries every source concurrently and reports its health
+// Results are returned in the same order as sources
+func CheckSources(sources []Source) []SourceStatus {
+	statuses := make([]SourceStatus, len(sources))
+
+	var wg sync.WaitGroup
+	for i, source := range sources {
+		wg.Add(1)
+		go func(i int, source Source) {
+			defer wg.Done()
+			statuses[i] = checkSource(source)
+		}(i, source)
+	}
+	wg.Wait()
+
+	return statuses
+}
+
+// checkSource fetches models from a source, timing the request
+func checkSource(source Source) SourceStatus {
+	status := SourceStatus{
+		Source: source.Name,
+		URL:    source.URL,
+		Auth:   "n/a",
+	}
+
+	start := time.Now()
+	models, err := source.Fetch()
+	status.LatencyMs = time.Since(start).Milliseconds()
+
+	if err != nil {
+		status.Error = err.Error()
+	} else {
+		status.Reachable = true
+		status.Models = len(models)
+	}
+
+	if source.Name == "openrouter" {
+		status.Auth = CheckOpenRouterKey(os.Getenv("OPENROUTER_API_KEY"))
+	}
+
+	return status
+}
+
+// CheckOpenRouterKey validates an OpenRouter API key
+// Returns "none" if no key is set, otherwise "valid", "invalid", or "unknown"
+func CheckOpenRouterKey(key string) string {
+	if key == "" {
+		return "none"
+	}
+
+	req, err := http.NewRequest("GET", openRouterKeyURL, nil)
+	if err != nil {
+		return "unknown"
+	}
+	req.Header.Set("Authorization", "Bearer "+key)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "unknown"
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return "valid"
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return "invalid"
+	default:
+		return "unknown"
+	}
+}
+
+// DisplaySourceStatuses prints source health as an aligned table
+func DisplaySourceStatuses(statuses []SourceStatus) {
+	if len(statuses) == 0 {
+		return
+	}
+
+	maxSourceWidth := len("SOURCE")
+	maxURLWidth := len("URL")
+	for _, status := range statuses {
+		if len(status.Source) > maxSourceWidth {
+			maxSourceWidth = len(status.Source)
+		}
+		if len(status.URL) > maxURLWidth {
+			maxURLWidth = len(status.URL)
+		}
+	}
+
+	fmt.Printf("%-*s %-*s %-6s %8s %-7s %6s\n",
+		maxSourceWidth, "SOURCE",
+		maxURLWidth, "URL",
+		"STATUS", "LATENCY", "AUTH", "MODELS")
+
+	for _, status := range statuses {
+		state := "ok"
+		if !status.Reachable {
+			state = "down"
+		}
+		fmt.Printf("%-*s %-*s %-6s %6dms %-7s %6d\n",
+			maxSourceWidth, status.Source,
+			maxURLWidth, status.URL,
+			state, status.LatencyMs, status.Auth, status.Models)
+	}
+}
+
+// DisplaySourceStatusesJSON prints source health as a JSON array
+func DisplaySourceStatusesJSON(statuses []SourceStatus) error {
+	data, err := json.MarshalIndent(statuses, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
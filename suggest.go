@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// maxSuggestions is the number of close matches shown for an unmatched pattern
+const maxSuggestions = 5
+
+// SuggestModelIDs returns up to limit catalog IDs close to query, best first
+// Known renames come first, followed by the nearest IDs by edit distance
+// Glob wildcards in query are ignored when comparing
+func SuggestModelIDs(query string, models []catalog.Model, limit int) []string {
+	query = strings.ToLower(strings.NewReplacer("*", "", "?", "").Replace(query))
+	if query == "" {
+		return nil
+	}
+
+	known := make(map[string]bool, len(models))
+	for _, model := range models {
+		known[model.ID] = true
+	}
+
+	var suggestions []string
+	seen := make(map[string]bool)
+	add := func(id string) {
+		if !seen[id] && len(suggestions) < limit {
+			seen[id] = true
+			suggestions = append(suggestions, id)
+		}
+	}
+
+	// Known renames, matched by full ID or by the part after the provider
+	for oldID, newID := range knownRenames {
+		if known[newID] && (query == oldID || query == catalog.ModelSuffix(oldID)) {
+			add(newID)
+		}
+	}
+
+	// Nearest IDs by edit distance, compared against the full ID and the
+	// part after the provider so that "gpt-4o" finds "openai/gpt-4o"
+	type candidate struct {
+		id       string
+		distance int
+	}
+	maxDistance := len(query) / 3
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+
+	var candidates []candidate
+	for _, model := range models {
+		id := strings.ToLower(model.ID)
+		distance := editDistance(query, id)
+		if d := editDistance(query, catalog.ModelSuffix(id)); d < distance {
+			distance = d
+		}
+		if distance <= maxDistance {
+			candidates = append(candidates, candidate{model.ID, distance})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].id < candidates[j].id
+	})
+
+	for _, c := range candidates {
+		add(c.id)
+	}
+
+	return suggestions
+}
+
+// editDistance computes the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	ra := []rune(a)
+	rb := []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// DisplayFilteredOut prints a hint to stderr when the pattern matched models
+// but the other filters excluded all of them
+func DisplayFilteredOut(pattern string, matched, total int) {
+	if pattern == "" {
+		fmt.Fprintf(os.Stderr, "No models left after filtering (%d models before filtering)\n", total)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "No models left after filtering: %d of %d models match %q, but the other filters exclude them\n", matched, total, pattern)
+}
+
+// DisplayNoMatch prints a hint to stderr when pattern matched no models:
+// how many models were searched, the closest IDs, and a reminder to quote globs
+func DisplayNoMatch(pattern string, models []catalog.Model) {
+	fmt.Fprintf(os.Stderr, "No models match %q (searched %d models)\n", pattern, len(models))
+
+	// A pattern close to a subcommand name is likely a mistyped subcommand
+	if command := SuggestCommand(pattern); command != "" {
+		fmt.Fprintf(os.Stderr, "Did you mean the subcommand: llmls %s\n", command)
+	}
+
+	if suggestions := SuggestModelIDs(pattern, models, maxSuggestions); len(suggestions) > 0 {
+		fmt.Fprintf(os.Stderr, "Did you mean:\n")
+		for _, id := range suggestions {
+			fmt.Fprintf(os.Stderr, "  %s\n", id)
+		}
+	}
+
+	// A pattern without wildcards may be a glob the shell already expanded
+	if !strings.ContainsAny(pattern, "*?") {
+		fmt.Fprintf(os.Stderr, "Hint: quote glob patterns so the shell does not expand them, e.g. llmls \"anthropic/*\"\n")
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+func TestSuggestModelIDs(t *testing.T) {
+	models := []catalog.Model{
+		{ID: "openai/gpt-4o"},
+		{ID: "openai/gpt-4o-mini"},
+		{ID: "openai/gpt-4-turbo"},
+		{ID: "anthropic/claude-sonnet-4"},
+	}
+	tests := []struct {
+		query string
+		limit int
+		want  []string
+	}{
+		{"gpt-4o", 5, []string{"openai/gpt-4o"}},
+		{"openai/gpt4o", 5, []string{"openai/gpt-4o"}},
+		{"gpt-4o*", 5, []string{"openai/gpt-4o"}},
+		{"gpt-4-turbo-preview", 5, []string{"openai/gpt-4-turbo"}},
+		{"claude-sonet-4", 5, []string{"anthropic/claude-sonnet-4"}},
+		{"gpt-4o-mi", 5, []string{"openai/gpt-4o-mini", "openai/gpt-4o"}},
+		{"gpt-4o-mi", 1, []string{"openai/gpt-4o-mini"}},
+		{"*", 5, nil},
+		{"unrelated-model-name", 5, nil},
+	}
+	for _, tt := range tests {
+		if got := SuggestModelIDs(tt.query, models, tt.limit); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SuggestModelIDs(%q, %d) = %v, want %v", tt.query, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"gpt", "", 3},
+		{"gpt-4o", "gpt-4o", 0},
+		{"gpt4o", "gpt-4o", 1},
+		{"kitten", "sitting", 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
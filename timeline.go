@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// timelineBlocks are the bar characters of a month cell, by level
+var timelineBlocks = []rune(" ▁▂▃▄▅▆▇█")
+
+// timelineOther groups the providers beyond --top
+const timelineOther = "other"
+
+// Timeline counts model releases per provider and month
+type Timeline struct {
+	Months    []time.Time // First day of each month, oldest first
+	Providers []string    // Most releases first, then timelineOther if any
+	Counts    map[string][]int
+	Max       int // Largest count of a single cell
+}
+
+// monthOf returns the first day of the month of a Unix timestamp, in local time
+func monthOf(timestamp int64) time.Time {
+	t := time.Unix(timestamp, 0)
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.Local)
+}
+
+// ParseTimelineMonth parses --since, e.g. 2024-06
+func ParseTimelineMonth(s string) (time.Time, error) {
+	t, err := time.ParseInLocation("2006-01", s, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid month: %s (expected YYYY-MM)", s)
+	}
+	return t, nil
+}
+
+// NewTimeline buckets the creation dates of models by month from since (or
+// the first release) to the last release, keeping the top providers by
+// release count and grouping the rest as "other"
+// Models without a creation date are left out
+func NewTimeline(models []catalog.Model, since time.Time, top int) *Timeline {
+	t := &Timeline{Counts: make(map[string][]int)}
+	var first, last time.Time
+	total := make(map[string]int)
+	for _, model := range models {
+		if model.Created <= 0 {
+			continue
+		}
+		month := monthOf(model.Created)
+		if !since.IsZero() && month.Before(since) {
+			continue
+		}
+		if first.IsZero() || month.Before(first) {
+			first = month
+		}
+		if month.After(last) {
+			last = month
+		}
+		total[catalog.ModelProvider(model)]++
+	}
+	if first.IsZero() {
+		return t
+	}
+	if !since.IsZero() {
+		first = since
+	}
+	for m := first; !m.After(last); m = m.AddDate(0, 1, 0) {
+		t.Months = append(t.Months, m)
+	}
+
+	providers := make([]string, 0, len(total))
+	for provider := range total {
+		providers = append(providers, provider)
+	}
+	sort.Slice(providers, func(i, j int) bool {
+		if total[providers[i]] != total[providers[j]] {
+			return total[providers[i]] > total[providers[j]]
+		}
+		return providers[i] < providers[j]
+	})
+	row := make(map[string]string, len(providers))
+	for i, provider := range providers {
+		if top > 0 && i >= top {
+			row[provider] = timelineOther
+			continue
+		}
+		row[provider] = provider
+		t.Providers = append(t.Providers, provider)
+	}
+	if top > 0 && len(providers) > top {
+		t.Providers = append(t.Providers, timelineOther)
+	}
+
+	for _, provider := range t.Providers {
+		t.Counts[provider] = make([]int, len(t.Months))
+	}
+	for _, model := range models {
+		if model.Created <= 0 {
+			continue
+		}
+		month := monthOf(model.Created)
+		if month.Before(first) {
+			continue
+		}
+		i := (month.Year()-first.Year())*12 + int(month.Month()-first.Month())
+		counts := t.Counts[row[catalog.ModelProvider(model)]]
+		counts[i]++
+		t.Max = max(t.Max, counts[i])
+	}
+	return t
+}
+
+// Render draws one row per provider with a bar character per month, sized
+// by the releases of that month, and the provider's total at the end
+// When the months do not fit in width, the latest are shown
+func (t *Timeline) Render(width int) string {
+	nameWidth := len("provider")
+	for _, provider := range t.Providers {
+		nameWidth = max(nameWidth, len(provider))
+	}
+	months := t.Months
+	offset := 0
+	if fit := width - nameWidth - 8; fit > 0 && len(months) > fit {
+		offset = len(months) - fit
+		months = months[offset:]
+	}
+
+	var b strings.Builder
+	// Year labels above the first month of each year, and above the first
+	// column unless the next year's label follows too closely
+	years := []rune(strings.Repeat(" ", len(months)+8))
+	for i, month := range months {
+		if month.Month() == time.January || (i == 0 && int(13-month.Month()) > 4) {
+			copy(years[i:], []rune(fmt.Sprint(month.Year())))
+		}
+	}
+	fmt.Fprintf(&b, "%-*s %s\n", nameWidth, "", strings.TrimRight(string(years), " "))
+	initials := make([]rune, len(months))
+	for i, month := range months {
+		initials[i] = []rune(month.Month().String())[0]
+	}
+	fmt.Fprintf(&b, "%-*s %s\n", nameWidth, "provider", string(initials))
+
+	for _, provider := range t.Providers {
+		counts := t.Counts[provider][offset:]
+		bar := make([]rune, len(counts))
+		sum := 0
+		for i, count := range counts {
+			level := 0
+			if count > 0 {
+				// Any release shows at least the lowest block
+				level = max(1, (count*(len(timelineBlocks)-1)+t.Max-1)/t.Max)
+			}
+			bar[i] = timelineBlocks[level]
+			sum += count
+		}
+		fmt.Fprintf(&b, "%-*s %s %s\n", nameWidth, provider, string(bar), FormatNumber(sum))
+	}
+	return b.String()
+}
+
+// SVG layout of llmls timeline --svg, in pixels
+const (
+	timelineCellWidth = 14
+	timelineRowHeight = 28
+	timelineNameWidth = 140
+	timelineTop       = 40
+)
+
+// SVG renders the timeline as bars per provider and month, with a tooltip
+// per bar
+func (t *Timeline) SVG() []byte {
+	width := timelineNameWidth + len(t.Months)*timelineCellWidth + 60
+	height := timelineTop + len(t.Providers)*timelineRowHeight + 20
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="11">`+"\n",
+		width, height, width, height)
+	fmt.Fprintf(&b, `<title>llmls: model releases per provider and month</title>`+"\n")
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="white"/>`+"\n")
+
+	for i, month := range t.Months {
+		x := timelineNameWidth + i*timelineCellWidth
+		if i == 0 || month.Month() == time.January {
+			fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#ddd"/>`+"\n", x, timelineTop-12, x, height-20)
+			fmt.Fprintf(&b, `<text x="%d" y="%d">%d</text>`+"\n", x+2, timelineTop-16, month.Year())
+		}
+	}
+	for row, provider := range t.Providers {
+		base := timelineTop + (row+1)*timelineRowHeight - 4
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">%s</text>`+"\n", timelineNameWidth-8, base, html.EscapeString(provider))
+		sum := 0
+		for i, count := range t.Counts[provider] {
+			sum += count
+			if count == 0 {
+				continue
+			}
+			h := max(2, count*(timelineRowHeight-6)/t.Max)
+			tooltip := fmt.Sprintf("%s %s: %d", provider, t.Months[i].Format("2006-01"), count)
+			fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="#3366cc"><title>%s</title></rect>`+"\n",
+				timelineNameWidth+i*timelineCellWidth+1, base-h, timelineCellWidth-2, h, html.EscapeString(tooltip))
+		}
+		fmt.Fprintf(&b, `<text x="%d" y="%d">%d</text>`+"\n", timelineNameWidth+len(t.Months)*timelineCellWidth+6, base, sum)
+	}
+	b.WriteString("</svg>\n")
+	return b.Bytes()
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// DefaultTranslateModel is the Ollama model used for --translate
+const DefaultTranslateModel = "llama3.2"
+
+// openRouterChatURL is the OpenRouter chat completions endpoint
+const openRouterChatURL = "https://openrouter.ai/api/v1/chat/completions"
+
+// translateModelPrefix selects the OpenRouter API instead of Ollama, e.g.
+// openrouter:openai/gpt-4o-mini
+const translateModelPrefix = "openrouter:"
+
+// translateWorkers bounds concurrent translation requests
+const translateWorkers = 4
+
+// languageNames are the names used in the translation prompt; other codes
+// are passed to the model as-is
+var languageNames = map[string]string{
+	"ar": "Arabic", "de": "German", "en": "English", "es": "Spanish", "fr": "French",
+	"hi": "Hindi", "id": "Indonesian", "it": "Italian", "ja": "Japanese", "ko": "Korean",
+	"nl": "Dutch", "pl": "Polish", "pt": "Portuguese", "ru": "Russian", "sv": "Swedish",
+	"th": "Thai", "tr": "Turkish", "uk": "Ukrainian", "vi": "Vietnamese",
+	"zh": "Simplified Chinese", "zh-TW": "Traditional Chinese",
+}
+
+// languageCodePattern matches language codes such as ja, pt-BR, or zh-TW
+var languageCodePattern = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z]{2,4})?$`)
+
+// ValidateLanguage checks that lang looks like a language code
+func ValidateLanguage(lang string) error {
+	if !languageCodePattern.MatchString(lang) {
+		return fmt.Errorf("invalid language code: %s (e.g. ja, de, pt-BR)", lang)
+	}
+	return nil
+}
+
+// GetTranslateModel returns the translation model from flag, env var, or default
+func GetTranslateModel(flagModel string) string {
+	if flagModel != "" {
+		return flagModel
+	}
+	if envModel := os.Getenv("LLMLS_TRANSLATE_MODEL"); envModel != "" {
+		return envModel
+	}
+	return DefaultTranslateModel
+}
+
+// Translator translates model descriptions with a local Ollama model or the
+// OpenRouter API, caching translations on disk per language
+type Translator struct {
+	Host     string // Ollama server URL
+	Model    string // Ollama model, or openrouter:<model id>
+	Language string // Target language code
+	cache    map[string]string
+	dirty    bool
+	mu       sync.Mutex
+}
+
+// translationKey is the cache key of a model's description; it changes when
+// the description does, so updated descriptions are translated again
+func translationKey(model catalog.Model) string {
+	return model.ID + "@" + textHash(model.Description)[:16]
+}
+
+// translateCachePath returns the cache file of the target language
+func (t *Translator) translateCachePath() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+	return filepath.Join(cacheDir, "llmls", "translations", t.Language+".json"), nil
+}
+
+// loadCache reads cached translations; a missing or unreadable cache starts empty
+func (t *Translator) loadCache() {
+	t.cache = make(map[string]string)
+	path, err := t.translateCachePath()
+	if err != nil {
+		return
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &t.cache)
+	}
+}
+
+// saveCache writes the translation cache if new translations were added
+func (t *Translator) saveCache() error {
+	if !t.dirty {
+		return nil
+	}
+	path, err := t.translateCachePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create translation cache directory: %w", err)
+	}
+	data, err := json.MarshalIndent(t.cache, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode translation cache: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write translation cache: %w", err)
+	}
+	t.dirty = false
+	return nil
+}
+
+// translationPrompt is the system prompt of a translation request
+func (t *Translator) translationPrompt() string {
+	language := t.Language
+	if name, ok := languageNames[language]; ok {
+		language = name
+	}
+	return "Translate the user's text, a description of an AI model, into " + language + ". " +
+		"Reply with the translation only. Keep model names, product names, numbers, and code unchanged."
+}
+
+// chatMessages are the messages of a translation request
+func (t *Translator) chatMessages(text string) []map[string]string {
+	return []map[string]string{
+		{"role": "system", "content": t.translationPrompt()},
+		{"role": "user", "content": text},
+	}
+}
+
+// postJSON sends a JSON request and decodes the JSON response into result
+func postJSON(url string, headers map[string]string, request, result interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	// Local models on CPU can take a while per description
+	client := &http.Client{Timeout: 2 * time.Minute}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error interface{} `json:"error"` // A string from Ollama, an object from OpenRouter
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		if apiErr.Error != nil {
+			return fmt.Errorf("status %d: %v", resp.StatusCode, apiErr.Error)
+		}
+		return &catalog.HTTPStatusError{StatusCode: resp.StatusCode}
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// translateOllama translates text with the Ollama chat API
+func (t *Translator) translateOllama(text string) (string, error) {
+	var result struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	}
+	request := map[string]interface{}{"model": t.Model, "messages": t.chatMessages(text), "stream": false}
+	if err := postJSON(t.Host+"/api/chat", nil, request, &result); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return "", fmt.Errorf("ollama translation failed: %w (try 'ollama pull %s')", err, t.Model)
+		}
+		return "", fmt.Errorf("ollama translation failed: %w", err)
+	}
+	return result.Message.Content, nil
+}
+
+// translateOpenRouter translates text with the OpenRouter chat completions API
+func (t *Translator) translateOpenRouter(text string) (string, error) {
+	key := os.Getenv("OPENROUTER_API_KEY")
+	if key == "" {
+		return "", fmt.Errorf("OPENROUTER_API_KEY is required to translate with %s", t.Model)
+	}
+	var result struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	request := map[string]interface{}{
+		"model":    strings.TrimPrefix(t.Model, translateModelPrefix),
+		"messages": t.chatMessages(text),
+	}
+	if err := postJSON(openRouterChatURL, map[string]string{"Authorization": "Bearer " + key}, request, &result); err != nil {
+		return "", fmt.Errorf("OpenRouter translation failed: %w", err)
+	}
+	if len(result.Choices) == 0 {
+		return "", fmt.Errorf("OpenRouter translation returned no choices")
+	}
+	return result.Choices[0].Message.Content, nil
+}
+
+// translate translates one text with the configured backend
+func (t *Translator) translate(text string) (string, error) {
+	var translated string
+	var err error
+	if strings.HasPrefix(t.Model, translateModelPrefix) {
+		translated, err = t.translateOpenRouter(text)
+	} else {
+		translated, err = t.translateOllama(text)
+	}
+	return strings.TrimSpace(translated), err
+}
+
+// TranslateDescriptions replaces model descriptions with translations,
+// requesting only those not in the cache
+// Models that fail to translate keep their original description; the first
+// error is returned so the caller can warn once
+func (t *Translator) TranslateDescriptions(models []catalog.Model) error {
+	if t.cache == nil {
+		t.loadCache()
+	}
+
+	var pending []int
+	for i, model := range models {
+		if model.Description == "" {
+			continue
+		}
+		if translated, ok := t.cache[translationKey(model)]; ok {
+			models[i].Description = translated
+			continue
+		}
+		pending = append(pending, i)
+	}
+
+	var firstErr error
+	var wg sync.WaitGroup
+	queue := make(chan int)
+	for w := 0; w < translateWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				translated, err := t.translate(models[i].Description)
+				t.mu.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = err
+					}
+				} else if translated != "" {
+					t.cache[translationKey(models[i])] = translated
+					t.dirty = true
+					models[i].Description = translated
+				}
+				t.mu.Unlock()
+			}
+		}()
+	}
+	for _, i := range pending {
+		t.mu.Lock()
+		failed := firstErr != nil
+		t.mu.Unlock()
+		if failed {
+			// The backend is likely down or the model missing; do not retry every model
+			break
+		}
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+
+	if err := t.saveCache(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	return firstErr
+}
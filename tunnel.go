@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tunnelTimeout bounds how long to wait for an SSH port-forward to come up
+const tunnelTimeout = 10 * time.Second
+
+// TunnelSet tracks SSH port-forwards opened for remote local-inference hosts
+type TunnelSet struct {
+	cmds []*exec.Cmd
+}
+
+// openTunnels are the port-forwards of every TunnelSet, which exit closes
+// since os.Exit skips deferred Close calls
+var openTunnels = struct {
+	sync.Mutex
+	cmds map[*exec.Cmd]bool
+}{cmds: make(map[*exec.Cmd]bool)}
+
+// Open returns an HTTP base URL for host
+// Hosts of the form ssh://user@box[:sshport][?port=N] are reached through an SSH
+// port-forward to localhost:N on the remote machine (N defaults to defaultPort);
+// any other host is returned unchanged
+func (ts *TunnelSet) Open(host string, defaultPort int) (string, error) {
+	u, err := url.Parse(host)
+	if err != nil || u.Scheme != "ssh" {
+		return host, nil
+	}
+
+	if u.Hostname() == "" {
+		return "", fmt.Errorf("invalid SSH host: %s", host)
+	}
+
+	remotePort := defaultPort
+	if p := u.Query().Get("port"); p != "" {
+		remotePort, err = strconv.Atoi(p)
+		if err != nil {
+			return "", fmt.Errorf("invalid remote port in %s: %s", host, p)
+		}
+	}
+
+	localPort, err := freeLocalPort()
+	if err != nil {
+		return "", fmt.Errorf("failed to allocate local port: %w", err)
+	}
+
+	dest := u.Hostname()
+	if u.User != nil {
+		dest = u.User.Username() + "@" + dest
+	}
+
+	args := []string{
+		"-N",
+		"-o", "BatchMode=yes",
+		"-o", "ExitOnForwardFailure=yes",
+		"-L", fmt.Sprintf("127.0.0.1:%d:localhost:%d", localPort, remotePort),
+	}
+	if u.Port() != "" {
+		args = append(args, "-p", u.Port())
+	}
+	args = append(args, dest)
+
+	localAddr := fmt.Sprintf("127.0.0.1:%d", localPort)
+	if dryRun {
+		fmt.Fprintf(os.Stderr, "[dry-run] ssh %s\n", strings.Join(args, " "))
+		return "http://" + localAddr, nil
+	}
+
+	cmd := exec.Command("ssh", args...)
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("failed to start ssh: %w", err)
+	}
+
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	deadline := time.Now().Add(tunnelTimeout)
+	for {
+		select {
+		case err := <-exited:
+			return "", fmt.Errorf("ssh tunnel to %s exited: %v", dest, err)
+		default:
+		}
+
+		if conn, err := net.DialTimeout("tcp", localAddr, 200*time.Millisecond); err == nil {
+			conn.Close()
+			break
+		}
+
+		if time.Now().After(deadline) {
+			cmd.Process.Kill()
+			return "", fmt.Errorf("timed out opening ssh tunnel to %s", dest)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	ts.cmds = append(ts.cmds, cmd)
+	openTunnels.Lock()
+	openTunnels.cmds[cmd] = true
+	openTunnels.Unlock()
+	return "http://" + localAddr, nil
+}
+
+// Close terminates all SSH port-forwards
+func (ts *TunnelSet) Close() {
+	openTunnels.Lock()
+	defer openTunnels.Unlock()
+	for _, cmd := range ts.cmds {
+		cmd.Process.Kill()
+		delete(openTunnels.cmds, cmd)
+	}
+	ts.cmds = nil
+}
+
+// exit terminates every open SSH port-forward, restores the console, and
+// exits with code; commands call it instead of os.Exit so that no ssh -N
+// process outlives llmls and deferred cleanup in main is not skipped
+func exit(code int) {
+	openTunnels.Lock()
+	for cmd := range openTunnels.cmds {
+		cmd.Process.Kill()
+	}
+	openTunnels.Unlock()
+	RestoreConsole()
+	os.Exit(code)
+}
+
+// freeLocalPort asks the OS for an unused TCP port on the loopback interface
+func freeLocalPort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
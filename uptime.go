@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// uptimeFetchWorkers bounds the concurrent endpoint requests of FillUptime
+const uptimeFetchWorkers = 8
+
+// NewModelUptime collects the reported uptimes of endpoints, best first
+func NewModelUptime(endpoints []Endpoint) catalog.ModelUptime {
+	endpoints = append([]Endpoint(nil), endpoints...)
+	SortEndpoints(endpoints, "uptime")
+	var u catalog.ModelUptime
+	for _, e := range endpoints {
+		if e.Uptime != nil {
+			u.Providers = append(u.Providers, catalog.ProviderUptime{Name: e.ProviderName, Uptime: math.Min(*e.Uptime, 100)})
+		}
+	}
+	return u
+}
+
+// ValidateMinUptime checks a --min-uptime percentage
+func ValidateMinUptime(min float64) error {
+	if min < 0 || min > 100 {
+		return fmt.Errorf("invalid --min-uptime: %g (expected a percentage from 0 to 100)", min)
+	}
+	return nil
+}
+
+// FilterEndpointsByUptime returns endpoints at or above min percent uptime;
+// endpoints without a reported uptime are dropped
+func FilterEndpointsByUptime(endpoints []Endpoint, min float64) []Endpoint {
+	var filtered []Endpoint
+	for _, e := range endpoints {
+		if e.Uptime != nil && *e.Uptime >= min {
+			filtered = append(filtered, e)
+		}
+	}
+	return filtered
+}
+
+// FillUptime looks up the upstream provider uptimes of OpenRouter models,
+// a few at a time; local models have no upstream providers and are skipped
+func FillUptime(models []catalog.Model) {
+	sem := make(chan struct{}, uptimeFetchWorkers)
+	var wg sync.WaitGroup
+	for i := range models {
+		if catalog.ModelSourceName(models[i]) != "openrouter" {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			endpoints, err := FetchModelEndpoints(models[i].ID)
+			if err != nil {
+				models[i].Uptime = &catalog.ModelUptime{Error: err.Error()}
+				return
+			}
+			uptime := NewModelUptime(endpoints)
+			models[i].Uptime = &uptime
+		}()
+	}
+	wg.Wait()
+}
+
+// FilterModelsByUptime returns models with an upstream provider at or above
+// min percent uptime (see FillUptime); models without uptime data are dropped
+func FilterModelsByUptime(models []catalog.Model, min float64) []catalog.Model {
+	var filtered []catalog.Model
+	for _, model := range models {
+		if model.Uptime == nil {
+			continue
+		}
+		if best, ok := model.Uptime.Best(); ok && best >= min {
+			filtered = append(filtered, model)
+		}
+	}
+	return filtered
+}
+
+// CountUptimeErrors returns the number of models whose uptime lookup failed
+func CountUptimeErrors(models []catalog.Model) int {
+	failed := 0
+	for _, model := range models {
+		if model.Uptime != nil && model.Uptime.Error != "" {
+			failed++
+		}
+	}
+	return failed
+}
+
+// FormatModelUptime renders uptimes as e.g. "99.9% (OpenAI 99.9%, Azure 97.1%)"
+func FormatModelUptime(u catalog.ModelUptime) string {
+	if u.Error != "" {
+		return "unavailable (" + u.Error + ")"
+	}
+	best, ok := u.Best()
+	if !ok {
+		return "not reported"
+	}
+	parts := make([]string, len(u.Providers))
+	for i, p := range u.Providers {
+		parts[i] = fmt.Sprintf("%s %.1f%%", p.Name, p.Uptime)
+	}
+	return fmt.Sprintf("%.1f%% (%s)", best, strings.Join(parts, ", "))
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// knownRenames maps retired model IDs to the ID that replaced them
+var knownRenames = map[string]string{
+	"openai/gpt-4-turbo-preview": "openai/gpt-4-turbo",
+	"openai/gpt-4-1106-preview":  "openai/gpt-4-turbo",
+	"openai/gpt-4-0125-preview":  "openai/gpt-4-turbo",
+}
+
+// RenamedModelID returns the ID that replaced a retired model ID, matched by
+// full ID or by the part after the provider, e.g. gpt-4-turbo-preview
+func RenamedModelID(id string) (string, bool) {
+	if newID, ok := knownRenames[id]; ok {
+		return newID, true
+	}
+	for oldID, newID := range knownRenames {
+		if id == catalog.ModelSuffix(oldID) {
+			return newID, true
+		}
+	}
+	return "", false
+}
+
+// Validation results
+const (
+	ValidationOK         = "ok"
+	ValidationUnknown    = "unknown"
+	ValidationRenamed    = "renamed"
+	ValidationDeprecated = "deprecated"
+)
+
+// ValidationResult represents the outcome of checking one model ID
+type ValidationResult struct {
+	ID     string
+	Status string
+	Detail string // Replacement ID, expiration date, or closest known ID
+}
+
+// Failed reports whether the result should fail validation
+// Deprecated models still exist and are reported as warnings only
+func (r ValidationResult) Failed() bool {
+	return r.Status == ValidationUnknown || r.Status == ValidationRenamed
+}
+
+// ReadModelIDs reads model IDs one per line, skipping blank lines and # comments
+func ReadModelIDs(r io.Reader) ([]string, error) {
+	var ids []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+		line = strings.TrimSpace(line)
+		if line != "" {
+			ids = append(ids, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read model IDs: %w", err)
+	}
+	return ids, nil
+}
+
+// ValidateModelIDs checks each ID against the catalog
+func ValidateModelIDs(ids []string, models []catalog.Model) []ValidationResult {
+	byID := make(map[string]catalog.Model, len(models))
+	for _, model := range models {
+		byID[model.ID] = model
+	}
+
+	results := make([]ValidationResult, 0, len(ids))
+	for _, id := range ids {
+		result := ValidationResult{ID: id, Status: ValidationOK}
+
+		if model, ok := byID[id]; ok {
+			if model.ExpirationDate != "" {
+				result.Status = ValidationDeprecated
+				result.Detail = model.ExpirationDate
+			}
+		} else if newID, ok := RenamedModelID(id); ok {
+			result.Status = ValidationRenamed
+			result.Detail = newID
+		} else {
+			result.Status = ValidationUnknown
+			if suggestions := SuggestModelIDs(id, models, 1); len(suggestions) > 0 {
+				result.Detail = suggestions[0]
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// DisplayValidationResults prints one line per checked ID
+func DisplayValidationResults(results []ValidationResult) {
+	for _, result := range results {
+		switch result.Status {
+		case ValidationRenamed:
+			fmt.Printf("%-10s %s -> %s\n", result.Status, result.ID, result.Detail)
+		case ValidationDeprecated:
+			fmt.Printf("%-10s %s (expires %s)\n", result.Status, result.ID, result.Detail)
+		case ValidationUnknown:
+			if result.Detail != "" {
+				fmt.Printf("%-10s %s (did you mean %s?)\n", result.Status, result.ID, result.Detail)
+			} else {
+				fmt.Printf("%-10s %s\n", result.Status, result.ID)
+			}
+		default:
+			fmt.Printf("%-10s %s\n", result.Status, result.ID)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+func TestReadModelIDs(t *testing.T) {
+	in := "# models in use\nopenai/gpt-4o\n\n  anthropic/claude-sonnet-4  # chat\n"
+	got, err := ReadModelIDs(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"openai/gpt-4o", "anthropic/claude-sonnet-4"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestValidateModelIDs(t *testing.T) {
+	models := []catalog.Model{
+		{ID: "openai/gpt-4o"},
+		{ID: "openai/gpt-4-turbo"},
+		{ID: "google/gemini-pro", ExpirationDate: "2025-06-01"},
+	}
+	tests := []struct {
+		id   string
+		want ValidationResult
+	}{
+		{"openai/gpt-4o", ValidationResult{ID: "openai/gpt-4o", Status: ValidationOK}},
+		{"google/gemini-pro", ValidationResult{ID: "google/gemini-pro", Status: ValidationDeprecated, Detail: "2025-06-01"}},
+		{"openai/gpt-4-turbo-preview", ValidationResult{ID: "openai/gpt-4-turbo-preview", Status: ValidationRenamed, Detail: "openai/gpt-4-turbo"}},
+		{"gpt-4-turbo-preview", ValidationResult{ID: "gpt-4-turbo-preview", Status: ValidationRenamed, Detail: "openai/gpt-4-turbo"}},
+		{"openai/gpt-4p", ValidationResult{ID: "openai/gpt-4p", Status: ValidationUnknown, Detail: "openai/gpt-4o"}},
+		{"mistral/unrelated-model-name", ValidationResult{ID: "mistral/unrelated-model-name", Status: ValidationUnknown}},
+	}
+	for _, tt := range tests {
+		got := ValidateModelIDs([]string{tt.id}, models)
+		if len(got) != 1 || got[0] != tt.want {
+			t.Errorf("ValidateModelIDs(%q) = %+v, want %+v", tt.id, got, tt.want)
+		}
+	}
+}
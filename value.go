@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// FormatBlendedPrice formats the blended price in USD per 1M tokens, or "-" if unpriced
+func FormatBlendedPrice(model catalog.Model, blend catalog.Blend) string {
+	price, ok := blend.Price(model)
+	if !ok {
+		return "-"
+	}
+	perMillion := price * 1000000
+	switch {
+	case perMillion == 0:
+		return FormatUSD("0") + "/1M"
+	case perMillion >= 100:
+		return FormatUSD(fmt.Sprintf("%.0f", perMillion)) + "/1M"
+	case perMillion >= 1:
+		return FormatUSD(fmt.Sprintf("%.2f", perMillion)) + "/1M"
+	}
+	return FormatUSD(fmt.Sprintf("%.3f", perMillion)) + "/1M"
+}
+
+// formatValue formats a per-dollar metric, e.g. "250K", "1.5M", or "free"
+func formatValue(v float64, ok bool) string {
+	switch {
+	case !ok:
+		return "-"
+	case math.IsInf(v, 1):
+		return "free"
+	case v >= 1e9:
+		return strings.TrimSuffix(fmt.Sprintf("%.1f", v/1e9), ".0") + "B"
+	}
+	return FormatContextLength(int(math.Round(v)))
+}
+
+// FormatModelValue formats tokens and context per dollar, or "-" if unpriced
+func FormatModelValue(model catalog.Model, blend catalog.Blend) string {
+	tokens, ok := catalog.TokensPerDollar(model, blend)
+	if !ok {
+		return "-"
+	}
+	if math.IsInf(tokens, 1) {
+		return "free"
+	}
+	context, contextOK := catalog.ContextPerDollar(model, blend)
+	return fmt.Sprintf("%s tok/$ %s ctx/$", formatValue(tokens, ok), formatValue(context, contextOK))
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/mkyutani/llmls/catalog"
+)
+
+// Webhook payload formats
+const (
+	WebhookJSON    = "json"
+	WebhookSlack   = "slack"
+	WebhookDiscord = "discord"
+)
+
+// ChangeNotification is the payload sent to webhooks and notify commands
+type ChangeNotification struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Added     []string  `json:"added"`
+	Removed   []string  `json:"removed"`
+	Changed   []string  `json:"changed"`
+}
+
+// Notifier delivers catalog change notifications
+type Notifier struct {
+	WebhookURL    string
+	WebhookFormat string // json, slack, or discord
+	Command       string // Shell command receiving the JSON payload on stdin
+}
+
+// HasChanges reports whether the diff contains any change
+func (d CatalogDiff) HasChanges() bool {
+	return len(d.Added) > 0 || len(d.Removed) > 0 || len(d.Changed) > 0
+}
+
+// modelIDs returns the IDs of models
+func modelIDs(models []catalog.Model) []string {
+	ids := make([]string, 0, len(models))
+	for _, model := range models {
+		ids = append(ids, model.ID)
+	}
+	return ids
+}
+
+// NewChangeNotification builds a notification payload from a diff
+func NewChangeNotification(diff CatalogDiff, checkedAt time.Time) ChangeNotification {
+	return ChangeNotification{
+		CheckedAt: checkedAt.UTC(),
+		Added:     modelIDs(diff.Added),
+		Removed:   modelIDs(diff.Removed),
+		Changed:   modelIDs(diff.Changed),
+	}
+}
+
+// Text renders the notification as a short human-readable message
+func (n ChangeNotification) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "llmls: %d added, %d removed, %d changed", len(n.Added), len(n.Removed), len(n.Changed))
+	for _, id := range n.Added {
+		fmt.Fprintf(&b, "\n+ %s", id)
+	}
+	for _, id := range n.Removed {
+		fmt.Fprintf(&b, "\n- %s", id)
+	}
+	for _, id := range n.Changed {
+		fmt.Fprintf(&b, "\n~ %s", id)
+	}
+	return b.String()
+}
+
+// Notify sends the notification to the configured webhook and command
+// Both are attempted; the first error is returned
+// Dry runs only print what would be notified; the webhook URL is left out
+// since chat webhooks carry their secret in the path
+func (nt Notifier) Notify(n ChangeNotification) error {
+	if dryRun {
+		if nt.WebhookURL != "" {
+			fmt.Fprintf(os.Stderr, "[dry-run] post %s webhook notification\n", nt.WebhookFormat)
+		}
+		if nt.Command != "" {
+			fmt.Fprintf(os.Stderr, "[dry-run] run notify command: %s\n", nt.Command)
+		}
+		return nil
+	}
+	var firstErr error
+	if nt.WebhookURL != "" {
+		if err := nt.postWebhook(n); err != nil {
+			firstErr = err
+		}
+	}
+	if nt.Command != "" {
+		if err := nt.runCommand(n); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// postWebhook POSTs the notification in the configured format
+func (nt Notifier) postWebhook(n ChangeNotification) error {
+	var payload interface{}
+	switch nt.WebhookFormat {
+	case WebhookSlack:
+		payload = map[string]string{"text": n.Text()}
+	case WebhookDiscord:
+		payload = map[string]string{"content": n.Text()}
+	default:
+		payload = n
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Post(nt.WebhookURL, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// runCommand runs the notify command with the JSON payload on stdin
+func (nt Notifier) runCommand(n ChangeNotification) error {
+	data, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", nt.Command)
+	} else {
+		cmd = exec.Command("sh", "-c", nt.Command)
+	}
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("notify command failed: %w", err)
+	}
+	return nil
+}
+
+// CheckForChanges fetches the catalog and compares it with the latest snapshot
+// A new snapshot is saved when there is no snapshot yet or the catalog changed
+func CheckForChanges(sourceConfig *SourceConfig) (CatalogDiff, error) {
+	var tunnels TunnelSet
+	current, err := sourceConfig.FetchSnapshot(&tunnels)
+	tunnels.Close()
+	if err != nil {
+		return CatalogDiff{}, err
+	}
+
+	paths, err := ListSnapshotFiles()
+	if err != nil {
+		return CatalogDiff{}, err
+	}
+	if len(paths) == 0 {
+		// First run: record a baseline without reporting every model as added
+		if _, err := SaveSnapshot(current); err != nil {
+			return CatalogDiff{}, err
+		}
+		return CatalogDiff{}, nil
+	}
+
+	snapshot, err := LoadSnapshot(paths[len(paths)-1])
+	if err != nil {
+		return CatalogDiff{}, err
+	}
+
+	diff := CompareSnapshots(snapshot, current)
+	if diff.HasChanges() {
+		if _, err := SaveSnapshot(current); err != nil {
+			return diff, err
+		}
+	}
+	return diff, nil
+}
+
+// DisplayCatalogDiff prints one line per changed model, prefixed with +, -, or ~
+func DisplayCatalogDiff(diff CatalogDiff, checkedAt time.Time) {
+	stamp := checkedAt.Format("2006-01-02 15:04:05")
+	for _, model := range diff.Added {
+		fmt.Printf("%s + %s\n", stamp, model.ID)
+	}
+	for _, model := range diff.Removed {
+		fmt.Printf("%s - %s\n", stamp, model.ID)
+	}
+	for _, model := range diff.Changed {
+		fmt.Printf("%s ~ %s\n", stamp, model.ID)
+	}
+}
+
+// WatchConfig holds the watch loop settings shared by the watch and daemon subcommands
+type WatchConfig struct {
+	Interval    time.Duration
+	Notifier    Notifier
+	Mail        MailConfig    // Recipients of the scheduled report; none disables it
+	ReportEvery time.Duration // Time between scheduled reports
+	Format      string        // Change format of watch: text or github; daemon leaves it empty
+}
+
+// RegisterFlags adds the watch loop flags to fs
+func (c *WatchConfig) RegisterFlags(fs *flag.FlagSet) {
+	fs.DurationVar(&c.Interval, "interval", time.Hour, "Time between catalog checks")
+	fs.StringVar(&c.Notifier.WebhookURL, "notify-webhook", "", "URL to POST change notifications to")
+	fs.StringVar(&c.Notifier.WebhookFormat, "webhook-format", WebhookJSON, "Webhook payload format: json, slack, discord")
+	fs.StringVar(&c.Notifier.Command, "notify-cmd", "", "Shell command run on changes with the JSON payload on stdin")
+	fs.DurationVar(&c.ReportEvery, "report-every", defaultReportPeriod, "Time between reports mailed to --email")
+	c.Mail.RegisterFlags(fs)
+}
+
+// Validate checks the watch loop settings
+func (c *WatchConfig) Validate() error {
+	switch c.Notifier.WebhookFormat {
+	case WebhookJSON, WebhookSlack, WebhookDiscord:
+	default:
+		return fmt.Errorf("invalid webhook format: %s", c.Notifier.WebhookFormat)
+	}
+	if c.Interval <= 0 {
+		return fmt.Errorf("interval must be positive: %s", c.Interval)
+	}
+	if c.Format != "" {
+		if err := ValidateResultFormat(c.Format); err != nil {
+			return err
+		}
+	}
+	if c.ReportEvery <= 0 {
+		return fmt.Errorf("report period must be positive: %s", c.ReportEvery)
+	}
+	return c.Mail.Validate()
+}
+
+// RunWatch checks for catalog changes every interval, printing and notifying on changes,
+// and mails a report every ReportEvery when --email is set
+// Errors are reported to stderr and the loop continues; with once, it returns
+// after one check, with the error of a failed check so cron jobs can detect it
+func RunWatch(sourceConfig *SourceConfig, c WatchConfig, once bool) error {
+	for {
+		checkedAt := time.Now()
+		diff, err := CheckForChanges(sourceConfig)
+		if err != nil {
+			if once {
+				return err
+			}
+			// Keep watching through transient failures
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		} else if diff.HasChanges() {
+			if c.Format == FormatGitHub {
+				PrintAnnotations(CatalogDiffAnnotations(diff))
+			} else {
+				DisplayCatalogDiff(diff, checkedAt)
+			}
+			if err := c.Notifier.Notify(NewChangeNotification(diff, checkedAt)); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		}
+
+		if c.Mail.To != "" {
+			if err := SendScheduledReport(sourceConfig, c); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: report: %v\n", err)
+			}
+		}
+
+		if once {
+			return nil
+		}
+		time.Sleep(c.Interval)
+	}
+}
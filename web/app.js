@@ -0,0 +1,231 @@
+// Web UI of llmls serve: a thin client of /v1/models and /openapi.json
+"use strict";
+
+const fields = ["pattern", "type", "variant", "series", "sort", "where"];
+const $ = (id) => document.getElementById(id);
+
+let models = [];
+let selected = null;
+let request = 0;
+
+// api fetches a JSON API path, asking for the bearer token when the server
+// requires one; the token is kept for the browser session only
+async function api(path) {
+  for (;;) {
+    const headers = {};
+    const token = sessionStorage.getItem("llmls-token");
+    if (token) {
+      headers.Authorization = "Bearer " + token;
+    }
+    const resp = await fetch(path, { headers });
+    if (resp.status === 401) {
+      const entered = prompt("This llmls server requires a token (LLMLS_SERVE_TOKEN):");
+      if (!entered) {
+        throw new Error("A token is required to browse this catalog.");
+      }
+      sessionStorage.setItem("llmls-token", entered.trim());
+      continue;
+    }
+    if (!resp.ok) {
+      const text = (await resp.text()).trim();
+      throw new Error(text || resp.status + " " + resp.statusText);
+    }
+    return resp.json();
+  }
+}
+
+// fillEnums adds the accepted values of the type and variant parameters,
+// as published in the OpenAPI document
+async function fillEnums() {
+  const doc = await api("openapi.json");
+  for (const param of doc.paths["/v1/models"].get.parameters) {
+    const select = $(param.name);
+    if (!select || select.tagName !== "SELECT" || !param.schema.enum) {
+      continue;
+    }
+    for (const value of param.schema.enum) {
+      select.add(new Option(value, value));
+    }
+  }
+}
+
+// queryString builds the /v1/models query from the form
+function queryString() {
+  const params = new URLSearchParams();
+  for (const name of fields) {
+    let value = $(name).value.trim();
+    if (name === "pattern" && value && !/[*?\/]/.test(value)) {
+      value = "*" + value + "*";
+    }
+    if (name === "sort" && $("reverse").checked) {
+      value += isDescending(value) ? ":asc" : ":desc";
+    }
+    if (value) {
+      params.set(name, value);
+    }
+  }
+  return params.toString();
+}
+
+// isDescending reports whether a sort key lists the largest values first by default
+function isDescending(key) {
+  return !["price", "provider", "name"].includes(key);
+}
+
+async function load() {
+  const current = ++request;
+  const query = queryString();
+  history.replaceState(null, "", query ? "#" + query : location.pathname);
+  try {
+    const result = await api("v1/models?" + query);
+    if (current !== request) {
+      return; // A newer query is on its way
+    }
+    models = result;
+    $("error").hidden = true;
+  } catch (err) {
+    if (current !== request) {
+      return;
+    }
+    models = [];
+    $("error").textContent = err.message;
+    $("error").hidden = false;
+  }
+  render();
+}
+
+function render() {
+  $("count").textContent = models.length === 1 ? "1 model" : models.length + " models";
+  const rows = models.map((model) => {
+    const row = document.createElement("tr");
+    row.classList.toggle("selected", selected === model.id);
+    for (const [text, numeric] of [
+      [model.id, false],
+      [provider(model), false],
+      [model.created ? new Date(model.created * 1000).toISOString().slice(0, 10) : "", false],
+      [model.context_length ? model.context_length.toLocaleString() : "", true],
+      [perMillion(model.pricing.prompt), true],
+      [perMillion(model.pricing.completion), true],
+    ]) {
+      const cell = row.insertCell();
+      cell.textContent = text;
+      cell.classList.toggle("num", numeric);
+    }
+    row.addEventListener("click", () => show(model));
+    return row;
+  });
+  $("models").replaceChildren(...rows);
+}
+
+function provider(model) {
+  return model.id.includes("/") ? model.id.split("/")[0] : "";
+}
+
+// perMillion formats a per-token price in dollars per million tokens
+function perMillion(price) {
+  const n = parseFloat(price);
+  if (isNaN(n) || n < 0) {
+    return "";
+  }
+  return n === 0 ? "free" : "$" + (n * 1e6).toFixed(2);
+}
+
+// show opens the detail drawer of a model
+function show(model) {
+  selected = model.id;
+  $("detail-id").textContent = model.id;
+  const items = [
+    ["Name", model.name],
+    ["Type", model.type],
+    ["Created", model.created ? new Date(model.created * 1000).toLocaleDateString() : ""],
+    ["Context", model.context_length ? model.context_length.toLocaleString() + " tokens" : ""],
+    ["Max output", model.top_provider && model.top_provider.max_completion_tokens
+      ? model.top_provider.max_completion_tokens.toLocaleString() + " tokens" : ""],
+    ["Input", (model.architecture.input_modalities || []).join(", ")],
+    ["Output", (model.architecture.output_modalities || []).join(", ")],
+    ["Tokenizer", model.architecture.tokenizer],
+    ["Input price", perMillion(model.pricing.prompt) && perMillion(model.pricing.prompt) + " per 1M tokens"],
+    ["Output price", perMillion(model.pricing.completion) && perMillion(model.pricing.completion) + " per 1M tokens"],
+    ["Expires", model.expiration_date],
+    ["Hugging Face", model.hf_stats
+      ? model.hf_stats.downloads.toLocaleString() + " downloads, " + model.hf_stats.likes.toLocaleString() + " likes" : ""],
+    ["Sources", (model.sources || []).map((s) => s.source).join(", ")],
+  ];
+  const nodes = [];
+  for (const [label, value] of items) {
+    if (!value) {
+      continue;
+    }
+    const dt = document.createElement("dt");
+    const dd = document.createElement("dd");
+    dt.textContent = label;
+    dd.textContent = value;
+    nodes.push(dt, dd);
+  }
+  if (/^https?:\/\//.test(model.url || "")) {
+    const dt = document.createElement("dt");
+    const dd = document.createElement("dd");
+    const link = document.createElement("a");
+    dt.textContent = "Page";
+    link.href = model.url;
+    link.textContent = model.url;
+    link.target = "_blank";
+    link.rel = "noopener noreferrer";
+    dd.append(link);
+    nodes.push(dt, dd);
+  }
+  $("detail").replaceChildren(...nodes);
+  $("detail-description").textContent = model.description || "";
+  $("drawer").hidden = false;
+  render();
+}
+
+function hide() {
+  selected = null;
+  $("drawer").hidden = true;
+  render();
+}
+
+// restore fills the form from a shared link
+function restore() {
+  const params = new URLSearchParams(location.hash.slice(1));
+  for (const name of fields) {
+    let value = params.get(name) || "";
+    if (name === "pattern") {
+      value = value.replace(/^\*(.*)\*$/, "$1");
+    }
+    if (name === "sort") {
+      const [key, direction] = value.split(":");
+      value = key || "created";
+      $("reverse").checked = !!direction && (direction === "desc") !== isDescending(key);
+    }
+    const input = $(name);
+    if (input.tagName === "SELECT" && value && ![...input.options].some((o) => o.value === value)) {
+      input.add(new Option(value, value));
+    }
+    input.value = value;
+  }
+}
+
+let timer;
+$("query").addEventListener("input", () => {
+  clearTimeout(timer);
+  timer = setTimeout(load, 250);
+});
+$("query").addEventListener("submit", (event) => {
+  event.preventDefault();
+  load();
+});
+$("close").addEventListener("click", hide);
+document.addEventListener("keydown", (event) => {
+  if (event.key === "Escape") {
+    hide();
+  }
+});
+
+fillEnums()
+  .catch(() => {}) // The listing reports the error
+  .finally(() => {
+    restore();
+    load();
+  });
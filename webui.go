@@ -0,0 +1,29 @@
+package main
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// webFiles are the static assets of the web UI of llmls serve
+//
+//go:embed web
+var webFiles embed.FS
+
+// webUI serves the web UI assets, with index.html at /
+var webUI = func() http.Handler {
+	root, err := fs.Sub(webFiles, "web")
+	if err != nil {
+		panic(err) // The embedded directory always exists
+	}
+	return http.FileServer(http.FS(root))
+}()
+
+// handleWebUI serves the single-page web UI; it only reads the catalog
+// through the JSON API, so it shows what any other API client would see
+func handleWebUI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Security-Policy", "default-src 'self'; img-src 'self' data:")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	webUI.ServeHTTP(w, r)
+}